@@ -0,0 +1,31 @@
+// Package auditcmd implements the `gorisk audit` subcommand.
+package auditcmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/1homsi/gorisk/internal/audit"
+)
+
+// Run executes the audit subcommand and returns an exit code: 0 when the
+// log's hash chain is intact, 1 when it is broken, 2 on usage or read errors.
+func Run(args []string) int {
+	if len(args) != 2 || args[0] != "verify" {
+		fmt.Fprintln(os.Stderr, "usage: gorisk audit verify <file>")
+		return 2
+	}
+	path := args[1]
+	if _, err := os.Stat(path); err != nil {
+		fmt.Fprintln(os.Stderr, "audit:", err)
+		return 2
+	}
+
+	n, err := audit.Verify(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "audit: %s: %v\n", path, err)
+		return 1
+	}
+	fmt.Printf("%s: %d entries, chain intact\n", path, n)
+	return 0
+}
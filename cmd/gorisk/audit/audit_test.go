@@ -0,0 +1,42 @@
+package auditcmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/1homsi/gorisk/internal/audit"
+)
+
+func TestRunVerify(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	for _, verdict := range []string{"pass", "fail"} {
+		if err := audit.Append(path, audit.Entry{GraphChecksum: "sum", Verdict: verdict}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if code := Run([]string{"verify", path}); code != 0 {
+		t.Errorf("verify intact log = %d, want 0", code)
+	}
+
+	// Rewriting the first verdict breaks the second entry's link.
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tampered := strings.Replace(string(data), `"verdict":"pass"`, `"verdict":"fail"`, 1)
+	if err := os.WriteFile(path, []byte(tampered), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if code := Run([]string{"verify", path}); code != 1 {
+		t.Errorf("verify tampered log = %d, want 1", code)
+	}
+
+	if code := Run([]string{"verify", filepath.Join(t.TempDir(), "missing.jsonl")}); code != 2 {
+		t.Errorf("verify missing log = %d, want 2", code)
+	}
+	if code := Run([]string{"verify"}); code != 2 {
+		t.Errorf("verify without a file = %d, want 2", code)
+	}
+}
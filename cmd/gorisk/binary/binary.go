@@ -0,0 +1,110 @@
+// Package binarycmd implements the `gorisk binary` subcommand.
+package binarycmd
+
+import (
+	"context"
+	"debug/buildinfo"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/1homsi/gorisk/internal/health"
+	"github.com/1homsi/gorisk/internal/report"
+)
+
+// BinaryReport is the JSON output of `gorisk binary`.
+type BinaryReport struct {
+	Binary    string                `json:"binary"`
+	GoVersion string                `json:"go_version"`
+	Main      string                `json:"main"`
+	Modules   []health.ModuleRef    `json:"modules"`
+	Health    []report.HealthReport `json:"health"`
+}
+
+// Run executes the binary subcommand and returns an exit code.
+func Run(args []string) int {
+	fs := flag.NewFlagSet("binary", flag.ExitOnError)
+	jsonOut := fs.Bool("json", false, "JSON output")
+	healthTimeout := fs.Duration("health-timeout", 0, "overall deadline for health scoring (0 = no limit)")
+	fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "usage: gorisk binary [--json] [--health-timeout 30s] <path>")
+		return 2
+	}
+	path := fs.Arg(0)
+
+	info, mods, err := readModules(path)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "read build info:", err)
+		return 2
+	}
+
+	ctx := context.Background()
+	if *healthTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *healthTimeout)
+		defer cancel()
+	}
+	reports, timing := health.ScoreAll(ctx, mods)
+	if timing.TimedOut {
+		fmt.Fprintf(os.Stderr, "[WARN] health scoring timed out after %s; results are partial (%d/%d modules scored)\n",
+			*healthTimeout, timing.Scored, timing.ModuleCount)
+	}
+
+	if *jsonOut {
+		if mods == nil {
+			mods = []health.ModuleRef{}
+		}
+		if reports == nil {
+			reports = []report.HealthReport{}
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(BinaryReport{
+			Binary:    path,
+			GoVersion: info.GoVersion,
+			Main:      info.Main.Path,
+			Modules:   mods,
+			Health:    reports,
+		}); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 2
+		}
+		return 0
+	}
+
+	fmt.Fprintf(os.Stdout, "Binary: %s\n", path)
+	fmt.Fprintf(os.Stdout, "Main:   %s (%s)\n", info.Main.Path, info.GoVersion)
+	fmt.Fprintf(os.Stdout, "Modules: %d (capability detection requires source and is skipped)\n\n", len(mods))
+	report.WriteHealth(os.Stdout, reports)
+	fmt.Fprintf(os.Stdout, "\nhealth scoring: %s\n", timing.Total.Round(time.Millisecond))
+	return 0
+}
+
+// readModules reads the build info embedded in the Go binary at path and
+// returns the dependency modules it was linked against. A replaced module is
+// reported under its replacement when the replacement carries a version;
+// local directory replacements keep the original path and version.
+func readModules(path string) (*buildinfo.BuildInfo, []health.ModuleRef, error) {
+	info, err := buildinfo.ReadFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	seen := make(map[string]bool)
+	var mods []health.ModuleRef
+	for _, dep := range info.Deps {
+		ref := health.ModuleRef{Path: dep.Path, Version: dep.Version}
+		if dep.Replace != nil && dep.Replace.Version != "" {
+			ref = health.ModuleRef{Path: dep.Replace.Path, Version: dep.Replace.Version}
+		}
+		if seen[ref.Path] {
+			continue
+		}
+		seen[ref.Path] = true
+		mods = append(mods, ref)
+	}
+	return info, mods, nil
+}
@@ -0,0 +1,47 @@
+package binarycmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReadModules(t *testing.T) {
+	// The test binary itself is a Go binary built with module info and links
+	// gopkg.in/yaml.v3 through the capability pattern loader.
+	exe, err := os.Executable()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	info, mods, err := readModules(exe)
+	if err != nil {
+		t.Fatalf("readModules: %v", err)
+	}
+	if info.GoVersion == "" {
+		t.Error("expected Go version from build info")
+	}
+
+	found := false
+	for _, m := range mods {
+		if m.Path == "gopkg.in/yaml.v3" {
+			found = true
+			if m.Version == "" {
+				t.Error("expected yaml.v3 to carry a version")
+			}
+		}
+	}
+	if !found {
+		t.Errorf("expected gopkg.in/yaml.v3 among dependencies, got %+v", mods)
+	}
+}
+
+func TestReadModulesNotBinary(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "notes.txt")
+	if err := os.WriteFile(path, []byte("not a binary"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := readModules(path); err == nil {
+		t.Error("expected error for non-binary file")
+	}
+}
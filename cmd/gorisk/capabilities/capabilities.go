@@ -1,12 +1,21 @@
 package capabilities
 
 import (
+	"bytes"
 	"flag"
 	"fmt"
+	"io"
 	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
 
+	goadapter "github.com/1homsi/gorisk/internal/adapters/go"
 	"github.com/1homsi/gorisk/internal/analyzer"
 	"github.com/1homsi/gorisk/internal/capability"
+	"github.com/1homsi/gorisk/internal/graph"
 	"github.com/1homsi/gorisk/internal/report"
 )
 
@@ -15,8 +24,45 @@ func Run(args []string) int {
 	jsonOut := fs.Bool("json", false, "JSON output")
 	minRisk := fs.String("min-risk", "low", "minimum risk level to show: low|medium|high")
 	lang := fs.String("lang", "auto", "language analyzer: auto|go|node")
+	ignoreCaps := fs.String("ignore-capability", "", "comma-separated capabilities to drop from the report")
+	list := fs.Bool("list", false, "list every capability gorisk detects, with weight and example triggers, and exit")
+	perPlatform := fs.Bool("per-platform", false, "report, per Go package capability, which GOOS values enable it (build-constrained files analyzed per platform)")
+	platforms := fs.String("goos", strings.Join(goadapter.DefaultPlatforms, ","), "comma-separated GOOS values compared by --per-platform")
+	jsonCompact := fs.Bool("json-compact", false, "emit JSON on a single line without indentation (default: indented)")
+	changedOnly := fs.Bool("changed-only", false, "report only capabilities detected in files git reports as modified, staged, or untracked")
 	fs.Parse(args)
 
+	report.SetCompactJSON(*jsonCompact)
+	defer report.SetCompactJSON(false)
+
+	if *list {
+		catalog := capability.Catalog()
+		if *lang != "auto" {
+			for i := range catalog {
+				ex := catalog[i].Triggers[*lang]
+				catalog[i].Triggers = nil
+				if len(ex) > 0 {
+					catalog[i].Triggers = map[string][]string{*lang: ex}
+				}
+			}
+		}
+		if *jsonOut {
+			if err := report.NewJSONEncoder(os.Stdout).Encode(catalog); err != nil {
+				fmt.Fprintln(os.Stderr, "write output:", err)
+				return 2
+			}
+			return 0
+		}
+		writeCatalog(os.Stdout, catalog)
+		return 0
+	}
+
+	ignored, err := capability.ParseList(*ignoreCaps)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "ignore capability:", err)
+		return 2
+	}
+
 	dir, err := os.Getwd()
 	if err != nil {
 		fmt.Fprintln(os.Stderr, err)
@@ -33,9 +79,55 @@ func Run(args []string) int {
 		fmt.Fprintln(os.Stderr, "load graph:", err)
 		return 2
 	}
+	g.StripCapabilities(ignored)
 
+	if *changedOnly {
+		if *perPlatform {
+			fmt.Fprintln(os.Stderr, "--changed-only cannot be combined with --per-platform")
+			return 2
+		}
+		files, err := changedFiles(dir)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "changed-only:", err)
+			return 2
+		}
+		restrictToFiles(g, files)
+	}
+
+	if *perPlatform {
+		var goos []string
+		for _, p := range strings.Split(*platforms, ",") {
+			if p = strings.TrimSpace(p); p != "" {
+				goos = append(goos, p)
+			}
+		}
+		if len(goos) == 0 {
+			fmt.Fprintln(os.Stderr, "--goos: no platforms given")
+			return 2
+		}
+		graphs, err := platformGraphs(*lang, dir, goos)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "load graph:", err)
+			return 2
+		}
+		rows := platformRows(graphs, ignored)
+		if *jsonOut {
+			if err := report.NewJSONEncoder(os.Stdout).Encode(rows); err != nil {
+				fmt.Fprintln(os.Stderr, "write output:", err)
+				return 2
+			}
+			return 0
+		}
+		writePlatforms(os.Stdout, rows, goos)
+		return 0
+	}
+
+	depths := g.ModuleDepths()
 	var reports []report.CapabilityReport
 	for _, pkg := range g.Packages {
+		if *changedOnly && pkg.Capabilities.IsEmpty() {
+			continue
+		}
 		riskLevel := pkg.Capabilities.RiskLevel()
 		if !meetsMinRisk(riskLevel, *minRisk) {
 			continue
@@ -49,6 +141,7 @@ func Run(args []string) int {
 			Module:       modPath,
 			Capabilities: pkg.Capabilities,
 			RiskLevel:    riskLevel,
+			Depth:        depths[pkg.ImportPath],
 		})
 	}
 
@@ -69,6 +162,163 @@ func Run(args []string) int {
 	return 0
 }
 
+// changedFiles returns the absolute paths of the files beneath dir that git
+// reports as modified, staged, or untracked.
+func changedFiles(dir string) (map[string]bool, error) {
+	// Porcelain paths are relative to the repository root; the prefix is
+	// dir's own path within it.
+	prefix, err := exec.Command("git", "-C", dir, "rev-parse", "--show-prefix").Output()
+	if err != nil {
+		return nil, fmt.Errorf("git rev-parse: %w (not a git repository?)", err)
+	}
+	out, err := exec.Command("git", "-C", dir, "status", "--porcelain", "-z", "--untracked-files=all").Output()
+	if err != nil {
+		return nil, fmt.Errorf("git status: %w", err)
+	}
+
+	files := make(map[string]bool)
+	entries := bytes.Split(out, []byte{0})
+	for i := 0; i < len(entries); i++ {
+		entry := string(entries[i])
+		if len(entry) < 4 {
+			continue
+		}
+		status, path := entry[:2], entry[3:]
+		if status[0] == 'R' || status[0] == 'C' {
+			i++ // the next entry is the rename or copy source
+		}
+		rel, ok := strings.CutPrefix(path, strings.TrimSpace(string(prefix)))
+		if !ok {
+			continue
+		}
+		files[filepath.Join(dir, filepath.FromSlash(rel))] = true
+	}
+	return files, nil
+}
+
+// restrictToFiles narrows every package's capabilities to the evidence
+// recorded in files.
+func restrictToFiles(g *graph.DependencyGraph, files map[string]bool) {
+	for _, pkg := range g.Packages {
+		var kept capability.CapabilitySet
+		for _, c := range pkg.Capabilities.List() {
+			for _, ev := range pkg.Capabilities.Evidence[c] {
+				path := ev.File
+				if path != "" && !filepath.IsAbs(path) {
+					path = filepath.Join(pkg.Dir, path)
+				}
+				if path != "" && files[filepath.Clean(path)] {
+					kept.AddWithEvidence(c, ev)
+				}
+			}
+		}
+		pkg.Capabilities = kept
+	}
+}
+
 func meetsMinRisk(level, min string) bool {
 	return capability.RiskValue(level) >= capability.RiskValue(min)
 }
+
+// writeCatalog prints the capability catalog: one line per capability with
+// its weight and standalone risk, then its example triggers per language.
+func writeCatalog(w io.Writer, catalog []capability.CapInfo) {
+	for _, c := range catalog {
+		fmt.Fprintf(w, "%-16s weight %-3d %-6s  %s\n", c.Name, c.Weight, c.Risk, c.Description)
+		langs := make([]string, 0, len(c.Triggers))
+		for lang := range c.Triggers {
+			langs = append(langs, lang)
+		}
+		sort.Strings(langs)
+		for _, lang := range langs {
+			fmt.Fprintf(w, "    %-10s %s\n", lang, strings.Join(c.Triggers[lang], ", "))
+		}
+	}
+}
+
+// platformRow is one capability of one Go package and the GOOS values
+// whose build of the package has it.
+type platformRow struct {
+	Package    string   `json:"package"`
+	Module     string   `json:"module,omitempty"`
+	Capability string   `json:"capability"`
+	Platforms  []string `json:"platforms"`
+}
+
+// platformGraphs loads the dependency graph of dir once per GOOS in goos,
+// as a cgo-enabled build for that target: a package imported only on some
+// platforms (golang.org/x/sys/windows) is only in their graphs.
+func platformGraphs(lang, dir string, goos []string) (map[string]*graph.DependencyGraph, error) {
+	graphs := make(map[string]*graph.DependencyGraph, len(goos))
+	for _, target := range goos {
+		a, err := analyzer.ForLangWith(lang, dir, analyzer.Options{
+			Go: goadapter.Config{Env: []string{"GOOS=" + target, "CGO_ENABLED=1"}},
+		})
+		if err != nil {
+			return nil, err
+		}
+		g, err := a.Load(dir)
+		if err != nil {
+			return nil, fmt.Errorf("GOOS=%s: %w", target, err)
+		}
+		graphs[target] = g
+	}
+	return graphs, nil
+}
+
+// platformRows analyzes every Go package once for each GOOS whose graph in
+// graphs includes it, and returns its capabilities with the platforms that
+// enable them, sorted by package and capability.
+func platformRows(graphs map[string]*graph.DependencyGraph, ignored map[string]bool) []platformRow {
+	pkgs := make(map[string]*graph.Package)
+	on := make(map[string][]string)
+	for goos, g := range graphs {
+		for _, pkg := range g.Packages {
+			if pkg.Dir == "" || !slices.ContainsFunc(pkg.GoFiles, isGoFile) {
+				continue
+			}
+			pkgs[pkg.ImportPath] = pkg
+			on[pkg.ImportPath] = append(on[pkg.ImportPath], goos)
+		}
+	}
+
+	var rows []platformRow
+	for path, pkg := range pkgs {
+		modPath := ""
+		if pkg.Module != nil {
+			modPath = pkg.Module.Path
+		}
+		for c, platforms := range goadapter.PlatformsByCapability(goadapter.PlatformCapabilities(pkg.Dir, on[path])) {
+			if ignored[c] {
+				continue
+			}
+			rows = append(rows, platformRow{Package: path, Module: modPath, Capability: c, Platforms: platforms})
+		}
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		if rows[i].Package != rows[j].Package {
+			return rows[i].Package < rows[j].Package
+		}
+		return rows[i].Capability < rows[j].Capability
+	})
+	return rows
+}
+
+func isGoFile(name string) bool { return strings.HasSuffix(name, ".go") }
+
+// writePlatforms prints one line per package capability. Capabilities
+// every platform has are marked "all"; the rest name their platforms.
+func writePlatforms(w io.Writer, rows []platformRow, goos []string) {
+	if len(rows) == 0 {
+		fmt.Fprintln(w, "no capabilities detected")
+		return
+	}
+	fmt.Fprintf(w, "%-50s %-16s %s\n", "PACKAGE", "CAPABILITY", "PLATFORMS")
+	for _, r := range rows {
+		on := strings.Join(r.Platforms, ",")
+		if len(r.Platforms) == len(goos) {
+			on = "all"
+		}
+		fmt.Fprintf(w, "%-50s %-16s %s\n", r.Package, r.Capability, on)
+	}
+}
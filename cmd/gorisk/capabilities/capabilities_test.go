@@ -1,9 +1,20 @@
 package capabilities
 
 import (
+	"bytes"
+	"encoding/json"
+	"io"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
 	"testing"
+
+	"github.com/1homsi/gorisk/internal/capability"
+	"github.com/1homsi/gorisk/internal/graph"
+	"github.com/1homsi/gorisk/internal/report"
 )
 
 func TestRun(t *testing.T) {
@@ -201,3 +212,137 @@ func TestMeetsMinRisk(t *testing.T) {
 		})
 	}
 }
+
+func TestRunList(t *testing.T) {
+	run := func(args ...string) string {
+		r, w, err := os.Pipe()
+		if err != nil {
+			t.Fatal(err)
+		}
+		stdout := os.Stdout
+		os.Stdout = w
+		done := make(chan string)
+		go func() {
+			var buf bytes.Buffer
+			io.Copy(&buf, r) //nolint:errcheck
+			done <- buf.String()
+		}()
+		code := Run(args)
+		w.Close()
+		os.Stdout = stdout
+		out := <-done
+		if code != 0 {
+			t.Fatalf("Run(%v) = %d, want 0", args, code)
+		}
+		return out
+	}
+
+	out := run("--list")
+	for _, c := range capability.Catalog() {
+		if !strings.Contains(out, c.Name+" ") || !strings.Contains(out, c.Description) {
+			t.Errorf("--list output is missing %s:\n%s", c.Name, out)
+		}
+	}
+
+	var listed []capability.CapInfo
+	if err := json.Unmarshal([]byte(run("--list", "--json", "--lang", "node")), &listed); err != nil {
+		t.Fatal(err)
+	}
+	if len(listed) != len(capability.Catalog()) {
+		t.Errorf("--json listed %d capabilities, want %d", len(listed), len(capability.Catalog()))
+	}
+	for _, c := range listed {
+		for lang := range c.Triggers {
+			if lang != "node" {
+				t.Errorf("%s: --lang node listed %s triggers", c.Name, lang)
+			}
+		}
+	}
+}
+
+func TestRunChangedOnly(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	dir := t.TempDir()
+	git := func(args ...string) {
+		cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+		cmd.Env = append(os.Environ(), "GIT_AUTHOR_NAME=t", "GIT_AUTHOR_EMAIL=t@example.com",
+			"GIT_COMMITTER_NAME=t", "GIT_COMMITTER_EMAIL=t@example.com")
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	write := func(name, src string) {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(src), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("package.json", `{"name": "app", "version": "1.0.0"}`)
+	write("package-lock.json", `{"name": "app", "version": "1.0.0", "lockfileVersion": 3, "packages": {"": {"name": "app", "version": "1.0.0"}}}`)
+	write("index.js", "const fs = require('fs');\nfs.readFileSync('a.txt');\n")
+	write("run.js", "module.exports = 1;\n")
+	git("init", "-q")
+	git("add", ".")
+	git("commit", "-q", "-m", "init")
+	write("run.js", "const cp = require('child_process');\ncp.exec('id');\n")
+
+	origDir, _ := os.Getwd()
+	defer os.Chdir(origDir)
+	os.Chdir(dir)
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	done := make(chan []byte)
+	go func() {
+		var buf bytes.Buffer
+		io.Copy(&buf, r) //nolint:errcheck
+		done <- buf.Bytes()
+	}()
+	Run([]string{"--lang", "node", "--changed-only", "--json"})
+	w.Close()
+	os.Stdout = stdout
+
+	var reports []report.CapabilityReport
+	if err := json.Unmarshal(<-done, &reports); err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, r := range reports {
+		for c := range r.Capabilities.Evidence {
+			got = append(got, c)
+		}
+	}
+	if !slices.Contains(got, capability.CapExec) {
+		t.Errorf("capabilities = %v, want exec from the modified run.js", got)
+	}
+	if slices.Contains(got, capability.CapFSRead) {
+		t.Errorf("capabilities = %v, want no fs:read from the unchanged index.js", got)
+	}
+}
+
+func TestPlatformRowsPerPlatformGraphs(t *testing.T) {
+	dir := t.TempDir()
+	src := "package winsvc\n\nimport \"os/exec\"\n\nfunc Start() { exec.Command(\"sc\", \"start\").Run() }\n"
+	if err := os.WriteFile(filepath.Join(dir, "svc_windows.go"), []byte(src), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	// Only the windows build imports the package.
+	linux, windows := graph.NewDependencyGraph(), graph.NewDependencyGraph()
+	windows.Packages["example.com/winsvc"] = &graph.Package{
+		ImportPath: "example.com/winsvc",
+		Dir:        dir,
+		GoFiles:    []string{"svc_windows.go"},
+	}
+
+	rows := platformRows(map[string]*graph.DependencyGraph{"linux": linux, "windows": windows}, nil)
+	want := []platformRow{{Package: "example.com/winsvc", Capability: "exec", Platforms: []string{"windows"}}}
+	if !reflect.DeepEqual(rows, want) {
+		t.Errorf("platformRows() = %+v, want %+v", rows, want)
+	}
+}
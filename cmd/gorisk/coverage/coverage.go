@@ -0,0 +1,140 @@
+// Package coveragecmd implements the `gorisk coverage` subcommand.
+package coveragecmd
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/1homsi/gorisk/internal/coverage"
+	"github.com/1homsi/gorisk/internal/graph"
+	"github.com/1homsi/gorisk/internal/report"
+)
+
+// CoverageReport is the JSON output of `gorisk coverage`.
+type CoverageReport struct {
+	Functions []coverage.Function `json:"functions"`
+	Total     int                 `json:"total"`
+	Exercised int                 `json:"exercised"`
+}
+
+// Run executes the coverage subcommand and returns an exit code.
+func Run(args []string) int {
+	fs := flag.NewFlagSet("coverage", flag.ExitOnError)
+	jsonOut := fs.Bool("json", false, "JSON output")
+	profile := fs.String("profile", "", "cover profile written by go test -coverprofile (default: run go test ./... to produce one)")
+	uncovered := fs.Bool("uncovered", false, "only list capability-bearing functions no test exercises")
+	fs.Parse(args)
+
+	dir, err := os.Getwd()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	profilePath := *profile
+	if profilePath == "" {
+		tmp, err := os.MkdirTemp("", "gorisk-coverage-*")
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 2
+		}
+		defer os.RemoveAll(tmp)
+		profilePath = filepath.Join(tmp, "cover.out")
+		cmd := exec.Command("go", "test", "-coverprofile="+profilePath, "./...")
+		cmd.Dir = dir
+		cmd.Stdout = os.Stderr
+		cmd.Stderr = os.Stderr
+		// Failing tests still write a profile; only a missing one is fatal.
+		if err := cmd.Run(); err != nil {
+			fmt.Fprintln(os.Stderr, "[WARN] go test:", err)
+		}
+	}
+
+	f, err := os.Open(profilePath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "open cover profile:", err)
+		return 2
+	}
+	blocks, err := coverage.ParseProfile(f)
+	f.Close()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	g, err := graph.Load(dir)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "load graph:", err)
+		return 2
+	}
+
+	var rep CoverageReport
+	for _, pkg := range g.Packages {
+		if pkg.Module == nil || !pkg.Module.Main || pkg.Dir == "" || len(pkg.GoFiles) == 0 {
+			continue
+		}
+		funcs, err := coverage.AnalyzePackage(pkg.ImportPath, pkg.Dir, pkg.GoFiles, blocks)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "[WARN] %s: %v\n", pkg.ImportPath, err)
+			continue
+		}
+		for _, fn := range funcs {
+			rep.Total++
+			if fn.Exercised() {
+				rep.Exercised++
+				if *uncovered {
+					continue
+				}
+			}
+			rep.Functions = append(rep.Functions, fn)
+		}
+	}
+	sort.Slice(rep.Functions, func(i, j int) bool {
+		a, b := rep.Functions[i], rep.Functions[j]
+		if a.Package != b.Package {
+			return a.Package < b.Package
+		}
+		return a.Line < b.Line
+	})
+
+	if *jsonOut {
+		if rep.Functions == nil {
+			rep.Functions = []coverage.Function{}
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(rep); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 2
+		}
+		return 0
+	}
+
+	palette := report.Colors()
+	red, green, reset := palette.Red, palette.Green, palette.Reset
+	for _, fn := range rep.Functions {
+		status := red + "UNTESTED" + reset
+		if fn.Exercised() {
+			status = green + fmt.Sprintf("%d/%d stmts", fn.Covered, fn.Statements) + reset
+		}
+		fmt.Printf("%-60s  %-24s  %s\n", fn.Package+"."+fn.Name, strings.Join(fn.Capabilities, ", "), status)
+		rel, err := filepath.Rel(dir, fn.File)
+		if err != nil {
+			rel = fn.File
+		}
+		fmt.Printf("  %s:%d\n", rel, fn.Line)
+	}
+	if rep.Total == 0 {
+		fmt.Println("no capability-bearing functions found")
+		return 0
+	}
+	fmt.Printf("\n%d of %d capability-bearing functions exercised by tests (%.0f%%)\n",
+		rep.Exercised, rep.Total, 100*float64(rep.Exercised)/float64(rep.Total))
+	return 0
+}
@@ -1,32 +1,82 @@
 package diff
 
 import (
+	"encoding/json"
 	"flag"
 	"fmt"
 	"os"
 	"strings"
 
+	"golang.org/x/mod/semver"
+
 	"github.com/1homsi/gorisk/internal/analyzer"
+	"github.com/1homsi/gorisk/internal/capability"
 	"github.com/1homsi/gorisk/internal/report"
+	"github.com/1homsi/gorisk/internal/upgrade"
 )
 
 func Run(args []string) int {
 	fs := flag.NewFlagSet("diff", flag.ExitOnError)
 	jsonOut := fs.Bool("json", false, "JSON output")
+	format := fs.String("format", "text", "text output format: text|unified")
 	lang := fs.String("lang", "auto", "language: auto|go|node")
+	nodeTrees := fs.Bool("node", false, "compare two installed node_modules trees (or project roots) instead of two versions")
+	policyFile := fs.String("policy", "", "policy JSON file; its high_risk_capabilities decide which added capabilities escalate")
+	toLatest := fs.String("to-latest", "", "diff this Go module's version pinned in go.mod/go.sum against the latest the go command resolves")
 	fs.Parse(args)
 
+	var highRisk capability.HighRisk
+	if *policyFile != "" {
+		hr, err := loadHighRisk(*policyFile)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "policy:", err)
+			return 2
+		}
+		highRisk = hr
+	}
+
+	if *format != "text" && *format != "unified" {
+		fmt.Fprintf(os.Stderr, "unknown --format %q (want text|unified)\n", *format)
+		return 2
+	}
+
+	if *toLatest != "" {
+		return runToLatest(*toLatest, highRisk, *jsonOut, *format)
+	}
+
+	if *nodeTrees {
+		if fs.NArg() < 2 {
+			fmt.Fprintln(os.Stderr, "usage: gorisk diff --node <old-node_modules> <new-node_modules>")
+			return 2
+		}
+		diffs, err := upgrade.NodeCapDiffer{}.DiffTrees(fs.Arg(0), fs.Arg(1))
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "diff:", err)
+			return 2
+		}
+		r := report.CapDiffReport{
+			Module:     "node_modules",
+			OldVersion: fs.Arg(0),
+			NewVersion: fs.Arg(1),
+		}
+		return writeReport(r, diffs, highRisk, *jsonOut, *format)
+	}
+
 	if fs.NArg() < 2 {
 		fmt.Fprintln(os.Stderr, "usage: gorisk diff <module@old> <module@new>")
 		return 2
 	}
 
+	if upgrade.IsModuleSource(fs.Arg(0)) || upgrade.IsModuleSource(fs.Arg(1)) {
+		return runSources(fs.Arg(0), fs.Arg(1), highRisk, *jsonOut, *format)
+	}
+
 	modulePath, oldVer, ok := splitAt(fs.Arg(0))
 	if !ok {
 		fmt.Fprintln(os.Stderr, "specify version: module@version")
 		return 2
 	}
-	_, newVer, ok := splitAt(fs.Arg(1))
+	newModulePath, newVer, ok := splitAt(fs.Arg(1))
 	if !ok {
 		fmt.Fprintln(os.Stderr, "specify version: module@version")
 		return 2
@@ -44,7 +94,19 @@ func Run(args []string) int {
 		return 2
 	}
 
-	diffs, err := features.CapDiff.DiffCapabilities(modulePath, oldVer, newVer)
+	var diffs []upgrade.CapDiff
+	if newModulePath == modulePath {
+		diffs, err = features.CapDiff.DiffCapabilities(modulePath, oldVer, newVer)
+	} else {
+		// Different paths only make sense for one Go module across a major
+		// version boundary (mod@v1 vs mod/v2@v2).
+		md, ok := features.CapDiff.(upgrade.ModuleCapDiffer)
+		if !ok || !upgrade.MajorVersionPair(modulePath, newModulePath) {
+			fmt.Fprintf(os.Stderr, "module paths differ: %s vs %s (only major-version paths like mod and mod/v2 can be compared)\n", modulePath, newModulePath)
+			return 2
+		}
+		diffs, err = md.DiffModules(modulePath, oldVer, newModulePath, newVer)
+	}
 	if err != nil {
 		fmt.Fprintln(os.Stderr, "diff:", err)
 		return 2
@@ -55,23 +117,132 @@ func Run(args []string) int {
 		OldVersion: oldVer,
 		NewVersion: newVer,
 	}
+	if newModulePath != modulePath {
+		r.NewModule = newModulePath
+	}
+	return writeReport(r, diffs, highRisk, *jsonOut, *format)
+}
+
+// runToLatest diffs the version of modulePath pinned by the module in the
+// working directory against the latest version the go command resolves.
+func runToLatest(modulePath string, highRisk capability.HighRisk, jsonOut bool, format string) int {
+	dir, err := os.Getwd()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	pinned, sum, err := upgrade.PinnedVersion(dir, modulePath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "pinned version:", err)
+		return 2
+	}
+	latest, err := upgrade.LatestVersion(modulePath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "latest version:", err)
+		return 2
+	}
+	if semver.Compare(latest, pinned) <= 0 {
+		fmt.Fprintf(os.Stderr, "%s@%s is already the latest version\n", modulePath, pinned)
+		return writeReport(report.CapDiffReport{Module: modulePath, OldVersion: pinned, NewVersion: latest}, nil, highRisk, jsonOut, format)
+	}
+
+	oldSrc, err := upgrade.DownloadModuleSource(modulePath, pinned, sum)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "download pinned:", err)
+		return 2
+	}
+	defer oldSrc.Close()
+	newSrc, err := upgrade.DownloadModuleSource(modulePath, latest, "")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "download latest:", err)
+		return 2
+	}
+	defer newSrc.Close()
+
+	diffs, breaking, err := upgrade.GoCapDiffer{}.DiffSources(oldSrc, newSrc)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "diff:", err)
+		return 2
+	}
+	r := report.CapDiffReport{
+		Module:     modulePath,
+		OldVersion: pinned,
+		NewVersion: latest,
+		Breaking:   breaking,
+	}
+	return writeReport(r, diffs, highRisk, jsonOut, format)
+}
+
+// runSources diffs two local Go module sources, each a module proxy .zip or
+// a directory, without network access.
+func runSources(oldArg, newArg string, highRisk capability.HighRisk, jsonOut bool, format string) int {
+	if !upgrade.IsModuleSource(oldArg) || !upgrade.IsModuleSource(newArg) {
+		fmt.Fprintln(os.Stderr, "compare a module .zip or directory with another, not with module@version")
+		return 2
+	}
+	oldSrc, err := upgrade.OpenModuleSource(oldArg)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "open old:", err)
+		return 2
+	}
+	defer oldSrc.Close()
+	newSrc, err := upgrade.OpenModuleSource(newArg)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "open new:", err)
+		return 2
+	}
+	defer newSrc.Close()
+
+	diffs, breaking, err := upgrade.GoCapDiffer{}.DiffSources(oldSrc, newSrc)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "diff:", err)
+		return 2
+	}
+	r := report.CapDiffReport{
+		Module:     oldSrc.Path,
+		OldVersion: sourceVersion(oldSrc, oldArg),
+		NewVersion: sourceVersion(newSrc, newArg),
+		Breaking:   breaking,
+	}
+	if newSrc.Path != oldSrc.Path {
+		r.NewModule = newSrc.Path
+	}
+	return writeReport(r, diffs, highRisk, jsonOut, format)
+}
+
+// sourceVersion labels a module source by its zip version, or by the
+// directory it was read from.
+func sourceVersion(m *upgrade.ModuleSource, arg string) string {
+	if m.Version != "" {
+		return m.Version
+	}
+	return arg
+}
+
+// writeReport adds diffs to r, prints it, and returns the exit code: 1 when
+// any package added a capability in highRisk, 0 otherwise.
+func writeReport(r report.CapDiffReport, diffs []upgrade.CapDiff, highRisk capability.HighRisk, jsonOut bool, format string) int {
 	for _, d := range diffs {
+		escalated := highRisk.Any(d.Added)
 		r.Diffs = append(r.Diffs, report.PackageCapDiff{
-			Package:   d.Package,
-			Added:     d.Added.List(),
-			Removed:   d.Removed.List(),
-			Escalated: d.Escalated,
+			Package:    d.Package,
+			OldPackage: d.OldPackage,
+			Added:      d.Added.List(),
+			Removed:    d.Removed.List(),
+			Escalated:  escalated,
 		})
-		if d.Escalated {
+		if escalated {
 			r.Escalated = true
 		}
 	}
 
-	if *jsonOut {
+	if jsonOut {
 		if err := report.WriteCapDiffJSON(os.Stdout, r); err != nil {
 			fmt.Fprintln(os.Stderr, "write output:", err)
 			return 2
 		}
+	} else if format == "unified" {
+		report.WriteCapDiffUnified(os.Stdout, r)
 	} else {
 		report.WriteCapDiff(os.Stdout, r)
 	}
@@ -82,6 +253,26 @@ func Run(args []string) int {
 	return 0
 }
 
+// loadHighRisk reads the high_risk_capabilities of the policy file at path.
+// Other policy fields are scan settings and are ignored here.
+func loadHighRisk(path string) (capability.HighRisk, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return capability.HighRisk{}, err
+	}
+	var p struct {
+		HighRiskCaps []string `json:"high_risk_capabilities"`
+	}
+	if err := json.Unmarshal(data, &p); err != nil {
+		return capability.HighRisk{}, fmt.Errorf("parse %s: %w", path, err)
+	}
+	hr, err := capability.ParseHighRisk(p.HighRiskCaps)
+	if err != nil {
+		return capability.HighRisk{}, fmt.Errorf("high_risk_capabilities: %w", err)
+	}
+	return hr, nil
+}
+
 func splitAt(s string) (left, right string, ok bool) {
 	at := strings.LastIndex(s, "@")
 	if at == -1 {
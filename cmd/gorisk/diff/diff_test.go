@@ -1,9 +1,18 @@
 package diff
 
 import (
+	"archive/zip"
+	"bytes"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
 	"testing"
+
+	"golang.org/x/mod/sumdb/dirhash"
 )
 
 func TestRun(t *testing.T) {
@@ -90,3 +99,149 @@ go 1.22
 		t.Error("Expected diff with invalid format to fail")
 	}
 }
+
+func TestRunNodeTrees(t *testing.T) {
+	before, after := t.TempDir(), t.TempDir()
+	for dir, src := range map[string]string{
+		before: "module.exports = (cmd) => cmd;\n",
+		after:  "const cp = require('child_process');\nmodule.exports = (cmd) => cp.exec(cmd);\n",
+	} {
+		pkgDir := filepath.Join(dir, "node_modules", "runner")
+		if err := os.MkdirAll(pkgDir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(pkgDir, "index.js"), []byte(src), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if code := Run([]string{"--node", "--json", before, after}); code != 1 {
+		t.Errorf("Run(--node) with an escalated package = %d, want 1", code)
+	}
+	if code := Run([]string{"--node", before, before}); code != 0 {
+		t.Errorf("Run(--node) on identical trees = %d, want 0", code)
+	}
+	if code := Run([]string{"--node", before}); code != 2 {
+		t.Errorf("Run(--node) with one tree = %d, want 2", code)
+	}
+}
+
+// moduleZip returns files as a module proxy zip for modPath@version.
+func moduleZip(t *testing.T, modPath, version string, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for rel, src := range files {
+		w, err := zw.Create(modPath + "@" + version + "/" + rel)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(src)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestRunToLatest(t *testing.T) {
+	const modPath = "example.com/tool"
+	gomod := "module " + modPath + "\n\ngo 1.22\n"
+	zips := map[string][]byte{
+		"v1.0.0": moduleZip(t, modPath, "v1.0.0", map[string]string{
+			"go.mod":  gomod,
+			"tool.go": "package tool\n\nfunc Run(name string) error { return nil }\n",
+		}),
+		"v1.2.0": moduleZip(t, modPath, "v1.2.0", map[string]string{
+			"go.mod":  gomod,
+			"tool.go": "package tool\n\nimport \"os/exec\"\n\nfunc Run(name string) error { return exec.Command(name).Run() }\n",
+		}),
+	}
+	var mu sync.Mutex
+	var fetched []string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		fetched = append(fetched, r.URL.Path)
+		mu.Unlock()
+		file, ok := strings.CutPrefix(r.URL.Path, "/"+modPath+"/@v/")
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		version, ext := strings.TrimSuffix(file, filepath.Ext(file)), filepath.Ext(file)
+		switch {
+		case file == "list":
+			w.Write([]byte("v1.0.0\nv1.2.0\n")) //nolint:errcheck
+		case zips[version] == nil:
+			http.NotFound(w, r)
+		case ext == ".info":
+			w.Write([]byte(`{"Version":"` + version + `","Time":"2026-01-02T00:00:00Z"}`)) //nolint:errcheck
+		case ext == ".mod":
+			w.Write([]byte(gomod)) //nolint:errcheck
+		case ext == ".zip":
+			w.Write(zips[version]) //nolint:errcheck
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer proxy.Close()
+	t.Setenv("GOPROXY", proxy.URL)
+	t.Setenv("GOSUMDB", "off")
+	t.Setenv("GOMODCACHE", t.TempDir())
+	t.Setenv("GOFLAGS", "-modcacherw")
+
+	zipFile := filepath.Join(t.TempDir(), "v1.0.0.zip")
+	if err := os.WriteFile(zipFile, zips["v1.0.0"], 0600); err != nil {
+		t.Fatal(err)
+	}
+	h1, err := dirhash.HashZip(zipFile, dirhash.Hash1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testDir := t.TempDir()
+	files := map[string]string{
+		"go.mod":  "module test\n\ngo 1.22\n\nrequire " + modPath + " v1.0.0\n",
+		"go.sum":  modPath + " v1.0.0 " + h1 + "\n" + modPath + " v1.0.0/go.mod h1:BBBB=\n",
+		"main.go": "package main\n\nfunc main() {}\n",
+	}
+	for name, src := range files {
+		if err := os.WriteFile(filepath.Join(testDir, name), []byte(src), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	origDir, _ := os.Getwd()
+	defer os.Chdir(origDir)
+	os.Chdir(testDir)
+
+	// v1.2.0 adds exec, an escalation.
+	if code := Run([]string{"--json", "--to-latest", modPath}); code != 1 {
+		t.Errorf("Run(--to-latest) = %d, want 1 (exec added in the latest version)", code)
+	}
+	for _, want := range []string{"/" + modPath + "/@v/v1.0.0.zip", "/" + modPath + "/@v/v1.2.0.zip"} {
+		if !slices.Contains(fetched, want) {
+			t.Errorf("proxy requests = %v, want %s among them", fetched, want)
+		}
+	}
+
+	if code := Run([]string{"--to-latest", "example.com/other"}); code != 2 {
+		t.Errorf("Run(--to-latest) for a module not in go.mod = %d, want 2", code)
+	}
+
+	// A pinned version whose files do not match go.sum is not diffed.
+	tampered := modPath + " v1.0.0 h1:AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA=\n" + modPath + " v1.0.0/go.mod h1:BBBB=\n"
+	if err := os.WriteFile(filepath.Join(testDir, "go.sum"), []byte(tampered), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if code := Run([]string{"--to-latest", modPath}); code != 2 {
+		t.Errorf("Run(--to-latest) with a go.sum mismatch = %d, want 2", code)
+	}
+
+	// GOPROXY=off is honoured rather than bypassed.
+	t.Setenv("GOPROXY", "off")
+	if code := Run([]string{"--to-latest", modPath}); code != 2 {
+		t.Errorf("Run(--to-latest) with GOPROXY=off = %d, want 2", code)
+	}
+}
@@ -83,8 +83,8 @@ func Run(args []string) int {
 	})
 
 	resolvedLang := analyzer.ResolveLang(*lang, dir)
-	astResult := astpipeline.Analyze(dir, resolvedLang, g)
-	taintFindings := taint.Analyze(g.Packages)
+	astResult := astpipeline.Analyze(dir, resolvedLang, g, astpipeline.Options{})
+	taintFindings := taint.Analyze(g.Packages, taint.Options{})
 	if astResult.UsedInterproc && len(astResult.Bundle.TaintFindings) > 0 {
 		taintFindings = astResult.Bundle.TaintFindings
 	}
@@ -160,15 +160,8 @@ func printJSONWithTaint(entries []evidenceEntry, taintFindings []taint.TaintFind
 }
 
 func printText(entries []evidenceEntry, cwd string) int {
-	const (
-		bold   = "\033[1m"
-		cyan   = "\033[36m"
-		yellow = "\033[33m"
-		red    = "\033[31m"
-		green  = "\033[32m"
-		gray   = "\033[90m"
-		reset  = "\033[0m"
-	)
+	palette := report.Colors()
+	bold, cyan, yellow, red, green, gray, reset := palette.Bold, palette.Cyan, palette.Yellow, palette.Red, palette.Green, palette.Gray, palette.Reset
 
 	if len(entries) == 0 {
 		fmt.Println("no capabilities found")
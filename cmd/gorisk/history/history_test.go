@@ -1,6 +1,8 @@
 package history
 
 import (
+	"encoding/json"
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -17,7 +19,7 @@ func setupHistoryDir(t *testing.T) string {
 	snap := history.Snapshot{
 		Commit: "abc1234",
 		Modules: []history.ModuleSnapshot{
-			{Module: "example.com/a", Version: "v1.0.0", RiskLevel: "HIGH", EffectiveScore: 50},
+			{Module: "example.com/a", Version: "v1.0.0", RiskLevel: "HIGH", EffectiveScore: 50, Capabilities: []string{"network"}},
 			{Module: "example.com/b", Version: "v2.0.0", RiskLevel: "MEDIUM", EffectiveScore: 15},
 			{Module: "example.com/c", Version: "v3.0.0", RiskLevel: "LOW", EffectiveScore: 5},
 		},
@@ -25,7 +27,7 @@ func setupHistoryDir(t *testing.T) string {
 	snap2 := history.Snapshot{
 		Commit: "def5678",
 		Modules: []history.ModuleSnapshot{
-			{Module: "example.com/a", Version: "v1.0.0", RiskLevel: "MEDIUM", EffectiveScore: 20},
+			{Module: "example.com/a", Version: "v1.0.0", RiskLevel: "MEDIUM", EffectiveScore: 20, Capabilities: []string{"exec", "network"}},
 			{Module: "example.com/d", Version: "v1.0.0", RiskLevel: "HIGH", EffectiveScore: 40},
 		},
 	}
@@ -212,6 +214,30 @@ func TestRunTrendWithModuleFilter(t *testing.T) {
 	}
 }
 
+func TestRunCapabilitiesWithHistory(t *testing.T) {
+	dir := setupHistoryDir(t)
+	origDir, _ := os.Getwd()
+	defer os.Chdir(origDir)
+	os.Chdir(dir)
+
+	exitCode := Run([]string{"capabilities"})
+	if exitCode != 0 {
+		t.Errorf("expected exit 0 for capabilities, got %d", exitCode)
+	}
+}
+
+func TestRunCapabilitiesWithHistoryJSON(t *testing.T) {
+	dir := setupHistoryDir(t)
+	origDir, _ := os.Getwd()
+	defer os.Chdir(origDir)
+	os.Chdir(dir)
+
+	exitCode := Run([]string{"--json", "capabilities", "--module", "example.com/a"})
+	if exitCode != 0 {
+		t.Errorf("expected exit 0 for JSON capabilities, got %d", exitCode)
+	}
+}
+
 func TestRunUnknownSubcommand(t *testing.T) {
 	dir := t.TempDir()
 	origDir, _ := os.Getwd()
@@ -289,3 +315,91 @@ go 1.22
 	exitCode := Run([]string{"show"})
 	_ = exitCode // May succeed or fail depending on .gorisk existence
 }
+
+func TestRunTrendSinceVersion(t *testing.T) {
+	dir := t.TempDir()
+	h := &history.History{}
+	for _, m := range []history.ModuleSnapshot{
+		{Module: "example.com/a", Version: "v1.0.0", EffectiveScore: 10},
+		{Module: "example.com/a", Version: "v1.1.0", EffectiveScore: 25},
+		{Module: "example.com/a", Version: "v1.1.0", EffectiveScore: 30},
+	} {
+		h.Record(history.Snapshot{Modules: []history.ModuleSnapshot{m, {Module: "example.com/b", Version: "v1.1.0"}}})
+	}
+	if err := h.Save(dir); err != nil {
+		t.Fatal(err)
+	}
+	origDir, _ := os.Getwd()
+	defer os.Chdir(origDir)
+	os.Chdir(dir)
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	oldStdout := os.Stdout
+	os.Stdout = w
+	code := Run([]string{"--json", "trend", "--module", "example.com/a", "--since-version", "v1.1.0"})
+	w.Close()
+	os.Stdout = oldStdout
+	out, _ := io.ReadAll(r)
+	if code != 0 {
+		t.Fatalf("trend --since-version = %d, want 0", code)
+	}
+
+	var rows []struct {
+		Module string `json:"module"`
+		Scores []int  `json:"scores"`
+	}
+	if err := json.Unmarshal(out, &rows); err != nil {
+		t.Fatalf("decode %s: %v", out, err)
+	}
+	if len(rows) != 1 || len(rows[0].Scores) != 2 || rows[0].Scores[0] != 25 {
+		t.Errorf("trend rows = %+v, want scores starting at 25 (the first v1.1.0 snapshot)", rows)
+	}
+
+	if code := Run([]string{"trend", "--module", "example.com/a", "--since-version", "v9.9.9"}); code != 2 {
+		t.Errorf("trend --since-version for an unrecorded version = %d, want 2", code)
+	}
+	// Without --module, or with one matching several modules, the version
+	// could belong to any of them.
+	if code := Run([]string{"trend", "--since-version", "v1.1.0"}); code != 2 {
+		t.Errorf("trend --since-version without --module = %d, want 2", code)
+	}
+	if code := Run([]string{"trend", "--module", "example.com", "--since-version", "v1.1.0"}); code != 2 {
+		t.Errorf("trend --since-version with an ambiguous --module = %d, want 2", code)
+	}
+}
+
+func TestRunExportImport(t *testing.T) {
+	srcDir := setupHistoryDir(t)
+	dstDir := t.TempDir()
+	baseline := filepath.Join(t.TempDir(), "baseline.json")
+
+	origDir, _ := os.Getwd()
+	defer os.Chdir(origDir)
+
+	os.Chdir(srcDir)
+	if code := Run([]string{"export", "--last", "1", baseline}); code != 0 {
+		t.Fatalf("export = %d, want 0", code)
+	}
+
+	os.Chdir(dstDir)
+	if code := Run([]string{"import", baseline}); code != 0 {
+		t.Fatalf("import = %d, want 0", code)
+	}
+	h, err := history.Load(dstDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(h.Snapshots) != 1 || h.Snapshots[0].Commit != "def5678" || len(h.Snapshots[0].Modules) != 2 {
+		t.Errorf("imported history = %+v, want the latest snapshot (def5678)", h.Snapshots)
+	}
+
+	if err := os.WriteFile(baseline, []byte(`{"schema":"gorisk-baseline/v0","snapshots":[]}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if code := Run([]string{"import", baseline}); code != 2 {
+		t.Errorf("import of an unsupported schema = %d, want 2", code)
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/1homsi/gorisk/internal/analyzer"
 	impactlib "github.com/1homsi/gorisk/internal/impact"
 	"github.com/1homsi/gorisk/internal/report"
+	"github.com/1homsi/gorisk/internal/upgrade"
 )
 
 func Run(args []string) int {
@@ -18,13 +19,22 @@ func Run(args []string) int {
 	fs.Parse(args)
 
 	if fs.NArg() < 1 {
-		fmt.Fprintln(os.Stderr, "usage: gorisk impact <module[@version]>")
+		fmt.Fprintln(os.Stderr, "usage: gorisk impact <module[@version]|module.zip|dir>")
 		return 2
 	}
 
 	target := fs.Arg(0)
 	modulePath := target
-	if at := strings.LastIndex(target, "@"); at != -1 {
+	if upgrade.IsModuleSource(target) {
+		// A module .zip or directory names the module without network access.
+		src, err := upgrade.OpenModuleSource(target)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "open module:", err)
+			return 2
+		}
+		modulePath = src.Path
+		src.Close()
+	} else if at := strings.LastIndex(target, "@"); at != -1 {
 		modulePath = target[:at]
 	}
 
@@ -8,6 +8,7 @@ import (
 
 	"github.com/1homsi/gorisk/internal/analyzer"
 	"github.com/1homsi/gorisk/internal/license"
+	"github.com/1homsi/gorisk/internal/report"
 )
 
 func Run(args []string) int {
@@ -54,13 +55,8 @@ func Run(args []string) int {
 		return 0
 	}
 
-	const (
-		red    = "\033[31m"
-		yellow = "\033[33m"
-		green  = "\033[32m"
-		bold   = "\033[1m"
-		reset  = "\033[0m"
-	)
+	palette := report.Colors()
+	red, yellow, green, bold, reset := palette.Red, palette.Yellow, palette.Green, palette.Bold, palette.Reset
 
 	fmt.Printf("%s%-60s  %-20s  %s\n", bold, "MODULE", "LICENSE", "STATUS"+reset)
 	fmt.Println(string(make([]byte, 100)))
@@ -4,7 +4,10 @@ import (
 	"fmt"
 	"os"
 
+	auditcmd "github.com/1homsi/gorisk/cmd/gorisk/audit"
+	binarycmd "github.com/1homsi/gorisk/cmd/gorisk/binary"
 	"github.com/1homsi/gorisk/cmd/gorisk/capabilities"
+	coveragecmd "github.com/1homsi/gorisk/cmd/gorisk/coverage"
 	"github.com/1homsi/gorisk/cmd/gorisk/diff"
 	diffrisk "github.com/1homsi/gorisk/cmd/gorisk/diffrisk"
 	"github.com/1homsi/gorisk/cmd/gorisk/explain"
@@ -25,11 +28,16 @@ import (
 	"github.com/1homsi/gorisk/cmd/gorisk/upgrade"
 	validatepolicy "github.com/1homsi/gorisk/cmd/gorisk/validate-policy"
 	"github.com/1homsi/gorisk/cmd/gorisk/viz"
+	"github.com/1homsi/gorisk/internal/report"
 )
 
 var version = "dev"
 
 func main() {
+	var noColor bool
+	os.Args, noColor = stripNoColor(os.Args)
+	report.SetColor(report.ShouldColor(os.Stdout, noColor))
+
 	if len(os.Args) < 2 {
 		usage()
 		os.Exit(2)
@@ -78,6 +86,12 @@ func main() {
 		os.Exit(plugins.Run(os.Args[2:]))
 	case "serve":
 		os.Exit(serve.Run(os.Args[2:]))
+	case "binary":
+		os.Exit(binarycmd.Run(os.Args[2:]))
+	case "coverage":
+		os.Exit(coveragecmd.Run(os.Args[2:]))
+	case "audit":
+		os.Exit(auditcmd.Run(os.Args[2:]))
 	case "version":
 		fmt.Println(version)
 	default:
@@ -87,24 +101,43 @@ func main() {
 	}
 }
 
+// stripNoColor removes the global --no-color flag from args, wherever it
+// appears, so subcommand flag sets never see it.
+func stripNoColor(args []string) ([]string, bool) {
+	out := make([]string, 0, len(args))
+	found := false
+	for _, a := range args {
+		if a == "--no-color" || a == "-no-color" {
+			found = true
+			continue
+		}
+		out = append(out, a)
+	}
+	return out, found
+}
+
 func usage() {
 	fmt.Fprintln(os.Stderr, `gorisk — Go dependency risk analyzer
 
 Usage:
-  gorisk capabilities   [--json] [--min-risk low|medium|high] [--lang auto|go|node]
+  gorisk capabilities   [--json [--json-compact]] [--min-risk low|medium|high] [--lang auto|go|node] [--ignore-capability a,b] [--list] [--per-platform [--goos linux,windows]] [--changed-only]
   gorisk explain        [--json] [--cap <name>] [--lang auto|go|node]
-  gorisk diff           [--json] <module@old> <module@new>
+  gorisk diff           [--json] [--format text|unified] [--policy file] <module@old> <module@new>
+  gorisk diff           [--json] [--format text|unified] <old.zip|dir> <new.zip|dir>
+  gorisk diff           [--json] [--format text|unified] [--policy file] --to-latest <module>
+  gorisk diff           --node [--json] [--format text|unified] <old-node_modules> <new-node_modules>
   gorisk upgrade        [--json] <module@version>
-  gorisk impact         [--json] <module[@version]>
-  gorisk scan           [--json] [--sarif] [--fail-on low|medium|high] [--policy file.json] [--timings] [--online] [--base <ref>] [--top N] [--focus <module>] [--hide-low-confidence]
+  gorisk impact         [--json] <module[@version]|module.zip|dir>
+  gorisk scan           [--json [--json-compact]] [--sarif] [--gitlab] [--metrics] [--format ndjson] [--formats sarif=path,json=path] [--fail-on low|medium|high] [--policy file.json | --policy-url https://... [--policy-url-required]] [--timings] [--cache-stats] [--profile cpu.pprof] [--memprofile mem.pprof] [--online] [--explain-health] [--base <ref>] [--baseline-compare scan.json [--baseline-strategy exact|rule|module] [--fail-on-new]] [--suggest] [--group-findings] [--require-justification] [--list-exceptions] [--by-language] [--include-submodules] [--vex file] [--trace-evidence trace.json] [--audit-log file] [--update-baseline] [--enrich cmd] [--timeout 5m] [--target native|wasm] [--top N] [--max-findings N] [--diff-only] [--fail-fast] [--fail-on-taint-only] [--focus <module>] [--packages a,b] [--files -|list.txt] [--ignore-capability a,b] [--hide-low-confidence] [--recursive]
   gorisk reachability   [--json] [--min-risk low|medium|high] [--entry file] [--lang auto|go|node]
-  gorisk pr             [--json] [--base ref] [--head ref]
-  gorisk graph          [--json] [--min-risk low|medium|high] [pattern]
-  gorisk sbom           [--format cyclonedx] [pattern]
+  gorisk pr             [--json] [--base ref] [--head ref] [--capabilities] [--max-new-deps N] [--deny-new-dependencies]
+  gorisk graph          [--json] [--min-risk low|medium|high] [--summary-only] [--export-callgraph file] [pattern]
+  gorisk sbom           [--format cyclonedx] [--json-compact] [--spec-version 1.4|1.5|1.6] [pattern]
   gorisk licenses       [--json] [--fail-on-risky] [pattern]
   gorisk viz            [--min-risk low|medium|high] > graph.html
   gorisk trace          [--timeout 10s] [--json] <package> [args...]
-  gorisk history        [record|diff|show|trend] [--json]
+  gorisk history        [record|diff|show|trend|capabilities] [--json]
+  gorisk history        export [--last N] [file] | import [--replace] <file|->
   gorisk diff-risk      --base <ref|path> [--json] [--lang auto|go|node]
   gorisk topology       [--json] [--lang auto|go|node]
   gorisk integrity      [--json] [--lang auto|go|node]
@@ -112,5 +145,11 @@ Usage:
   gorisk validate-policy  [--policy file.json]
   gorisk plugins          [list|install|remove] [args...]
   gorisk serve            [--port 8080] [--host 127.0.0.1]
-  gorisk version`)
+  gorisk binary           [--json] [--health-timeout 30s] <path>
+  gorisk coverage         [--json] [--profile cover.out] [--uncovered]
+  gorisk audit            verify <file>
+  gorisk version
+
+Global flags:
+  --no-color    disable ANSI colors (also off when NO_COLOR is set or stdout is not a terminal)`)
 }
@@ -7,10 +7,14 @@ import (
 	"fmt"
 	"net/http"
 	"os"
+	"os/exec"
+	"path/filepath"
 	"strings"
 
 	"github.com/1homsi/gorisk/internal/analyzer"
+	"github.com/1homsi/gorisk/internal/capability"
 	"github.com/1homsi/gorisk/internal/prdiff"
+	"github.com/1homsi/gorisk/internal/report"
 )
 
 func Run(args []string) int {
@@ -20,7 +24,13 @@ func Run(args []string) int {
 	head := fs.String("head", "HEAD", "head ref to diff")
 	lang := fs.String("lang", "auto", "language: auto|go|node")
 	comment := fs.Bool("comment", false, "post scan diff as a GitHub PR comment (requires GITHUB_TOKEN and GORISK_PR_URL)")
+	capDiff := fs.Bool("capabilities", false, "also diff per-package capabilities by analyzing temporary checkouts of base and head, and fail when a package escalates to HIGH")
+	maxNewDeps := fs.Int("max-new-deps", -1, "fail when head adds more than N new dependencies versus base (-1 = no limit)")
+	denyNewDeps := fs.Bool("deny-new-dependencies", false, "fail when head adds any new dependency (same as --max-new-deps 0)")
 	fs.Parse(args)
+	if *denyNewDeps {
+		*maxNewDeps = 0
+	}
 
 	dir, err := os.Getwd()
 	if err != nil {
@@ -32,26 +42,34 @@ func Run(args []string) int {
 		fmt.Fprintln(os.Stderr, "features:", err)
 		return 2
 	}
+	// Read the palette before the diff result shadows the report package.
+	palette := report.Colors()
+	red, yellow, green, reset := palette.Red, palette.Yellow, palette.Green, palette.Reset
+
 	report, err := features.PRDiff.Diff(*base, *head)
 	if err != nil {
 		fmt.Fprintln(os.Stderr, "pr diff:", err)
 		return 2
 	}
+	if *capDiff {
+		pkgs, err := capabilityChanges(dir, *lang, *base, *head)
+		if err != nil {
+			// Dependency results are still useful without the capability diff.
+			fmt.Fprintln(os.Stderr, "warning: capability diff:", err)
+		}
+		report.Packages = pkgs
+	}
 
 	if *jsonOut {
 		enc := json.NewEncoder(os.Stdout)
 		enc.SetIndent("", "  ")
 		enc.Encode(report)
+		if exceedsNewDepLimit(report.Added, *maxNewDeps) {
+			return 1
+		}
 		return 0
 	}
 
-	const (
-		red    = "\033[31m"
-		yellow = "\033[33m"
-		green  = "\033[32m"
-		reset  = "\033[0m"
-	)
-
 	colorForRisk := func(risk string) string {
 		switch risk {
 		case "HIGH":
@@ -110,6 +128,30 @@ func Run(args []string) int {
 		fmt.Println("no dependency changes detected")
 	}
 
+	if len(report.Packages) > 0 {
+		fmt.Println("Changed package capabilities:")
+		for _, p := range report.Packages {
+			risk := p.Caps.RiskLevel()
+			col := colorForRisk(risk)
+			var changes []string
+			for _, c := range p.AddedCaps {
+				changes = append(changes, "+"+c)
+			}
+			for _, c := range p.RemovedCaps {
+				changes = append(changes, "-"+c)
+			}
+			escalated := ""
+			if p.Escalated {
+				escalated = red + " ▲ capability escalation" + reset
+			}
+			fmt.Printf("  ~ %s  %s%-6s%s%s  %s\n",
+				p.Package, col, risk, reset, escalated, strings.Join(changes, " "))
+			if p.Escalated && risk == "HIGH" {
+				failed = true
+			}
+		}
+	}
+
 	if *comment {
 		if code := postGitHubComment(report); code != 0 {
 			return code
@@ -117,12 +159,73 @@ func Run(args []string) int {
 	}
 
 	if failed {
-		fmt.Fprintln(os.Stderr, "✗ FAILED: new HIGH risk dependency introduced")
+		fmt.Fprintln(os.Stderr, "✗ FAILED: new HIGH risk dependency or package capability introduced")
+		return 1
+	}
+	if exceedsNewDepLimit(report.Added, *maxNewDeps) {
 		return 1
 	}
 	return 0
 }
 
+// exceedsNewDepLimit reports whether added holds more than max modules and,
+// if so, lists them on stderr. A negative max disables the gate.
+func exceedsNewDepLimit(added []prdiff.ModuleDiff, max int) bool {
+	if max < 0 || len(added) <= max {
+		return false
+	}
+	fmt.Fprintf(os.Stderr, "✗ FAILED: %d new dependencies added, limit is %d\n", len(added), max)
+	for _, m := range added {
+		fmt.Fprintln(os.Stderr, "  +", strings.TrimSpace(m.Module+" "+m.NewVersion))
+	}
+	return true
+}
+
+// capabilityChanges analyzes the base and head trees of the repository at
+// dir and returns the packages whose capability sets differ. This catches
+// our own code gaining a capability when no dependency version changed.
+func capabilityChanges(dir, lang, baseRef, headRef string) ([]prdiff.PackageCapDiff, error) {
+	baseCaps, err := treeCapabilities(dir, lang, baseRef)
+	if err != nil {
+		return nil, err
+	}
+	headCaps, err := treeCapabilities(dir, lang, headRef)
+	if err != nil {
+		return nil, err
+	}
+	return prdiff.DiffCapabilities(baseCaps, headCaps), nil
+}
+
+// treeCapabilities checks ref out into a temporary git worktree and returns
+// the capabilities of every package the analyzer finds there.
+func treeCapabilities(dir, lang, ref string) (map[string]capability.CapabilitySet, error) {
+	tmp, err := os.MkdirTemp("", "gorisk-pr-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmp)
+
+	tree := filepath.Join(tmp, "tree")
+	if out, err := exec.Command("git", "-C", dir, "worktree", "add", "--detach", tree, ref).CombinedOutput(); err != nil {
+		return nil, fmt.Errorf("checkout %s: %v: %s", ref, err, strings.TrimSpace(string(out)))
+	}
+	defer exec.Command("git", "-C", dir, "worktree", "remove", "--force", tree).Run()
+
+	a, err := analyzer.ForLang(lang, tree)
+	if err != nil {
+		return nil, err
+	}
+	g, err := a.Load(tree)
+	if err != nil {
+		return nil, fmt.Errorf("load %s: %w", ref, err)
+	}
+	caps := make(map[string]capability.CapabilitySet, len(g.Packages))
+	for path, pkg := range g.Packages {
+		caps[path] = pkg.Capabilities
+	}
+	return caps, nil
+}
+
 // postGitHubComment posts the scan diff as a markdown comment on the GitHub PR
 // identified by GORISK_PR_URL. Requires GITHUB_TOKEN to be set.
 func postGitHubComment(report prdiff.PRDiffReport) int {
@@ -195,6 +298,10 @@ func buildCommentBody(report prdiff.PRDiffReport) string {
 	for _, mod := range report.Removed {
 		fmt.Fprintf(&sb, "| %s | removed | — | — |\n", mod)
 	}
+	for _, p := range report.Packages {
+		fmt.Fprintf(&sb, "| %s | %s | %s | %s |\n",
+			p.Package, p.OldCaps.RiskLevel(), p.Caps.RiskLevel(), strings.Join(p.AddedCaps, ", "))
+	}
 
 	sb.WriteString("\n> Generated by gorisk pr\n")
 	return sb.String()
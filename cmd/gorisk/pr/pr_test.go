@@ -4,6 +4,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
 
@@ -202,3 +203,116 @@ func TestBuildCommentBody_Removed(t *testing.T) {
 		t.Error("body should indicate removal")
 	}
 }
+
+func TestCapabilityChangesHeadAddsExec(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping git test in short mode")
+	}
+
+	dir := t.TempDir()
+	git := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Skipf("git %s: %v: %s", strings.Join(args, " "), err, out)
+		}
+	}
+	write := func(name, src string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(src), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	git("init")
+	git("config", "user.email", "test@example.com")
+	git("config", "user.name", "Test User")
+	write("package.json", `{"name": "app", "version": "1.0.0"}`)
+	write("package-lock.json", `{"name": "app", "version": "1.0.0", "lockfileVersion": 3, "packages": {"": {"name": "app", "version": "1.0.0"}}}`)
+	write("index.js", "console.log('hi');\n")
+	git("add", ".")
+	git("commit", "-m", "base")
+
+	write("index.js", "require('child_process').exec('id');\n")
+	git("commit", "-am", "head adds exec")
+
+	changes, err := capabilityChanges(dir, "node", "HEAD~1", "HEAD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 1 || changes[0].Package != "app" {
+		t.Fatalf("changes = %+v, want only app", changes)
+	}
+	if !slices.Contains(changes[0].AddedCaps, string(capability.CapExec)) {
+		t.Errorf("added caps = %v, want exec", changes[0].AddedCaps)
+	}
+
+	// The temporary worktrees must not be left registered.
+	out, err := exec.Command("git", "-C", dir, "worktree", "list").Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(strings.TrimSpace(string(out)), "\n"); n != 0 {
+		t.Errorf("leftover worktrees:\n%s", out)
+	}
+}
+
+func TestRunMaxNewDeps(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping git test in short mode")
+	}
+
+	dir := t.TempDir()
+	git := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Skipf("git %s: %v: %s", strings.Join(args, " "), err, out)
+		}
+	}
+	write := func(name, src string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(src), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	git("init")
+	git("config", "user.email", "test@example.com")
+	git("config", "user.name", "Test User")
+	write("package.json", "{\n  \"name\": \"app\",\n  \"dependencies\": {\n  }\n}\n")
+	git("add", ".")
+	git("commit", "-m", "base")
+
+	write("package.json", "{\n  \"name\": \"app\",\n  \"dependencies\": {\n    \"left-pad\": \"^1.3.0\",\n    \"is-odd\": \"^3.0.1\"\n  }\n}\n")
+	git("commit", "-am", "head adds two deps")
+
+	origDir, _ := os.Getwd()
+	defer os.Chdir(origDir)
+	os.Chdir(dir)
+
+	common := []string{"--lang", "node", "--base", "HEAD~1", "--head", "HEAD", "--capabilities=false"}
+	if code := Run(append(common, "--max-new-deps", "1")); code != 1 {
+		t.Errorf("--max-new-deps 1 exit = %d, want 1", code)
+	}
+	if code := Run(append(common, "--max-new-deps", "2")); code != 0 {
+		t.Errorf("--max-new-deps 2 exit = %d, want 0", code)
+	}
+	if code := Run(append(common, "--json", "--deny-new-dependencies")); code != 1 {
+		t.Errorf("--deny-new-dependencies exit = %d, want 1", code)
+	}
+}
+
+func TestExceedsNewDepLimit(t *testing.T) {
+	added := []prdiff.ModuleDiff{{Module: "a"}, {Module: "b"}}
+	for _, tt := range []struct {
+		max  int
+		want bool
+	}{{-1, false}, {0, true}, {1, true}, {2, false}} {
+		if got := exceedsNewDepLimit(added, tt.max); got != tt.want {
+			t.Errorf("exceedsNewDepLimit(2 added, %d) = %v, want %v", tt.max, got, tt.want)
+		}
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/1homsi/gorisk/internal/astpipeline"
 	"github.com/1homsi/gorisk/internal/capability"
 	"github.com/1homsi/gorisk/internal/reachability"
+	"github.com/1homsi/gorisk/internal/report"
 )
 
 func Run(args []string) int {
@@ -53,7 +54,7 @@ func Run(args []string) int {
 	if a, err := analyzer.ForLang(*lang, dir); err == nil {
 		if g, err := a.Load(dir); err == nil {
 			resolvedLang := analyzer.ResolveLang(*lang, dir)
-			ast := astpipeline.Analyze(dir, resolvedLang, g)
+			ast := astpipeline.Analyze(dir, resolvedLang, g, astpipeline.Options{})
 			if ast.UsedInterproc {
 				hints = ast.Bundle.ReachabilityHints
 				for _, f := range ast.Bundle.TaintFindings {
@@ -72,14 +73,21 @@ func Run(args []string) int {
 	}
 
 	if *jsonOut {
+		// callPath explains why a capability is reachable: the chain of
+		// calls (Go) or imports (Node) from the entrypoint to its site.
+		type callPath struct {
+			Capability string   `json:"capability"`
+			Path       []string `json:"path"`
+		}
 		type jsonEntry struct {
-			Package          string   `json:"package"`
-			Reachable        bool     `json:"reachable"`
-			Risk             string   `json:"risk"`
-			Score            int      `json:"score"`
-			Caps             []string `json:"capabilities"`
-			ASTReachableHint *bool    `json:"ast_reachable_hint"` // null=no hint, true/false=known
-			ASTTaintFlows    int      `json:"ast_taint_flows,omitempty"`
+			Package          string     `json:"package"`
+			Reachable        bool       `json:"reachable"`
+			Risk             string     `json:"risk"`
+			Score            int        `json:"score"`
+			Caps             []string   `json:"capabilities"`
+			ASTReachableHint *bool      `json:"ast_reachable_hint"` // null=no hint, true/false=known
+			ASTTaintFlows    int        `json:"ast_taint_flows,omitempty"`
+			Paths            []callPath `json:"paths,omitempty"`
 		}
 		var out []jsonEntry
 		for _, r := range filtered {
@@ -87,6 +95,12 @@ func Run(args []string) int {
 			if v, ok := hints[r.Package]; ok {
 				astHint = &v
 			}
+			var paths []callPath
+			for _, c := range r.ReachableCaps.List() {
+				if path, ok := r.Paths[c]; ok {
+					paths = append(paths, callPath{Capability: c, Path: path})
+				}
+			}
 			out = append(out, jsonEntry{
 				Package:          r.Package,
 				Reachable:        r.Reachable,
@@ -95,6 +109,7 @@ func Run(args []string) int {
 				Caps:             r.ReachableCaps.List(),
 				ASTReachableHint: astHint,
 				ASTTaintFlows:    flowCount[r.Package],
+				Paths:            paths,
 			})
 		}
 		if out == nil {
@@ -106,13 +121,8 @@ func Run(args []string) int {
 		return 0
 	}
 
-	const (
-		red    = "\033[31m"
-		yellow = "\033[33m"
-		green  = "\033[32m"
-		gray   = "\033[90m"
-		reset  = "\033[0m"
-	)
+	palette := report.Colors()
+	red, yellow, green, gray, reset := palette.Red, palette.Yellow, palette.Green, palette.Gray, palette.Reset
 
 	colorForRisk := func(risk string) string {
 		switch risk {
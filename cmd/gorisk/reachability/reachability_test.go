@@ -1,6 +1,9 @@
 package reachability
 
 import (
+	"bytes"
+	"encoding/json"
+	"io"
 	"os"
 	"path/filepath"
 	"testing"
@@ -78,3 +81,75 @@ go 1.22
 		t.Errorf("Expected JSON reachability to succeed, got exit code %d", exitCode)
 	}
 }
+
+func TestRunJSONCallPaths(t *testing.T) {
+	testDir := t.TempDir()
+	files := map[string]string{
+		"package.json":                  `{"name": "app", "version": "1.0.0", "dependencies": {"runner": "1.0.0"}}`,
+		"package-lock.json":             `{"name": "app", "version": "1.0.0", "lockfileVersion": 3, "packages": {"": {"name": "app", "version": "1.0.0", "dependencies": {"runner": "1.0.0"}}, "node_modules/runner": {"version": "1.0.0", "dependencies": {"spawner": "1.0.0"}}, "node_modules/spawner": {"version": "1.0.0"}}}`,
+		"index.js":                      "require('runner');\n",
+		"node_modules/runner/index.js":  "module.exports = require('spawner');\n",
+		"node_modules/spawner/index.js": "require('child_process').exec('id');\n",
+	}
+	for name, src := range files {
+		path := filepath.Join(testDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(src), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	code := Run([]string{"--json", "--lang", "node", testDir})
+	w.Close()
+	os.Stdout = stdout
+	var buf bytes.Buffer
+	io.Copy(&buf, r) //nolint:errcheck
+	if code != 0 {
+		t.Fatalf("Run() = %d, want 0", code)
+	}
+
+	var entries []struct {
+		Package   string `json:"package"`
+		Reachable bool   `json:"reachable"`
+		Paths     []struct {
+			Capability string   `json:"capability"`
+			Path       []string `json:"path"`
+		} `json:"paths"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &entries); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, buf.String())
+	}
+	for _, e := range entries {
+		if e.Package != "spawner" {
+			continue
+		}
+		if !e.Reachable {
+			t.Fatal("spawner should be reachable through runner")
+		}
+		for _, p := range e.Paths {
+			if p.Capability != "exec" {
+				continue
+			}
+			want := []string{"app", "runner", "spawner"}
+			if len(p.Path) != len(want) {
+				t.Fatalf("exec path = %v, want %v", p.Path, want)
+			}
+			for i := range want {
+				if p.Path[i] != want[i] {
+					t.Fatalf("exec path = %v, want %v", p.Path, want)
+				}
+			}
+			return
+		}
+		t.Fatalf("spawner has no exec call path: %s", buf.String())
+	}
+	t.Fatalf("spawner missing from output: %s", buf.String())
+}
@@ -1,10 +1,11 @@
 package sbom
 
 import (
-	"encoding/json"
 	"flag"
 	"fmt"
 	"os"
+	"slices"
+	"strings"
 
 	"github.com/1homsi/gorisk/internal/analyzer"
 	"github.com/1homsi/gorisk/internal/health"
@@ -15,13 +16,22 @@ import (
 func Run(args []string) int {
 	fs := flag.NewFlagSet("sbom", flag.ExitOnError)
 	format := fs.String("format", "cyclonedx", "output format: cyclonedx")
+	specVersion := fs.String("spec-version", sbom.DefaultSpecVersion, "CycloneDX spec version: 1.4|1.5|1.6")
 	lang := fs.String("lang", "auto", "language analyzer: auto|go|node")
+	jsonCompact := fs.Bool("json-compact", false, "emit JSON on a single line without indentation (default: indented)")
 	fs.Parse(args)
 
+	report.SetCompactJSON(*jsonCompact)
+	defer report.SetCompactJSON(false)
+
 	if *format != "cyclonedx" {
 		fmt.Fprintf(os.Stderr, "unsupported format %q, only cyclonedx is supported\n", *format)
 		return 2
 	}
+	if !slices.Contains(sbom.SupportedSpecVersions, *specVersion) {
+		fmt.Fprintf(os.Stderr, "unsupported --spec-version %q (supported: %s)\n", *specVersion, strings.Join(sbom.SupportedSpecVersions, ", "))
+		return 2
+	}
 
 	dir, err := os.Getwd()
 	if err != nil {
@@ -64,11 +74,13 @@ func Run(args []string) int {
 		healthReports = append(healthReports, health.Score(mod.Path, mod.Version))
 	}
 
-	bom := sbom.Generate(g, capReports, healthReports)
+	bom, err := sbom.GenerateVersion(*specVersion, g, capReports, healthReports)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
 
-	enc := json.NewEncoder(os.Stdout)
-	enc.SetIndent("", "  ")
-	if err := enc.Encode(bom); err != nil {
+	if err := report.NewJSONEncoder(os.Stdout).Encode(bom); err != nil {
 		fmt.Fprintln(os.Stderr, "encode:", err)
 		return 2
 	}
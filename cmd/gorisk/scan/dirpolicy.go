@@ -0,0 +1,251 @@
+package scan
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/1homsi/gorisk/internal/capability"
+)
+
+// dirPolicyFile is the path, relative to a directory, of the policy that
+// governs the packages beneath it.
+const dirPolicyFile = ".gorisk/policy.json"
+
+// gatePolicy is the part of a policy a directory policy may override: the
+// settings that decide whether a single package fails the scan. Everything
+// else (exceptions, health rules, taint rules, ...) comes from --policy.
+type gatePolicy struct {
+	Version             int      `json:"version"`
+	FailOn              string   `json:"fail_on"`
+	FirstPartyFailOn    string   `json:"first_party_fail_on"`
+	DenyCapabilities    []string `json:"deny_capabilities"`
+	SafeExecCommands    []string `json:"safe_exec_commands"`
+	ConfidenceThreshold float64  `json:"confidence_threshold"`
+	MaxCompositeScore   float64  `json:"max_composite_score"`
+	MediumThreshold     float64  `json:"medium_threshold"`
+	HighThreshold       float64  `json:"high_threshold"`
+
+	// highRisk comes from the --policy file only; directory policies inherit it.
+	highRisk capability.HighRisk
+}
+
+// gate is a gatePolicy resolved for evaluation.
+type gate struct {
+	source              string // policy file relative to the scan root; "" for --policy
+	failOn              string
+	failLevel           int
+	firstPartyFailLevel int
+	deniedCaps          map[string]bool
+	safeExec            map[string]bool
+	confidence          float64
+	maxComposite        float64
+	thresholds          riskThresholds
+}
+
+// gatePolicyOf returns the gate settings of p with fail_on already resolved
+// (flags and GORISK_FAIL_ON applied).
+func gatePolicyOf(p policy, failOn string, highRisk capability.HighRisk) gatePolicy {
+	return gatePolicy{
+		FailOn:              failOn,
+		FirstPartyFailOn:    p.FirstPartyFailOn,
+		DenyCapabilities:    p.DenyCapabilities,
+		SafeExecCommands:    p.SafeExecCommands,
+		ConfidenceThreshold: p.ConfidenceThreshold,
+		MaxCompositeScore:   p.MaxCompositeScore,
+		MediumThreshold:     p.MediumThreshold,
+		HighThreshold:       p.HighThreshold,
+		highRisk:            highRisk,
+	}
+}
+
+// resolve validates gp and builds its gate.
+func (gp gatePolicy) resolve(source string) (gate, error) {
+	for name, v := range map[string]string{"fail_on": gp.FailOn, "first_party_fail_on": gp.FirstPartyFailOn} {
+		switch v {
+		case "", "low", "medium", "high":
+		default:
+			return gate{}, fmt.Errorf("%s must be low|medium|high, got %q", name, v)
+		}
+	}
+	t, err := policyThresholds(policy{MediumThreshold: gp.MediumThreshold, HighThreshold: gp.HighThreshold})
+	if err != nil {
+		return gate{}, err
+	}
+	t.highRisk = gp.highRisk
+	g := gate{
+		source:       source,
+		failOn:       gp.FailOn,
+		failLevel:    capability.RiskValue(gp.FailOn),
+		deniedCaps:   make(map[string]bool),
+		safeExec:     make(map[string]bool),
+		confidence:   gp.ConfidenceThreshold,
+		maxComposite: gp.MaxCompositeScore,
+		thresholds:   t,
+	}
+	// Our own packages may be held to a different (usually stricter) gate.
+	g.firstPartyFailLevel = g.failLevel
+	if gp.FirstPartyFailOn != "" {
+		g.firstPartyFailLevel = capability.RiskValue(gp.FirstPartyFailOn)
+	}
+	for _, c := range gp.DenyCapabilities {
+		g.deniedCaps[strings.ToLower(c)] = true
+	}
+	for _, c := range gp.SafeExecCommands {
+		g.safeExec[c] = true
+	}
+	return g, nil
+}
+
+// rule describes the risk-level gate that level tripped.
+func (g gate) rule(level string) string {
+	if g.source != "" {
+		return fmt.Sprintf("%s risk (fail_on %s, %s)", level, g.failOn, g.source)
+	}
+	return fmt.Sprintf("%s risk (fail_on %s)", level, g.failOn)
+}
+
+// gateTree maps each directory with a .gorisk/policy.json to its gate,
+// merged with every policy above it, like .editorconfig resolution.
+type gateTree struct {
+	root  string
+	base  gate
+	byDir map[string]gate
+}
+
+// loadGateTree finds every .gorisk/policy.json beneath root and merges each
+// over the policy of its nearest ancestor, starting from base. Dependency,
+// vendored, and hidden directories are not searched.
+func loadGateTree(root string, base gatePolicy) (*gateTree, error) {
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return nil, err
+	}
+	baseGate, err := base.resolve("")
+	if err != nil {
+		return nil, err
+	}
+	t := &gateTree{root: root, base: baseGate, byDir: make(map[string]gate)}
+
+	var dirs []string
+	err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || !d.IsDir() {
+			return nil
+		}
+		name := d.Name()
+		if path != root && (strings.HasPrefix(name, ".") || name == "node_modules" || name == "vendor" || name == "testdata") {
+			return filepath.SkipDir
+		}
+		if _, err := os.Stat(filepath.Join(path, dirPolicyFile)); err == nil {
+			dirs = append(dirs, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Parents sort before their children, so each merge starts from an
+	// already-merged ancestor.
+	sort.Strings(dirs)
+	merged := map[string]gatePolicy{}
+	for _, dir := range dirs {
+		parent := base
+		if anc, ok := nearest(t, filepath.Dir(dir), merged); ok {
+			parent = anc
+		}
+		gp, err := mergeGatePolicy(parent, filepath.Join(dir, dirPolicyFile))
+		rel, _ := filepath.Rel(root, filepath.Join(dir, dirPolicyFile))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", rel, err)
+		}
+		g, err := gp.resolve(filepath.ToSlash(rel))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", rel, err)
+		}
+		merged[dir] = gp
+		t.byDir[dir] = g
+	}
+	return t, nil
+}
+
+// mergeGatePolicy overlays the policy file at path on parent. Fields the
+// file omits are inherited; a file that sets fail_on without
+// first_party_fail_on gates first-party code at its own fail_on.
+func mergeGatePolicy(parent gatePolicy, path string) (gatePolicy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return parent, err
+	}
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return parent, err
+	}
+	gp := parent
+	gp.DenyCapabilities = slices.Clone(parent.DenyCapabilities)
+	gp.SafeExecCommands = slices.Clone(parent.SafeExecCommands)
+	if _, ok := raw["fail_on"]; ok {
+		if _, ok := raw["first_party_fail_on"]; !ok {
+			gp.FirstPartyFailOn = ""
+		}
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&gp); err != nil {
+		return parent, fmt.Errorf("%w (a directory policy may only set fail_on, first_party_fail_on, deny_capabilities, safe_exec_commands, confidence_threshold, max_composite_score, medium_threshold, and high_threshold)", err)
+	}
+	if gp.Version != 0 && gp.Version != 1 {
+		return parent, fmt.Errorf("unsupported version %d (supported: 1)", gp.Version)
+	}
+	return gp, nil
+}
+
+// nearest returns the entry of m for dir or its closest ancestor within the
+// tree's root.
+func nearest[V any](t *gateTree, dir string, m map[string]V) (V, bool) {
+	var zero V
+	rel, err := filepath.Rel(t.root, dir)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return zero, false
+	}
+	for {
+		if v, ok := m[dir]; ok {
+			return v, true
+		}
+		if dir == t.root {
+			return zero, false
+		}
+		dir = filepath.Dir(dir)
+	}
+}
+
+// forDir returns the gate for a package in dir: the closest directory
+// policy, or the base gate when none applies.
+func (t *gateTree) forDir(dir string) gate {
+	if len(t.byDir) == 0 || dir == "" {
+		return t.base
+	}
+	if abs, err := filepath.Abs(dir); err == nil {
+		dir = abs
+	}
+	if g, ok := nearest(t, dir, t.byDir); ok {
+		return g
+	}
+	return t.base
+}
+
+// policies returns the directory policies found, relative to the root.
+func (t *gateTree) policies() []string {
+	out := make([]string, 0, len(t.byDir))
+	for _, g := range t.byDir {
+		out = append(out, g.source)
+	}
+	sort.Strings(out)
+	return out
+}
@@ -0,0 +1,54 @@
+package scan
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/1homsi/gorisk/internal/report"
+)
+
+// enrichReport pipes sr, encoded as the --json report, to command's stdin
+// and decodes the enriched report it writes to stdout. command is split on
+// whitespace and run without a shell; its stderr passes through.
+//
+// The returned report must use the same schema: unknown fields and a
+// different schema_version are errors. The pass/fail verdict is gorisk's
+// own, so an enricher can annotate findings but cannot change it.
+func enrichReport(ctx context.Context, command string, sr report.ScanReport) (report.ScanReport, error) {
+	argv := strings.Fields(command)
+	if len(argv) == 0 {
+		return sr, fmt.Errorf("empty command")
+	}
+
+	var in, out bytes.Buffer
+	if err := report.WriteScanJSON(&in, sr); err != nil {
+		return sr, err
+	}
+	cmd := exec.CommandContext(ctx, argv[0], argv[1:]...)
+	cmd.Stdin = &in
+	cmd.Stdout = &out
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return sr, fmt.Errorf("%s: %w", argv[0], err)
+	}
+
+	var enriched report.ScanReport
+	dec := json.NewDecoder(&out)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&enriched); err != nil {
+		return sr, fmt.Errorf("%s returned an invalid report: %w", argv[0], err)
+	}
+	if dec.More() {
+		return sr, fmt.Errorf("%s returned more than one report", argv[0])
+	}
+	if enriched.SchemaVersion != sr.SchemaVersion {
+		return sr, fmt.Errorf("%s returned schema_version %q, want %q", argv[0], enriched.SchemaVersion, sr.SchemaVersion)
+	}
+	enriched.Passed, enriched.FailReason = sr.Passed, sr.FailReason
+	return enriched, nil
+}
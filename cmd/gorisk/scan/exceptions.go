@@ -0,0 +1,119 @@
+package scan
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/1homsi/gorisk/internal/report"
+)
+
+// expiryLayout is the date format of PolicyException.Expires.
+const expiryLayout = "2006-01-02"
+
+// parseExpiry returns the date an exception stops applying, or the zero
+// time when it never expires.
+func parseExpiry(ex PolicyException) (time.Time, error) {
+	if ex.Expires == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(expiryLayout, ex.Expires)
+}
+
+// exceptionStatus is one configured exception as listed by
+// --list-exceptions.
+type exceptionStatus struct {
+	report.ExceptionRecord
+	DaysLeft *int   `json:"days_left,omitempty"` // nil when the exception never expires
+	Status   string `json:"status"`              // active, expired, never, or invalid
+}
+
+// listExceptions returns every exception with its days until expiry as of
+// now, soonest expiry first. Expired exceptions, which the scan ignores,
+// sort ahead of all others; exceptions without an expiry come last.
+func listExceptions(allowExceptions []PolicyException, now time.Time) []exceptionStatus {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	out := make([]exceptionStatus, 0, len(allowExceptions))
+	for _, ex := range allowExceptions {
+		st := exceptionStatus{ExceptionRecord: report.ExceptionRecord{
+			Package:      ex.Package,
+			Capabilities: ex.Capabilities,
+			Taint:        ex.Taint,
+			Expires:      ex.Expires,
+			Owner:        ex.Owner,
+			Reason:       ex.Reason,
+		}}
+		expiry, err := parseExpiry(ex)
+		switch {
+		case err != nil:
+			st.Status = "invalid"
+		case expiry.IsZero():
+			st.Status = "never"
+		default:
+			days := int(expiry.Sub(today).Hours() / 24)
+			st.DaysLeft = &days
+			st.Status = "active"
+			if now.After(expiry) {
+				st.Status = "expired"
+			}
+		}
+		out = append(out, st)
+	}
+	rank := func(st exceptionStatus) int {
+		switch st.Status {
+		case "invalid":
+			return 0
+		case "never":
+			return 2
+		}
+		return 1
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		ri, rj := rank(out[i]), rank(out[j])
+		if ri != rj {
+			return ri < rj
+		}
+		if out[i].DaysLeft != nil && *out[i].DaysLeft != *out[j].DaysLeft {
+			return *out[i].DaysLeft < *out[j].DaysLeft
+		}
+		return out[i].Package < out[j].Package
+	})
+	return out
+}
+
+// writeExceptionList prints the --list-exceptions table.
+func writeExceptionList(w io.Writer, list []exceptionStatus) {
+	if len(list) == 0 {
+		fmt.Fprintln(w, "no exceptions configured")
+		return
+	}
+	fmt.Fprintf(w, "%-40s %-10s %-16s %-16s %s\n", "PACKAGE", "EXPIRES", "STATUS", "OWNER", "REASON")
+	for _, st := range list {
+		expires, status := st.Expires, st.Status
+		switch {
+		case st.Status == "never":
+			expires = "-"
+		case st.Status == "invalid":
+			status = "INVALID DATE"
+		case st.Status == "expired" && *st.DaysLeft == 0:
+			status = "EXPIRED today"
+		case st.Status == "expired":
+			status = fmt.Sprintf("EXPIRED %dd ago", -*st.DaysLeft)
+		default:
+			status = fmt.Sprintf("%dd left", *st.DaysLeft)
+		}
+		owner := st.Owner
+		if owner == "" {
+			owner = "-"
+		}
+		what := strings.Join(append(append([]string{}, st.Capabilities...), st.Taint...), ",")
+		fmt.Fprintf(w, "%-40s %-10s %-16s %-16s %s\n", st.Package+" ["+what+"]", expires, status, owner, st.Reason)
+	}
+}
+
+// writeExceptionListJSON prints the --list-exceptions --json output.
+func writeExceptionListJSON(w io.Writer, list []exceptionStatus) error {
+	return report.NewJSONEncoder(w).Encode(list)
+}
@@ -0,0 +1,70 @@
+package scan
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/1homsi/gorisk/internal/report"
+)
+
+// fileWriters are the report formats --formats can write to a file.
+var fileWriters = map[string]func(io.Writer, report.ScanReport) error{
+	"json":    report.WriteScanJSON,
+	"sarif":   report.WriteScanSARIF,
+	"gitlab":  report.WriteScanGitLab,
+	"metrics": report.WriteScanMetrics,
+	"ndjson":  report.WriteScanNDJSON,
+}
+
+// formatTarget is one --formats entry: a report format and its output file.
+type formatTarget struct {
+	Format string
+	Path   string
+}
+
+// parseFormats parses a --formats value such as
+// "sarif=gorisk.sarif,json=report.json".
+func parseFormats(s string) ([]formatTarget, error) {
+	var targets []formatTarget
+	paths := make(map[string]bool)
+	for _, entry := range strings.Split(s, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		format, path, ok := strings.Cut(entry, "=")
+		format, path = strings.ToLower(strings.TrimSpace(format)), strings.TrimSpace(path)
+		if !ok || path == "" {
+			return nil, fmt.Errorf("%q: want format=path", entry)
+		}
+		if fileWriters[format] == nil {
+			return nil, fmt.Errorf("unknown format %q (want json|sarif|gitlab|metrics|ndjson)", format)
+		}
+		if paths[path] {
+			return nil, fmt.Errorf("%s is listed more than once", path)
+		}
+		paths[path] = true
+		targets = append(targets, formatTarget{Format: format, Path: path})
+	}
+	return targets, nil
+}
+
+// writeFormatFiles writes sr to each target's file in its format.
+func writeFormatFiles(targets []formatTarget, sr report.ScanReport) error {
+	for _, t := range targets {
+		f, err := os.Create(t.Path)
+		if err != nil {
+			return err
+		}
+		err = fileWriters[t.Format](f, sr)
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %w", t.Path, err)
+		}
+	}
+	return nil
+}
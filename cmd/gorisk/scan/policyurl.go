@@ -0,0 +1,105 @@
+package scan
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/1homsi/gorisk/internal/cache"
+)
+
+// policyClient fetches --policy-url; overridden in tests to trust a stub
+// TLS server. The timeout covers the whole request, so a server that stops
+// responding counts as unreachable instead of hanging the scan.
+var policyClient = &http.Client{Timeout: 30 * time.Second}
+
+// policyCacheTTL is how long a fetched policy stays usable as a fallback
+// when the URL cannot be reached.
+const policyCacheTTL = 30 * 24 * time.Hour
+
+// cachedPolicy is a fetched policy document with the validator the server
+// sent for it.
+type cachedPolicy struct {
+	ETag      string    `json:"etag,omitempty"`
+	FetchedAt time.Time `json:"fetched_at"`
+	Body      []byte    `json:"body"`
+}
+
+// fetchPolicy returns the policy document at rawURL, which must be HTTPS.
+// A cached copy is revalidated with If-None-Match, so an unchanged policy
+// costs one 304. A fresh document must pass validate before it is cached
+// or used. When the URL cannot be fetched, the cached copy is used with a
+// warning, unless required is set.
+func fetchPolicy(rawURL string, required bool, validate func([]byte) error) ([]byte, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Scheme != "https" || u.Host == "" {
+		return nil, fmt.Errorf("%q is not an https URL", rawURL)
+	}
+	sum := sha256.Sum256([]byte("policy-url\x00" + rawURL))
+	key := hex.EncodeToString(sum[:])
+
+	var cached *cachedPolicy
+	if raw, ok := cache.Get(key); ok {
+		var c cachedPolicy
+		if json.Unmarshal(raw, &c) == nil && len(c.Body) > 0 {
+			cached = &c
+		}
+	}
+
+	body, etag, err := getPolicy(rawURL, cached)
+	if err != nil {
+		if required || cached == nil {
+			return nil, err
+		}
+		fmt.Fprintf(os.Stderr, "[WARN] policy-url: %v; using the copy cached %s\n", err, cached.FetchedAt.Format(time.RFC3339))
+		return cached.Body, nil
+	}
+	if body == nil { // 304 Not Modified
+		body, etag = cached.Body, cached.ETag
+	} else if err := validate(body); err != nil {
+		return nil, err
+	}
+
+	if encoded, err := json.Marshal(cachedPolicy{ETag: etag, FetchedAt: time.Now(), Body: body}); err == nil {
+		_ = cache.Set(key, encoded, policyCacheTTL)
+	}
+	return body, nil
+}
+
+// getPolicy performs the request for fetchPolicy. It returns a nil body
+// when the server confirms cached is current.
+func getPolicy(rawURL string, cached *cachedPolicy) ([]byte, string, error) {
+	req, err := http.NewRequest("GET", rawURL, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	req.Header.Set("Accept", "application/json")
+	if tok := os.Getenv("GORISK_POLICY_TOKEN"); tok != "" {
+		req.Header.Set("Authorization", "Bearer "+tok)
+	}
+	if cached != nil && cached.ETag != "" {
+		req.Header.Set("If-None-Match", cached.ETag)
+	}
+	resp, err := policyClient.Do(req)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusNotModified && cached != nil:
+		return nil, cached.ETag, nil
+	case resp.StatusCode != http.StatusOK:
+		return nil, "", fmt.Errorf("GET %s: %s", rawURL, resp.Status)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, "", err
+	}
+	return body, resp.Header.Get("ETag"), nil
+}
@@ -1,28 +1,38 @@
 package scan
 
 import (
+	"bufio"
+	"bytes"
+	"context"
 	"encoding/json"
 	"flag"
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
 	"sync"
 	"time"
 
+	goadapter "github.com/1homsi/gorisk/internal/adapters/go"
 	"github.com/1homsi/gorisk/internal/analyzer"
 	"github.com/1homsi/gorisk/internal/astpipeline"
+	"github.com/1homsi/gorisk/internal/audit"
 	"github.com/1homsi/gorisk/internal/capability"
 	"github.com/1homsi/gorisk/internal/engines/integrity"
 	"github.com/1homsi/gorisk/internal/engines/topology"
 	"github.com/1homsi/gorisk/internal/engines/versiondiff"
 	"github.com/1homsi/gorisk/internal/graph"
 	"github.com/1homsi/gorisk/internal/health"
+	"github.com/1homsi/gorisk/internal/history"
 	"github.com/1homsi/gorisk/internal/interproc"
 	"github.com/1homsi/gorisk/internal/priority"
 	"github.com/1homsi/gorisk/internal/report"
 	"github.com/1homsi/gorisk/internal/taint"
+	"github.com/1homsi/gorisk/internal/vex"
 )
 
 type PolicyException struct {
@@ -30,6 +40,8 @@ type PolicyException struct {
 	Capabilities []string `json:"capabilities"`
 	Taint        []string `json:"taint"`   // e.g. ["network→exec", "env→exec"]
 	Expires      string   `json:"expires"` // ISO 8601 date "2026-06-01"
+	Owner        string   `json:"owner"`   // who approved the exception
+	Reason       string   `json:"reason"`  // why the exception is acceptable
 }
 
 // PolicySuppress holds suppression rules that silence findings matching specific
@@ -41,23 +53,61 @@ type PolicySuppress struct {
 }
 
 type policy struct {
-	Version             int               `json:"version"`
-	FailOn              string            `json:"fail_on"`
-	MaxHealthScore      int               `json:"max_health_score"`
-	MinHealthScore      int               `json:"min_health_score"`
-	BlockArchived       bool              `json:"block_archived"`
-	DenyCapabilities    []string          `json:"deny_capabilities"`
-	AllowExceptions     []PolicyException `json:"allow_exceptions"`
-	MaxDepDepth         int               `json:"max_dep_depth"`
-	ExcludePackages     []string          `json:"exclude_packages"`
-	ConfidenceThreshold float64           `json:"confidence_threshold"` // default 0.0 = no filter
-	Suppress            PolicySuppress    `json:"suppress"`
+	Version             int                 `json:"version"`
+	FailOn              string              `json:"fail_on"`
+	FirstPartyFailOn    string              `json:"first_party_fail_on"` // gate for main-module packages; default = fail_on
+	MaxHealthScore      int                 `json:"max_health_score"`
+	MinHealthScore      int                 `json:"min_health_score"`
+	BlockArchived       bool                `json:"block_archived"`
+	BlockAbandoned      bool                `json:"block_abandoned"` // fail on modules with no release in 2 years or an outdated go directive
+	DenyCapabilities    []string            `json:"deny_capabilities"`
+	AllowExceptions     []PolicyException   `json:"allow_exceptions"`
+	MaxDepDepth         int                 `json:"max_dep_depth"`
+	ExcludePackages     []string            `json:"exclude_packages"`
+	ConfidenceThreshold float64             `json:"confidence_threshold"` // default 0.0 = no filter
+	MaxCompositeScore   float64             `json:"max_composite_score"`  // default 0 = disabled
+	SafeExecCommands    []string            `json:"safe_exec_commands"`   // e.g. ["git", "go"]
+	IgnoreCapabilities  []string            `json:"ignore_capabilities"`  // removed from all reports and scores
+	DisabledTaintRules  []string            `json:"disabled_taint_rules"` // e.g. ["env→crypto"]
+	MediumThreshold     float64             `json:"medium_threshold"`     // default 0 = built-in cutoff (10)
+	HighThreshold       float64             `json:"high_threshold"`       // default 0 = built-in cutoff (30)
+	Suppress            PolicySuppress      `json:"suppress"`
+	TrustedPrefixes     []string            `json:"trusted_prefixes"`       // e.g. ["golang.org/x", "github.com/acme"]
+	RequireCapabilities map[string][]string `json:"require_capabilities"`   // package pattern → capabilities it must keep
+	SkipDirs            []string            `json:"skip_dirs"`              // extra first-party dirs to skip (Node), e.g. ["generated"]
+	HighRiskCaps        []string            `json:"high_risk_capabilities"` // capabilities that alone make a package HIGH, e.g. ["exec", "reflect"]
+	AllowedHosts        []string            `json:"allowed_hosts"`          // hosts Go code may name literally in requests, e.g. ["api.github.com", "*.example.com"]
+	MaxCapabilityDepth  int                 `json:"max_capability_depth"`   // warn when a high-risk capability enters deeper than this (0 = disabled)
+	RequireBaseline     bool                `json:"require_baseline"`       // fail when .gorisk-history.json has no recorded snapshot
 }
 
 type exceptionStats struct {
 	Applied         int
 	Expired         int
 	TaintSuppressed int
+	Records         []report.ExceptionRecord // applied exceptions, for the summary and JSON report
+}
+
+// checkJustifications warns about exceptions without an owner or reason.
+// With require set, the first unjustified exception is an error instead.
+func checkJustifications(allowExceptions []PolicyException, require bool) error {
+	for _, ex := range allowExceptions {
+		var missing []string
+		if strings.TrimSpace(ex.Owner) == "" {
+			missing = append(missing, "owner")
+		}
+		if strings.TrimSpace(ex.Reason) == "" {
+			missing = append(missing, "reason")
+		}
+		if len(missing) == 0 {
+			continue
+		}
+		if require {
+			return fmt.Errorf("exception for %s has no %s (required by --require-justification)", ex.Package, strings.Join(missing, " or "))
+		}
+		fmt.Fprintf(os.Stderr, "[WARN] exception for %s has no %s\n", ex.Package, strings.Join(missing, " or "))
+	}
+	return nil
 }
 
 // buildExceptions processes policy exceptions with validation.
@@ -73,12 +123,12 @@ func buildExceptions(allowExceptions []PolicyException) (
 
 	for _, ex := range allowExceptions {
 		expired := false
-		if ex.Expires != "" {
-			expiryDate, err := time.Parse("2006-01-02", ex.Expires)
-			if err != nil {
-				fmt.Fprintf(os.Stderr, "[WARN] exception for %s has invalid expiry date %q\n", ex.Package, ex.Expires)
-				continue
-			}
+		expiryDate, err := parseExpiry(ex)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "[WARN] exception for %s has invalid expiry date %q\n", ex.Package, ex.Expires)
+			continue
+		}
+		if !expiryDate.IsZero() {
 			if now.After(expiryDate) {
 				fmt.Fprintf(os.Stderr, "[WARN] exception for %s expired on %s\n", ex.Package, ex.Expires)
 				stats.Expired++
@@ -119,20 +169,26 @@ func buildExceptions(allowExceptions []PolicyException) (
 
 		if applied {
 			stats.Applied++
+			stats.Records = append(stats.Records, report.ExceptionRecord{
+				Package:      ex.Package,
+				Capabilities: ex.Capabilities,
+				Taint:        ex.Taint,
+				Expires:      ex.Expires,
+				Owner:        ex.Owner,
+				Reason:       ex.Reason,
+			})
 		}
 	}
 
 	return exceptions, taintExceptions, stats
 }
 
-// filterTaintByConfidence removes taint findings below the given confidence threshold.
-func filterTaintByConfidence(findings []taint.TaintFinding, threshold float64) []taint.TaintFinding {
-	if threshold <= 0 {
-		return findings
-	}
+// filterTaintByConfidence removes taint findings below the confidence
+// threshold of their package; a threshold of zero keeps every finding.
+func filterTaintByConfidence(findings []taint.TaintFinding, threshold func(pkg string) float64) []taint.TaintFinding {
 	out := make([]taint.TaintFinding, 0, len(findings))
 	for _, f := range findings {
-		if f.Confidence >= threshold {
+		if f.Confidence >= threshold(f.Package) {
 			out = append(out, f)
 		}
 	}
@@ -159,6 +215,211 @@ func filterCapsConfidence(caps capability.CapabilitySet, threshold float64) capa
 	return caps.Without(excepts)
 }
 
+// riskThresholds are the score cutoffs for MEDIUM and HIGH. The defaults
+// match CapabilitySet.RiskLevel and the priority package.
+type riskThresholds struct {
+	medium, high float64
+	highRisk     capability.HighRisk
+}
+
+var defaultThresholds = riskThresholds{medium: 10, high: 30}
+
+// level buckets score into LOW, MEDIUM, or HIGH.
+func (t riskThresholds) level(score float64) string {
+	switch {
+	case score >= t.high:
+		return "HIGH"
+	case score >= t.medium:
+		return "MEDIUM"
+	default:
+		return "LOW"
+	}
+}
+
+// levelOf is level(score), raised to HIGH when caps holds a capability
+// from the policy's high_risk_capabilities.
+func (t riskThresholds) levelOf(caps capability.CapabilitySet, score float64) string {
+	if t.highRisk.Escalates(caps) {
+		return "HIGH"
+	}
+	return t.level(score)
+}
+
+// policyThresholds applies the policy's medium_threshold and high_threshold
+// over the defaults. Zero keeps the built-in cutoff.
+func policyThresholds(p policy) (riskThresholds, error) {
+	t := defaultThresholds
+	if p.MediumThreshold < 0 || p.HighThreshold < 0 {
+		return t, fmt.Errorf("medium_threshold and high_threshold must not be negative")
+	}
+	if p.MediumThreshold > 0 {
+		t.medium = p.MediumThreshold
+	}
+	if p.HighThreshold > 0 {
+		t.high = p.HighThreshold
+	}
+	if t.medium >= t.high {
+		return t, fmt.Errorf("medium_threshold (%g) must be below high_threshold (%g)", t.medium, t.high)
+	}
+	return t, nil
+}
+
+// withoutSafeExec drops the exec capability when every exec call site runs a
+// constant command listed in safe. Import-only evidence does not count
+// against the allowlist, but any dynamic, non-allowlisted, or transitive exec
+// evidence keeps the capability in place.
+func withoutSafeExec(caps capability.CapabilitySet, safe map[string]bool) capability.CapabilitySet {
+	if len(safe) == 0 || !caps.Has(capability.CapExec) {
+		return caps
+	}
+	sawSafe := false
+	for _, ev := range caps.Evidence[capability.CapExec] {
+		if ev.Via == "import" {
+			continue
+		}
+		if ev.Via != "callSite" {
+			return caps
+		}
+		name, ok := literalCommand(ev.Context)
+		if !ok || !safe[name] {
+			return caps
+		}
+		sawSafe = true
+	}
+	if !sawSafe {
+		return caps
+	}
+	return caps.Without(map[string]bool{capability.CapExec: true})
+}
+
+// literalCommand extracts the command name from exec call-site evidence such
+// as `exec.Command("git")`. It reports false for dynamic commands.
+func literalCommand(context string) (string, bool) {
+	open := strings.IndexByte(context, '(')
+	if open < 0 || !strings.HasSuffix(context, ")") {
+		return "", false
+	}
+	name, err := strconv.Unquote(context[open+1 : len(context)-1])
+	if err != nil {
+		return "", false
+	}
+	return name, true
+}
+
+// healthPhase is a health scoring run executing in the background.
+type healthPhase struct {
+	done    chan struct{}
+	reports []report.HealthReport
+	timing  health.HealthTiming
+}
+
+// startHealth runs score over mods in a new goroutine and returns
+// immediately. Call wait to collect the results.
+func startHealth(
+	ctx context.Context,
+	mods []health.ModuleRef,
+	score func(context.Context, []health.ModuleRef) ([]report.HealthReport, health.HealthTiming),
+) *healthPhase {
+	h := &healthPhase{done: make(chan struct{})}
+	go func() {
+		defer close(h.done)
+		h.reports, h.timing = score(ctx, mods)
+	}()
+	return h
+}
+
+// wait blocks until the health run finishes. A nil phase (offline scan)
+// returns no reports.
+func (h *healthPhase) wait() ([]report.HealthReport, health.HealthTiming) {
+	if h == nil {
+		return nil, health.HealthTiming{}
+	}
+	<-h.done
+	return h.reports, h.timing
+}
+
+// healthModules returns the non-main modules of g sorted by path, so health
+// reports come out in a stable order. A non-nil only (from --packages or
+// --files) keeps just the modules that own one of its packages.
+func healthModules(g *graph.DependencyGraph, only map[string]bool) []health.ModuleRef {
+	var selected map[string]bool
+	if only != nil {
+		selected = make(map[string]bool)
+		for name := range only {
+			if pkg := g.Packages[name]; pkg != nil && pkg.Module != nil {
+				selected[pkg.Module.Path] = true
+			}
+		}
+	}
+	seen := make(map[string]bool)
+	var mods []health.ModuleRef
+	for _, mod := range g.Modules {
+		if mod.Main || seen[mod.Path] || (selected != nil && !selected[mod.Path]) {
+			continue
+		}
+		seen[mod.Path] = true
+		mods = append(mods, health.ModuleRef{Path: mod.Path, Version: mod.Version})
+	}
+	sort.Slice(mods, func(i, j int) bool { return mods[i].Path < mods[j].Path })
+	return mods
+}
+
+// strictConfidenceMin is the evidence confidence required for a capability to
+// count toward the fail decision under --strict-confidence (import-level).
+const strictConfidenceMin = 0.90
+
+// splitStrict partitions caps into capabilities backed by at least one piece
+// of import-level evidence (actionable) and those seen only via call sites,
+// propagation, or without evidence (informational).
+func splitStrict(caps capability.CapabilitySet) (actionable, informational capability.CapabilitySet) {
+	for _, c := range caps.List() {
+		evs := caps.Evidence[c]
+		strong := false
+		for _, ev := range evs {
+			if ev.Confidence >= strictConfidenceMin {
+				strong = true
+				break
+			}
+		}
+		dst := &informational
+		if strong {
+			dst = &actionable
+		}
+		if len(evs) == 0 {
+			dst.Add(c)
+		}
+		for _, ev := range evs {
+			dst.AddWithEvidence(c, ev)
+		}
+	}
+	return actionable, informational
+}
+
+// strictTaint keeps only taint findings whose source and sink are both
+// actionable under --strict-confidence.
+func strictTaint(findings []taint.TaintFinding, actionable capability.CapabilitySet) []taint.TaintFinding {
+	var out []taint.TaintFinding
+	for _, f := range findings {
+		if actionable.Has(f.Source) && actionable.Has(f.Sink) {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+// exceedsCompositeScore computes the priority composite for a package and
+// reports whether it is strictly greater than limit.
+func exceedsCompositeScore(
+	caps capability.CapabilitySet,
+	reachable *bool,
+	cveCount int,
+	findings []taint.TaintFinding,
+	limit float64,
+) (priority.CompositeScore, bool) {
+	cs := priority.Compute(caps, reachable, cveCount, findings)
+	return cs, cs.Composite > limit
+}
+
 // suppressedByPolicy reports whether a package (or its module) should be
 // silenced by the suppress rules in the policy.
 func suppressedByPolicy(pkg string, mod string, suppress PolicySuppress) bool {
@@ -192,6 +453,21 @@ func filterTaintFindings(findings []taint.TaintFinding, taintExceptions map[stri
 	return filtered
 }
 
+// withoutIgnoredTaint drops taint findings whose source or sink is an
+// ignored capability.
+func withoutIgnoredTaint(findings []taint.TaintFinding, ignored map[string]bool) []taint.TaintFinding {
+	if len(ignored) == 0 {
+		return findings
+	}
+	out := make([]taint.TaintFinding, 0, len(findings))
+	for _, f := range findings {
+		if !ignored[f.Source] && !ignored[f.Sink] {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
 // writeExceptionSummary outputs a summary of policy exceptions applied.
 func writeExceptionSummary(w *os.File, stats exceptionStats) {
 	fmt.Fprintf(w, "=== Policy Exceptions ===\n")
@@ -202,6 +478,17 @@ func writeExceptionSummary(w *os.File, stats exceptionStats) {
 	if stats.Expired > 0 {
 		fmt.Fprintf(w, "Expired (not applied): %d\n", stats.Expired)
 	}
+	for _, rec := range stats.Records {
+		scope := strings.Join(append(append([]string{}, rec.Capabilities...), rec.Taint...), ", ")
+		owner, reason := rec.Owner, rec.Reason
+		if owner == "" {
+			owner = "(no owner)"
+		}
+		if reason == "" {
+			reason = "(no reason)"
+		}
+		fmt.Fprintf(w, "  %s [%s] owner=%s: %s\n", rec.Package, scope, owner, reason)
+	}
 }
 
 // filterByFocus returns only capability reports whose module or package path
@@ -216,57 +503,271 @@ func filterByFocus(reports []report.CapabilityReport, focus string, g *graph.Dep
 	return out
 }
 
+// parsePackageList splits a comma-separated --packages value into a set and
+// checks it against the graph. Unknown names are warned about; an error is
+// returned when none of the names exist.
+func parsePackageList(list string, g *graph.DependencyGraph) (map[string]bool, error) {
+	names := make(map[string]bool)
+	found := 0
+	for _, name := range strings.Split(list, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" || names[name] {
+			continue
+		}
+		names[name] = true
+		if _, ok := g.Packages[name]; ok {
+			found++
+		} else {
+			fmt.Fprintf(os.Stderr, "[WARN] --packages: %s not found in dependency graph\n", name)
+		}
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("--packages: no package names given")
+	}
+	if found == 0 {
+		return nil, fmt.Errorf("--packages: none of the named packages exist in the dependency graph")
+	}
+	return names, nil
+}
+
+// filterByPackages returns only capability reports whose package is in names.
+func filterByPackages(reports []report.CapabilityReport, names map[string]bool) []report.CapabilityReport {
+	var out []report.CapabilityReport
+	for _, cr := range reports {
+		if names[cr.Package] {
+			out = append(out, cr)
+		}
+	}
+	return out
+}
+
+// readFileList reads newline-delimited file paths from r. Blank lines are
+// skipped; relative paths are resolved against dir.
+func readFileList(r io.Reader, dir string) (map[string]bool, error) {
+	files := make(map[string]bool)
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" {
+			continue
+		}
+		files[absPath(line, dir)] = true
+	}
+	return files, sc.Err()
+}
+
+func absPath(path, dir string) string {
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(dir, path)
+	}
+	return filepath.Clean(path)
+}
+
+// restrictToFiles narrows every package's capabilities to evidence recorded
+// in files and returns the packages owning at least one listed file. A Go
+// package owns its GoFiles; for other adapters ownership is inferred from
+// evidence locations.
+func restrictToFiles(g *graph.DependencyGraph, files map[string]bool) map[string]bool {
+	owned := make(map[string]bool)
+	for name, pkg := range g.Packages {
+		for _, f := range pkg.GoFiles {
+			if files[absPath(f, pkg.Dir)] {
+				owned[name] = true
+			}
+		}
+
+		var kept capability.CapabilitySet
+		for _, c := range pkg.Capabilities.List() {
+			for _, ev := range pkg.Capabilities.Evidence[c] {
+				if ev.File != "" && files[absPath(ev.File, pkg.Dir)] {
+					kept.AddWithEvidence(c, ev)
+					owned[name] = true
+				}
+			}
+		}
+		pkg.Capabilities = kept
+	}
+	return owned
+}
+
+// decodePolicy decodes a policy JSON document over the defaults in p and
+// validates its version and risk levels. Unknown fields are rejected.
+func decodePolicy(data []byte, p *policy) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(p); err != nil {
+		return fmt.Errorf("parse policy: %w", err)
+	}
+	if p.Version != 0 && p.Version != 1 {
+		return fmt.Errorf("policy: unsupported version %d (supported: 1)", p.Version)
+	}
+	switch p.FailOn {
+	case "", "low", "medium", "high":
+	default:
+		return fmt.Errorf("policy: fail_on must be low|medium|high, got %q", p.FailOn)
+	}
+	switch p.FirstPartyFailOn {
+	case "", "low", "medium", "high":
+	default:
+		return fmt.Errorf("policy: first_party_fail_on must be low|medium|high, got %q", p.FirstPartyFailOn)
+	}
+	return nil
+}
+
 func Run(args []string) int {
 	fs := flag.NewFlagSet("scan", flag.ExitOnError)
 	jsonOut := fs.Bool("json", false, "JSON output")
 	sarifOut := fs.Bool("sarif", false, "SARIF 2.1.0 output")
+	gitlabOut := fs.Bool("gitlab", false, "GitLab Code Quality JSON output")
+	metricsOut := fs.Bool("metrics", false, "OpenMetrics/Prometheus text output")
+	format := fs.String("format", "", "output format: text|json|sarif|gitlab|metrics|ndjson (ndjson = one JSON object per finding per line, streamed as the scan runs)")
 	failOn := fs.String("fail-on", "high", "fail on risk level: low|medium|high")
+	failOnScore := fs.Float64("fail-on-score", 0, "fail when any package's composite score exceeds N (0 = disabled)")
 	policyFile := fs.String("policy", "", "policy JSON file")
+	policyURL := fs.String("policy-url", "", "fetch the policy JSON from this HTTPS URL (bearer token from GORISK_POLICY_TOKEN); cached, revalidated by ETag")
+	policyURLRequired := fs.Bool("policy-url-required", false, "fail when --policy-url cannot be fetched instead of falling back to the cached copy")
 	lang := fs.String("lang", "auto", "language analyzer: auto|go|node")
 	timings := fs.Bool("timings", false, "print per-phase timing breakdown after output")
+	cpuProfile := fs.String("profile", "", "write a pprof CPU profile of the scan to file")
+	memProfile := fs.String("memprofile", "", "write a pprof allocation profile to file when the scan ends")
 	verbose := fs.Bool("verbose", false, "enable verbose debug logging")
 	online := fs.Bool("online", false, "enable health/CVE scoring via GitHub and OSV APIs")
+	explainHealth := fs.Bool("explain-health", false, "print each module's health-score signal breakdown (requires --online)")
+	healthTimeout := fs.Duration("health-timeout", 0, "abort health scoring after this duration and keep partial results (0 = no limit)")
 	base := fs.String("base", "", "compare against this git ref or lockfile path for diff-risk scoring")
 	topN := fs.Int("top", 0, "show only top N packages by final score (0 = all)")
 	focus := fs.String("focus", "", "filter output to this module and its transitive deps")
 	hideLowConf := fs.Bool("hide-low-confidence", false, "filter findings with confidence < 0.65 (alias for --confidence-threshold 0.65)")
 	workspace := fs.Bool("workspace", false, "treat dir as a workspace root and merge all member graphs")
+	recursive := fs.Bool("recursive", false, "scan every Go module (go.mod) beneath dir and merge the results, without a go.work")
+	strictConf := fs.Bool("strict-confidence", false, "only import-level evidence (confidence >= 0.90) can fail the scan; weaker findings are informational")
+	packagesFlag := fs.String("packages", "", "comma-separated import paths (or npm names) to restrict the scan to")
+	filesFlag := fs.String("files", "", "analyze only the newline-delimited file paths read from this file (\"-\" = stdin)")
+	ignoreCaps := fs.String("ignore-capability", "", "comma-separated capabilities to drop from all reports, scores, and taint findings")
+	baselineCompare := fs.String("baseline-compare", "", "compare findings against a previous scan --json output and show severity transitions")
+	baselineStrategy := fs.String("baseline-strategy", report.BaselineRule, "how --baseline-compare matches findings: exact (package, capability or rule, and file:line), rule (package and rule; moved code is not new), or module (new only if the module had no such finding)")
+	failOnNew := fs.Bool("fail-on-new", false, "with --baseline-compare, fail when a finding at or above fail_on is new since the baseline")
+	suggest := fs.Bool("suggest", false, "print a remediation hint for each finding (insecure TLS, math/rand secrets, vulnerable or archived modules)")
+	groupFindings := fs.Bool("group-findings", false, "collapse taint findings by source→sink rule, listing the affected packages under each")
+	requireJustification := fs.Bool("require-justification", false, "fail when a policy exception has no owner or reason")
+	byLanguage := fs.Bool("by-language", false, "split findings into per-language sections with their own pass/fail (multi-language projects)")
+	includeSubmodules := fs.Bool("include-submodules", false, "also analyze each git submodule listed in .gitmodules as its own project")
+	vexFile := fs.String("vex", "", "OpenVEX or CSAF VEX document; CVEs it marks not_affected are excluded from health scoring but still reported")
+	auditLog := fs.String("audit-log", "", "append a hash-chained JSON line recording this scan's commit, graph checksum, and verdict to file")
+	timeout := fs.Duration("timeout", 0, "abort the whole scan after this duration, print partial results, and exit 3 (0 = no limit)")
+	target := fs.String("target", "native", "Go compilation target: native|wasm (wasm drops exec and raw-syscall fs, adds host imports)")
+	maxFindings := fs.Int("max-findings", 0, "print at most N findings (most severe first) and summarize the rest; the exit code still considers all findings (0 = all)")
+	diffOnly := fs.Bool("diff-only", false, "print only the findings that fail the policy (package, score, rule), every one of them, instead of the full report")
+	failOnTaintOnly := fs.Bool("fail-on-taint-only", false, "decide pass/fail only on taint flows at or above fail_on; capability and health findings are reported but never fail the scan")
+	failFast := fs.Bool("fail-fast", false, "stop at the first package whose capabilities fail the policy, skipping taint and health analysis (faster, less complete)")
+	enrich := fs.String("enrich", "", "pipe the JSON report to this command and print the enriched report it returns (same schema)")
+	traceEvidence := fs.String("trace-evidence", "", "gorisk trace --json output; capabilities it confirmed at runtime get static confidence 1.0")
+	listExceptionsFlag := fs.Bool("list-exceptions", false, "list the policy's exceptions with owner, reason, and days until expiry (soonest first, expired flagged) and exit")
+	formats := fs.String("formats", "", "also write the report to files, one per format, from the same run: format=path,... (formats: json|sarif|gitlab|metrics|ndjson)")
+	updateBaseline := fs.Bool("update-baseline", false, "when the scan passes, record a history snapshot (.gorisk-history.json) as the new accepted baseline; never on failure")
+	jsonCompact := fs.Bool("json-compact", false, "emit JSON on a single line without indentation (default: indented)")
+	cacheStats := fs.Bool("cache-stats", false, "print whether the summary cache was enabled, its directory, and its hits, misses, and hit rate to stderr when the scan ends")
 	fs.Parse(args)
 
+	ndjsonOut := false
+	switch *format {
+	case "", "text":
+	case "json":
+		*jsonOut = true
+	case "sarif":
+		*sarifOut = true
+	case "gitlab":
+		*gitlabOut = true
+	case "metrics":
+		*metricsOut = true
+	case "ndjson":
+		ndjsonOut = true
+	default:
+		fmt.Fprintf(os.Stderr, "unknown --format %q (want text|json|sarif|gitlab|metrics|ndjson)\n", *format)
+		return 2
+	}
+
+	report.SetCompactJSON(*jsonCompact)
+	defer report.SetCompactJSON(false)
+
+	formatTargets, err := parseFormats(*formats)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "--formats:", err)
+		return 2
+	}
+
+	if err := goadapter.CheckTarget(*target); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	stopProfiles, err := startProfiles(*cpuProfile, *memProfile)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	defer stopProfiles()
+
 	dir, err := os.Getwd()
 	if err != nil {
 		fmt.Fprintln(os.Stderr, err)
 		return 2
 	}
 
+	if *policyFile != "" && *policyURL != "" {
+		fmt.Fprintln(os.Stderr, "--policy and --policy-url are mutually exclusive")
+		return 2
+	}
+	if *policyURLRequired && *policyURL == "" {
+		fmt.Fprintln(os.Stderr, "--policy-url-required requires --policy-url")
+		return 2
+	}
+
 	p := policy{FailOn: "high", MaxHealthScore: 30}
-	if *policyFile != "" {
-		f, err := os.Open(*policyFile)
+	switch {
+	case *policyFile != "":
+		data, err := os.ReadFile(*policyFile)
 		if err != nil {
 			fmt.Fprintln(os.Stderr, "load policy:", err)
 			return 2
 		}
-		dec := json.NewDecoder(f)
-		dec.DisallowUnknownFields()
-		if err := dec.Decode(&p); err != nil {
-			f.Close()
-			fmt.Fprintln(os.Stderr, "parse policy:", err)
+		if err := decodePolicy(data, &p); err != nil {
+			fmt.Fprintln(os.Stderr, err)
 			return 2
 		}
-		f.Close()
-		if p.Version != 0 && p.Version != 1 {
-			fmt.Fprintf(os.Stderr, "policy: unsupported version %d (supported: 1)\n", p.Version)
+	case *policyURL != "":
+		data, err := fetchPolicy(*policyURL, *policyURLRequired, func(data []byte) error {
+			probe := p
+			return decodePolicy(data, &probe)
+		})
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "policy-url:", err)
+			return 2
+		}
+		if err := decodePolicy(data, &p); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 2
+		}
+	}
+	if p.FailOn != "" && (*policyFile != "" || *policyURL != "") {
+		*failOn = p.FailOn
+	}
+
+	if *listExceptionsFlag {
+		if *policyFile == "" && *policyURL == "" {
+			fmt.Fprintln(os.Stderr, "--list-exceptions requires --policy or --policy-url")
 			return 2
 		}
-		if p.FailOn != "" {
-			switch p.FailOn {
-			case "low", "medium", "high":
-				*failOn = p.FailOn
-			default:
-				fmt.Fprintf(os.Stderr, "policy: fail_on must be low|medium|high, got %q\n", p.FailOn)
+		list := listExceptions(p.AllowExceptions, time.Now())
+		if *jsonOut {
+			if err := writeExceptionListJSON(os.Stdout, list); err != nil {
+				fmt.Fprintln(os.Stderr, "write output:", err)
 				return 2
 			}
+		} else {
+			writeExceptionList(os.Stdout, list)
 		}
+		return 0
 	}
 
 	// Apply environment variable overrides (take precedence over policy file).
@@ -293,21 +794,122 @@ func Run(args []string) int {
 		*lang = v
 	}
 
+	if *failOnScore > 0 {
+		p.MaxCompositeScore = *failOnScore
+	}
+
+	disabledRules, err := taint.DisabledRules(p.DisabledTaintRules)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "policy: disabled_taint_rules:", err)
+		return 2
+	}
+	highRisk, err := capability.ParseHighRisk(p.HighRiskCaps)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "policy: high_risk_capabilities:", err)
+		return 2
+	}
+	analyzerOpts := analyzer.Options{
+		Go:       goadapter.Config{Target: *target, AllowedHosts: p.AllowedHosts},
+		SkipDirs: p.SkipDirs,
+	}
+	engineOpts := astpipeline.Options{
+		Go:       analyzerOpts.Go,
+		SkipDirs: analyzerOpts.SkipDirs,
+		Taint:    taint.Options{Disabled: disabledRules},
+	}
+
+	if p.MaxCapabilityDepth < 0 {
+		fmt.Fprintln(os.Stderr, "policy: max_capability_depth must not be negative")
+		return 2
+	}
+
+	for pattern, caps := range p.RequireCapabilities {
+		if _, err := capability.ParseList(strings.Join(caps, ",")); err != nil {
+			fmt.Fprintf(os.Stderr, "policy: require_capabilities[%q]: %v\n", pattern, err)
+			return 2
+		}
+	}
+
+	if *explainHealth && !*online {
+		fmt.Fprintln(os.Stderr, "[WARN] --explain-health has no effect without --online")
+	}
+
+	var traced *runtimeTrace
+	if *traceEvidence != "" {
+		traced, err = loadTraceEvidence(*traceEvidence)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "trace evidence:", err)
+			return 2
+		}
+		if len(traced.roots) == 0 && len(traced.confirmed) > 0 {
+			fmt.Fprintln(os.Stderr, "[WARN] --trace-evidence: the trace names no traced package; regenerate it with gorisk trace --json")
+		}
+	}
+
+	var vexDoc *vex.Document
+	if *vexFile != "" {
+		if !*online {
+			fmt.Fprintln(os.Stderr, "[WARN] --vex has no effect without --online")
+		}
+		vexDoc, err = vex.Load(*vexFile)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "vex:", err)
+			return 2
+		}
+	}
+
+	if !report.ValidBaselineStrategy(*baselineStrategy) {
+		fmt.Fprintf(os.Stderr, "--baseline-strategy must be exact|rule|module, got %q\n", *baselineStrategy)
+		return 2
+	}
+	if *failOnNew && *baselineCompare == "" {
+		fmt.Fprintln(os.Stderr, "--fail-on-new requires --baseline-compare")
+		return 2
+	}
+	if *failFast && (*diffOnly || *byLanguage) {
+		fmt.Fprintln(os.Stderr, "--fail-fast cannot be combined with --diff-only or --by-language, which need every finding")
+		return 2
+	}
+	if *failFast && *failOnTaintOnly {
+		fmt.Fprintln(os.Stderr, "--fail-fast cannot be combined with --fail-on-taint-only: it stops before taint analysis")
+		return 2
+	}
+
+	var baselineScan *report.ScanReport
+	if *baselineCompare != "" {
+		bs, err := loadBaselineScan(*baselineCompare)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "baseline-compare:", err)
+			return 2
+		}
+		baselineScan = &bs
+	}
+
 	// Apply --hide-low-confidence: set threshold to 0.65 if not already set.
 	if *hideLowConf && p.ConfidenceThreshold == 0 {
 		p.ConfidenceThreshold = 0.65
 	}
 
-	excludePatterns := p.ExcludePackages
+	// Directory policies (.gorisk/policy.json) override the per-package
+	// gate for the packages beneath them.
+	gates, err := loadGateTree(dir, gatePolicyOf(p, *failOn, highRisk))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "policy:", err)
+		return 2
+	}
+	if *verbose && len(gates.byDir) > 0 {
+		fmt.Fprintf(os.Stderr, "directory policies: %s\n", strings.Join(gates.policies(), ", "))
+	}
 
-	exceptions, taintExceptions, exceptionStats := buildExceptions(p.AllowExceptions)
+	excludePatterns := p.ExcludePackages
 
-	deniedCaps := make(map[string]bool)
-	for _, c := range p.DenyCapabilities {
-		deniedCaps[strings.ToLower(c)] = true
+	if err := checkJustifications(p.AllowExceptions, *requireJustification); err != nil {
+		fmt.Fprintln(os.Stderr, "policy:", err)
+		return 2
 	}
+	exceptions, taintExceptions, exceptionStats := buildExceptions(p.AllowExceptions)
 
-	a, err := analyzer.ForLang(*lang, dir)
+	a, err := analyzer.ForLangWith(*lang, dir, analyzerOpts)
 	if err != nil {
 		fmt.Fprintln(os.Stderr, err)
 		return 2
@@ -318,20 +920,104 @@ func Run(args []string) int {
 		taint.SetVerbose(true)
 	}
 
+	ignored, err := capability.ParseList(strings.Join(append([]string{*ignoreCaps}, p.IgnoreCapabilities...), ","))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "ignore capability:", err)
+		return 2
+	}
+
+	// --timeout bounds the whole pipeline. Phases that do not take a
+	// context run under within, which stops waiting when it expires.
+	scanCtx := context.Background()
+	if *timeout > 0 {
+		var cancel context.CancelFunc
+		scanCtx, cancel = context.WithTimeout(scanCtx, *timeout)
+		defer cancel()
+	}
+	timedOut := ""
+
 	// Phase: load graph
 	t0 := time.Now()
-	var g *graph.DependencyGraph
-	if *workspace {
-		g, err = analyzer.LoadWorkspace(dir)
-	} else {
-		g, err = a.Load(dir)
+	type loaded struct {
+		g   *graph.DependencyGraph
+		err error
+	}
+	ld, ok := within(scanCtx, func() loaded {
+		switch {
+		case *recursive:
+			g, err := analyzer.LoadRecursive(dir, analyzerOpts)
+			return loaded{g, err}
+		case *workspace:
+			g, err := analyzer.LoadWorkspace(dir, analyzerOpts)
+			return loaded{g, err}
+		default:
+			g, err := a.Load(dir)
+			return loaded{g, err}
+		}
+	})
+	if !ok {
+		fmt.Fprintf(os.Stderr, "scan timed out after %s during graph load; no results\n", *timeout)
+		return exitTimeout
 	}
+	g, err := ld.g, ld.err
 	loadDur := time.Since(t0)
 	if err != nil {
 		fmt.Fprintln(os.Stderr, "load graph:", err)
 		return 2
 	}
 
+	g.TagLanguage(a.Name())
+	if *includeSubmodules {
+		var errs []error
+		g, _, errs = analyzer.LoadSubmodules(g, dir, analyzerOpts)
+		for _, err := range errs {
+			fmt.Fprintf(os.Stderr, "[WARN] %v\n", err)
+		}
+	}
+	g.StripCapabilities(ignored)
+	if n := boostConfirmed(g, traced); n > 0 {
+		fmt.Fprintf(os.Stderr, "trace evidence: confirmed %s at runtime in %d packages\n", confirmedList(traced.confirmed), n)
+	}
+
+	var onlyPkgs map[string]bool
+	if *packagesFlag != "" {
+		onlyPkgs, err = parsePackageList(*packagesFlag, g)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 2
+		}
+	}
+
+	// --files: keep only evidence from the listed files and the packages that
+	// own them. Combined with --packages, both must match.
+	if *filesFlag != "" {
+		var r io.Reader = os.Stdin
+		if *filesFlag != "-" {
+			f, err := os.Open(*filesFlag)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, "files:", err)
+				return 2
+			}
+			defer f.Close()
+			r = f
+		}
+		files, err := readFileList(r, dir)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "files:", err)
+			return 2
+		}
+		filePkgs := restrictToFiles(g, files)
+		if onlyPkgs != nil {
+			for name := range onlyPkgs {
+				if !filePkgs[name] {
+					delete(onlyPkgs, name)
+				}
+			}
+		} else {
+			onlyPkgs = filePkgs
+		}
+	}
+
 	// Phase: build capability reports (sorted for determinism)
 	t1 := time.Now()
 	pkgKeys := make([]string, 0, len(g.Packages))
@@ -340,10 +1026,11 @@ func Run(args []string) int {
 	}
 	sort.Strings(pkgKeys)
 
+	depths := g.ModuleDepths()
 	var capReports []report.CapabilityReport
 	for _, pkgKey := range pkgKeys {
 		pkg := g.Packages[pkgKey]
-		riskLevel := pkg.Capabilities.RiskLevel()
+		riskLevel := gates.forDir(pkg.Dir).thresholds.levelOf(pkg.Capabilities, float64(pkg.Capabilities.Score))
 		modPath := ""
 		if pkg.Module != nil {
 			modPath = pkg.Module.Path
@@ -353,6 +1040,7 @@ func Run(args []string) int {
 			Module:       modPath,
 			Capabilities: pkg.Capabilities,
 			RiskLevel:    riskLevel,
+			Depth:        depths[pkgKey],
 		})
 	}
 	capDur := time.Since(t1)
@@ -362,67 +1050,113 @@ func Run(args []string) int {
 		capReports = filterByFocus(capReports, *focus, g)
 	}
 
-	// Phase: run engines concurrently
-	t2 := time.Now()
+	// Apply --packages allow list: unlisted packages are dropped entirely.
+	if onlyPkgs != nil {
+		capReports = filterByPackages(capReports, onlyPkgs)
+	}
 
-	var (
-		topoReport  topology.TopologyReport
-		integReport integrity.IntegrityReport
-		diffReport  versiondiff.DiffReport
-		wg          sync.WaitGroup
-	)
+	if p.MaxCapabilityDepth > 0 {
+		for _, w := range deepCapabilities(capReports, p.MaxCapabilityDepth, highRisk) {
+			fmt.Fprintf(os.Stderr, "[WARN] %s\n", w)
+		}
+	}
 
-	wg.Add(2)
-	go func() {
-		defer wg.Done()
-		if tr, err := topology.Compute(dir, *lang); err == nil {
-			topoReport = tr
+	// --format ndjson streams each part of the report as soon as its phase
+	// finishes, ending with the summary once the verdict is known. --enrich
+	// rewrites the whole report, so it is written at the end instead.
+	var stream *report.NDJSONWriter
+	if ndjsonOut && !*diffOnly && *enrich == "" {
+		stream = report.NewNDJSONWriter(os.Stdout)
+		if err := stream.Capabilities(topByScore(capReports, *topN)); err != nil {
+			fmt.Fprintln(os.Stderr, "write output:", err)
+			return 2
 		}
-	}()
-	go func() {
-		defer wg.Done()
-		if ir, err := integrity.Check(dir, *lang); err == nil {
-			integReport = ir
+	}
+
+	// --fail-fast: when capabilities alone already fail the policy, the
+	// verdict is known, so the expensive taint and health phases are skipped.
+	var fastFailure *failingFinding
+	if *failFast {
+		if f, ok := capabilityFailure(capReports, g, gates, exceptions, p, *strictConf); ok {
+			fastFailure = &f
+			fmt.Fprintf(os.Stderr, "[WARN] --fail-fast: %s; taint and health analysis skipped\n", f.Reason)
+		}
+	}
+
+	// Health scoring (only when --online) is network-bound and independent of
+	// capability and taint analysis, so it runs in the background until the
+	// fail evaluation needs it.
+	var healthRun *healthPhase
+	if *online && fastFailure == nil {
+		ctx := scanCtx
+		if *healthTimeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, *healthTimeout)
+			defer cancel()
+		}
+		healthRun = startHealth(ctx, healthModules(g, onlyPkgs), health.ScoreAll)
+	}
+
+	// Phase: run engines concurrently
+	t2 := time.Now()
+
+	// On timeout the engine results are dropped wholesale: the report keeps
+	// capabilities and health but has no taint, topology, or integrity data.
+	var engines engineResults
+	if fastFailure == nil {
+		var ok bool
+		run := runEngines
+		engines, ok = within(scanCtx, func() engineResults {
+			return run(dir, *lang, *base, g, engineOpts)
+		})
+		if !ok {
+			timedOut = "taint and engine analysis"
 		}
-	}()
-	if *base != "" {
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-			if dr, err := versiondiff.Compute(dir, *base, *lang); err == nil {
-				diffReport = dr
-			}
-		}()
 	}
+	topoReport, integReport, diffReport := engines.topo, engines.integ, engines.diff
+	astResult, taintFindings := engines.ast, engines.taint
 
-	// Health scoring: only when --online
-	var healthReports []report.HealthReport
-	var healthTiming health.HealthTiming
-	if *online {
-		seen := make(map[string]bool)
-		var mods []health.ModuleRef
-		for _, mod := range g.Modules {
-			if mod.Main || seen[mod.Path] {
-				continue
+	taintFindings = withoutIgnoredTaint(taintFindings, ignored)
+	filteredTaint := filterTaintFindings(taintFindings, taintExceptions)
+	filteredTaint = filterTaintByConfidence(filteredTaint, func(path string) float64 {
+		if pkg := g.Packages[path]; pkg != nil {
+			return gates.forDir(pkg.Dir).confidence
+		}
+		return gates.base.confidence
+	})
+	if onlyPkgs != nil {
+		kept := filteredTaint[:0]
+		for _, tf := range filteredTaint {
+			if onlyPkgs[tf.Package] {
+				kept = append(kept, tf)
 			}
-			seen[mod.Path] = true
-			mods = append(mods, health.ModuleRef{Path: mod.Path, Version: mod.Version})
 		}
-		healthReports, healthTiming = health.ScoreAll(mods)
+		filteredTaint = kept
+	}
+	if stream != nil {
+		if err := stream.Taint(filteredTaint); err != nil {
+			fmt.Fprintln(os.Stderr, "write output:", err)
+			return 2
+		}
 	}
 
-	wg.Wait()
+	healthReports, healthTiming := healthRun.wait()
+	if vexDoc != nil {
+		health.ApplyVEX(healthReports, vexDoc)
+	}
 	engineDur := time.Since(t2)
-
-	resolvedLang := analyzer.ResolveLang(*lang, dir)
-	astResult := astpipeline.Analyze(dir, resolvedLang, g)
-	taintFindings := taint.Analyze(g.Packages)
-	if astResult.UsedInterproc && len(astResult.Bundle.TaintFindings) > 0 {
-		taintFindings = astResult.Bundle.TaintFindings
+	if timedOut == "" && scanCtx.Err() != nil && healthTiming.TimedOut {
+		timedOut = "health scoring"
 	}
-	filteredTaint := filterTaintFindings(taintFindings, taintExceptions)
-	if p.ConfidenceThreshold > 0 {
-		filteredTaint = filterTaintByConfidence(filteredTaint, p.ConfidenceThreshold)
+	if healthTiming.TimedOut && timedOut == "" {
+		fmt.Fprintf(os.Stderr, "[WARN] health scoring timed out after %s; results are partial (%d/%d modules scored)\n",
+			*healthTimeout, healthTiming.Scored, healthTiming.ModuleCount)
+	}
+	if stream != nil {
+		if err := stream.Health(healthReports); err != nil {
+			fmt.Fprintln(os.Stderr, "write output:", err)
+			return 2
+		}
 	}
 
 	sr := report.ScanReport{
@@ -433,14 +1167,20 @@ func Run(args []string) int {
 		TaintFindings: filteredTaint,
 		Topology:      &topoReport,
 		Integrity:     &integReport,
+		Exceptions:    exceptionStats.Records,
+		Languages:     make(map[string]string, len(g.Packages)+len(g.Modules)),
 		Passed:        true,
 	}
+	for path, mod := range g.Modules {
+		sr.Languages[path] = mod.Language
+	}
+	for path, pkg := range g.Packages {
+		sr.Languages[path] = pkg.Language()
+	}
 	if *base != "" {
 		sr.VersionDiff = &diffReport
 	}
 
-	failLevel := capability.RiskValue(*failOn)
-
 	// Build module→CVE count map (only used when --online)
 	moduleCVEs := make(map[string]int)
 	for _, hr := range healthReports {
@@ -457,6 +1197,33 @@ func Run(args []string) int {
 	topoScore := topoReport.Score
 	integScore := integReport.Score
 
+	// The first failure fails the scan. With --by-language every language
+	// keeps its own first failure, so each section gets a verdict.
+	// --diff-only evaluates every package so it can list all findings
+	// that would fail the scan, not just the first.
+	langFailures := make(map[string]string)
+	var failures []failingFinding
+	fail := func(lang string, f failingFinding) {
+		failures = append(failures, f)
+		if sr.Passed {
+			sr.Passed = false
+			sr.FailReason = f.Reason
+		}
+		if _, ok := langFailures[lang]; !ok {
+			langFailures[lang] = f.Reason
+		}
+	}
+	settled := func(lang string) bool {
+		if *diffOnly {
+			return false
+		}
+		_, failed := langFailures[lang]
+		return failed || (!sr.Passed && !*byLanguage)
+	}
+	if fastFailure != nil {
+		fail(g.Packages[fastFailure.Package].Language(), *fastFailure)
+	}
+
 	for _, cr := range capReports {
 		if isExcluded(cr.Package, excludePatterns) {
 			continue
@@ -471,13 +1238,54 @@ func Run(args []string) int {
 			continue
 		}
 
+		gt := gates.forDir(pkg.Dir)
 		effectiveCaps := cr.Capabilities
 		if exCaps := exceptions[cr.Package]; len(exCaps) > 0 {
 			effectiveCaps = cr.Capabilities.Without(exCaps)
 		}
+		effectiveCaps = withoutSafeExec(effectiveCaps, gt.safeExec)
 		// Apply confidence threshold filter after exceptions.
-		if p.ConfidenceThreshold > 0 {
-			effectiveCaps = filterCapsConfidence(effectiveCaps, p.ConfidenceThreshold)
+		if gt.confidence > 0 {
+			effectiveCaps = filterCapsConfidence(effectiveCaps, gt.confidence)
+		}
+
+		pkgTaint := pkgTaints[cr.Package]
+		if *strictConf {
+			var informational capability.CapabilitySet
+			effectiveCaps, informational = splitStrict(effectiveCaps)
+			pkgTaint = strictTaint(pkgTaint, effectiveCaps)
+			if !informational.IsEmpty() {
+				sr.Informational = append(sr.Informational, report.CapabilityReport{
+					Package:      cr.Package,
+					Module:       cr.Module,
+					Capabilities: informational,
+					RiskLevel:    gt.thresholds.levelOf(informational, float64(informational.Score)),
+				})
+			}
+		}
+
+		// Trusted modules keep their capabilities in the report but never
+		// gate the build. The main module is always gated.
+		if isTrustedModule(pkg.Module, p.TrustedPrefixes) {
+			if !effectiveCaps.IsEmpty() {
+				sr.Trusted = append(sr.Trusted, report.CapabilityReport{
+					Package:      cr.Package,
+					Module:       cr.Module,
+					Capabilities: effectiveCaps,
+					RiskLevel:    gt.thresholds.levelOf(effectiveCaps, float64(effectiveCaps.Score)),
+				})
+			}
+			continue
+		}
+		if *failOnTaintOnly {
+			continue
+		}
+
+		// The first failure stands; keep walking so --strict-confidence
+		// reports informational capabilities for every package.
+		lang := pkg.Language()
+		if settled(lang) {
+			continue
 		}
 
 		// Per-package diff score: sum of RiskDelta for this package name.
@@ -505,73 +1313,327 @@ func Run(args []string) int {
 		finalScore := priority.ComputeFinal(
 			effectiveCaps,
 			reachable,
-			pkgTaints[cr.Package],
+			pkgTaint,
 			pkgDiffScore,
 			integScore,
 			topoScore,
 		)
 
-		if capability.RiskValue(finalScore.Level) >= failLevel {
-			sr.Passed = false
-			sr.FailReason = fmt.Sprintf("package %s has %s AST-aware risk (score: %.1f)", cr.Package, finalScore.Level, finalScore.Final)
-			break
+		failLevel := gt.failLevel
+		if pkg.Module.Main {
+			failLevel = gt.firstPartyFailLevel
+		}
+		if level := gt.thresholds.levelOf(effectiveCaps, finalScore.Final); capability.RiskValue(level) >= failLevel {
+			fail(lang, failingFinding{
+				Package: cr.Package,
+				Score:   finalScore.Final,
+				Rule:    gt.rule(level),
+				Reason:  fmt.Sprintf("package %s has %s AST-aware risk (score: %.1f)", cr.Package, level, finalScore.Final),
+			})
+			continue
+		}
+
+		if gt.maxComposite > 0 {
+			if comp, over := exceedsCompositeScore(effectiveCaps, reachable, moduleCVEs[pkg.Module.Path], pkgTaint, gt.maxComposite); over {
+				fail(lang, failingFinding{
+					Package: cr.Package,
+					Score:   comp.Composite,
+					Rule:    fmt.Sprintf("composite score > %.1f", gt.maxComposite),
+					Reason:  fmt.Sprintf("package %s composite score %.1f exceeds maximum %.1f", cr.Package, comp.Composite, gt.maxComposite),
+				})
+				continue
+			}
 		}
 
-		if len(deniedCaps) > 0 {
+		if len(gt.deniedCaps) > 0 {
 			exCaps := exceptions[cr.Package]
 			for _, capName := range cr.Capabilities.List() {
-				if deniedCaps[strings.ToLower(capName)] && !exCaps[strings.ToLower(capName)] {
-					sr.Passed = false
-					sr.FailReason = fmt.Sprintf("package %s uses denied capability: %s", cr.Package, capName)
+				if *strictConf && !effectiveCaps.Has(capName) {
+					continue
+				}
+				if capName == capability.CapExec && !withoutSafeExec(cr.Capabilities, gt.safeExec).Has(capName) {
+					continue
+				}
+				if gt.deniedCaps[strings.ToLower(capName)] && !exCaps[strings.ToLower(capName)] {
+					fail(lang, failingFinding{
+						Package: cr.Package,
+						Score:   finalScore.Final,
+						Rule:    "denied capability " + capName,
+						Reason:  fmt.Sprintf("package %s uses denied capability: %s", cr.Package, capName),
+					})
 					break
 				}
 			}
-			if !sr.Passed {
+		}
+	}
+
+	// Required capabilities catch a package that stopped doing what it is
+	// expected to do, e.g. a crypto library swapped for a broken fork.
+	if !*failOnTaintOnly {
+		for _, cr := range capReports {
+			if isExcluded(cr.Package, excludePatterns) {
+				continue
+			}
+			pkg := g.Packages[cr.Package]
+			if pkg == nil || pkg.Module == nil {
+				continue
+			}
+			lang := pkg.Language()
+			if settled(lang) {
+				continue
+			}
+			if missing := missingRequiredCaps(cr.Package, cr.Capabilities, p.RequireCapabilities); len(missing) > 0 {
+				fail(lang, failingFinding{
+					Package: cr.Package,
+					Score:   float64(cr.Capabilities.Score),
+					Rule:    "missing required capability " + strings.Join(missing, ", "),
+					Reason:  fmt.Sprintf("package %s is missing required capability: %s", cr.Package, strings.Join(missing, ", ")),
+				})
+			}
+		}
+		// A required package that is gone entirely has no capabilities to
+		// check, so it fails on its own.
+		for _, pattern := range unmatchedRequirePatterns(g, p.RequireCapabilities) {
+			if settled("") {
 				break
 			}
+			fail("", failingFinding{
+				Package: pattern,
+				Rule:    "required package not found",
+				Reason:  fmt.Sprintf("no package matches require_capabilities pattern %s", pattern),
+			})
+		}
+	}
+
+	// --fail-on-taint-only gates on source→sink flows instead of capability
+	// presence.
+	if *failOnTaintOnly {
+		for _, tf := range filteredTaint {
+			pkg := g.Packages[tf.Package]
+			if pkg == nil || pkg.Module == nil || isExcluded(tf.Package, excludePatterns) ||
+				suppressedByPolicy(tf.Package, pkg.Module.Path, p.Suppress) || isTrustedModule(pkg.Module, p.TrustedPrefixes) {
+				continue
+			}
+			lang := pkg.Language()
+			if settled(lang) {
+				continue
+			}
+			if *strictConf {
+				actionable, _ := splitStrict(pkg.Capabilities)
+				if len(strictTaint([]taint.TaintFinding{tf}, actionable)) == 0 {
+					continue
+				}
+			}
+			gt := gates.forDir(pkg.Dir)
+			failLevel := gt.failLevel
+			if pkg.Module.Main {
+				failLevel = gt.firstPartyFailLevel
+			}
+			if capability.RiskValue(tf.Risk) >= failLevel {
+				fail(lang, failingFinding{
+					Package: tf.Package,
+					Score:   float64(pkg.Capabilities.Score),
+					Rule:    "taint " + gt.rule(tf.Risk),
+					Reason:  fmt.Sprintf("package %s has %s taint flow %s → %s", tf.Package, tf.Risk, tf.Source, tf.Sink),
+				})
+			}
 		}
 	}
 
-	if sr.Passed && *online {
+	if (sr.Passed || *byLanguage || *diffOnly) && *online && !*failOnTaintOnly {
 		for _, hr := range healthReports {
+			lang := ""
+			if mod := g.Modules[hr.Module]; mod != nil {
+				lang = mod.Language
+			}
+			if settled(lang) {
+				continue
+			}
 			if p.BlockArchived && hr.Archived {
-				sr.Passed = false
-				sr.FailReason = fmt.Sprintf("module %s is archived", hr.Module)
-				break
+				fail(lang, failingFinding{
+					Package: hr.Module,
+					Score:   float64(hr.Score),
+					Rule:    "archived module",
+					Reason:  fmt.Sprintf("module %s is archived", hr.Module),
+				})
+				continue
 			}
-			if p.MinHealthScore > 0 && hr.Score < p.MinHealthScore {
-				sr.Passed = false
-				sr.FailReason = fmt.Sprintf("module %s health score %d is below minimum %d", hr.Module, hr.Score, p.MinHealthScore)
-				break
+			if p.BlockAbandoned && hr.Abandoned {
+				fail(lang, failingFinding{
+					Package: hr.Module,
+					Score:   float64(hr.Score),
+					Rule:    "abandoned module",
+					Reason:  fmt.Sprintf("module %s is potentially abandoned: %s", hr.Module, hr.AbandonedReason),
+				})
+				continue
+			}
+			if p.MinHealthScore > 0 && !hr.Unknown && hr.Score < p.MinHealthScore {
+				fail(lang, failingFinding{
+					Package: hr.Module,
+					Score:   float64(hr.Score),
+					Rule:    fmt.Sprintf("health score < %d", p.MinHealthScore),
+					Reason:  fmt.Sprintf("module %s health score %d is below minimum %d", hr.Module, hr.Score, p.MinHealthScore),
+				})
+			}
+		}
+	}
+
+	// require_baseline enforces drift tracking: a project that has never
+	// recorded a snapshot has nothing to drift from, so it fails until one
+	// is recorded. --fail-on-taint-only gates on taint flows alone, so it
+	// skips this gate.
+	if p.RequireBaseline && (sr.Passed || *byLanguage || *diffOnly) && !*failOnTaintOnly {
+		h, err := history.Load(dir)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "load history: %v\n", err)
+			return 2
+		}
+		if len(h.Snapshots) == 0 {
+			fail("", failingFinding{
+				Rule:   "require_baseline",
+				Reason: "no baseline recorded in .gorisk-history.json; run `gorisk history record` to record one",
+			})
+		}
+	}
+
+	// Compare against the baseline before --top truncates the report, so
+	// findings outside the top N are not reported as resolved.
+	if baselineScan != nil {
+		sr.BaselineTransitions = report.CompareFindingsBy(*baselineScan, sr, *baselineStrategy)
+		if *failOnNew {
+			// Only findings the policy gates can be new: exceptions,
+			// trusted prefixes and exclude_packages apply as in the
+			// capability gate.
+			gated := gatedReport(sr, g, gates, exceptions, p)
+			for _, t := range report.CompareFindingsBy(*baselineScan, gated, *baselineStrategy) {
+				if t.Change != "new" || (*failOnTaintOnly && t.Kind != "taint") {
+					continue
+				}
+				// A new finding fails only at the fail_on level that would
+				// fail it in a full scan, so a new LOW finding does not fail
+				// a fail_on: high policy.
+				lang, main, srcDir := "", false, ""
+				if pkg := g.Packages[t.Package]; pkg != nil && pkg.Module != nil {
+					lang, main, srcDir = pkg.Language(), pkg.Module.Main, pkg.Dir
+				} else if mod := g.Modules[t.Package]; mod != nil {
+					lang, main, srcDir = mod.Language, mod.Main, mod.Dir
+				}
+				gt := gates.forDir(srcDir)
+				failLevel := gt.failLevel
+				if main {
+					failLevel = gt.firstPartyFailLevel
+				}
+				if capability.RiskValue(t.NewRisk) < failLevel {
+					continue
+				}
+				fail(lang, failingFinding{
+					Package: t.Package,
+					Rule:    "new since baseline (" + *baselineStrategy + ")",
+					Reason:  fmt.Sprintf("%s finding %s in %s is new since the baseline", t.Kind, t.Finding, t.Package),
+				})
+			}
+		}
+	}
+
+	if *suggest {
+		sr.Suggestions = report.Suggest(sr)
+	}
+	if *groupFindings {
+		sr.TaintGroups = report.GroupTaintFindings(sr.TaintFindings)
+	}
+	if *includeSubmodules {
+		pkgSubmodule := make(map[string]string)
+		subLangs := make(map[string]string)
+		for path, pkg := range g.Packages {
+			if pkg.Module != nil && pkg.Module.Submodule != "" {
+				pkgSubmodule[path] = pkg.Module.Submodule
+				subLangs[pkg.Module.Submodule] = pkg.Module.Language
 			}
 		}
+		sr.Submodules = report.SplitSubmodules(sr.Capabilities, pkgSubmodule, subLangs)
+	}
+	if *byLanguage {
+		pkgLang := make(map[string]string, len(g.Packages))
+		for path, pkg := range g.Packages {
+			pkgLang[path] = pkg.Language()
+		}
+		sr.ByLanguage = report.SplitByLanguage(sr, pkgLang, langFailures)
 	}
 
 	// Apply --top N: sort by capability score descending and truncate.
 	if *topN > 0 && len(capReports) > *topN {
-		sort.Slice(capReports, func(i, j int) bool {
-			return capReports[i].Capabilities.Score > capReports[j].Capabilities.Score
-		})
-		capReports = capReports[:*topN]
+		capReports = topByScore(capReports, *topN)
 		sr.Capabilities = capReports
 	}
 
+	if *enrich != "" {
+		enriched, err := enrichReport(scanCtx, *enrich, sr)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "enrich:", err)
+			return 2
+		}
+		sr = enriched
+	}
+
 	// Phase: output formatting
 	t3 := time.Now()
-	var writeErr error
+	// Files first: the text report below trims sr for --max-findings.
+	writeErr := writeFormatFiles(formatTargets, sr)
 	switch {
+	case writeErr != nil:
+		// reported below
+	case *diffOnly:
+		writeErr = writeFailingFindings(os.Stdout, failures, *jsonOut)
+	case stream != nil:
+		writeErr = stream.Summary(sr)
+	case ndjsonOut:
+		writeErr = report.WriteScanNDJSON(os.Stdout, sr)
+	case *metricsOut:
+		writeErr = report.WriteScanMetrics(os.Stdout, sr)
 	case *sarifOut:
 		writeErr = report.WriteScanSARIF(os.Stdout, sr)
+	case *gitlabOut:
+		writeErr = report.WriteScanGitLab(os.Stdout, sr)
 	case *jsonOut:
 		writeErr = report.WriteScanJSON(os.Stdout, sr)
 	default:
+		// --max-findings only trims the printed report; the verdict above
+		// already considered every finding.
+		if *maxFindings > 0 {
+			report.TruncateFindings(&sr, *maxFindings)
+			if sr.TaintGroups != nil {
+				sr.TaintGroups = report.GroupTaintFindings(sr.TaintFindings)
+			}
+		}
 		fmt.Fprintf(os.Stdout, "graph checksum: %s\n\n", sr.GraphChecksum)
 		report.WriteScan(os.Stdout, sr)
+		if *explainHealth && len(sr.Health) > 0 {
+			fmt.Fprintln(os.Stdout)
+			report.WriteHealthBreakdown(os.Stdout, sr.Health)
+		}
 		writeTopologySection(os.Stdout, &topoReport)
 		writeIntegritySection(os.Stdout, &integReport)
+		if *strictConf {
+			writeInformationalSection(os.Stdout, sr.Informational)
+		}
+		if len(sr.Trusted) > 0 {
+			writeTrustedSection(os.Stdout, sr.Trusted)
+		}
 		if *base != "" {
 			writeDiffSection(os.Stdout, &diffReport)
 		}
+		if baselineScan != nil {
+			fmt.Fprintln(os.Stdout)
+			report.WriteFindingTransitions(os.Stdout, sr.BaselineTransitions)
+		}
+		if *suggest {
+			fmt.Fprintln(os.Stdout)
+			report.WriteSuggestions(os.Stdout, sr.Suggestions)
+		}
+		if *includeSubmodules {
+			fmt.Fprintln(os.Stdout)
+			report.WriteSubmodules(os.Stdout, sr.Submodules)
+		}
 		if exceptionStats.Applied > 0 || exceptionStats.Expired > 0 {
 			fmt.Fprintln(os.Stdout)
 			writeExceptionSummary(os.Stdout, exceptionStats)
@@ -584,6 +1646,48 @@ func Run(args []string) int {
 		return 2
 	}
 
+	if *auditLog != "" {
+		entry := audit.Entry{
+			Commit:        audit.Commit(dir),
+			GraphChecksum: sr.GraphChecksum,
+			Verdict:       "pass",
+		}
+		if !sr.Passed {
+			entry.Verdict, entry.FailReason = "fail", sr.FailReason
+		}
+		if timedOut != "" {
+			entry.Verdict = "timeout"
+		}
+		if err := audit.Append(*auditLog, entry); err != nil {
+			fmt.Fprintln(os.Stderr, "audit log:", err)
+			return 2
+		}
+	}
+
+	if *updateBaseline {
+		switch {
+		case timedOut != "":
+			fmt.Fprintln(os.Stderr, "baseline not updated: scan timed out")
+		case !sr.Passed:
+			fmt.Fprintln(os.Stderr, "baseline not updated: scan failed")
+		default:
+			h, err := history.Load(dir)
+			if err == nil {
+				h.Record(history.SnapshotOf(g, audit.Commit(dir)))
+				err = h.Save(dir)
+			}
+			if err != nil {
+				fmt.Fprintln(os.Stderr, "update baseline:", err)
+				return 2
+			}
+			fmt.Fprintf(os.Stderr, "baseline updated: snapshot %d recorded\n", len(h.Snapshots))
+		}
+	}
+
+	if *cacheStats {
+		writeCacheStats(os.Stderr, astResult.Bundle.CacheStats)
+	}
+
 	if *timings {
 		total := loadDur + capDur + engineDur + outDur
 		fmt.Fprintln(os.Stdout)
@@ -594,20 +1698,112 @@ func Run(args []string) int {
 		if *online {
 			fmt.Fprintf(os.Stdout, "  %-23s  (%d modules, %d workers)\n",
 				"health scoring", healthTiming.ModuleCount, healthTiming.Workers)
+			if healthTiming.TimedOut {
+				fmt.Fprintf(os.Stdout, "  %-23s  (%d/%d modules scored)\n",
+					"health scoring timed out", healthTiming.Scored, healthTiming.ModuleCount)
+			}
 			fmt.Fprintf(os.Stdout, "  %-23s  %s  (%d calls)\n", "github API", fmtDur(healthTiming.GithubTime), healthTiming.GithubCalls)
 			fmt.Fprintf(os.Stdout, "  %-23s  %s  (%d calls)\n", "osv API", fmtDur(healthTiming.OsvTime), healthTiming.OsvCalls)
+			fmt.Fprintf(os.Stdout, "  %-23s  %s  (%d calls)\n", "module proxy", fmtDur(healthTiming.ProxyTime), healthTiming.ProxyCalls)
 		}
 		fmt.Fprintf(os.Stdout, "%-25s  %s\n", "output formatting", fmtDur(outDur))
 		fmt.Fprintln(os.Stdout, strings.Repeat("─", 40))
 		fmt.Fprintf(os.Stdout, "%-25s  %s\n", "total", fmtDur(total))
 	}
 
+	if timedOut != "" {
+		fmt.Fprintf(os.Stderr, "scan timed out after %s during %s; results above are partial\n", *timeout, timedOut)
+		return exitTimeout
+	}
 	if !sr.Passed {
 		return 1
 	}
 	return 0
 }
 
+// writeCacheStats prints one line describing the interprocedural summary
+// cache: where it lives and how many lookups it answered.
+func writeCacheStats(w io.Writer, st interproc.CacheStats) {
+	if !st.Enabled {
+		fmt.Fprintln(w, "cache: disabled")
+		return
+	}
+	fmt.Fprintf(w, "cache: enabled (%s): %d hits, %d misses (%.1f%% hit rate)\n",
+		st.Dir, st.Hits, st.Misses, st.HitRate())
+}
+
+// exitTimeout is the exit code for a scan stopped by --timeout, distinct
+// from a policy failure (1) and a usage or load error (2).
+const exitTimeout = 3
+
+// within runs fn in a new goroutine and waits for it or for ctx to expire.
+// It reports false on expiry; fn keeps running but its result is dropped.
+func within[T any](ctx context.Context, fn func() T) (T, bool) {
+	done := make(chan T, 1)
+	go func() {
+		done <- fn()
+	}()
+	select {
+	case v := <-done:
+		return v, true
+	case <-ctx.Done():
+		var zero T
+		return zero, false
+	}
+}
+
+// engineResults is the output of the concurrent analysis phase.
+type engineResults struct {
+	topo  topology.TopologyReport
+	integ integrity.IntegrityReport
+	diff  versiondiff.DiffReport
+	ast   astpipeline.Result
+	taint []taint.TaintFinding
+}
+
+// runEngines runs topology, integrity, and (with base) version-diff scoring
+// concurrently with AST and taint analysis configured by opts. It is a
+// variable so tests can substitute a slow phase.
+var runEngines = func(dir, lang, base string, g *graph.DependencyGraph, opts astpipeline.Options) engineResults {
+	var (
+		res engineResults
+		wg  sync.WaitGroup
+	)
+
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		if tr, err := topology.Compute(dir, lang); err == nil {
+			res.topo = tr
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		if ir, err := integrity.Check(dir, lang); err == nil {
+			res.integ = ir
+		}
+	}()
+	if base != "" {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if dr, err := versiondiff.Compute(dir, base, lang); err == nil {
+				res.diff = dr
+			}
+		}()
+	}
+
+	resolvedLang := analyzer.ResolveLang(lang, dir)
+	res.ast = astpipeline.Analyze(dir, resolvedLang, g, opts)
+	res.taint = taint.Analyze(g.Packages, opts.Taint)
+	if res.ast.UsedInterproc && len(res.ast.Bundle.TaintFindings) > 0 {
+		res.taint = taint.WithPackageLevel(res.ast.Bundle.TaintFindings, res.taint)
+	}
+
+	wg.Wait()
+	return res
+}
+
 func writeTopologySection(w *os.File, r *topology.TopologyReport) {
 	fmt.Fprintln(w)
 	fmt.Fprintln(w, "=== Topology ===")
@@ -637,6 +1833,61 @@ func writeIntegritySection(w *os.File, r *integrity.IntegrityReport) {
 	}
 }
 
+func writeInformationalSection(w *os.File, reports []report.CapabilityReport) {
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "=== Informational (below strict confidence) ===")
+	if len(reports) == 0 {
+		fmt.Fprintln(w, "none")
+		return
+	}
+	fmt.Fprintf(w, "%-50s  %s\n", "Package", "Capabilities")
+	fmt.Fprintln(w, strings.Repeat("─", 80))
+	for _, r := range reports {
+		fmt.Fprintf(w, "%-50s  %s\n", r.Package, r.Capabilities.String())
+	}
+}
+
+// failingFinding is one policy rule a package or module crossed. Reason is
+// the sentence used as the scan's FailReason when it is the first.
+type failingFinding struct {
+	Package string  `json:"package"` // module path for health rules
+	Score   float64 `json:"score"`
+	Rule    string  `json:"rule"`
+	Reason  string  `json:"-"`
+}
+
+// writeFailingFindings prints the --diff-only output: every failing finding,
+// or a one-line pass when there are none.
+func writeFailingFindings(w *os.File, failures []failingFinding, asJSON bool) error {
+	if asJSON {
+		if failures == nil {
+			failures = []failingFinding{}
+		}
+		return report.NewJSONEncoder(w).Encode(failures)
+	}
+	if len(failures) == 0 {
+		fmt.Fprintln(w, "✓ PASSED: no findings fail the policy")
+		return nil
+	}
+	fmt.Fprintf(w, "✗ FAILED: %d finding(s) fail the policy\n", len(failures))
+	fmt.Fprintf(w, "%-50s  %7s  %s\n", "Package", "Score", "Rule")
+	fmt.Fprintln(w, strings.Repeat("─", 80))
+	for _, f := range failures {
+		fmt.Fprintf(w, "%-50s  %7.1f  %s\n", f.Package, f.Score, f.Rule)
+	}
+	return nil
+}
+
+func writeTrustedSection(w *os.File, reports []report.CapabilityReport) {
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "=== Trusted (not gated by policy) ===")
+	fmt.Fprintf(w, "%-50s  %-6s  %s\n", "Package", "Risk", "Capabilities")
+	fmt.Fprintln(w, strings.Repeat("─", 80))
+	for _, r := range reports {
+		fmt.Fprintf(w, "%-50s  %-6s  %s\n", r.Package, r.RiskLevel, r.Capabilities.String())
+	}
+}
+
 func writeDiffSection(w *os.File, r *versiondiff.DiffReport) {
 	fmt.Fprintln(w)
 	fmt.Fprintf(w, "=== Version Diff (base: %s) ===\n", r.Base)
@@ -671,6 +1922,204 @@ func isExcluded(pkg string, patterns []string) bool {
 	return false
 }
 
+// isTrustedModule reports whether mod falls under one of the policy's
+// trusted_prefixes. A prefix matches the module path itself or any path
+// below it at a "/" boundary, so "golang.org/x" covers "golang.org/x/sys"
+// but not "golang.org/xyz". The main module is never trusted.
+func isTrustedModule(mod *graph.Module, prefixes []string) bool {
+	if mod == nil || mod.Main {
+		return false
+	}
+	for _, prefix := range prefixes {
+		prefix = strings.TrimSuffix(prefix, "/")
+		if prefix == "" {
+			continue
+		}
+		if mod.Path == prefix || strings.HasPrefix(mod.Path, prefix+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// topByScore returns the n reports with the highest capability score, or
+// reports unchanged when n is not positive or keeps them all.
+func topByScore(reports []report.CapabilityReport, n int) []report.CapabilityReport {
+	if n <= 0 || len(reports) <= n {
+		return reports
+	}
+	top := slices.Clone(reports)
+	sort.Slice(top, func(i, j int) bool {
+		return top[i].Capabilities.Score > top[j].Capabilities.Score
+	})
+	return top[:n]
+}
+
+// gatedReport returns sr restricted to the findings the policy gates.
+// Excluded, suppressed and trusted packages are dropped, and each package's
+// allow_exceptions are removed from its capabilities, which are then
+// re-leveled under the package's thresholds.
+func gatedReport(
+	sr report.ScanReport,
+	g *graph.DependencyGraph,
+	gates *gateTree,
+	exceptions map[string]map[string]bool,
+	p policy,
+) report.ScanReport {
+	gated := func(path string) bool {
+		pkg := g.Packages[path]
+		return pkg != nil && pkg.Module != nil && !isExcluded(path, p.ExcludePackages) &&
+			!suppressedByPolicy(path, pkg.Module.Path, p.Suppress) && !isTrustedModule(pkg.Module, p.TrustedPrefixes)
+	}
+	caps := make([]report.CapabilityReport, 0, len(sr.Capabilities))
+	for _, cr := range sr.Capabilities {
+		if !gated(cr.Package) {
+			continue
+		}
+		if exCaps := exceptions[cr.Package]; len(exCaps) > 0 {
+			cr.Capabilities = cr.Capabilities.Without(exCaps)
+			if cr.Capabilities.IsEmpty() {
+				continue
+			}
+			cr.RiskLevel = gates.forDir(g.Packages[cr.Package].Dir).thresholds.levelOf(cr.Capabilities, float64(cr.Capabilities.Score))
+		}
+		caps = append(caps, cr)
+	}
+	var taints []taint.TaintFinding
+	for _, tf := range sr.TaintFindings {
+		if gated(tf.Package) {
+			taints = append(taints, tf)
+		}
+	}
+	sr.Capabilities, sr.TaintFindings = caps, taints
+	return sr
+}
+
+// capabilityFailure returns the first package of reports whose
+// capabilities alone fail its gate: a denied capability, or a risk level at
+// or above fail_on. It applies the same exclusions, suppressions,
+// exceptions, and trusted prefixes as the full evaluation, but without
+// taint, reachability, diff, or topology data it scores each package as
+// unreachable with no taint flows, a lower bound of the full score. So a
+// package that fails here fails the full evaluation too, and --fail-fast
+// can stop before computing that data.
+func capabilityFailure(
+	reports []report.CapabilityReport,
+	g *graph.DependencyGraph,
+	gates *gateTree,
+	exceptions map[string]map[string]bool,
+	p policy,
+	strict bool,
+) (failingFinding, bool) {
+	for _, cr := range reports {
+		pkg := g.Packages[cr.Package]
+		if pkg == nil || pkg.Module == nil || isExcluded(cr.Package, p.ExcludePackages) ||
+			suppressedByPolicy(cr.Package, pkg.Module.Path, p.Suppress) || isTrustedModule(pkg.Module, p.TrustedPrefixes) {
+			continue
+		}
+		gt := gates.forDir(pkg.Dir)
+		caps := cr.Capabilities
+		if exCaps := exceptions[cr.Package]; len(exCaps) > 0 {
+			caps = caps.Without(exCaps)
+		}
+		caps = withoutSafeExec(caps, gt.safeExec)
+		if gt.confidence > 0 {
+			caps = filterCapsConfidence(caps, gt.confidence)
+		}
+		if strict {
+			caps, _ = splitStrict(caps)
+		}
+
+		unreachable := false
+		score := priority.ComputeFinal(caps, &unreachable, nil, 0, 0, 0).Final
+		failLevel := gt.failLevel
+		if pkg.Module.Main {
+			failLevel = gt.firstPartyFailLevel
+		}
+		if level := gt.thresholds.levelOf(caps, score); capability.RiskValue(level) >= failLevel {
+			return failingFinding{
+				Package: cr.Package,
+				Score:   score,
+				Rule:    gt.rule(level),
+				Reason:  fmt.Sprintf("package %s has %s risk (score: %.1f)", cr.Package, level, score),
+			}, true
+		}
+		for _, c := range caps.List() {
+			if gt.deniedCaps[strings.ToLower(c)] {
+				return failingFinding{
+					Package: cr.Package,
+					Score:   score,
+					Rule:    "denied capability " + c,
+					Reason:  fmt.Sprintf("package %s uses denied capability: %s", cr.Package, c),
+				}, true
+			}
+		}
+	}
+	return failingFinding{}, false
+}
+
+// deepCapabilities returns a warning for each package deeper than maxDepth
+// in the dependency tree that holds a high-risk capability: code that far
+// from the main module is rarely reviewed and hard to audit.
+func deepCapabilities(reports []report.CapabilityReport, maxDepth int, highRisk capability.HighRisk) []string {
+	var warnings []string
+	for _, cr := range reports {
+		if cr.Depth <= maxDepth {
+			continue
+		}
+		var risky []string
+		for _, c := range cr.Capabilities.List() {
+			if highRisk.Has(c) {
+				risky = append(risky, c)
+			}
+		}
+		if len(risky) > 0 {
+			warnings = append(warnings, fmt.Sprintf("%s enters at dependency depth %d through %s (max_capability_depth %d)",
+				strings.Join(risky, ", "), cr.Depth, cr.Package, maxDepth))
+		}
+	}
+	return warnings
+}
+
+// missingRequiredCaps returns, sorted, the capabilities that require
+// demands of pkg (through every matching pattern) but caps lacks.
+func missingRequiredCaps(pkg string, caps capability.CapabilitySet, require map[string][]string) []string {
+	var missing []string
+	for pattern, want := range require {
+		if !matchPattern(pkg, pattern) {
+			continue
+		}
+		for _, c := range want {
+			c = strings.ToLower(strings.TrimSpace(c))
+			if c != "" && !caps.Has(c) && !slices.Contains(missing, c) {
+				missing = append(missing, c)
+			}
+		}
+	}
+	sort.Strings(missing)
+	return missing
+}
+
+// unmatchedRequirePatterns returns, sorted, the require patterns that match
+// no package of g, such as a required package that was removed or renamed.
+func unmatchedRequirePatterns(g *graph.DependencyGraph, require map[string][]string) []string {
+	var unmatched []string
+	for pattern := range require {
+		found := false
+		for path := range g.Packages {
+			if matchPattern(path, pattern) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			unmatched = append(unmatched, pattern)
+		}
+	}
+	sort.Strings(unmatched)
+	return unmatched
+}
+
 // matchPattern reports whether subject matches pattern.
 // Patterns ending with "/*" match the exact prefix or any sub-path.
 // Exact patterns require an exact string match.
@@ -688,3 +2137,16 @@ func fmtDur(d time.Duration) string {
 	}
 	return fmt.Sprintf("%.2fs", d.Seconds())
 }
+
+// loadBaselineScan reads a scan report previously written with --json.
+func loadBaselineScan(path string) (report.ScanReport, error) {
+	var sr report.ScanReport
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return sr, err
+	}
+	if err := json.Unmarshal(data, &sr); err != nil {
+		return sr, fmt.Errorf("parse %s: %w", path, err)
+	}
+	return sr, nil
+}
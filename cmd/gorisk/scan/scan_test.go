@@ -1,13 +1,33 @@
 package scan
 
 import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"reflect"
+	"regexp"
+	"slices"
 	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/1homsi/gorisk/internal/astpipeline"
 	"github.com/1homsi/gorisk/internal/capability"
+	"github.com/1homsi/gorisk/internal/graph"
+	"github.com/1homsi/gorisk/internal/health"
+	"github.com/1homsi/gorisk/internal/history"
+	"github.com/1homsi/gorisk/internal/interproc"
+	"github.com/1homsi/gorisk/internal/priority"
+	"github.com/1homsi/gorisk/internal/report"
 	"github.com/1homsi/gorisk/internal/taint"
 )
 
@@ -256,15 +276,50 @@ func TestWriteExceptionSummaryZeroCounts(t *testing.T) {
 
 // ── Run ───────────────────────────────────────────────────────────────────────
 
-func TestRunBadLang(t *testing.T) {
+// writeProject writes files (slash-separated path → contents) into a new
+// temporary directory, changes into it for the rest of the test, and
+// returns its path.
+func writeProject(t *testing.T, files map[string]string) string {
+	t.Helper()
 	dir := t.TempDir()
-	gomod := "module test\ngo 1.22\n"
-	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte(gomod), 0600); err != nil {
-		t.Fatal(err)
+	for name, src := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(src), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Chdir(dir)
+	return dir
+}
+
+// nodeApp returns the files of a Node project named app whose index.js is
+// main and which depends on each of deps (package name → index.js), installed
+// under node_modules at version 1.0.0.
+func nodeApp(main string, deps map[string]string) map[string]string {
+	files := map[string]string{"index.js": main}
+	root := map[string]any{"name": "app", "version": "1.0.0"}
+	lock := map[string]any{"": root}
+	if len(deps) > 0 {
+		versions := map[string]string{}
+		for name, src := range deps {
+			versions[name] = "1.0.0"
+			lock["node_modules/"+name] = map[string]string{"version": "1.0.0"}
+			files["node_modules/"+name+"/index.js"] = src
+		}
+		root["dependencies"] = versions
 	}
-	orig, _ := os.Getwd()
-	defer os.Chdir(orig) //nolint:errcheck
-	os.Chdir(dir)        //nolint:errcheck
+	manifest, _ := json.Marshal(root)
+	lockfile, _ := json.Marshal(map[string]any{"name": "app", "version": "1.0.0", "lockfileVersion": 3, "packages": lock})
+	files["package.json"] = string(manifest)
+	files["package-lock.json"] = string(lockfile)
+	return files
+}
+
+func TestRunBadLang(t *testing.T) {
+	writeProject(t, map[string]string{"go.mod": "module test\ngo 1.22\n"})
 
 	code := Run([]string{"--lang", "cobol"})
 	if code == 0 {
@@ -273,14 +328,7 @@ func TestRunBadLang(t *testing.T) {
 }
 
 func TestRunBadPolicyFile(t *testing.T) {
-	dir := t.TempDir()
-	gomod := "module test\ngo 1.22\n"
-	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte(gomod), 0600); err != nil {
-		t.Fatal(err)
-	}
-	orig, _ := os.Getwd()
-	defer os.Chdir(orig) //nolint:errcheck
-	os.Chdir(dir)        //nolint:errcheck
+	writeProject(t, map[string]string{"go.mod": "module test\ngo 1.22\n"})
 
 	code := Run([]string{"--policy", "/nonexistent/policy.json"})
 	if code == 0 {
@@ -289,60 +337,36 @@ func TestRunBadPolicyFile(t *testing.T) {
 }
 
 func TestRunInvalidPolicyJSON(t *testing.T) {
-	dir := t.TempDir()
-	gomod := "module test\ngo 1.22\n"
-	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte(gomod), 0600); err != nil {
-		t.Fatal(err)
-	}
-	policyPath := filepath.Join(dir, "policy.json")
-	if err := os.WriteFile(policyPath, []byte(`{invalid json`), 0600); err != nil {
-		t.Fatal(err)
-	}
-	orig, _ := os.Getwd()
-	defer os.Chdir(orig) //nolint:errcheck
-	os.Chdir(dir)        //nolint:errcheck
+	writeProject(t, map[string]string{
+		"go.mod":      "module test\ngo 1.22\n",
+		"policy.json": `{invalid json`,
+	})
 
-	code := Run([]string{"--policy", policyPath})
+	code := Run([]string{"--policy", "policy.json"})
 	if code == 0 {
 		t.Error("expected non-zero exit for malformed policy JSON")
 	}
 }
 
 func TestRunInvalidPolicyVersion(t *testing.T) {
-	dir := t.TempDir()
-	gomod := "module test\ngo 1.22\n"
-	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte(gomod), 0600); err != nil {
-		t.Fatal(err)
-	}
-	policyPath := filepath.Join(dir, "policy.json")
-	if err := os.WriteFile(policyPath, []byte(`{"version": 99}`), 0600); err != nil {
-		t.Fatal(err)
-	}
-	orig, _ := os.Getwd()
-	defer os.Chdir(orig) //nolint:errcheck
-	os.Chdir(dir)        //nolint:errcheck
+	writeProject(t, map[string]string{
+		"go.mod":      "module test\ngo 1.22\n",
+		"policy.json": `{"version": 99}`,
+	})
 
-	code := Run([]string{"--policy", policyPath})
+	code := Run([]string{"--policy", "policy.json"})
 	if code == 0 {
 		t.Error("expected non-zero exit for unsupported policy version")
 	}
 }
 
 func TestRunInvalidFailOn(t *testing.T) {
-	dir := t.TempDir()
-	gomod := "module test\ngo 1.22\n"
-	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte(gomod), 0600); err != nil {
-		t.Fatal(err)
-	}
-	policyPath := filepath.Join(dir, "policy.json")
-	if err := os.WriteFile(policyPath, []byte(`{"version":1,"fail_on":"critical"}`), 0600); err != nil {
-		t.Fatal(err)
-	}
-	orig, _ := os.Getwd()
-	defer os.Chdir(orig) //nolint:errcheck
-	os.Chdir(dir)        //nolint:errcheck
+	writeProject(t, map[string]string{
+		"go.mod":      "module test\ngo 1.22\n",
+		"policy.json": `{"version":1,"fail_on":"critical"}`,
+	})
 
-	code := Run([]string{"--policy", policyPath})
+	code := Run([]string{"--policy", "policy.json"})
 	if code == 0 {
 		t.Error("expected non-zero exit for invalid fail_on value")
 	}
@@ -352,16 +376,7 @@ func TestRunGoProjectPasses(t *testing.T) {
 	if testing.Short() {
 		t.Skip("Skipping integration test in short mode")
 	}
-	dir := t.TempDir()
-	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module test\ngo 1.22\n"), 0600); err != nil {
-		t.Fatal(err)
-	}
-	if err := os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\nfunc main() {}\n"), 0600); err != nil {
-		t.Fatal(err)
-	}
-	orig, _ := os.Getwd()
-	defer os.Chdir(orig) //nolint:errcheck
-	os.Chdir(dir)        //nolint:errcheck
+	writeProject(t, map[string]string{"go.mod": "module test\ngo 1.22\n", "main.go": "package main\nfunc main() {}\n"})
 
 	code := Run([]string{"--lang", "go"})
 	if code != 0 {
@@ -373,16 +388,7 @@ func TestRunJSONOutput(t *testing.T) {
 	if testing.Short() {
 		t.Skip("Skipping integration test in short mode")
 	}
-	dir := t.TempDir()
-	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module test\ngo 1.22\n"), 0600); err != nil {
-		t.Fatal(err)
-	}
-	if err := os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\nfunc main() {}\n"), 0600); err != nil {
-		t.Fatal(err)
-	}
-	orig, _ := os.Getwd()
-	defer os.Chdir(orig) //nolint:errcheck
-	os.Chdir(dir)        //nolint:errcheck
+	writeProject(t, map[string]string{"go.mod": "module test\ngo 1.22\n", "main.go": "package main\nfunc main() {}\n"})
 
 	code := Run([]string{"--json", "--lang", "go"})
 	if code != 0 {
@@ -394,16 +400,7 @@ func TestRunSARIFOutput(t *testing.T) {
 	if testing.Short() {
 		t.Skip("Skipping integration test in short mode")
 	}
-	dir := t.TempDir()
-	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module test\ngo 1.22\n"), 0600); err != nil {
-		t.Fatal(err)
-	}
-	if err := os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\nfunc main() {}\n"), 0600); err != nil {
-		t.Fatal(err)
-	}
-	orig, _ := os.Getwd()
-	defer os.Chdir(orig) //nolint:errcheck
-	os.Chdir(dir)        //nolint:errcheck
+	writeProject(t, map[string]string{"go.mod": "module test\ngo 1.22\n", "main.go": "package main\nfunc main() {}\n"})
 
 	code := Run([]string{"--sarif", "--lang", "go"})
 	if code != 0 {
@@ -415,22 +412,13 @@ func TestRunWithExcludePackages(t *testing.T) {
 	if testing.Short() {
 		t.Skip("Skipping integration test in short mode")
 	}
-	dir := t.TempDir()
-	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module test\ngo 1.22\n"), 0600); err != nil {
-		t.Fatal(err)
-	}
-	if err := os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\nfunc main() {}\n"), 0600); err != nil {
-		t.Fatal(err)
-	}
-	policyPath := filepath.Join(dir, "policy.json")
-	if err := os.WriteFile(policyPath, []byte(`{"version":1,"fail_on":"high","exclude_packages":["somelib/v2"]}`), 0600); err != nil {
-		t.Fatal(err)
-	}
-	orig, _ := os.Getwd()
-	defer os.Chdir(orig) //nolint:errcheck
-	os.Chdir(dir)        //nolint:errcheck
+	writeProject(t, map[string]string{
+		"go.mod":      "module test\ngo 1.22\n",
+		"main.go":     "package main\nfunc main() {}\n",
+		"policy.json": `{"version":1,"fail_on":"high","exclude_packages":["somelib/v2"]}`,
+	})
 
-	code := Run([]string{"--lang", "go", "--policy", policyPath})
+	code := Run([]string{"--lang", "go", "--policy", "policy.json"})
 	if code != 0 {
 		t.Errorf("Run with policy = %d, want 0", code)
 	}
@@ -470,3 +458,1358 @@ func TestIsExcluded(t *testing.T) {
 		}
 	}
 }
+
+func TestFilterByPackages(t *testing.T) {
+	g := graph.NewDependencyGraph()
+	for _, p := range []string{"example.com/a", "example.com/b", "lodash"} {
+		g.Packages[p] = &graph.Package{ImportPath: p}
+	}
+	reports := []report.CapabilityReport{
+		{Package: "example.com/a"},
+		{Package: "example.com/b"},
+		{Package: "lodash"},
+	}
+
+	names, err := parsePackageList("example.com/a, lodash,", g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := filterByPackages(reports, names)
+	if len(got) != 2 {
+		t.Fatalf("expected 2 reports, got %d: %+v", len(got), got)
+	}
+	for _, cr := range got {
+		if cr.Package != "example.com/a" && cr.Package != "lodash" {
+			t.Errorf("unexpected package in filtered reports: %s", cr.Package)
+		}
+	}
+
+	if _, err := parsePackageList("example.com/missing", g); err == nil {
+		t.Error("expected error when no named package exists in the graph")
+	}
+
+	// Health scoring covers only the modules of the selected packages.
+	for _, m := range []string{"example.com/a", "example.com/b"} {
+		mod := &graph.Module{Path: m, Version: "v1.0.0"}
+		g.Modules[m] = mod
+		g.Packages[m].Module = mod
+	}
+	if mods := healthModules(g, names); len(mods) != 1 || mods[0].Path != "example.com/a" {
+		t.Errorf("healthModules(--packages example.com/a,lodash) = %+v, want example.com/a only", mods)
+	}
+}
+
+func TestStrictConfidenceFailDecision(t *testing.T) {
+	failLevel := capability.RiskValue("medium")
+
+	var callSiteOnly capability.CapabilitySet
+	callSiteOnly.AddWithEvidence(capability.CapExec, capability.CapabilityEvidence{
+		Context: "exec.Command", Via: "callSite", Confidence: 0.75,
+	})
+	callSiteOnly.AddWithEvidence(capability.CapNetwork, capability.CapabilityEvidence{
+		Context: "http.Get", Via: "callSite", Confidence: 0.75,
+	})
+
+	actionable, informational := splitStrict(callSiteOnly)
+	if !actionable.IsEmpty() {
+		t.Errorf("call-site-only caps should not be actionable, got %v", actionable.List())
+	}
+	if !informational.Has(capability.CapExec) || !informational.Has(capability.CapNetwork) {
+		t.Errorf("expected exec and network to be informational, got %v", informational.List())
+	}
+	score := priority.ComputeFinal(actionable, nil, nil, 0, 0, 0)
+	if capability.RiskValue(score.Level) >= failLevel {
+		t.Errorf("call-site-only finding should not fail in strict mode, got level %s", score.Level)
+	}
+
+	importLevel := callSiteOnly
+	importLevel.AddWithEvidence(capability.CapExec, capability.CapabilityEvidence{
+		Context: `import "os/exec"`, Via: "import", Confidence: 0.90,
+	})
+	actionable, informational = splitStrict(importLevel)
+	if !actionable.Has(capability.CapExec) {
+		t.Fatalf("import-level exec should be actionable, got %v", actionable.List())
+	}
+	if informational.Has(capability.CapExec) {
+		t.Error("exec should not be informational once import-level evidence exists")
+	}
+	score = priority.ComputeFinal(actionable, nil, nil, 0, 0, 0)
+	if capability.RiskValue(score.Level) < failLevel {
+		t.Errorf("import-level finding should fail in strict mode, got level %s", score.Level)
+	}
+}
+
+func TestStrictTaint(t *testing.T) {
+	var actionable capability.CapabilitySet
+	actionable.Add(capability.CapEnv)
+	actionable.Add(capability.CapExec)
+
+	findings := []taint.TaintFinding{
+		{Package: "p", Source: capability.CapEnv, Sink: capability.CapExec},
+		{Package: "p", Source: capability.CapNetwork, Sink: capability.CapExec},
+	}
+	got := strictTaint(findings, actionable)
+	if len(got) != 1 || got[0].Source != capability.CapEnv {
+		t.Errorf("expected only env→exec to remain, got %+v", got)
+	}
+}
+
+func TestExceedsCompositeScore(t *testing.T) {
+	var caps capability.CapabilitySet
+	caps.Add(capability.CapExec) // weight 20 → composite 20 with neutral modifiers
+
+	comp, over := exceedsCompositeScore(caps, nil, 0, nil, 19.9)
+	if !over {
+		t.Errorf("composite %.1f should exceed max 19.9", comp.Composite)
+	}
+	if _, over := exceedsCompositeScore(caps, nil, 0, nil, 20); over {
+		t.Error("composite equal to max should not fail")
+	}
+}
+
+func TestWithoutSafeExec(t *testing.T) {
+	safe := map[string]bool{"git": true}
+	build := func(contexts ...string) capability.CapabilitySet {
+		var cs capability.CapabilitySet
+		cs.AddWithEvidence(capability.CapExec, capability.CapabilityEvidence{
+			Context: "os/exec", Via: "import", Confidence: 0.90,
+		})
+		for _, c := range contexts {
+			cs.AddWithEvidence(capability.CapExec, capability.CapabilityEvidence{
+				Context: c, Via: "callSite", Confidence: 0.75,
+			})
+		}
+		return cs
+	}
+
+	tests := []struct {
+		name     string
+		caps     capability.CapabilitySet
+		wantExec bool
+	}{
+		{"allowlisted constant", build(`exec.Command("git")`), false},
+		{"context variant", build(`exec.CommandContext("git")`), false},
+		{"variable command", build("exec.Command"), true},
+		{"non-allowlisted constant", build(`exec.Command("sh")`), true},
+		{"mixed", build(`exec.Command("git")`, "exec.Command"), true},
+		{"import only", build(), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := withoutSafeExec(tt.caps, safe)
+			if got.Has(capability.CapExec) != tt.wantExec {
+				t.Errorf("exec present = %v, want %v", got.Has(capability.CapExec), tt.wantExec)
+			}
+		})
+	}
+
+	if got := withoutSafeExec(build(`exec.Command("git")`), nil); !got.Has(capability.CapExec) {
+		t.Error("empty allowlist should leave exec in place")
+	}
+}
+
+func TestStartHealthMatchesSequential(t *testing.T) {
+	// Scoring waits for the analysis stand-in below to start, so it only
+	// finishes if startHealth runs it alongside the caller; a sequential
+	// implementation blocks here.
+	analyzing := make(chan struct{})
+	score := func(ctx context.Context, mods []health.ModuleRef) ([]report.HealthReport, health.HealthTiming) {
+		<-analyzing
+		out := make([]report.HealthReport, len(mods))
+		for i, m := range mods {
+			out[i] = report.HealthReport{Module: m.Path, Version: m.Version, Score: 100 - i}
+		}
+		return out, health.HealthTiming{ModuleCount: len(mods)}
+	}
+
+	g := &graph.DependencyGraph{Modules: map[string]*graph.Module{
+		"example.com/main": {Path: "example.com/main", Main: true},
+		"example.com/b":    {Path: "example.com/b", Version: "v1.0.0"},
+		"example.com/a":    {Path: "example.com/a", Version: "v0.2.0"},
+	}}
+	mods := healthModules(g, nil)
+	if len(mods) != 2 || mods[0].Path != "example.com/a" {
+		t.Fatalf("healthModules = %+v, want sorted non-main modules", mods)
+	}
+
+	run := startHealth(context.Background(), mods, score)
+	close(analyzing) // stands in for capability and taint analysis
+	gotReports, gotTiming := run.wait()
+
+	wantReports, wantTiming := score(context.Background(), mods)
+	if !reflect.DeepEqual(gotReports, wantReports) || gotTiming != wantTiming {
+		t.Errorf("concurrent health results differ from sequential:\ngot  %+v\nwant %+v", gotReports, wantReports)
+	}
+
+	var offline *healthPhase
+	if reports, _ := offline.wait(); reports != nil {
+		t.Errorf("offline wait should return no reports, got %+v", reports)
+	}
+}
+
+func TestWithoutIgnoredTaint(t *testing.T) {
+	findings := []taint.TaintFinding{
+		{Package: "p", Source: capability.CapEnv, Sink: capability.CapExec},
+		{Package: "p", Source: capability.CapNetwork, Sink: capability.CapCrypto},
+	}
+	got := withoutIgnoredTaint(findings, map[string]bool{capability.CapCrypto: true})
+	if len(got) != 1 || got[0].Sink != capability.CapExec {
+		t.Errorf("expected only env→exec to remain, got %+v", got)
+	}
+}
+
+func TestRestrictToFilesFromStdinList(t *testing.T) {
+	dir := t.TempDir()
+	pkgDir := filepath.Join(dir, "a")
+	otherDir := filepath.Join(dir, "b")
+
+	evidence := func(cs *capability.CapabilitySet, c, file string) {
+		cs.AddWithEvidence(c, capability.CapabilityEvidence{
+			File: file, Line: 1, Via: "callSite", Confidence: 0.75,
+		})
+	}
+
+	a := &graph.Package{ImportPath: "example.com/m/a", Dir: pkgDir, GoFiles: []string{"exec.go", "net.go", "fs.go"}}
+	evidence(&a.Capabilities, capability.CapExec, filepath.Join(pkgDir, "exec.go"))
+	evidence(&a.Capabilities, capability.CapNetwork, filepath.Join(pkgDir, "net.go"))
+	evidence(&a.Capabilities, capability.CapFSWrite, filepath.Join(pkgDir, "fs.go"))
+
+	b := &graph.Package{ImportPath: "example.com/m/b", Dir: otherDir, GoFiles: []string{"env.go"}}
+	evidence(&b.Capabilities, capability.CapEnv, filepath.Join(otherDir, "env.go"))
+
+	g := graph.NewDependencyGraph()
+	g.Packages[a.ImportPath] = a
+	g.Packages[b.ImportPath] = b
+
+	// Two staged files, as a pre-commit hook would pipe them: one relative,
+	// one absolute.
+	stdin := strings.NewReader("a/exec.go\n\n" + filepath.Join(pkgDir, "net.go") + "\n")
+	files, err := readFileList(stdin, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 2 {
+		t.Fatalf("readFileList returned %d files, want 2: %v", len(files), files)
+	}
+
+	owned := restrictToFiles(g, files)
+	if !owned[a.ImportPath] || owned[b.ImportPath] {
+		t.Errorf("owned packages = %v, want only %s", owned, a.ImportPath)
+	}
+
+	got := g.Packages[a.ImportPath].Capabilities
+	if !got.Has(capability.CapExec) || !got.Has(capability.CapNetwork) {
+		t.Errorf("expected exec and network from listed files, got %v", got.List())
+	}
+	if got.Has(capability.CapFSWrite) {
+		t.Error("fs:write comes from an unlisted file and should be dropped")
+	}
+}
+
+func TestPolicyThresholdsLowerHigh(t *testing.T) {
+	const score = 20.0
+
+	if got := defaultThresholds.level(score); got != "MEDIUM" {
+		t.Fatalf("default level(%v) = %s, want MEDIUM", score, got)
+	}
+
+	th, err := policyThresholds(policy{HighThreshold: 15})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := th.level(score); got != "HIGH" {
+		t.Errorf("level(%v) with high_threshold 15 = %s, want HIGH", score, got)
+	}
+	if got := th.level(12); got != "MEDIUM" {
+		t.Errorf("medium cutoff should stay at the default, level(12) = %s", got)
+	}
+
+	if _, err := policyThresholds(policy{MediumThreshold: 40}); err == nil {
+		t.Error("expected an error when medium_threshold is not below high_threshold")
+	}
+}
+
+func TestRunRequireJustification(t *testing.T) {
+	writeProject(t, map[string]string{
+		"go.mod":      "module test\ngo 1.22\n",
+		"policy.json": `{"version":1,"allow_exceptions":[{"package":"test/pkg","capabilities":["exec"],"owner":"platform-team"}]}`,
+	})
+
+	if code := Run([]string{"--policy", "policy.json", "--require-justification"}); code != 2 {
+		t.Errorf("expected exit 2 for exception without reason, got %d", code)
+	}
+}
+
+func TestExceptionSummaryJustification(t *testing.T) {
+	allowExceptions := []PolicyException{
+		{Package: "test/pkg", Capabilities: []string{"exec"}, Owner: "platform-team", Reason: "runs git only"},
+	}
+	if err := checkJustifications(allowExceptions, true); err != nil {
+		t.Fatalf("justified exception rejected: %v", err)
+	}
+	_, _, stats := buildExceptions(allowExceptions)
+
+	f, err := os.CreateTemp(t.TempDir(), "*.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeExceptionSummary(f, stats)
+	f.Close()
+	got, _ := os.ReadFile(f.Name())
+	if !strings.Contains(string(got), "test/pkg [exec] owner=platform-team: runs git only") {
+		t.Errorf("summary missing justification: %s", got)
+	}
+}
+
+func TestRunTimeoutExitCode(t *testing.T) {
+	writeProject(t, nodeApp("require('child_process').exec('id');\n", nil))
+
+	// An engine phase that never finishes on its own.
+	release := make(chan struct{})
+	defer close(release)
+	targets := make(chan string, 1)
+	fast := runEngines
+	defer func() { runEngines = fast }()
+	runEngines = func(_, _, _ string, _ *graph.DependencyGraph, opts astpipeline.Options) engineResults {
+		targets <- opts.Go.Target
+		<-release
+		return engineResults{}
+	}
+
+	start := time.Now()
+	code := Run([]string{"--lang", "node", "--timeout", "200ms", "--fail-on", "low", "--target", "wasm"})
+	if code != exitTimeout {
+		t.Errorf("Run() = %d, want %d on timeout", code, exitTimeout)
+	}
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Errorf("timeout fired after %s", elapsed)
+	}
+	// The abandoned phase was handed the scan's own settings.
+	if got := <-targets; got != "wasm" {
+		t.Errorf("engine phase ran for target %q, want wasm", got)
+	}
+
+	// The next scan does not wait for the abandoned phase. The same
+	// failing project without a timeout reports a policy failure.
+	runEngines = fast
+	if code := Run([]string{"--lang", "node", "--timeout", "1m", "--fail-on", "low"}); code != 1 {
+		t.Errorf("Run() without expiry = %d, want 1", code)
+	}
+}
+
+func TestRunNDJSONStreams(t *testing.T) {
+	writeProject(t, nodeApp("require('child_process').exec('id');\n", nil))
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	oldStdout := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = oldStdout }()
+	out := bufio.NewReader(r)
+
+	// The capability lines are on stdout before the engine phase starts.
+	fast := runEngines
+	defer func() { runEngines = fast }()
+	var early string
+	runEngines = func(dir, lang, base string, g *graph.DependencyGraph, opts astpipeline.Options) engineResults {
+		line := make(chan string, 1)
+		go func() {
+			l, _ := out.ReadString('\n')
+			line <- l
+		}()
+		select {
+		case early = <-line:
+		case <-time.After(5 * time.Second):
+		}
+		return fast(dir, lang, base, g, opts)
+	}
+
+	code := Run([]string{"--lang", "node", "--format", "ndjson", "--fail-on", "low"})
+	w.Close()
+	os.Stdout = oldStdout
+	rest, _ := io.ReadAll(out)
+	if code != 1 {
+		t.Errorf("Run() = %d, want 1", code)
+	}
+	if !strings.Contains(early, `"type":"capability"`) {
+		t.Fatalf("no capability line before the engine phase, got %q", early)
+	}
+
+	lines := strings.Split(strings.TrimSpace(early+string(rest)), "\n")
+	last := lines[len(lines)-1]
+	if !strings.Contains(last, `"type":"summary"`) || !strings.Contains(last, `"passed":false`) {
+		t.Errorf("last line = %s, want the failing summary", last)
+	}
+}
+
+func TestRunTrustedPrefixes(t *testing.T) {
+	runner := "const cp = require('child_process');\nconst http = require('http');\n" +
+		"http.get(process.env.URL, (res) => cp.exec(res.headers.cmd));\n"
+	dir := writeProject(t, nodeApp("module.exports = 1;\n", map[string]string{"@acme/runner": runner}))
+	writePolicy := func(pol string) string {
+		path := filepath.Join(t.TempDir(), "policy.json")
+		if err := os.WriteFile(path, []byte(pol), 0600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	// The dependency's exec capability fails the build by default.
+	untrusted := writePolicy(`{"version":1,"fail_on":"high"}`)
+	if code := Run([]string{"--lang", "node", "--policy", untrusted}); code != 1 {
+		t.Fatalf("Run() without trusted_prefixes = %d, want 1", code)
+	}
+
+	trusted := writePolicy(`{"version":1,"fail_on":"high","trusted_prefixes":["@acme"]}`)
+	if code := Run([]string{"--lang", "node", "--policy", trusted}); code != 0 {
+		t.Errorf("Run() with @acme trusted = %d, want 0", code)
+	}
+
+	// Trusting the main module's own name does not exempt its code.
+	if err := os.WriteFile(filepath.Join(dir, "index.js"), []byte(runner), 0600); err != nil {
+		t.Fatal(err)
+	}
+	self := writePolicy(`{"version":1,"fail_on":"high","trusted_prefixes":["@acme","app"]}`)
+	if code := Run([]string{"--lang", "node", "--policy", self}); code != 1 {
+		t.Errorf("Run() trusting the main module = %d, want 1", code)
+	}
+}
+
+func TestIsTrustedModule(t *testing.T) {
+	prefixes := []string{"golang.org/x", "github.com/acme/"}
+	tests := []struct {
+		mod  *graph.Module
+		want bool
+	}{
+		{&graph.Module{Path: "golang.org/x/sys"}, true},
+		{&graph.Module{Path: "golang.org/x"}, true},
+		{&graph.Module{Path: "golang.org/xyz"}, false},
+		{&graph.Module{Path: "github.com/acme/lib"}, true},
+		{&graph.Module{Path: "github.com/acme/app", Main: true}, false},
+		{nil, false},
+	}
+	for _, tt := range tests {
+		if got := isTrustedModule(tt.mod, prefixes); got != tt.want {
+			t.Errorf("isTrustedModule(%v) = %v, want %v", tt.mod, got, tt.want)
+		}
+	}
+}
+
+func TestRunProfile(t *testing.T) {
+	writeProject(t, nodeApp("require('child_process').exec('id');\n", nil))
+
+	out := t.TempDir()
+	cpu := filepath.Join(out, "cpu.pprof")
+	mem := filepath.Join(out, "mem.pprof")
+	if code := Run([]string{"--lang", "node", "--profile", cpu, "--memprofile", mem}); code != 0 {
+		t.Fatalf("Run() = %d, want 0", code)
+	}
+	for _, path := range []string{cpu, mem} {
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatalf("profile not written: %v", err)
+		}
+		if info.Size() == 0 {
+			t.Errorf("%s is empty", filepath.Base(path))
+		}
+	}
+
+	if code := Run([]string{"--lang", "node", "--profile", filepath.Join(out, "missing", "cpu.pprof")}); code != 2 {
+		t.Errorf("Run() with unwritable profile path = %d, want 2", code)
+	}
+}
+
+func TestRunRequireCapabilities(t *testing.T) {
+	dir := writeProject(t, nodeApp("module.exports = 1;\n", map[string]string{
+		"@acme/hash": "const crypto = require('crypto');\nmodule.exports = (s) => crypto.createHash('sha256').update(s).digest('hex');\n",
+	}))
+	pol := filepath.Join(t.TempDir(), "policy.json")
+	if err := os.WriteFile(pol, []byte(`{"version":1,"fail_on":"high","require_capabilities":{"@acme/*":["crypto"]}}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if code := Run([]string{"--lang", "node", "--policy", pol}); code != 0 {
+		t.Fatalf("Run() with crypto present = %d, want 0", code)
+	}
+
+	// A fork that no longer hashes anything loses the expected capability.
+	if err := os.WriteFile(filepath.Join(dir, "node_modules/@acme/hash/index.js"), []byte("module.exports = (s) => s;\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if code := Run([]string{"--lang", "node", "--policy", pol}); code != 1 {
+		t.Errorf("Run() with crypto missing = %d, want 1", code)
+	}
+
+	// A required package that is not installed at all fails as well.
+	gone := filepath.Join(t.TempDir(), "policy.json")
+	if err := os.WriteFile(gone, []byte(`{"version":1,"require_capabilities":{"@acme/cipher":["crypto"]}}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	var sr report.ScanReport
+	var code int
+	js := captureStdout(func() { code = Run([]string{"--lang", "node", "--policy", gone, "--json"}) })
+	if code != 1 {
+		t.Errorf("Run() with no package matching a required pattern = %d, want 1", code)
+	}
+	if err := json.Unmarshal(js, &sr); err != nil {
+		t.Fatal(err)
+	}
+	if want := "no package matches require_capabilities pattern @acme/cipher"; sr.FailReason != want {
+		t.Errorf("FailReason = %q, want %q", sr.FailReason, want)
+	}
+
+	bad := filepath.Join(t.TempDir(), "policy.json")
+	if err := os.WriteFile(bad, []byte(`{"version":1,"require_capabilities":{"@acme/*":["crypt"]}}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if code := Run([]string{"--lang", "node", "--policy", bad}); code != 2 {
+		t.Errorf("Run() with unknown capability = %d, want 2", code)
+	}
+}
+
+func TestMissingRequiredCaps(t *testing.T) {
+	var caps capability.CapabilitySet
+	caps.Add(capability.CapCrypto)
+	require := map[string][]string{
+		"github.com/acme/*":      {"crypto", "network"},
+		"github.com/acme/hash":   {"Network", "fs:read"},
+		"github.com/other/thing": {"exec"},
+	}
+	got := missingRequiredCaps("github.com/acme/hash", caps, require)
+	if want := []string{"fs:read", "network"}; !slices.Equal(got, want) {
+		t.Errorf("missingRequiredCaps = %v, want %v", got, want)
+	}
+	if got := missingRequiredCaps("github.com/unrelated", caps, require); len(got) != 0 {
+		t.Errorf("unmatched package: missing = %v, want none", got)
+	}
+}
+
+func TestUnmatchedRequirePatterns(t *testing.T) {
+	g := &graph.DependencyGraph{Packages: map[string]*graph.Package{
+		"github.com/acme/hash":     {ImportPath: "github.com/acme/hash"},
+		"github.com/acme/hash/sha": {ImportPath: "github.com/acme/hash/sha"},
+	}}
+	require := map[string][]string{
+		"github.com/acme/*":      {"crypto"},
+		"github.com/acme/hash":   {"crypto"},
+		"github.com/acme/cipher": {"crypto"},
+		"github.com/other/*":     {"exec"},
+	}
+	got := unmatchedRequirePatterns(g, require)
+	if want := []string{"github.com/acme/cipher", "github.com/other/*"}; !slices.Equal(got, want) {
+		t.Errorf("unmatchedRequirePatterns = %v, want %v", got, want)
+	}
+}
+
+func TestDeepCapabilities(t *testing.T) {
+	var risky, benign capability.CapabilitySet
+	risky.Add(capability.CapExec)
+	risky.Add(capability.CapEnv)
+	benign.Add(capability.CapEnv)
+	reports := []report.CapabilityReport{
+		{Package: "direct", Capabilities: risky, Depth: 1},
+		{Package: "deep/exec", Capabilities: risky, Depth: 4},
+		{Package: "deep/env", Capabilities: benign, Depth: 5},
+	}
+	got := deepCapabilities(reports, 3, capability.HighRisk{})
+	want := []string{"exec enters at dependency depth 4 through deep/exec (max_capability_depth 3)"}
+	if !slices.Equal(got, want) {
+		t.Errorf("deepCapabilities = %q, want %q", got, want)
+	}
+
+	envOnly, err := capability.ParseHighRisk([]string{"env"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got = deepCapabilities(reports, 3, envOnly)
+	want = []string{
+		"env enters at dependency depth 4 through deep/exec (max_capability_depth 3)",
+		"env enters at dependency depth 5 through deep/env (max_capability_depth 3)",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("deepCapabilities with high_risk_capabilities [env] = %q, want %q", got, want)
+	}
+}
+
+func TestRunFirstPartyFailOn(t *testing.T) {
+	spawn := "const cp = require('child_process');\ncp.exec('id');\n"
+	dir := writeProject(t, nodeApp("module.exports = 1;\n", map[string]string{"spawner": spawn}))
+	pol := filepath.Join(t.TempDir(), "policy.json")
+	if err := os.WriteFile(pol, []byte(`{"version":1,"fail_on":"high","first_party_fail_on":"medium"}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	// A MEDIUM dependency passes the dependency gate.
+	if code := Run([]string{"--lang", "node", "--policy", pol}); code != 0 {
+		t.Fatalf("Run() with MEDIUM dependency = %d, want 0", code)
+	}
+
+	// The same MEDIUM capability in our own code fails the stricter gate.
+	if err := os.WriteFile(filepath.Join(dir, "index.js"), []byte(spawn), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if code := Run([]string{"--lang", "node", "--policy", pol}); code != 1 {
+		t.Errorf("Run() with MEDIUM first-party code = %d, want 1", code)
+	}
+}
+
+func TestRunFailOnNewBaselineStrategy(t *testing.T) {
+	dir := writeProject(t, nodeApp("module.exports = 1;\n", map[string]string{
+		"spawner": "const cp = require('child_process');\ncp.exec('id');\n",
+	}))
+	tmp := t.TempDir()
+	writePolicy := func(name, pol string) string {
+		path := filepath.Join(tmp, name)
+		if err := os.WriteFile(path, []byte(pol), 0600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	strict := writePolicy("strict.json", `{"version":1,"fail_on":"medium"}`)
+	lenient := writePolicy("lenient.json", `{"version":1,"fail_on":"high"}`)
+	empty := writePolicy("empty.json", `{}`)
+	baseline := filepath.Join(tmp, "baseline.json")
+
+	// The MEDIUM exec finding fails fail_on medium whether or not it is
+	// new, so read the failing findings to tell whether --fail-on-new
+	// flagged it.
+	failsAsNew := func(pol string, extra ...string) bool {
+		t.Helper()
+		var findings []failingFinding
+		args := append([]string{"--lang", "node", "--policy", pol, "--diff-only", "--json", "--fail-on-new"}, extra...)
+		js := captureStdout(func() { Run(args) })
+		if err := json.Unmarshal(js, &findings); err != nil {
+			t.Fatalf("--diff-only --json: %v\n%s", err, js)
+		}
+		for _, f := range findings {
+			if strings.HasPrefix(f.Rule, "new since baseline") {
+				return true
+			}
+		}
+		return false
+	}
+
+	if code := Run([]string{"--lang", "node", "--policy", lenient, "--formats", "json=" + baseline}); code != 0 {
+		t.Fatalf("baseline scan = %d, want 0", code)
+	}
+	if !failsAsNew(strict, "--baseline-compare", empty) {
+		t.Error("--fail-on-new against an empty baseline did not flag the exec finding")
+	}
+	if failsAsNew(strict, "--baseline-compare", baseline) {
+		t.Error("--fail-on-new against an identical baseline flagged the exec finding")
+	}
+	if code := Run([]string{"--lang", "node", "--policy", lenient, "--baseline-compare", empty, "--fail-on-new"}); code != 0 {
+		t.Errorf("--fail-on-new with a new MEDIUM finding under fail_on high = %d, want 0", code)
+	}
+
+	// A finding the policy does not gate is never new: an exception, a
+	// trusted prefix, or exclude_packages keeps it from failing.
+	for name, pol := range map[string]string{
+		"allow_exceptions": `{"version":1,"fail_on":"medium","allow_exceptions":[{"package":"spawner","capabilities":["exec"]}]}`,
+		"trusted_prefixes": `{"version":1,"fail_on":"medium","trusted_prefixes":["spawner"]}`,
+		"exclude_packages": `{"version":1,"fail_on":"medium","exclude_packages":["spawner"]}`,
+	} {
+		path := writePolicy(name+".json", pol)
+		if code := Run([]string{"--lang", "node", "--policy", path, "--baseline-compare", empty, "--fail-on-new"}); code != 0 {
+			t.Errorf("--fail-on-new with spawner under %s = %d, want 0", name, code)
+		}
+	}
+
+	// Shift the exec call down a line: only the exact strategy sees a new
+	// finding.
+	shifted := "// spawner\nconst cp = require('child_process');\ncp.exec('id');\n"
+	if err := os.WriteFile(filepath.Join(dir, "node_modules/spawner/index.js"), []byte(shifted), 0600); err != nil {
+		t.Fatal(err)
+	}
+	for strategy, want := range map[string]bool{"exact": true, "rule": false, "module": false} {
+		if got := failsAsNew(strict, "--baseline-compare", baseline, "--baseline-strategy", strategy); got != want {
+			t.Errorf("--baseline-strategy %s after a line shift flagged a new finding = %v, want %v", strategy, got, want)
+		}
+	}
+
+	// Move the exec call to another file: again only the exact strategy
+	// sees a new finding.
+	moved := map[string]string{
+		"node_modules/spawner/index.js": "module.exports = require('./lib');\n",
+		"node_modules/spawner/lib.js":   "const cp = require('child_process');\ncp.exec('id');\n",
+	}
+	for name, src := range moved {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(src), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for strategy, want := range map[string]bool{"exact": true, "rule": false, "module": false} {
+		if got := failsAsNew(strict, "--baseline-compare", baseline, "--baseline-strategy", strategy); got != want {
+			t.Errorf("--baseline-strategy %s after a file move flagged a new finding = %v, want %v", strategy, got, want)
+		}
+	}
+
+	if code := Run([]string{"--lang", "node", "--policy", strict, "--baseline-strategy", "fuzzy"}); code != 2 {
+		t.Errorf("unknown --baseline-strategy = %d, want 2", code)
+	}
+	if code := Run([]string{"--lang", "node", "--policy", strict, "--fail-on-new"}); code != 2 {
+		t.Errorf("--fail-on-new without --baseline-compare = %d, want 2", code)
+	}
+}
+
+func TestRunEnrichPassthrough(t *testing.T) {
+	if _, err := exec.LookPath("cat"); err != nil {
+		t.Skip("cat not available")
+	}
+	writeProject(t, nodeApp("module.exports = 1;\n", map[string]string{
+		"spawner": "require('child_process').exec('id');\n",
+	}))
+
+	var plainCode, enrichedCode int
+	plain := captureStdout(func() { plainCode = Run([]string{"--lang", "node", "--json"}) })
+	enriched := captureStdout(func() { enrichedCode = Run([]string{"--lang", "node", "--json", "--enrich", "cat"}) })
+	if plainCode != enrichedCode {
+		t.Errorf("exit code with --enrich cat = %d, without = %d", enrichedCode, plainCode)
+	}
+	if !bytes.Equal(plain, enriched) {
+		t.Errorf("passthrough enrichment changed the report:\nwithout:\n%s\nwith:\n%s", plain, enriched)
+	}
+
+	if code := Run([]string{"--lang", "node", "--enrich", "false"}); code != 2 {
+		t.Errorf("Run() with failing enricher = %d, want 2", code)
+	}
+}
+
+func TestEnrichReportValidation(t *testing.T) {
+	sr := report.ScanReport{SchemaVersion: "v1", Passed: false, FailReason: "package x has HIGH risk"}
+	script := func(body string) string {
+		path := filepath.Join(t.TempDir(), "enrich.sh")
+		if err := os.WriteFile(path, []byte("#!/bin/sh\n"+body+"\n"), 0700); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	for name, body := range map[string]string{
+		"not json":       "echo not json",
+		"unknown field":  `echo '{"schema_version":"v1","Bogus":1}'`,
+		"schema version": `echo '{"schema_version":"v2"}'`,
+	} {
+		if _, err := enrichReport(context.Background(), script(body), sr); err == nil {
+			t.Errorf("%s: enrichReport succeeded, want error", name)
+		}
+	}
+
+	// An enricher may annotate the report but not flip the verdict.
+	got, err := enrichReport(context.Background(), script(`echo '{"schema_version":"v1","suggestions":[{"package":"x"}],"Passed":true}'`), sr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Suggestions) != 1 {
+		t.Errorf("suggestions = %+v, want the enricher's one", got.Suggestions)
+	}
+	if got.Passed || got.FailReason != sr.FailReason {
+		t.Errorf("verdict = %v %q, want the original failure", got.Passed, got.FailReason)
+	}
+}
+
+func TestRunDiffOnly(t *testing.T) {
+	risky := "const cp = require('child_process');\nconst http = require('http');\n" +
+		"http.get(process.env.URL, (res) => cp.exec(res.headers.cmd));\n"
+	writeProject(t, nodeApp("module.exports = 1;\n", map[string]string{
+		"alpha": risky,
+		"beta":  risky,
+		"calm":  "module.exports = (a, b) => a + b;\n",
+	}))
+
+	var code int
+	out := string(captureStdout(func() { code = Run([]string{"--lang", "node", "--diff-only"}) }))
+	if code != 1 {
+		t.Errorf("Run(--diff-only) = %d, want 1", code)
+	}
+	for _, want := range []string{"2 finding(s)", "alpha", "beta", "HIGH risk (fail_on high)"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("--diff-only output missing %q:\n%s", want, out)
+		}
+	}
+	for _, unwanted := range []string{"calm", "=== Capability Report ==="} {
+		if strings.Contains(out, unwanted) {
+			t.Errorf("--diff-only output contains %q:\n%s", unwanted, out)
+		}
+	}
+
+	var findings []failingFinding
+	js := captureStdout(func() { Run([]string{"--lang", "node", "--diff-only", "--json"}) })
+	if err := json.Unmarshal(js, &findings); err != nil {
+		t.Fatalf("--diff-only --json: %v\n%s", err, js)
+	}
+	if len(findings) != 2 || findings[0].Package != "alpha" || findings[1].Package != "beta" {
+		t.Errorf("--diff-only --json = %+v, want alpha and beta", findings)
+	}
+
+	// Project-wide gates run after capability failures too, so the list
+	// stays complete.
+	if err := os.WriteFile("policy.json", []byte(`{"require_baseline": true}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	findings = nil
+	js = captureStdout(func() { Run([]string{"--lang", "node", "--diff-only", "--json", "--policy", "policy.json"}) })
+	if err := json.Unmarshal(js, &findings); err != nil {
+		t.Fatalf("--diff-only --json: %v\n%s", err, js)
+	}
+	if len(findings) != 3 || findings[2].Rule != "require_baseline" {
+		t.Errorf("--diff-only with require_baseline = %+v, want alpha, beta, and require_baseline", findings)
+	}
+}
+
+func TestRunCacheStats(t *testing.T) {
+	t.Setenv("HOME", t.TempDir()) // keep the default summary cache out of the real home
+	writeProject(t, nodeApp("const cp = require('child_process');\n"+
+		"function run(cmd) { return cp.execSync(cmd); }\n"+
+		"function main() { return run('ls'); }\n"+
+		"module.exports = main;\n", nil))
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	oldStderr := os.Stderr
+	os.Stderr = w
+	captureStdout(func() { Run([]string{"--lang", "node", "--cache-stats"}) })
+	w.Close()
+	os.Stderr = oldStderr
+	stderr, _ := io.ReadAll(r)
+
+	m := regexp.MustCompile(`cache: enabled \(.+\): (\d+) hits, (\d+) misses`).FindSubmatch(stderr)
+	if m == nil {
+		t.Fatalf("--cache-stats output missing cache stats:\n%s", stderr)
+	}
+	if string(m[1]) == "0" && string(m[2]) == "0" {
+		t.Errorf("no cache lookups reported: %s", m[0])
+	}
+}
+
+func TestWriteCacheStatsDisabled(t *testing.T) {
+	var buf bytes.Buffer
+	writeCacheStats(&buf, interproc.CacheStats{})
+	if got := buf.String(); got != "cache: disabled\n" {
+		t.Errorf("writeCacheStats(disabled) = %q", got)
+	}
+}
+
+func TestRunFormatsWritesEachFile(t *testing.T) {
+	dir := writeProject(t, nodeApp("const cp = require('child_process');\ncp.exec('ls');\n", nil))
+
+	var code int
+	captureStdout(func() {
+		code = Run([]string{"--lang", "node", "--formats", "sarif=out.sarif,json=report.json"})
+	})
+	if code != 0 {
+		t.Fatalf("Run(--formats) = %d, want 0", code)
+	}
+
+	var sr report.ScanReport
+	data, err := os.ReadFile(filepath.Join(dir, "report.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(data, &sr); err != nil || sr.SchemaVersion == "" {
+		t.Errorf("report.json is not a scan report (err %v):\n%s", err, data)
+	}
+
+	var sarif struct {
+		Version string            `json:"version"`
+		Runs    []json.RawMessage `json:"runs"`
+	}
+	data, err = os.ReadFile(filepath.Join(dir, "out.sarif"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(data, &sarif); err != nil || sarif.Version != "2.1.0" || len(sarif.Runs) != 1 {
+		t.Errorf("out.sarif is not a SARIF 2.1.0 log (err %v):\n%s", err, data)
+	}
+}
+
+func TestParseFormats(t *testing.T) {
+	got, err := parseFormats("sarif=a.sarif, JSON=b.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []formatTarget{{"sarif", "a.sarif"}, {"json", "b.json"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseFormats = %+v, want %+v", got, want)
+	}
+	for _, bad := range []string{"sarif", "html=a.html", "json=", "json=a,sarif=a"} {
+		if _, err := parseFormats(bad); err == nil {
+			t.Errorf("parseFormats(%q) should fail", bad)
+		}
+	}
+}
+
+func TestRunUpdateBaseline(t *testing.T) {
+	risky := "const cp = require('child_process');\nconst http = require('http');\n" +
+		"http.get(process.env.URL, (res) => cp.exec(res.headers.cmd));\n"
+	for _, tt := range []struct {
+		name     string
+		dep      string
+		wantCode int
+	}{
+		{"pass", "module.exports = (a, b) => a + b;\n", 0},
+		{"fail", risky, 1},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := writeProject(t, nodeApp("module.exports = 1;\n", map[string]string{"dep": tt.dep}))
+
+			var code int
+			captureStdout(func() { code = Run([]string{"--lang", "node", "--update-baseline"}) })
+			if code != tt.wantCode {
+				t.Fatalf("Run(--update-baseline) = %d, want %d", code, tt.wantCode)
+			}
+			h, err := history.Load(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			wantSnaps := 0
+			if tt.wantCode == 0 {
+				wantSnaps = 1
+			}
+			if len(h.Snapshots) != wantSnaps {
+				t.Fatalf("baseline has %d snapshots, want %d", len(h.Snapshots), wantSnaps)
+			}
+			if wantSnaps == 1 {
+				mods := h.Snapshots[0].Modules
+				if len(mods) != 1 || mods[0].Module != "dep" || mods[0].Version != "1.0.0" {
+					t.Errorf("snapshot modules = %+v, want dep@1.0.0", mods)
+				}
+			}
+		})
+	}
+}
+
+func TestListExceptions(t *testing.T) {
+	now := time.Date(2026, 5, 27, 15, 0, 0, 0, time.UTC)
+	list := listExceptions([]PolicyException{
+		{Package: "github.com/later/pkg", Capabilities: []string{"network"}, Expires: "2026-07-01"},
+		{Package: "github.com/forever/pkg", Capabilities: []string{"env"}},
+		{Package: "github.com/soon/pkg", Capabilities: []string{"exec"}, Expires: "2026-06-01", Owner: "alice", Reason: "build tool"},
+		{Package: "github.com/old/pkg", Capabilities: []string{"exec"}, Expires: "2026-05-20"},
+	}, now)
+
+	var order []string
+	for _, st := range list {
+		order = append(order, st.Package)
+	}
+	want := []string{"github.com/old/pkg", "github.com/soon/pkg", "github.com/later/pkg", "github.com/forever/pkg"}
+	if !slices.Equal(order, want) {
+		t.Fatalf("order = %v, want %v", order, want)
+	}
+	soon := list[1]
+	if soon.Status != "active" || soon.DaysLeft == nil || *soon.DaysLeft != 5 {
+		t.Errorf("expiring-soon exception = %+v, want active with 5 days left", soon)
+	}
+	if list[0].Status != "expired" || *list[0].DaysLeft != -7 {
+		t.Errorf("expired exception = %+v, want expired 7 days ago", list[0])
+	}
+	if list[3].Status != "never" || list[3].DaysLeft != nil {
+		t.Errorf("exception without expiry = %+v, want never", list[3])
+	}
+
+	var buf bytes.Buffer
+	writeExceptionList(&buf, list)
+	for _, s := range []string{"5d left", "EXPIRED 7d ago", "alice", "build tool"} {
+		if !strings.Contains(buf.String(), s) {
+			t.Errorf("table missing %q:\n%s", s, buf.String())
+		}
+	}
+}
+
+func TestTraceEvidenceBoostsConfirmedExec(t *testing.T) {
+	trace := filepath.Join(t.TempDir(), "trace.json")
+	events := `[
+  {"kind":"ast_call","detail":"main.main -> runner.Run (conf=0.80)"},
+  {"kind":"subprocess","detail":"/usr/bin/git","package":"example.com/cmd/tool"}
+]`
+	if err := os.WriteFile(trace, []byte(events), 0600); err != nil {
+		t.Fatal(err)
+	}
+	traced, err := loadTraceEvidence(trace)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !traced.confirmed[capability.CapExec] || len(traced.confirmed) != 1 {
+		t.Fatalf("confirmed = %v, want exec only", traced.confirmed)
+	}
+	if len(traced.roots) != 1 || traced.roots[0] != "example.com/cmd/tool" {
+		t.Fatalf("roots = %v, want [example.com/cmd/tool]", traced.roots)
+	}
+
+	var caps capability.CapabilitySet
+	caps.AddWithEvidence(capability.CapExec, capability.CapabilityEvidence{
+		File: "runner.go", Line: 12, Context: "exec.Command(name)", Via: "callSite", Confidence: 0.75,
+	})
+	caps.AddWithEvidence(capability.CapEnv, capability.CapabilityEvidence{
+		File: "runner.go", Line: 8, Context: "os.Getenv", Via: "callSite", Confidence: 0.75,
+	})
+	var other capability.CapabilitySet
+	other.AddWithEvidence(capability.CapExec, capability.CapabilityEvidence{
+		File: "other.go", Line: 3, Context: "exec.Command(name)", Via: "callSite", Confidence: 0.75,
+	})
+	// The traced binary imports runner; example.com/other never ran.
+	g := &graph.DependencyGraph{
+		Packages: map[string]*graph.Package{
+			"example.com/cmd/tool": {ImportPath: "example.com/cmd/tool"},
+			"example.com/runner":   {ImportPath: "example.com/runner", Capabilities: caps},
+			"example.com/other":    {ImportPath: "example.com/other", Capabilities: other},
+		},
+		Edges: map[string][]string{"example.com/cmd/tool": {"example.com/runner"}},
+	}
+
+	// Without runtime confirmation, a call-site-only exec is downgraded.
+	if _, informational := splitStrict(caps); !informational.Has(capability.CapExec) {
+		t.Fatal("unconfirmed exec should be informational under --strict-confidence")
+	}
+
+	if n := boostConfirmed(g, traced); n != 1 {
+		t.Fatalf("boostConfirmed() = %d packages, want 1", n)
+	}
+	if got := g.Packages["example.com/other"].Capabilities.Confidence(capability.CapExec); got != 0.75 {
+		t.Errorf("exec confidence of a package outside the traced binary = %.2f, want unchanged 0.75", got)
+	}
+	boosted := g.Packages["example.com/runner"].Capabilities
+	if got := boosted.Confidence(capability.CapExec); got != 1.0 {
+		t.Errorf("exec confidence = %.2f, want 1.0", got)
+	}
+	if got := boosted.Confidence(capability.CapEnv); got != 0.75 {
+		t.Errorf("env confidence = %.2f, want unchanged 0.75", got)
+	}
+	actionable, informational := splitStrict(boosted)
+	if !actionable.Has(capability.CapExec) || informational.Has(capability.CapExec) {
+		t.Error("trace-confirmed exec was downgraded to informational")
+	}
+	if filtered := filterCapsConfidence(boosted, 0.9); !filtered.Has(capability.CapExec) {
+		t.Error("trace-confirmed exec was dropped by confidence_threshold")
+	}
+}
+
+func TestRunDirectoryPolicies(t *testing.T) {
+	env := "module.exports = () => process.env.HOME;\n"
+	lock := `{"name": "mono", "version": "1.0.0", "lockfileVersion": 3, "packages": {
+		"": {"name": "mono", "version": "1.0.0"}}}`
+	dir := writeProject(t, map[string]string{
+		"package.json":                    `{"name": "mono", "version": "1.0.0", "workspaces": ["apps/*", "lib/*"]}`,
+		"package-lock.json":               lock,
+		"apps/tool/package.json":          `{"name": "tool", "version": "1.0.0"}`,
+		"apps/tool/index.js":              env,
+		"apps/legacy/package.json":        `{"name": "legacy", "version": "1.0.0"}`,
+		"apps/legacy/index.js":            env,
+		"lib/experimental/package.json":   `{"name": "experimental", "version": "1.0.0"}`,
+		"lib/experimental/index.js":       env,
+		"apps/.gorisk/policy.json":        `{"fail_on": "low"}`,
+		"apps/legacy/.gorisk/policy.json": `{"deny_capabilities": ["exec"]}`,
+	})
+
+	// apps/ is held to the strictest gate and apps/legacy inherits it;
+	// lib/experimental keeps the default fail_on high.
+	var code int
+	js := captureStdout(func() { code = Run([]string{"--lang", "node", "--diff-only", "--json"}) })
+	if code != 1 {
+		t.Errorf("Run() = %d, want 1", code)
+	}
+	var findings []failingFinding
+	if err := json.Unmarshal(js, &findings); err != nil {
+		t.Fatalf("--diff-only --json: %v\n%s", err, js)
+	}
+	got := map[string]string{}
+	for _, f := range findings {
+		got[f.Package] = f.Rule
+	}
+	want := map[string]string{
+		"tool":   "LOW risk (fail_on low, apps/.gorisk/policy.json)",
+		"legacy": "LOW risk (fail_on low, apps/legacy/.gorisk/policy.json)",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("failing packages = %v, want %v", got, want)
+	}
+
+	// The reported risk level uses each package's own thresholds: exec
+	// scores 20, MEDIUM by default but LOW under a medium_threshold of 25.
+	spawn := "require('child_process').exec('id');\n"
+	for name, src := range map[string]string{
+		"apps/tool/index.js":                   spawn,
+		"lib/experimental/index.js":            spawn,
+		"lib/experimental/.gorisk/policy.json": `{"medium_threshold": 25, "high_threshold": 40}`,
+	} {
+		if err := os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(src), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var sr report.ScanReport
+	if err := json.Unmarshal(captureStdout(func() { Run([]string{"--lang", "node", "--json"}) }), &sr); err != nil {
+		t.Fatal(err)
+	}
+	levels := map[string]string{}
+	for _, cr := range sr.Capabilities {
+		levels[cr.Package] = cr.RiskLevel
+	}
+	if levels["experimental"] != "LOW" || levels["tool"] != "MEDIUM" {
+		t.Errorf("risk levels = %v, want experimental LOW and tool MEDIUM", levels)
+	}
+
+	// A directory policy may only set per-package gate fields.
+	if err := os.WriteFile(filepath.Join(dir, "apps/.gorisk/policy.json"), []byte(`{"block_archived": true}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if code := Run([]string{"--lang", "node"}); code != 2 {
+		t.Errorf("Run() with unsupported directory policy field = %d, want 2", code)
+	}
+}
+
+func TestRunRequireBaseline(t *testing.T) {
+	dir := writeProject(t, nodeApp("module.exports = 1;\n", nil))
+	pol := filepath.Join(t.TempDir(), "policy.json")
+	if err := os.WriteFile(pol, []byte(`{"version":1,"fail_on":"high","require_baseline":true}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	scan := func(extra ...string) int {
+		return Run(append([]string{"--lang", "node", "--policy", pol, "--json"}, extra...))
+	}
+	if code := scan(); code != 1 {
+		t.Fatalf("scan with no recorded history = %d, want 1", code)
+	}
+	// A failing scan must not record the baseline it is missing.
+	if code := scan("--update-baseline"); code != 1 {
+		t.Fatalf("scan --update-baseline with no recorded history = %d, want 1", code)
+	}
+	if _, err := os.Stat(filepath.Join(dir, ".gorisk-history.json")); err == nil {
+		t.Fatal("failing scan recorded a baseline")
+	}
+
+	h, err := history.Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.Record(history.Snapshot{Timestamp: "2026-01-01T00:00:00Z"})
+	if err := h.Save(dir); err != nil {
+		t.Fatal(err)
+	}
+	if code := scan(); code != 0 {
+		t.Errorf("scan with a recorded snapshot = %d, want 0", code)
+	}
+}
+
+func TestRunFailFast(t *testing.T) {
+	writeProject(t, nodeApp("module.exports = 1;\n", map[string]string{
+		"spawner": "const cp = require('child_process');\ncp.exec('id');\n",
+	}))
+
+	ran := false
+	full := runEngines
+	defer func() { runEngines = full }()
+	runEngines = func(dir, lang, base string, g *graph.DependencyGraph, opts astpipeline.Options) engineResults {
+		ran = true
+		return full(dir, lang, base, g, opts)
+	}
+
+	out := filepath.Join(t.TempDir(), "report.json")
+	if code := Run([]string{"--lang", "node", "--fail-on", "medium", "--fail-fast", "--formats", "json=" + out}); code != 1 {
+		t.Fatalf("Run(--fail-fast) = %d, want 1", code)
+	}
+	if ran {
+		t.Error("--fail-fast ran the taint and engine phase after a capability failure")
+	}
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var sr report.ScanReport
+	if err := json.Unmarshal(data, &sr); err != nil {
+		t.Fatal(err)
+	}
+	if sr.Passed || !strings.Contains(sr.FailReason, "spawner") {
+		t.Errorf("report Passed=%v FailReason=%q, want a failure naming spawner", sr.Passed, sr.FailReason)
+	}
+
+	// Without a capability failure the full scan runs.
+	if code := Run([]string{"--lang", "node", "--fail-on", "high", "--fail-fast"}); code != 0 {
+		t.Errorf("Run(--fail-fast) on a passing project = %d, want 0", code)
+	}
+	if !ran {
+		t.Error("--fail-fast skipped the engine phase on a passing project")
+	}
+
+	// exec alone scores 20, MEDIUM under medium_threshold 15, but only 10
+	// when the package is unreachable, so --fail-fast cannot decide it
+	// without reachability and leaves the verdict to the full scan.
+	ran = false
+	pol := filepath.Join(t.TempDir(), "policy.json")
+	if err := os.WriteFile(pol, []byte(`{"version":1,"fail_on":"medium","medium_threshold":15}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if code := Run([]string{"--lang", "node", "--policy", pol, "--fail-fast"}); code != 1 {
+		t.Errorf("Run(--fail-fast) below the unreachable lower bound = %d, want 1", code)
+	}
+	if !ran {
+		t.Error("--fail-fast stopped on a score that reachability could lower")
+	}
+
+	if code := Run([]string{"--lang", "node", "--fail-fast", "--diff-only"}); code != 2 {
+		t.Errorf("--fail-fast with --diff-only = %d, want 2", code)
+	}
+}
+
+func TestRunPolicyURL(t *testing.T) {
+	t.Setenv("HOME", t.TempDir()) // isolate the file-backed cache
+	t.Setenv("GORISK_POLICY_TOKEN", "s3cret")
+	writeProject(t, nodeApp("module.exports = 1;\n", map[string]string{
+		"spawner": "const cp = require('child_process');\ncp.exec('id');\n",
+	}))
+
+	var fetches, revalidated int
+	var stalled atomic.Bool
+	release := make(chan struct{})
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer s3cret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if stalled.Load() {
+			<-release
+			return
+		}
+		switch r.URL.Path {
+		case "/policy.json":
+			fetches++
+			if r.Header.Get("If-None-Match") == `"v1"` {
+				revalidated++
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+			w.Header().Set("ETag", `"v1"`)
+			fmt.Fprint(w, `{"version":1,"fail_on":"medium"}`)
+		case "/bad.json":
+			fmt.Fprint(w, `{"version":1,"fail_onn":"medium"}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	origClient := policyClient
+	policyClient = srv.Client()
+	defer func() { policyClient = origClient }()
+
+	scan := func(extra ...string) int {
+		return Run(append([]string{"--lang", "node"}, extra...))
+	}
+	url := srv.URL + "/policy.json"
+	// The remote policy's fail_on medium fails the MEDIUM exec package,
+	// which the default fail_on high lets through.
+	if code := scan(); code != 0 {
+		t.Fatalf("scan without a policy = %d, want 0", code)
+	}
+	if code := scan("--policy-url", url); code != 1 {
+		t.Errorf("scan --policy-url = %d, want 1", code)
+	}
+	if code := scan("--policy-url", url); code != 1 || revalidated != 1 {
+		t.Errorf("second scan --policy-url = %d with %d revalidations, want 1 and 1", code, revalidated)
+	}
+	if code := scan("--policy-url", srv.URL+"/bad.json"); code != 2 {
+		t.Errorf("scan with an invalid remote policy = %d, want 2", code)
+	}
+	if code := scan("--policy-url", "http://example.com/policy.json"); code != 2 {
+		t.Errorf("scan with a plain-http policy URL = %d, want 2", code)
+	}
+
+	// A server that stops responding is unreachable once the request times
+	// out, and the cached copy is used.
+	stalled.Store(true)
+	policyClient.Timeout = 200 * time.Millisecond
+	if code := scan("--policy-url", url); code != 1 {
+		t.Errorf("scan --policy-url against a stalled server = %d, want 1 from the cached policy", code)
+	}
+	close(release)
+
+	// Offline: the cached copy is used unless the URL is required.
+	srv.Close()
+	if code := scan("--policy-url", url); code != 1 {
+		t.Errorf("offline scan --policy-url = %d, want 1 from the cached policy", code)
+	}
+	if code := scan("--policy-url", url, "--policy-url-required"); code != 2 {
+		t.Errorf("offline scan --policy-url-required = %d, want 2", code)
+	}
+	if fetches != 2 {
+		t.Errorf("server saw %d policy requests, want 2", fetches)
+	}
+}
+
+func TestRunFailOnTaintOnly(t *testing.T) {
+	// exec and unsafe make spawner HIGH, but nothing flows between them.
+	dir := writeProject(t, nodeApp("module.exports = 1;\n", map[string]string{
+		"spawner": "const cp = require('child_process');\nconst vm = require('vm');\n" +
+			"cp.exec('id');\nvm.runInNewContext('1 + 1');\n",
+	}))
+
+	if code := Run([]string{"--lang", "node"}); code != 1 {
+		t.Fatalf("scan of a HIGH capability package = %d, want 1", code)
+	}
+	if code := Run([]string{"--lang", "node", "--fail-on-taint-only"}); code != 0 {
+		t.Errorf("--fail-on-taint-only without a taint flow = %d, want 0", code)
+	}
+
+	// Neither require_baseline nor a new capability finding fails a
+	// taint-only scan.
+	if err := os.WriteFile("policy.json", []byte(`{"version":1,"require_baseline":true}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	empty := filepath.Join(t.TempDir(), "empty.json")
+	if err := os.WriteFile(empty, []byte(`{}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if code := Run([]string{"--lang", "node", "--policy", "policy.json", "--fail-on-taint-only"}); code != 0 {
+		t.Errorf("--fail-on-taint-only with require_baseline = %d, want 0", code)
+	}
+	if code := Run([]string{"--lang", "node", "--fail-on-taint-only", "--baseline-compare", empty, "--fail-on-new"}); code != 0 {
+		t.Errorf("--fail-on-taint-only with a new capability finding = %d, want 0", code)
+	}
+	if err := os.Remove("policy.json"); err != nil {
+		t.Fatal(err)
+	}
+
+	// A network response reaching exec is a flow, and fails the scan.
+	flow := "const cp = require('child_process');\nconst http = require('http');\n" +
+		"http.get(process.env.URL, (res) => cp.exec(res.headers.cmd));\n"
+	if err := os.WriteFile(filepath.Join(dir, "node_modules/spawner/index.js"), []byte(flow), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if code := Run([]string{"--lang", "node", "--fail-on-taint-only"}); code != 1 {
+		t.Errorf("--fail-on-taint-only with a network → exec flow = %d, want 1", code)
+	}
+
+	// disabled_taint_rules turns the rule off for that scan only.
+	if err := os.WriteFile("policy.json", []byte(`{"version":1,"disabled_taint_rules":["network→exec"]}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if code := Run([]string{"--lang", "node", "--policy", "policy.json", "--fail-on-taint-only"}); code != 0 {
+		t.Errorf("--fail-on-taint-only with network → exec disabled = %d, want 0", code)
+	}
+	if err := os.Remove("policy.json"); err != nil {
+		t.Fatal(err)
+	}
+
+	// A destructured exec import is below strict confidence, so the flow
+	// it forms cannot fail a strict scan.
+	weak := "const { exec } = require('child_process');\nconst http = require('http');\n" +
+		"http.get(process.env.URL, (res) => exec(res.headers.cmd));\n"
+	if err := os.WriteFile(filepath.Join(dir, "node_modules/spawner/index.js"), []byte(weak), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if code := Run([]string{"--lang", "node", "--fail-on-taint-only"}); code != 1 {
+		t.Errorf("--fail-on-taint-only with a destructured exec flow = %d, want 1", code)
+	}
+	if code := Run([]string{"--lang", "node", "--fail-on-taint-only", "--strict-confidence"}); code != 0 {
+		t.Errorf("--fail-on-taint-only --strict-confidence with a destructured exec flow = %d, want 0", code)
+	}
+
+	if code := Run([]string{"--lang", "node", "--fail-on-taint-only", "--fail-fast"}); code != 2 {
+		t.Errorf("--fail-on-taint-only with --fail-fast = %d, want 2", code)
+	}
+}
@@ -0,0 +1,107 @@
+package scan
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+
+	"github.com/1homsi/gorisk/internal/capability"
+	"github.com/1homsi/gorisk/internal/graph"
+)
+
+// traceKinds maps the event kinds of `gorisk trace --json` to the
+// capability each one confirms. ast_call events are static and confirm
+// nothing.
+var traceKinds = map[string]capability.Capability{
+	"subprocess": capability.CapExec,
+	"network":    capability.CapNetwork,
+	"filesystem": capability.CapFSRead,
+}
+
+// runtimeTrace is what a `gorisk trace --json` file confirmed: the
+// capabilities its runtime events show, and the traced packages they were
+// recorded for.
+type runtimeTrace struct {
+	confirmed map[capability.Capability]bool
+	roots     []string
+}
+
+// loadTraceEvidence reads a `gorisk trace --json` file and returns the
+// capabilities its runtime events confirm.
+func loadTraceEvidence(path string) (*runtimeTrace, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var events []struct {
+		Kind    string `json:"kind"`
+		Detail  string `json:"detail"`
+		Package string `json:"package"`
+	}
+	if err := json.Unmarshal(data, &events); err != nil {
+		return nil, fmt.Errorf("%s: not a gorisk trace --json file: %w", path, err)
+	}
+	te := &runtimeTrace{confirmed: make(map[capability.Capability]bool)}
+	for _, e := range events {
+		c, ok := traceKinds[e.Kind]
+		if !ok {
+			continue
+		}
+		te.confirmed[c] = true
+		if e.Package != "" && !slices.Contains(te.roots, e.Package) {
+			te.roots = append(te.roots, e.Package)
+		}
+	}
+	return te, nil
+}
+
+// boostConfirmed raises the static evidence for each confirmed capability
+// to confidence 1.0, so behaviour seen at runtime is never filtered or
+// downgraded as a low-confidence guess. Trace events are process-wide, so
+// only the traced packages and their import closure — the code that ran —
+// are boosted. It returns the number of packages boosted.
+func boostConfirmed(g *graph.DependencyGraph, te *runtimeTrace) int {
+	if te == nil {
+		return 0
+	}
+	ran := make(map[string]bool)
+	queue := slices.Clone(te.roots)
+	for len(queue) > 0 {
+		p := queue[0]
+		queue = queue[1:]
+		if ran[p] {
+			continue
+		}
+		ran[p] = true
+		queue = append(queue, g.Edges[p]...)
+	}
+
+	boosted := 0
+	for path, pkg := range g.Packages {
+		if !ran[path] {
+			continue
+		}
+		hit := false
+		for c := range te.confirmed {
+			evs := pkg.Capabilities.Evidence[c]
+			for i := range evs {
+				evs[i].Confidence = 1.0
+				hit = true
+			}
+		}
+		if hit {
+			boosted++
+		}
+	}
+	return boosted
+}
+
+// confirmedList returns the confirmed capabilities, sorted and joined.
+func confirmedList(confirmed map[capability.Capability]bool) string {
+	var cs capability.CapabilitySet
+	for c := range confirmed {
+		cs.Add(c)
+	}
+	return cs.String()
+}
@@ -14,11 +14,13 @@ import (
 
 	"github.com/1homsi/gorisk/internal/analyzer"
 	"github.com/1homsi/gorisk/internal/astpipeline"
+	"github.com/1homsi/gorisk/internal/report"
 )
 
 type event struct {
 	kind   string
 	detail string
+	pkg    string // traced package a runtime event belongs to; "" for ast_call
 }
 
 func Run(args []string) int {
@@ -53,6 +55,10 @@ func Run(args []string) int {
 		fmt.Fprintln(os.Stderr, "trace:", err)
 		return 2
 	}
+	pkg := importPath(rest[0])
+	for i := range events {
+		events[i].pkg = pkg
+	}
 	events = append(astEvents, events...)
 
 	if *jsonOut {
@@ -84,6 +90,20 @@ func buildBinary(pkg string) (string, error) {
 	return bin, nil
 }
 
+// importPath resolves the package argument to its import path, so scan
+// --trace-evidence can attribute runtime events to it. It returns pkg
+// unchanged when go list cannot resolve it.
+func importPath(pkg string) string {
+	out, err := exec.Command("go", "list", "-f", "{{.ImportPath}}", pkg).Output()
+	if err != nil {
+		return pkg
+	}
+	if p := strings.TrimSpace(string(out)); p != "" && !strings.Contains(p, "\n") {
+		return p
+	}
+	return pkg
+}
+
 func detectTracer() (string, error) {
 	switch runtime.GOOS {
 	case "linux":
@@ -145,13 +165,34 @@ syscall::execve:entry  { printf("exec %s\n", copyinstr(arg0)); }`
 	}
 
 	if filepath.Base(tracer) == "strace" {
-		return parseStrace(string(data)), nil
+		return parseStrace(string(data), bin), nil
+	}
+	return parseDtrace(string(data), bin), nil
+}
+
+// loaderPaths are files the dynamic loader and runtime open before main
+// runs. Opening them is not the program reading the filesystem.
+var loaderPaths = []string{"/etc/ld.so.", "/lib/", "/lib64/", "/usr/lib/", "/usr/lib64/", "/proc", "/sys"}
+
+// startupNoise reports whether an open of path is the loader's or the
+// runtime's, or of the traced binary itself.
+func startupNoise(path, bin string) bool {
+	if path == bin {
+		return true
 	}
-	return parseDtrace(string(data)), nil
+	for _, p := range loaderPaths {
+		if strings.HasPrefix(path, p) {
+			return true
+		}
+	}
+	return false
 }
 
-func parseStrace(out string) []event {
+// parseStrace turns strace output into events. The tracer's own execve of
+// bin, which starts the traced program, is not a subprocess.
+func parseStrace(out, bin string) []event {
 	seen := make(map[string]bool)
+	started := false
 	var events []event
 	scanner := bufio.NewScanner(strings.NewReader(out))
 	for scanner.Scan() {
@@ -167,12 +208,12 @@ func parseStrace(out string) []event {
 		switch call {
 		case "openat", "open":
 			detail := firstQuoted(rest)
-			if detail == "" || strings.HasPrefix(detail, "/proc") || strings.HasPrefix(detail, "/sys") {
+			if detail == "" || startupNoise(detail, bin) {
 				continue
 			}
 			if key := "fs:" + detail; !seen[key] {
 				seen[key] = true
-				events = append(events, event{"filesystem", detail})
+				events = append(events, event{kind: "filesystem", detail: detail})
 			}
 		case "connect":
 			if !strings.Contains(rest, "AF_INET") {
@@ -181,21 +222,28 @@ func parseStrace(out string) []event {
 			detail := netDetail(rest)
 			if key := "net:" + detail; !seen[key] {
 				seen[key] = true
-				events = append(events, event{"network", detail})
+				events = append(events, event{kind: "network", detail: detail})
 			}
 		case "execve":
 			detail := firstQuoted(rest)
+			if !started && detail == bin {
+				started = true
+				continue
+			}
 			if key := "exec:" + detail; !seen[key] {
 				seen[key] = true
-				events = append(events, event{"subprocess", detail})
+				events = append(events, event{kind: "subprocess", detail: detail})
 			}
 		}
 	}
 	return events
 }
 
-func parseDtrace(out string) []event {
+// parseDtrace turns dtrace output into events, skipping the start of bin
+// and its loader's opens as parseStrace does.
+func parseDtrace(out, bin string) []event {
 	seen := make(map[string]bool)
+	started := false
 	var events []event
 	scanner := bufio.NewScanner(strings.NewReader(out))
 	for scanner.Scan() {
@@ -207,17 +255,24 @@ func parseDtrace(out string) []event {
 		var k string
 		switch kind {
 		case "fs":
+			if startupNoise(detail, bin) {
+				continue
+			}
 			k = "filesystem"
 		case "net":
 			k = "network"
 		case "exec":
+			if !started && detail == bin {
+				started = true
+				continue
+			}
 			k = "subprocess"
 		default:
 			continue
 		}
 		if key := k + ":" + detail; !seen[key] {
 			seen[key] = true
-			events = append(events, event{k, detail})
+			events = append(events, event{kind: k, detail: detail})
 		}
 	}
 	return events
@@ -254,14 +309,8 @@ func netDetail(s string) string {
 }
 
 func printText(events []event) {
-	const (
-		red    = "\033[31m"
-		yellow = "\033[33m"
-		green  = "\033[32m"
-		cyan   = "\033[36m"
-		bold   = "\033[1m"
-		reset  = "\033[0m"
-	)
+	palette := report.Colors()
+	red, yellow, green, cyan, bold, reset := palette.Red, palette.Yellow, palette.Green, palette.Cyan, palette.Bold, palette.Reset
 
 	byKind := make(map[string][]string)
 	for _, e := range events {
@@ -294,6 +343,10 @@ func printJSON(events []event) {
 		if i == len(events)-1 {
 			comma = ""
 		}
+		if e.pkg != "" {
+			fmt.Printf("  {\"kind\":%q,\"detail\":%q,\"package\":%q}%s\n", e.kind, e.detail, e.pkg, comma)
+			continue
+		}
 		fmt.Printf("  {\"kind\":%q,\"detail\":%q}%s\n", e.kind, e.detail, comma)
 	}
 	fmt.Println("]")
@@ -313,7 +366,7 @@ func buildASTTraceEvents() []event {
 		return nil
 	}
 	lang := analyzer.ResolveLang("auto", dir)
-	res := astpipeline.Analyze(dir, lang, g)
+	res := astpipeline.Analyze(dir, lang, g, astpipeline.Options{})
 	if !res.UsedInterproc || res.Bundle.CallGraph == nil {
 		return nil
 	}
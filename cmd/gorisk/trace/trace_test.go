@@ -121,3 +121,26 @@ func TestDetectTracer(t *testing.T) {
 		}
 	}
 }
+
+func TestParseStraceSkipsStartup(t *testing.T) {
+	out := `1234  execve("/tmp/gorisk-trace-1", ["/tmp/gorisk-trace-1"], 0x7ffd /* 20 vars */) = 0
+1234  openat(AT_FDCWD, "/etc/ld.so.cache", O_RDONLY|O_CLOEXEC) = 3
+1234  openat(AT_FDCWD, "/lib/x86_64-linux-gnu/libc.so.6", O_RDONLY|O_CLOEXEC) = 3
+1234  openat(AT_FDCWD, "/sys/kernel/mm/transparent_hugepage/hpage_pmd_size", O_RDONLY) = 3
+1235  execve("/usr/bin/git", ["git", "status"], 0x7ffd /* 20 vars */) = 0
+1234  openat(AT_FDCWD, "config.yaml", O_RDONLY|O_CLOEXEC) = 3
+`
+	events := parseStrace(out, "/tmp/gorisk-trace-1")
+	want := []event{
+		{kind: "subprocess", detail: "/usr/bin/git"},
+		{kind: "filesystem", detail: "config.yaml"},
+	}
+	if len(events) != len(want) {
+		t.Fatalf("parseStrace() = %+v, want %+v", events, want)
+	}
+	for i := range want {
+		if events[i] != want[i] {
+			t.Errorf("event %d = %+v, want %+v", i, events[i], want[i])
+		}
+	}
+}
@@ -123,12 +123,8 @@ func printTransitiveAdditionsText(additions []transitiveAddition) {
 		return
 	}
 
-	const (
-		bold  = "\033[1m"
-		cyan  = "\033[36m"
-		green = "\033[32m"
-		reset = "\033[0m"
-	)
+	palette := report.Colors()
+	bold, cyan, green, reset := palette.Bold, palette.Cyan, palette.Green, palette.Reset
 
 	fmt.Fprintf(os.Stdout, "\n%s%s=== Transitive Additions ===%s\n", bold, cyan, reset)
 	for _, a := range additions {
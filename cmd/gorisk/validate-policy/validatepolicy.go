@@ -31,11 +31,16 @@ func Run(args []string) int {
 	}
 
 	known := map[string]bool{
-		"version": true, "fail_on": true, "max_health_score": true,
-		"min_health_score": true, "block_archived": true,
+		"version": true, "fail_on": true, "first_party_fail_on": true, "max_health_score": true,
+		"min_health_score": true, "block_archived": true, "block_abandoned": true,
 		"deny_capabilities": true, "allow_exceptions": true,
-		"max_dep_depth": true, "exclude_packages": true,
+		"max_dep_depth": true, "max_capability_depth": true, "require_baseline": true, "exclude_packages": true,
 		"confidence_threshold": true, "suppress": true,
+		"max_composite_score": true, "safe_exec_commands": true,
+		"ignore_capabilities": true, "disabled_taint_rules": true,
+		"medium_threshold": true, "high_threshold": true, "allowed_hosts": true,
+		"trusted_prefixes": true, "require_capabilities": true,
+		"skip_dirs": true, "high_risk_capabilities": true,
 	}
 
 	var errs []string
@@ -50,14 +55,18 @@ func Run(args []string) int {
 		}
 	}
 
-	// Validate fail_on value.
-	if v, ok := raw["fail_on"]; ok {
-		var failOn string
-		if json.Unmarshal(v, &failOn) == nil {
-			switch failOn {
+	// Validate fail_on and first_party_fail_on values.
+	for _, field := range []string{"fail_on", "first_party_fail_on"} {
+		v, ok := raw[field]
+		if !ok {
+			continue
+		}
+		var level string
+		if json.Unmarshal(v, &level) == nil {
+			switch level {
 			case "low", "medium", "high":
 			default:
-				errs = append(errs, fmt.Sprintf("  fail_on: %q is invalid (must be low|medium|high)", failOn))
+				errs = append(errs, fmt.Sprintf("  %s: %q is invalid (must be low|medium|high)", field, level))
 			}
 		}
 	}
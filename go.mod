@@ -2,10 +2,10 @@ module github.com/1homsi/gorisk
 
 go 1.25
 
-require golang.org/x/tools v0.29.0
-
 require (
-	golang.org/x/mod v0.23.0 // indirect
-	golang.org/x/sync v0.11.0 // indirect
-	gopkg.in/yaml.v3 v3.0.1 // indirect
+	golang.org/x/mod v0.23.0
+	golang.org/x/tools v0.29.0
+	gopkg.in/yaml.v3 v3.0.1
 )
+
+require golang.org/x/sync v0.11.0 // indirect
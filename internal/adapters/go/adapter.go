@@ -1,31 +1,53 @@
 package goadapter
 
 import (
+	"maps"
+	"slices"
+
 	"github.com/1homsi/gorisk/internal/graph"
 	"github.com/1homsi/gorisk/internal/interproc"
 	"github.com/1homsi/gorisk/internal/ir"
 )
 
 // Adapter wraps graph.Load to implement the Analyzer interface for Go projects.
-type Adapter struct{}
+type Adapter struct {
+	Config Config
+}
 
 func (a *Adapter) Name() string { return "go" }
 
 func (a *Adapter) Load(dir string) (*graph.DependencyGraph, error) {
-	g, err := graph.Load(dir)
+	d, err := a.Config.detector()
+	if err != nil {
+		return nil, err
+	}
+	g, err := graph.LoadEnv(dir, d.env)
 	if err != nil {
 		return nil, err
 	}
 
 	// First pass: detect per-package capabilities
 	for _, pkg := range g.Packages {
-		if pkg.Dir == "" || len(pkg.GoFiles) == 0 {
+		goFiles := append(slices.Clip(pkg.GoFiles), pkg.CgoFiles...)
+		if pkg.Dir == "" || len(goFiles) == 0 {
 			continue
 		}
-		caps, err := DetectPackage(pkg.Dir, pkg.GoFiles)
+		caps, err := d.detectPackage(pkg.Dir, goFiles)
 		if err == nil {
 			pkg.Capabilities = caps
 		}
+		// C code in the preamble and .c files runs with the Go package's
+		// privileges but is invisible to the Go AST analysis.
+		if len(pkg.CgoFiles) > 0 {
+			pkg.Capabilities.MergeWithEvidence(DetectCgo(pkg.Dir, pkg.CgoFiles, pkg.CFiles))
+		}
+		// Hand-written assembly can trap into the kernel directly.
+		if len(pkg.SFiles) > 0 {
+			pkg.Capabilities.MergeWithEvidence(DetectAsm(pkg.Dir, pkg.SFiles))
+		}
+		// Plugin and shared-library builds expose exported functions to
+		// whatever program loads them.
+		pkg.Capabilities.MergeWithEvidence(DetectPluginExports(pkg.Dir, pkg.CgoFiles))
 	}
 
 	// Second pass: interprocedural analysis for main module
@@ -43,8 +65,21 @@ func (a *Adapter) Load(dir string) (*graph.DependencyGraph, error) {
 		}
 
 		if len(mainPkgs) > 0 {
-			pkgCaps, pkgEdges, err := BuildModuleGraph(dir, convertToPackageMap(mainPkgs))
+			pkgCaps, pkgEdges, err := d.buildModuleGraph(dir, convertToPackageMap(mainPkgs))
 			if err == nil {
+				// Flag exec/network/plugin reached from init() across packages.
+				allFuncs := make(map[string]ir.FunctionCaps)
+				var allEdges []ir.CallEdge
+				for pkgPath, funcs := range pkgCaps {
+					maps.Copy(allFuncs, funcs)
+					allEdges = append(allEdges, pkgEdges[pkgPath]...)
+				}
+				for pkgPath, initCaps := range RunsOnImport(allFuncs, allEdges) {
+					if pkg := g.Packages[pkgPath]; pkg != nil {
+						pkg.Capabilities.MergeWithEvidence(initCaps)
+					}
+				}
+
 				// Use interprocedural engine with context-sensitive analysis
 				irGraph := interproc.ConsolidateIR(pkgCaps, pkgEdges)
 				opts := interproc.DefaultOptions()
@@ -68,8 +103,13 @@ func (a *Adapter) Load(dir string) (*graph.DependencyGraph, error) {
 	return g, nil
 }
 
-// BuildIRGraph builds a function-level IR graph for main-module Go packages.
-func BuildIRGraph(dir string, g *graph.DependencyGraph) (ir.IRGraph, error) {
+// BuildIRGraph builds a function-level IR graph for main-module Go packages,
+// detecting capabilities for the build cfg selects.
+func BuildIRGraph(dir string, g *graph.DependencyGraph, cfg Config) (ir.IRGraph, error) {
+	d, err := cfg.detector()
+	if err != nil {
+		return ir.IRGraph{}, err
+	}
 	if g == nil || g.Main == nil {
 		return ir.IRGraph{Functions: map[string]ir.FunctionCaps{}, Calls: []ir.CallEdge{}}, nil
 	}
@@ -87,7 +127,7 @@ func BuildIRGraph(dir string, g *graph.DependencyGraph) (ir.IRGraph, error) {
 	if len(mainPkgs) == 0 {
 		return ir.IRGraph{Functions: map[string]ir.FunctionCaps{}, Calls: []ir.CallEdge{}}, nil
 	}
-	pkgCaps, pkgEdges, err := BuildModuleGraph(dir, mainPkgs)
+	pkgCaps, pkgEdges, err := d.buildModuleGraph(dir, mainPkgs)
 	if err != nil {
 		return ir.IRGraph{}, err
 	}
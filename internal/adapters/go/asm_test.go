@@ -0,0 +1,66 @@
+package goadapter
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/1homsi/gorisk/internal/capability"
+)
+
+func TestDetectAsmExecve(t *testing.T) {
+	dir := t.TempDir()
+	amd64 := `#include "textflag.h"
+
+// func spawn(path *byte, argv, envp **byte)
+TEXT ·spawn(SB),NOSPLIT,$0-24
+	MOVQ	path+0(FP), DI
+	MOVQ	argv+8(FP), SI
+	MOVQ	envp+16(FP), DX
+	MOVQ	$59, AX // execve
+	SYSCALL
+	RET
+
+// func pid() int
+TEXT ·pid(SB),NOSPLIT,$0-8
+	MOVQ	$39, AX // getpid
+	SYSCALL
+	MOVQ	AX, ret+0(FP)
+	RET
+
+// func idle() leaves AX as set above; no syscall number is known here.
+TEXT ·idle(SB),NOSPLIT,$0
+	SYSCALL
+	RET
+`
+	arm64 := `#include "textflag.h"
+
+#define SYS_SOCKET 198
+
+TEXT ·sock(SB),NOSPLIT,$0-8
+	MOVD	$2, R0
+	MOVD	$SYS_SOCKET, R8
+	SVC
+	RET
+`
+	for name, src := range map[string]string{"spawn_amd64.s": amd64, "sock_arm64.s": arm64} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(src), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cs := DetectAsm(dir, []string{"spawn_amd64.s", "sock_arm64.s"})
+	execEv := cs.Evidence[capability.CapExec]
+	if len(execEv) != 1 {
+		t.Fatalf("exec evidence = %+v, want the one execve SYSCALL", execEv)
+	}
+	if ev := execEv[0]; filepath.Base(ev.File) != "spawn_amd64.s" || ev.Line != 9 || ev.Confidence != 0.65 {
+		t.Errorf("exec evidence = %+v, want spawn_amd64.s:9 at confidence 0.65", ev)
+	}
+	if !cs.Has(capability.CapNetwork) {
+		t.Errorf("SVC with R8 = SYS_SOCKET should report network, got %v", cs.List())
+	}
+	if got := len(cs.List()); got != 2 {
+		t.Errorf("capabilities = %v, want only exec and network", cs.List())
+	}
+}
@@ -0,0 +1,71 @@
+package goadapter
+
+import (
+	"bufio"
+	"bytes"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/1homsi/gorisk/internal/capability"
+)
+
+// libraryBuildModeRe matches a go build flag producing a plugin or C
+// library, whose exported symbols any loading program can call.
+var libraryBuildModeRe = regexp.MustCompile(`-buildmode[= ](plugin|c-shared|c-archive)\b`)
+
+// buildScripts are files in a package directory that commonly hold its go
+// build command.
+var buildScripts = []string{"Makefile", "makefile", "GNUmakefile", "build.sh", "justfile", "Taskfile.yml", ".goreleaser.yml", ".goreleaser.yaml"}
+
+// DetectPluginExports flags a package built as a plugin or shared library:
+// one whose cgoFiles carry //export comments, or whose build scripts pass
+// -buildmode=plugin, c-shared, or c-archive. Such a package hands its
+// exported functions to arbitrary loaders, so it is reported as plugin with
+// plugin-exported evidence.
+func DetectPluginExports(dir string, cgoFiles []string) capability.CapabilitySet {
+	var cs capability.CapabilitySet
+	add := func(file string, line int, ctx string) {
+		cs.AddWithEvidence(capability.CapPlugin, capability.CapabilityEvidence{
+			File:       file,
+			Line:       line,
+			Context:    "plugin-exported: " + ctx,
+			Via:        capability.ViaPluginExported,
+			Confidence: 0.80,
+		})
+	}
+
+	fset := token.NewFileSet()
+	for _, name := range cgoFiles {
+		fpath := filepath.Join(dir, name)
+		f, err := parser.ParseFile(fset, fpath, nil, parser.ParseComments)
+		if err != nil {
+			continue
+		}
+		for _, cg := range f.Comments {
+			for _, c := range cg.List {
+				if strings.HasPrefix(c.Text, "//export ") {
+					add(fpath, fset.Position(c.Pos()).Line, c.Text)
+				}
+			}
+		}
+	}
+
+	for _, name := range buildScripts {
+		fpath := filepath.Join(dir, name)
+		data, err := os.ReadFile(fpath)
+		if err != nil {
+			continue
+		}
+		sc := bufio.NewScanner(bytes.NewReader(data))
+		for n := 1; sc.Scan(); n++ {
+			if m := libraryBuildModeRe.FindString(sc.Text()); m != "" {
+				add(fpath, n, m+" in "+name)
+			}
+		}
+	}
+	return cs
+}
@@ -0,0 +1,165 @@
+package goadapter
+
+import (
+	"bufio"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/1homsi/gorisk/internal/capability"
+)
+
+// cgoCalls maps C library functions to the capability they grant. Calls in
+// the cgo preamble and in .c/.h files are invisible to the Go AST analysis.
+var cgoCalls = map[string]capability.Capability{
+	"system":  capability.CapExec,
+	"popen":   capability.CapExec,
+	"execl":   capability.CapExec,
+	"execle":  capability.CapExec,
+	"execlp":  capability.CapExec,
+	"execv":   capability.CapExec,
+	"execve":  capability.CapExec,
+	"execvp":  capability.CapExec,
+	"execvpe": capability.CapExec,
+	"socket":  capability.CapNetwork,
+	"connect": capability.CapNetwork,
+	"fopen":   capability.CapFSRead,
+}
+
+// cgoCallRe matches a call to one of cgoCalls. The leading boundary rejects
+// members and longer identifiers such as my_system( or obj.connect(.
+var cgoCallRe = regexp.MustCompile(`(?:^|[^\w.>])(system|popen|execl|execle|execlp|execv|execve|execvp|execvpe|socket|connect|fopen)\s*\(`)
+
+// fopenWriteRe matches an fopen mode string that opens for writing.
+var fopenWriteRe = regexp.MustCompile(`fopen\s*\([^,]*,\s*"[^"]*[wa+]`)
+
+// DetectCgo scans the C code compiled into a cgo package — the preamble
+// comment above import "C" in each of cgoFiles, and the C sources and
+// headers in cFiles — and attributes its process, network, and file
+// capabilities to the Go package.
+func DetectCgo(dir string, cgoFiles, cFiles []string) capability.CapabilitySet {
+	var cs capability.CapabilitySet
+	fset := token.NewFileSet()
+	for _, name := range cgoFiles {
+		fpath := filepath.Join(dir, name)
+		f, err := parser.ParseFile(fset, fpath, nil, parser.ImportsOnly|parser.ParseComments)
+		if err != nil {
+			continue
+		}
+		preamble := cgoPreamble(f)
+		if preamble == nil {
+			continue
+		}
+		for _, c := range preamble.List {
+			line := fset.Position(c.Pos()).Line
+			text := strings.TrimPrefix(c.Text, "//")
+			if strings.HasPrefix(c.Text, "/*") {
+				text = strings.TrimSuffix(strings.TrimPrefix(c.Text, "/*"), "*/")
+			}
+			detectCSource(&cs, fpath, line, text)
+		}
+	}
+	for _, name := range cFiles {
+		fpath := filepath.Join(dir, name)
+		data, err := os.ReadFile(fpath)
+		if err != nil {
+			continue
+		}
+		detectCSource(&cs, fpath, 1, string(data))
+	}
+	return cs
+}
+
+// detectCSource adds evidence for every cgoCalls call in src, whose first
+// line is line firstLine of fpath. Calls inside comments are skipped.
+func detectCSource(cs *capability.CapabilitySet, fpath string, firstLine int, src string) {
+	sc := bufio.NewScanner(strings.NewReader(src))
+	inComment := false
+	for n := firstLine; sc.Scan(); n++ {
+		var line string
+		line, inComment = stripCComments(sc.Text(), inComment)
+		for _, m := range cgoCallRe.FindAllStringSubmatch(line, -1) {
+			fn := m[1]
+			c := cgoCalls[fn]
+			if fn == "fopen" && fopenWriteRe.MatchString(line) {
+				c = capability.CapFSWrite
+			}
+			cs.AddWithEvidence(c, capability.CapabilityEvidence{
+				File:       fpath,
+				Line:       n,
+				Context:    "C " + fn + "(...) via cgo",
+				Via:        "callSite",
+				Confidence: 0.70,
+			})
+		}
+	}
+}
+
+// stripCComments returns line with its comments blanked out. inComment
+// reports whether line starts inside a /* */ comment; the second result
+// whether it ends inside one. Comment markers inside string and character
+// literals are kept as text.
+func stripCComments(line string, inComment bool) (string, bool) {
+	var b strings.Builder
+	for i := 0; i < len(line); {
+		switch {
+		case inComment:
+			end := strings.Index(line[i:], "*/")
+			if end < 0 {
+				return b.String(), true
+			}
+			i += end + 2
+			inComment = false
+			b.WriteByte(' ')
+		case strings.HasPrefix(line[i:], "/*"):
+			i += 2
+			inComment = true
+		case strings.HasPrefix(line[i:], "//"):
+			return b.String(), false
+		case line[i] == '"' || line[i] == '\'':
+			quote := line[i]
+			j := i + 1
+			for j < len(line) && line[j] != quote {
+				if line[j] == '\\' {
+					j++
+				}
+				j++
+			}
+			j = min(j+1, len(line))
+			b.WriteString(line[i:j])
+			i = j
+		default:
+			b.WriteByte(line[i])
+			i++
+		}
+	}
+	return b.String(), inComment
+}
+
+// cgoPreamble returns the comment cgo compiles as C code: the doc comment
+// of the import "C" declaration, or nil when f has none.
+func cgoPreamble(f *ast.File) *ast.CommentGroup {
+	for _, decl := range f.Decls {
+		gd, ok := decl.(*ast.GenDecl)
+		if !ok || gd.Tok != token.IMPORT {
+			continue
+		}
+		for _, spec := range gd.Specs {
+			is := spec.(*ast.ImportSpec)
+			if is.Path.Value != `"C"` {
+				continue
+			}
+			if is.Doc != nil {
+				return is.Doc
+			}
+			if !gd.Lparen.IsValid() {
+				return gd.Doc
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,132 @@
+package goadapter
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/1homsi/gorisk/internal/capability"
+)
+
+func TestDetectCgoSystemCall(t *testing.T) {
+	dir := t.TempDir()
+	goSrc := `package shell
+
+/*
+#include <stdlib.h>
+
+static int run(const char *cmd) {
+	// system() is only mentioned here, not called
+	return system(cmd);
+}
+*/
+import "C"
+
+func Run(cmd string) { C.run(C.CString(cmd)) }
+`
+	cSrc := `#include <stdio.h>
+#include <sys/socket.h>
+
+int dial(void) { return socket(AF_INET, SOCK_STREAM, 0); }
+void logline(void) { FILE *f = fopen("/tmp/log", "a"); fclose(f); }
+int my_system(void) { return 0; }
+`
+	for name, src := range map[string]string{"shell.go": goSrc, "net.c": cSrc} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(src), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cs := DetectCgo(dir, []string{"shell.go"}, []string{"net.c"})
+	for _, c := range []capability.Capability{capability.CapExec, capability.CapNetwork, capability.CapFSWrite} {
+		if !cs.Has(c) {
+			t.Errorf("expected %s, got %v", c, cs.List())
+		}
+	}
+	if cs.Has(capability.CapFSRead) {
+		t.Errorf("fopen with mode \"a\" should be fs:write only, got %v", cs.List())
+	}
+
+	execEv := cs.Evidence[capability.CapExec]
+	if len(execEv) != 1 {
+		t.Fatalf("exec evidence = %+v, want the one system() call", execEv)
+	}
+	if ev := execEv[0]; filepath.Base(ev.File) != "shell.go" || ev.Line != 8 {
+		t.Errorf("exec evidence at %s:%d, want shell.go:8", ev.File, ev.Line)
+	}
+}
+
+func TestDetectCgoComments(t *testing.T) {
+	dir := t.TempDir()
+	cSrc := `/* Legacy path, kept for reference:
+   popen("id", "r");
+*/
+void reset(int *fd) {
+	*fd = socket(AF_INET, SOCK_STREAM, 0); /* system("x") */
+}
+/* setup */ int run(char *cmd) { return system(cmd); }
+const char *url = "http://example.com"; // fopen("log", "w")
+`
+	if err := os.WriteFile(filepath.Join(dir, "lib.c"), []byte(cSrc), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	cs := DetectCgo(dir, nil, []string{"lib.c"})
+	var got []string
+	for _, c := range cs.List() {
+		for _, ev := range cs.Evidence[capability.Capability(c)] {
+			got = append(got, fmt.Sprintf("%s:%d", c, ev.Line))
+		}
+	}
+	// Line 5 starts with * but is code; the calls in comments are ignored.
+	want := []string{"exec:7", "network:5"}
+	if !slices.Equal(got, want) {
+		t.Errorf("evidence = %v, want %v", got, want)
+	}
+}
+
+func TestDetectPluginExports(t *testing.T) {
+	dir := t.TempDir()
+	goSrc := `package main
+
+import "C"
+
+//export Add
+func Add(a, b C.int) C.int { return a + b }
+
+// Sub is not exported to C.
+func Sub(a, b int) int { return a - b }
+
+func main() {}
+`
+	if err := os.WriteFile(filepath.Join(dir, "lib.go"), []byte(goSrc), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	cs := DetectPluginExports(dir, []string{"lib.go"})
+	if !cs.Has(capability.CapPlugin) {
+		t.Fatalf("expected plugin for //export, got %s", cs.String())
+	}
+	evs := cs.Evidence[capability.CapPlugin]
+	if len(evs) != 1 {
+		t.Fatalf("got %d evidence entries, want 1: %+v", len(evs), evs)
+	}
+	if ev := evs[0]; ev.Via != capability.ViaPluginExported || ev.Line != 5 || ev.Context != "plugin-exported: //export Add" {
+		t.Errorf("evidence = %+v, want plugin-exported //export Add at line 5", ev)
+	}
+
+	// A build script naming a library build mode is flagged too.
+	plain := t.TempDir()
+	if cs := DetectPluginExports(plain, nil); !cs.IsEmpty() {
+		t.Errorf("plain package flagged: %s", cs.String())
+	}
+	makefile := "build:\n\tgo build -buildmode=c-shared -o libx.so .\n"
+	if err := os.WriteFile(filepath.Join(plain, "Makefile"), []byte(makefile), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if cs := DetectPluginExports(plain, nil); !cs.Has(capability.CapPlugin) {
+		t.Errorf("expected plugin for -buildmode=c-shared in Makefile, got %s", cs.String())
+	}
+}
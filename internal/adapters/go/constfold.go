@@ -0,0 +1,284 @@
+package goadapter
+
+import (
+	"go/ast"
+	"go/token"
+)
+
+// deadCode returns the branches of f that can never run because their if
+// condition folds to a constant: the body of `if false { ... }` or of a
+// guard on a package-level `const Enabled = false`, and the else branch of
+// a condition that folds to true. Only constants declared in f are folded,
+// and a name is folded only where it resolves to that declaration, so a
+// local that shadows a constant keeps its branch live.
+func deadCode(f *ast.File) map[ast.Node]bool {
+	consts := boolConsts(f)
+	dead := make(map[ast.Node]bool)
+	ast.Inspect(f, func(n ast.Node) bool {
+		stmt, ok := n.(*ast.IfStmt)
+		if !ok {
+			return true
+		}
+		v, ok := foldBool(stmt.Cond, consts)
+		switch {
+		case !ok:
+		case v && stmt.Else != nil:
+			dead[stmt.Else] = true
+		case !v:
+			dead[stmt.Body] = true
+		}
+		return true
+	})
+	return dead
+}
+
+// boolConsts returns the package-level boolean constants declared in f whose
+// value folds to true or false, keyed by the object the parser resolved for
+// each declaration.
+func boolConsts(f *ast.File) map[*ast.Object]bool {
+	consts := make(map[*ast.Object]bool)
+	for _, decl := range f.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.CONST {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			vs, ok := spec.(*ast.ValueSpec)
+			if !ok || len(vs.Names) != len(vs.Values) {
+				continue
+			}
+			for i, name := range vs.Names {
+				if v, ok := foldBool(vs.Values[i], consts); ok && name.Obj != nil {
+					consts[name.Obj] = v
+				}
+			}
+		}
+	}
+	return consts
+}
+
+// foldBool evaluates a boolean expression built from true, false, known
+// constants, !, &&, and ||. ok is false when the value depends on anything
+// else.
+func foldBool(expr ast.Expr, consts map[*ast.Object]bool) (value, ok bool) {
+	switch e := ast.Unparen(expr).(type) {
+	case *ast.Ident:
+		if e.Obj != nil {
+			v, ok := consts[e.Obj]
+			return v, ok
+		}
+		switch e.Name {
+		case "true":
+			return true, true
+		case "false":
+			return false, true
+		}
+	case *ast.UnaryExpr:
+		if e.Op == token.NOT {
+			v, ok := foldBool(e.X, consts)
+			return !v, ok
+		}
+	case *ast.BinaryExpr:
+		x, xok := foldBool(e.X, consts)
+		y, yok := foldBool(e.Y, consts)
+		switch e.Op {
+		case token.LAND:
+			if (xok && !x) || (yok && !y) {
+				return false, true
+			}
+			return true, xok && yok
+		case token.LOR:
+			if (xok && x) || (yok && y) {
+				return true, true
+			}
+			return false, xok && yok
+		}
+	}
+	return false, false
+}
+
+// deadOnlyImports returns the local names of imports that f references only
+// inside dead code. Their import-level capabilities are not reported.
+func deadOnlyImports(f *ast.File, dead map[ast.Node]bool) map[string]bool {
+	if len(dead) == 0 {
+		return nil
+	}
+	total := make(map[string]int)
+	inDead := make(map[string]int)
+	var deadDepth int
+	var stack []ast.Node
+	ast.Inspect(f, func(n ast.Node) bool {
+		if n == nil {
+			if dead[stack[len(stack)-1]] {
+				deadDepth--
+			}
+			stack = stack[:len(stack)-1]
+			return true
+		}
+		stack = append(stack, n)
+		if dead[n] {
+			deadDepth++
+		}
+		if sel, ok := n.(*ast.SelectorExpr); ok {
+			if id, ok := sel.X.(*ast.Ident); ok {
+				total[id.Name]++
+				if deadDepth > 0 {
+					inDead[id.Name]++
+				}
+			}
+		}
+		return true
+	})
+	out := make(map[string]bool)
+	for name, n := range inDead {
+		if n == total[name] {
+			out[name] = true
+		}
+	}
+	return out
+}
+
+// constStrings returns the names in f whose every binding — const, var, :=,
+// and = alike — is a constant string expression, so a query held in one is
+// as safe as a literal. Names are not scoped: a name also bound to anything
+// dynamic anywhere in f is left out.
+func constStrings(f *ast.File) map[string]bool {
+	bindings := stringBindings(f)
+
+	// Iterate so constants built from other constants are folded too.
+	consts := make(map[string]bool)
+	for changed := true; changed; {
+		changed = false
+		for name, values := range bindings {
+			if consts[name] {
+				continue
+			}
+			all := true
+			for _, v := range values {
+				if v == nil || !isConstString(v, consts) {
+					all = false
+					break
+				}
+			}
+			if all {
+				consts[name] = true
+				changed = true
+			}
+		}
+	}
+	return consts
+}
+
+// builtStrings returns the names in f that some binding builds at run time
+// — a + concatenation or += with a non-constant operand, or an fmt.Sprintf
+// call — so a query held in one is built as isBuiltString describes. consts
+// comes from constStrings.
+func builtStrings(f *ast.File, importAliases map[string]string, consts map[string]bool) map[string]bool {
+	built := make(map[string]bool)
+	ast.Inspect(f, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.ValueSpec:
+			if len(n.Values) == len(n.Names) {
+				for i, name := range n.Names {
+					if isBuiltString(n.Values[i], importAliases, consts, nil) {
+						built[name.Name] = true
+					}
+				}
+			}
+		case *ast.AssignStmt:
+			if len(n.Rhs) != len(n.Lhs) {
+				return true
+			}
+			for i, lhs := range n.Lhs {
+				id, ok := lhs.(*ast.Ident)
+				if !ok {
+					continue
+				}
+				rhs := n.Rhs[i]
+				if n.Tok == token.ADD_ASSIGN && !isConstString(rhs, consts) {
+					built[id.Name] = true
+				} else if (n.Tok == token.DEFINE || n.Tok == token.ASSIGN) && isBuiltString(rhs, importAliases, consts, nil) {
+					built[id.Name] = true
+				}
+			}
+		}
+		return true
+	})
+	return built
+}
+
+// isBuiltString reports whether expr builds a string at run time: a +
+// concatenation that is not constant, an fmt.Sprintf whose arguments are
+// not all constant, or a name in built.
+func isBuiltString(expr ast.Expr, importAliases map[string]string, consts, built map[string]bool) bool {
+	switch e := ast.Unparen(expr).(type) {
+	case *ast.Ident:
+		return built[e.Name]
+	case *ast.BinaryExpr:
+		return e.Op == token.ADD && !isConstString(e, consts)
+	case *ast.CallExpr:
+		sel, ok := e.Fun.(*ast.SelectorExpr)
+		if !ok || sel.Sel.Name != "Sprintf" {
+			return false
+		}
+		if id, ok := sel.X.(*ast.Ident); !ok || importAliases[id.Name] != "fmt" {
+			return false
+		}
+		for _, arg := range e.Args {
+			if !isConstString(arg, consts) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// stringBindings returns every value bound to each name in f by const,
+// var, :=, and =. A nil value means the name is bound dynamically: by +=,
+// a multi-value assignment, or a declaration without a value.
+func stringBindings(f *ast.File) map[string][]ast.Expr {
+	bindings := make(map[string][]ast.Expr)
+	bind := func(name *ast.Ident, value ast.Expr) {
+		if name != nil && name.Name != "_" {
+			bindings[name.Name] = append(bindings[name.Name], value)
+		}
+	}
+	ast.Inspect(f, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.ValueSpec:
+			for i, name := range n.Names {
+				var value ast.Expr
+				if len(n.Values) == len(n.Names) {
+					value = n.Values[i]
+				}
+				bind(name, value)
+			}
+		case *ast.AssignStmt:
+			for i, lhs := range n.Lhs {
+				var value ast.Expr
+				if len(n.Rhs) == len(n.Lhs) && (n.Tok == token.DEFINE || n.Tok == token.ASSIGN) {
+					value = n.Rhs[i]
+				}
+				if id, ok := lhs.(*ast.Ident); ok {
+					bind(id, value)
+				}
+			}
+		}
+		return true
+	})
+	return bindings
+}
+
+// isConstString reports whether expr is a string literal, a name in consts,
+// or a + concatenation of those.
+func isConstString(expr ast.Expr, consts map[string]bool) bool {
+	switch e := ast.Unparen(expr).(type) {
+	case *ast.BasicLit:
+		return e.Kind == token.STRING
+	case *ast.Ident:
+		return consts[e.Name]
+	case *ast.BinaryExpr:
+		return e.Op == token.ADD && isConstString(e.X, consts) && isConstString(e.Y, consts)
+	}
+	return false
+}
@@ -3,10 +3,15 @@ package goadapter
 import (
 	"fmt"
 	"go/ast"
+	"go/token"
 	"go/types"
+	"maps"
 	"path/filepath"
+	"slices"
+	"strings"
 
 	"github.com/1homsi/gorisk/internal/capability"
+	"github.com/1homsi/gorisk/internal/graph"
 	"github.com/1homsi/gorisk/internal/ir"
 	"golang.org/x/tools/go/packages"
 )
@@ -54,13 +59,78 @@ func PropagateAcrossPackages(
 	return result
 }
 
+// runsOnImportCaps are the capabilities flagged when reached from init():
+// side effects that fire merely by importing the package.
+var runsOnImportCaps = []capability.Capability{
+	capability.CapExec, capability.CapNetwork, capability.CapPlugin,
+}
+
+// RunsOnImport walks the call graph from every init function in funcs and
+// returns, per package, the exec/network/plugin capabilities used directly
+// or transitively from init. Each evidence entry is re-recorded with
+// Via "runsOnImport", which also bumps the capability's score.
+func RunsOnImport(funcs map[string]ir.FunctionCaps, edges []ir.CallEdge) map[string]capability.CapabilitySet {
+	callees := make(map[string][]string)
+	for _, e := range edges {
+		callees[e.Caller.String()] = append(callees[e.Caller.String()], e.Callee.String())
+	}
+
+	result := make(map[string]capability.CapabilitySet)
+	for key, fc := range funcs {
+		if fc.Symbol.Name != "init" || fc.Symbol.Kind != "func" {
+			continue
+		}
+		cs := result[fc.Symbol.Package]
+		visited := map[string]bool{key: true}
+		queue := []string{key}
+		for len(queue) > 0 {
+			cur := queue[0]
+			queue = queue[1:]
+			reached, ok := funcs[cur]
+			if !ok {
+				continue
+			}
+			for _, c := range runsOnImportCaps {
+				for _, ev := range reached.DirectCaps.Evidence[c] {
+					ctx := "init(): " + ev.Context
+					if cur != key {
+						ctx = "init() → " + cur + ": " + ev.Context
+					}
+					cs.AddWithEvidence(c, capability.CapabilityEvidence{
+						File:       ev.File,
+						Line:       ev.Line,
+						Context:    ctx,
+						Via:        capability.ViaRunsOnImport,
+						Confidence: ev.Confidence,
+					})
+				}
+			}
+			for _, next := range callees[cur] {
+				if !visited[next] {
+					visited[next] = true
+					queue = append(queue, next)
+				}
+			}
+		}
+		if !cs.IsEmpty() {
+			result[fc.Symbol.Package] = cs
+		}
+	}
+	return result
+}
+
 // BuildModuleGraph loads all packages in the module at dir and builds a cross-package
 // call graph using golang.org/x/tools/go/packages.
 func BuildModuleGraph(dir string, g map[string]*Package) (map[string]map[string]ir.FunctionCaps, map[string][]ir.CallEdge, error) {
+	return native.buildModuleGraph(dir, g)
+}
+
+func (d *detector) buildModuleGraph(dir string, g map[string]*Package) (map[string]map[string]ir.FunctionCaps, map[string][]ir.CallEdge, error) {
 	// Load all packages in the module
 	cfg := &packages.Config{
 		Mode: packages.NeedName | packages.NeedFiles | packages.NeedSyntax | packages.NeedTypes | packages.NeedTypesInfo | packages.NeedImports,
 		Dir:  dir,
+		Env:  graph.Environ(d.env),
 	}
 
 	pkgs, err := packages.Load(cfg, "./...")
@@ -71,6 +141,14 @@ func BuildModuleGraph(dir string, g map[string]*Package) (map[string]map[string]
 	// Continue despite package loading errors — partial analysis is better than none
 	_ = packages.PrintErrors(pkgs)
 
+	pkgCaps, pkgEdges := d.buildPackageGraphs(pkgs)
+	return pkgCaps, pkgEdges, nil
+}
+
+// buildPackageGraphs builds the call graph of every loaded package, sharing
+// the facts collected across all of them.
+func (d *detector) buildPackageGraphs(pkgs []*packages.Package) (map[string]map[string]ir.FunctionCaps, map[string][]ir.CallEdge) {
+	mod := newModuleFacts(pkgs)
 	pkgCaps := make(map[string]map[string]ir.FunctionCaps)
 	pkgEdges := make(map[string][]ir.CallEdge)
 
@@ -78,139 +156,874 @@ func BuildModuleGraph(dir string, g map[string]*Package) (map[string]map[string]
 		if len(pkg.Syntax) == 0 {
 			continue
 		}
+		funcs, edges := d.buildPackageGraph(pkg.PkgPath, pkg.Fset, pkg.Syntax, pkg.TypesInfo, mod)
+		pkgCaps[pkg.PkgPath] = funcs
+		pkgEdges[pkg.PkgPath] = edges
+	}
+	return pkgCaps, pkgEdges
+}
 
-		funcs := make(map[string]ir.FunctionCaps)
-		var edges []ir.CallEdge
+// moduleFacts are what a package's call graph needs from the rest of the
+// module. A generic function is often instantiated outside the package that
+// declares it, and a concrete value stored into an interface variable or
+// field (including an embedded one) declared elsewhere, so both are
+// collected from every package before any graph is built.
+type moduleFacts struct {
+	typeArgs typeArgs
+	impls    implSet
+}
 
-		fset := pkg.Fset
+func newModuleFacts(pkgs []*packages.Package) *moduleFacts {
+	mod := &moduleFacts{typeArgs: make(typeArgs), impls: make(implSet)}
+	for _, pkg := range pkgs {
+		mod.typeArgs.collect(pkg.Syntax, pkg.TypesInfo)
+	}
+	for _, pkg := range pkgs {
+		fv := newFuncValues(pkg.PkgPath, pkg.TypesInfo, mod)
+		for _, file := range pkg.Syntax {
+			fv.collect(file)
+		}
+	}
+	return mod
+}
 
-		// Build import alias map for this package
-		importAliases := make(map[string]string)
-		for path, imp := range pkg.Imports {
-			localName := filepath.Base(path)
-			if imp.Name != "" {
-				localName = imp.Name
-			}
-			importAliases[localName] = path
+// buildPackageGraph computes per-function direct capabilities and call edges
+// for one type-checked package.
+//
+// Calls are attributed to the function that makes them, including calls in
+// defer and go statements and in recover handlers; calls in package-level
+// var initializers belong to the package's init function. A function value
+// (function, method value such as r.Run, or closure) gets a synthetic edge
+// from every site that invokes the variable, field, element, or call result
+// holding it, and is assumed to be invoked when passed outside the package
+// (once.Do(r.Start)). A method called on a type parameter or promoted from
+// an embedded interface resolves to that method on every type argument or
+// concrete type stored for it.
+//
+// mod holds the instantiations and stored types of the whole module; nil
+// collects them from files alone.
+func (d *detector) buildPackageGraph(pkgPath string, fset *token.FileSet, files []*ast.File, info *types.Info, mod *moduleFacts) (map[string]ir.FunctionCaps, []ir.CallEdge) {
+	funcs := make(map[string]ir.FunctionCaps)
+	var edges []ir.CallEdge
+
+	if mod == nil {
+		mod = newModuleFacts([]*packages.Package{{PkgPath: pkgPath, Syntax: files, TypesInfo: info}})
+	}
+	fv := newFuncValues(pkgPath, info, mod)
+	dead := make(map[ast.Node]bool)
+	drops := make(map[string]*payloadDrops) // by file name
+	for _, file := range files {
+		fv.collect(file)
+		maps.Copy(dead, deadCode(file))
+		if d := newPayloadDrops(file, fileImportAliases(file)); d != nil {
+			drops[fset.Position(file.Pos()).Filename] = d
 		}
+	}
 
-		for _, file := range pkg.Syntax {
-			// Scan functions
-			for _, decl := range file.Decls {
-				fn, ok := decl.(*ast.FuncDecl)
-				if !ok || fn.Body == nil {
-					continue
-				}
+	scan := func(body ast.Node, callerSym ir.Symbol) {
+		callerKey := callerSym.String()
+		fc, exists := funcs[callerKey]
+		if !exists {
+			fc = ir.FunctionCaps{Symbol: callerSym}
+		}
 
-				callerSym := funcSymbolForPackage(fn, pkg.PkgPath)
-				callerKey := callerSym.String()
-				fc, exists := funcs[callerKey]
-				if !exists {
-					fc = ir.FunctionCaps{Symbol: callerSym}
-				}
+		addEdge := func(callee ir.Symbol, pos token.Pos, synthetic bool) {
+			p := fset.Position(pos)
+			edges = append(edges, ir.CallEdge{
+				Caller:    callerSym,
+				Callee:    callee,
+				File:      p.Filename,
+				Line:      p.Line,
+				Synthetic: synthetic,
+			})
+		}
 
-				// Scan call expressions
-				ast.Inspect(fn.Body, func(n ast.Node) bool {
-					call, ok := n.(*ast.CallExpr)
-					if !ok {
-						return true
-					}
+		// Scan call expressions
+		ast.Inspect(body, func(n ast.Node) bool {
+			if dead[n] {
+				return false
+			}
+			call, ok := n.(*ast.CallExpr)
+			if !ok {
+				return true
+			}
+			if len(drops) > 0 {
+				drops[fset.Position(call.Pos()).Filename].check(fset, call, callerKey)
+			}
+
+			// Indirect calls through a variable, field, or map/slice element
+			// holding a function value.
+			for _, callee := range fv.calleesOf(call.Fun) {
+				addEdge(callee, call.Pos(), true)
+			}
+			for _, callee := range fv.callbacks(call) {
+				addEdge(callee, call.Pos(), true)
+			}
 
-					switch fun := call.Fun.(type) {
-					case *ast.SelectorExpr:
-						// pkg.Func() or obj.Method()
-						if pkg.TypesInfo != nil && pkg.TypesInfo.Uses != nil {
-							if sel, ok := pkg.TypesInfo.Selections[fun]; ok {
-								// Method call
-								if fn := sel.Obj(); fn != nil {
-									calleePkg := ""
-									if fn.Pkg() != nil {
-										calleePkg = fn.Pkg().Path()
-									}
-									calleeSym := ir.Symbol{
-										Package: calleePkg,
-										Name:    fn.Name(),
-										Kind:    "method",
-									}
-									edges = append(edges, ir.CallEdge{
-										Caller: callerSym,
-										Callee: calleeSym,
-										File:   fset.Position(call.Pos()).Filename,
-										Line:   fset.Position(call.Pos()).Line,
+			switch fun := fv.generic(call.Fun).(type) {
+			case *ast.SelectorExpr:
+				// pkg.Func() or obj.Method()
+				if info != nil && info.Uses != nil {
+					if sel, ok := info.Selections[fun]; ok {
+						// Method call
+						if fn, ok := sel.Obj().(*types.Func); ok {
+							addEdge(funcObjSymbol(fn, ""), call.Pos(), false)
+						}
+					} else if ident, ok := fun.X.(*ast.Ident); ok {
+						// Package-level function call
+						if obj := info.Uses[ident]; obj != nil {
+							if pkgName, ok := obj.(*types.PkgName); ok {
+								calleePkg := pkgName.Imported().Path()
+								funcName := fun.Sel.Name
+								calleeSym := ir.Symbol{
+									Package: calleePkg,
+									Name:    funcName,
+									Kind:    "func",
+								}
+								addEdge(calleeSym, call.Pos(), false)
+
+								// Also check for direct capability
+								pkgShort := filepath.Base(calleePkg)
+								pattern := pkgShort + "." + funcName
+								for _, c := range d.patterns.CallSites[pattern] {
+									pos := fset.Position(call.Pos())
+									fc.DirectCaps.AddWithEvidence(c, capability.CapabilityEvidence{
+										File:       pos.Filename,
+										Line:       pos.Line,
+										Context:    callSiteContext(pattern, d.patterns.CallSites[pattern], call),
+										Via:        "callSite",
+										Confidence: 0.75,
 									})
 								}
-							} else if ident, ok := fun.X.(*ast.Ident); ok {
-								// Package-level function call
-								if obj := pkg.TypesInfo.Uses[ident]; obj != nil {
-									if pkgName, ok := obj.(*types.PkgName); ok {
-										calleePkg := pkgName.Imported().Path()
-										funcName := fun.Sel.Name
-										calleeSym := ir.Symbol{
-											Package: calleePkg,
-											Name:    funcName,
-											Kind:    "func",
-										}
-										edges = append(edges, ir.CallEdge{
-											Caller: callerSym,
-											Callee: calleeSym,
-											File:   fset.Position(call.Pos()).Filename,
-											Line:   fset.Position(call.Pos()).Line,
-										})
-
-										// Also check for direct capability
-										pkgShort := filepath.Base(calleePkg)
-										pattern := pkgShort + "." + funcName
-										for _, c := range GoPatterns.CallSites[pattern] {
-											pos := fset.Position(call.Pos())
-											fc.DirectCaps.AddWithEvidence(c, capability.CapabilityEvidence{
-												File:       pos.Filename,
-												Line:       pos.Line,
-												Context:    pattern,
-												Via:        "callSite",
-												Confidence: 0.75,
-											})
-										}
-									}
+								if readsProc(d.patterns.CallSites[pattern], call) {
+									pos := fset.Position(call.Pos())
+									fc.DirectCaps.AddWithEvidence(capability.CapProcessInspect, capability.CapabilityEvidence{
+										File:       pos.Filename,
+										Line:       pos.Line,
+										Context:    callSiteContext(pattern, d.patterns.CallSites[pattern], call),
+										Via:        "callSite",
+										Confidence: 0.75,
+									})
 								}
 							}
 						}
+					}
+				}
 
-					case *ast.Ident:
-						// Bare function call within the same package
-						if pkg.TypesInfo != nil && pkg.TypesInfo.Uses != nil {
-							if obj := pkg.TypesInfo.Uses[fun]; obj != nil {
-								if fn, ok := obj.(*types.Func); ok {
-									calleePkg := pkg.PkgPath
-									if fn.Pkg() != nil {
-										calleePkg = fn.Pkg().Path()
-									}
-									calleeSym := ir.Symbol{
-										Package: calleePkg,
-										Name:    fn.Name(),
-										Kind:    "func",
-									}
-									edges = append(edges, ir.CallEdge{
-										Caller: callerSym,
-										Callee: calleeSym,
-										File:   fset.Position(call.Pos()).Filename,
-										Line:   fset.Position(call.Pos()).Line,
-									})
-								}
+			case *ast.Ident:
+				// Bare function call within the same package
+				if info != nil && info.Uses != nil {
+					if obj := info.Uses[fun]; obj != nil {
+						if fn, ok := obj.(*types.Func); ok {
+							calleePkg := pkgPath
+							if fn.Pkg() != nil {
+								calleePkg = fn.Pkg().Path()
 							}
+							calleeSym := ir.Symbol{
+								Package: calleePkg,
+								Name:    fn.Name(),
+								Kind:    "func",
+							}
+							addEdge(calleeSym, call.Pos(), false)
 						}
 					}
-					return true
-				})
+				}
+			}
+			return true
+		})
+
+		funcs[callerKey] = fc
+	}
+
+	initSym := ir.Symbol{Package: pkgPath, Name: "init", Kind: "func"}
+	for _, file := range files {
+		// Scan functions
+		for _, decl := range file.Decls {
+			switch decl := decl.(type) {
+			case *ast.FuncDecl:
+				if decl.Body != nil {
+					scan(decl.Body, funcSymbolForPackage(decl, pkgPath))
+				}
+			case *ast.GenDecl:
+				// Package-level var initializers run during package
+				// initialization, before main, so their calls belong to init.
+				if decl.Tok != token.VAR {
+					continue
+				}
+				for _, spec := range decl.Specs {
+					for _, v := range spec.(*ast.ValueSpec).Values {
+						skipStoredLits(v, dead)
+						scan(v, initSym)
+					}
+				}
+			}
+		}
+	}
+
+	// Closures held in variables get their own node so indirect callers pick
+	// up their capabilities. Package-level closures are only reachable this way.
+	for _, lit := range fv.lits {
+		scan(lit.body, lit.sym)
+	}
 
-				funcs[callerKey] = fc
+	execFiles := make(map[string]bool)
+	for _, fc := range funcs {
+		for _, ev := range fc.DirectCaps.Evidence[capability.CapExec] {
+			execFiles[ev.File] = true
+		}
+	}
+	for name, d := range drops {
+		if !execFiles[name] {
+			continue
+		}
+		d.each(func(caller string, ev capability.CapabilityEvidence) {
+			fc := funcs[caller]
+			fc.DirectCaps.AddWithEvidence(capability.CapExec, ev)
+			funcs[caller] = fc
+		})
+	}
+
+	return funcs, edges
+}
+
+// skipStoredLits marks the function literals in a package-level initializer
+// that are stored rather than called (var handler = func() { ... }): their
+// bodies run only when invoked, and are scanned as bound closures instead.
+// Immediately invoked literals (var x = func() T { ... }()) run at init and
+// stay.
+func skipStoredLits(init ast.Expr, skip map[ast.Node]bool) {
+	called := make(map[*ast.FuncLit]bool)
+	ast.Inspect(init, func(n ast.Node) bool {
+		if call, ok := n.(*ast.CallExpr); ok {
+			if lit, ok := ast.Unparen(call.Fun).(*ast.FuncLit); ok {
+				called[lit] = true
 			}
 		}
+		return true
+	})
+	ast.Inspect(init, func(n ast.Node) bool {
+		lit, ok := n.(*ast.FuncLit)
+		if !ok {
+			return true
+		}
+		if !called[lit] {
+			skip[lit] = true
+			return false
+		}
+		return true
+	})
+}
 
-		pkgCaps[pkg.PkgPath] = funcs
-		pkgEdges[pkg.PkgPath] = edges
+// boundLit is a function literal stored in a variable, field, or container.
+type boundLit struct {
+	sym  ir.Symbol
+	body *ast.BlockStmt
+}
+
+// funcValues records which functions a variable or field may hold, and
+// which concrete types are stored into interface-typed holders so method
+// calls through them (plugin registries, handler maps) can be resolved.
+type funcValues struct {
+	pkgPath  string
+	info     *types.Info
+	bindings map[types.Object][]ir.Symbol
+	aliases  map[types.Object][]types.Object
+	impls    implSet
+	typeArgs typeArgs
+	lits     []boundLit
+	litSyms  map[*ast.FuncLit]ir.Symbol
+	counters map[string]int
+}
+
+// implSet maps an interface-typed holder to the concrete types stored
+// into it.
+type implSet map[types.Object][]*types.Named
+
+func newFuncValues(pkgPath string, info *types.Info, mod *moduleFacts) *funcValues {
+	return &funcValues{
+		pkgPath:  pkgPath,
+		info:     info,
+		bindings: make(map[types.Object][]ir.Symbol),
+		aliases:  make(map[types.Object][]types.Object),
+		impls:    mod.impls,
+		typeArgs: mod.typeArgs,
+		litSyms:  make(map[*ast.FuncLit]ir.Symbol),
+		counters: make(map[string]int),
 	}
+}
 
-	return pkgCaps, pkgEdges, nil
+// collect walks file and records every function value stored into a
+// variable, struct field, or map/slice element.
+func (fv *funcValues) collect(file *ast.File) {
+	if fv.info == nil {
+		return
+	}
+	for _, decl := range file.Decls {
+		encl := "glob."
+		if fn, ok := decl.(*ast.FuncDecl); ok {
+			encl = funcSymbolForPackage(fn, fv.pkgPath).Name
+		}
+		if fn, ok := decl.(*ast.FuncDecl); ok && fn.Body != nil {
+			fv.collectReturns(fn, encl)
+		}
+		ast.Inspect(decl, func(n ast.Node) bool {
+			switch n := n.(type) {
+			case *ast.AssignStmt:
+				if len(n.Lhs) == len(n.Rhs) {
+					for i, lhs := range n.Lhs {
+						fv.bind(fv.holder(lhs), n.Rhs[i], encl)
+					}
+				}
+			case *ast.ValueSpec:
+				if len(n.Names) == len(n.Values) {
+					for i, name := range n.Names {
+						fv.bind(fv.info.Defs[name], n.Values[i], encl)
+					}
+				}
+			case *ast.CallExpr:
+				// Arguments flow into the parameters of same-package callees,
+				// so Register("x", handler{}) reaches the registry it stores into.
+				if fn := fv.staticCallee(n); fn != nil {
+					sig := fn.Type().(*types.Signature)
+					for i, arg := range n.Args {
+						if i >= sig.Params().Len() || (sig.Variadic() && i >= sig.Params().Len()-1) {
+							break
+						}
+						fv.bind(sig.Params().At(i), arg, encl)
+					}
+				}
+			case *ast.CompositeLit:
+				// Struct literals: Field: fn binds the field itself, as does
+				// a positional element (Wrapper{execRunner{}}).
+				var st *types.Struct
+				if t := fv.info.TypeOf(n); t != nil {
+					st, _ = derefType(t).Underlying().(*types.Struct)
+				}
+				for i, elt := range n.Elts {
+					kv, ok := elt.(*ast.KeyValueExpr)
+					if !ok {
+						if st != nil && i < st.NumFields() {
+							fv.bind(st.Field(i), elt, encl)
+						}
+						continue
+					}
+					if key, ok := kv.Key.(*ast.Ident); ok {
+						if field, ok := fv.info.Uses[key].(*types.Var); ok && field.IsField() {
+							fv.bind(field, kv.Value, encl)
+						}
+					}
+				}
+			}
+			return true
+		})
+	}
+}
+
+// collectReturns binds fn's own function object to every function value
+// its body returns, so a call to fn stands for the functions it hands back
+// (func (r *Runner) task() func() error { return r.Run }). Returns inside
+// nested function literals belong to those literals and are skipped.
+func (fv *funcValues) collectReturns(fn *ast.FuncDecl, encl string) {
+	obj := fv.info.Defs[fn.Name]
+	if obj == nil {
+		return
+	}
+	ast.Inspect(fn.Body, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.FuncLit:
+			return false
+		case *ast.ReturnStmt:
+			for _, res := range n.Results {
+				if isFunc(fv.info.TypeOf(res)) {
+					fv.bind(obj, res, encl)
+				}
+			}
+		}
+		return true
+	})
+}
+
+// bind records that holder may contain the function value(s) or concrete
+// type in rhs. A composite literal of functions (map, slice, array) binds
+// every element; copying another holder links the two.
+func (fv *funcValues) bind(holder types.Object, rhs ast.Expr, encl string) {
+	if holder == nil {
+		return
+	}
+	rhs = ast.Unparen(rhs)
+	fv.addImpl(holder, fv.info.TypeOf(rhs))
+	if cl, ok := rhs.(*ast.CompositeLit); ok {
+		if _, isStruct := fv.info.TypeOf(cl).Underlying().(*types.Struct); isStruct {
+			return // fields are bound individually in collect
+		}
+		for _, elt := range cl.Elts {
+			if kv, ok := elt.(*ast.KeyValueExpr); ok {
+				elt = kv.Value
+			}
+			fv.bind(holder, elt, encl)
+		}
+		return
+	}
+	if lit, ok := rhs.(*ast.FuncLit); ok {
+		fv.litSymbol(lit, encl)
+	}
+	if call, ok := rhs.(*ast.CallExpr); ok {
+		// f := r.task(): f holds whatever task returns.
+		if fn := fv.staticCallee(call); fn != nil && fn != holder {
+			fv.aliases[holder] = append(fv.aliases[holder], fn)
+		}
+		return
+	}
+	if src, ok := fv.holder(rhs).(*types.Var); ok {
+		if src != holder {
+			fv.aliases[holder] = append(fv.aliases[holder], src)
+		}
+		return
+	}
+	fv.bindings[holder] = append(fv.bindings[holder], fv.valueOf(rhs)...)
+}
+
+// addImpl records t as a concrete type stored into holder. Interface types
+// carry no implementation and are ignored.
+func (fv *funcValues) addImpl(holder types.Object, t types.Type) {
+	if t == nil {
+		return
+	}
+	if ptr, ok := t.(*types.Pointer); ok {
+		t = ptr.Elem()
+	}
+	named, ok := t.(*types.Named)
+	if !ok || types.IsInterface(named) {
+		return
+	}
+	for _, seen := range fv.impls[holder] {
+		if seen == named {
+			return
+		}
+	}
+	fv.impls[holder] = append(fv.impls[holder], named)
+}
+
+// reach returns holder and every holder whose contents flow into it.
+// Aliases are resolved lazily so declaration order does not matter.
+func (fv *funcValues) reach(holder types.Object) []types.Object {
+	out := []types.Object{holder}
+	seen := map[types.Object]bool{holder: true}
+	for i := 0; i < len(out); i++ {
+		for _, src := range fv.aliases[out[i]] {
+			if !seen[src] {
+				seen[src] = true
+				out = append(out, src)
+			}
+		}
+	}
+	return out
+}
+
+// funcsOf returns the function values holder may contain.
+func (fv *funcValues) funcsOf(holder types.Object) []ir.Symbol {
+	var syms []ir.Symbol
+	for _, h := range fv.reach(holder) {
+		syms = append(syms, fv.bindings[h]...)
+	}
+	return syms
+}
+
+// dispatch resolves an interface method call through holder to the methods
+// of every concrete type stored into it.
+func (fv *funcValues) dispatch(holder types.Object, method string) []ir.Symbol {
+	var syms []ir.Symbol
+	for _, h := range fv.reach(holder) {
+		for _, named := range fv.impls[h] {
+			if sym, ok := fv.methodOf(named, method); ok {
+				syms = append(syms, sym)
+			}
+		}
+	}
+	return syms
+}
+
+// methodOf returns the symbol of named's method, including promoted and
+// pointer-receiver methods.
+func (fv *funcValues) methodOf(named *types.Named, method string) (ir.Symbol, bool) {
+	obj, _, _ := types.LookupFieldOrMethod(types.NewPointer(named), false, named.Obj().Pkg(), method)
+	fn, ok := obj.(*types.Func)
+	if !ok {
+		return ir.Symbol{}, false
+	}
+	return funcObjSymbol(fn, fv.pkgPath), true
+}
+
+// typeArgs maps each type parameter to the type arguments it is
+// instantiated with.
+type typeArgs map[*types.TypeParam][]types.Type
+
+// collect records every instantiation of a generic function or type in
+// files, plus the link from a generic type's methods to its parameters.
+func (ta typeArgs) collect(files []*ast.File, info *types.Info) {
+	if info == nil {
+		return
+	}
+	for _, file := range files {
+		ast.Inspect(file, func(n ast.Node) bool {
+			switch n := n.(type) {
+			case *ast.FuncDecl:
+				ta.linkRecv(n, info)
+			case *ast.Ident:
+				if inst, ok := info.Instances[n]; ok {
+					ta.add(info.Uses[n], inst.TypeArgs)
+				}
+			}
+			return true
+		})
+	}
+}
+
+// add records the type arguments of one instantiation of the generic
+// function or type obj against its declared type parameters.
+func (ta typeArgs) add(obj types.Object, args *types.TypeList) {
+	var tparams *types.TypeParamList
+	switch obj := obj.(type) {
+	case *types.Func:
+		if sig, ok := obj.Origin().Type().(*types.Signature); ok {
+			tparams = sig.TypeParams()
+		}
+	case *types.TypeName:
+		if named, ok := obj.Type().(*types.Named); ok {
+			tparams = named.Origin().TypeParams()
+		}
+	}
+	if tparams == nil || args == nil {
+		return
+	}
+	for i := 0; i < tparams.Len() && i < args.Len(); i++ {
+		ta[tparams.At(i)] = append(ta[tparams.At(i)], args.At(i))
+	}
+}
+
+// linkRecv maps the receiver type parameters of a method on a generic type
+// (func (b Box[T]) Run()) to the type's own parameters, which are distinct
+// objects, so instantiations of Box reach the method body.
+func (ta typeArgs) linkRecv(fn *ast.FuncDecl, info *types.Info) {
+	obj, ok := info.Defs[fn.Name].(*types.Func)
+	if !ok {
+		return
+	}
+	sig := obj.Type().(*types.Signature)
+	if sig.Recv() == nil || sig.RecvTypeParams() == nil {
+		return
+	}
+	recv := sig.Recv().Type()
+	if ptr, ok := recv.(*types.Pointer); ok {
+		recv = ptr.Elem()
+	}
+	named, ok := recv.(*types.Named)
+	if !ok {
+		return
+	}
+	declared := named.Origin().TypeParams()
+	for i := 0; i < sig.RecvTypeParams().Len() && i < declared.Len(); i++ {
+		tp := sig.RecvTypeParams().At(i)
+		ta[tp] = append(ta[tp], declared.At(i))
+	}
+}
+
+// concrete returns the named types tp may be instantiated with, following
+// type parameters forwarded from one generic function to another.
+func (ta typeArgs) concrete(tp *types.TypeParam) []*types.Named {
+	var out []*types.Named
+	seen := map[*types.TypeParam]bool{tp: true}
+	queue := []*types.TypeParam{tp}
+	for i := 0; i < len(queue); i++ {
+		for _, t := range ta[queue[i]] {
+			if ptr, ok := t.(*types.Pointer); ok {
+				t = ptr.Elem()
+			}
+			switch t := t.(type) {
+			case *types.TypeParam:
+				if !seen[t] {
+					seen[t] = true
+					queue = append(queue, t)
+				}
+			case *types.Named:
+				if !types.IsInterface(t) && !slices.Contains(out, t) {
+					out = append(out, t)
+				}
+			}
+		}
+	}
+	return out
+}
+
+// generic strips explicit type arguments from a generic function reference
+// (Run[T] or pkg.Run[T, U]) so the call resolves like a plain call. Index
+// expressions on maps and slices are returned unchanged.
+func (fv *funcValues) generic(fun ast.Expr) ast.Expr {
+	if fv.info == nil {
+		return fun
+	}
+	var x ast.Expr
+	switch e := ast.Unparen(fun).(type) {
+	case *ast.IndexExpr:
+		x = e.X
+	case *ast.IndexListExpr:
+		x = e.X
+	default:
+		return fun
+	}
+	id, ok := ast.Unparen(x).(*ast.Ident)
+	if sel, isSel := ast.Unparen(x).(*ast.SelectorExpr); isSel {
+		id, ok = sel.Sel, true
+	}
+	if !ok {
+		return fun
+	}
+	if _, isInst := fv.info.Instances[id]; !isInst {
+		return fun
+	}
+	return x
+}
+
+// staticCallee returns the same-package function or method call invokes
+// directly, or nil for indirect and cross-package calls.
+func (fv *funcValues) staticCallee(call *ast.CallExpr) *types.Func {
+	var obj types.Object
+	switch fun := ast.Unparen(fv.generic(call.Fun)).(type) {
+	case *ast.Ident:
+		obj = fv.info.Uses[fun]
+	case *ast.SelectorExpr:
+		obj = fv.info.Uses[fun.Sel]
+	}
+	fn, ok := obj.(*types.Func)
+	if !ok || fn.Pkg() == nil || fn.Pkg().Path() != fv.pkgPath {
+		return nil
+	}
+	return fn
+}
+
+// litSymbol returns the synthetic symbol for a bound function literal,
+// named after its enclosing function like the compiler does (run.func1).
+func (fv *funcValues) litSymbol(lit *ast.FuncLit, encl string) ir.Symbol {
+	if sym, ok := fv.litSyms[lit]; ok {
+		return sym
+	}
+	fv.counters[encl]++
+	sym := ir.Symbol{
+		Package: fv.pkgPath,
+		Name:    fmt.Sprintf("%s.func%d", strings.TrimSuffix(encl, "."), fv.counters[encl]),
+		Kind:    "func",
+	}
+	fv.litSyms[lit] = sym
+	fv.lits = append(fv.lits, boundLit{sym: sym, body: lit.Body})
+	return sym
+}
+
+// valueOf resolves a function-valued expression to the symbols it may denote:
+// a named function or method value, a bound closure, another holder, or the
+// result of a call returning one of those.
+func (fv *funcValues) valueOf(expr ast.Expr) []ir.Symbol {
+	if fv.info == nil {
+		return nil
+	}
+	switch e := ast.Unparen(expr).(type) {
+	case *ast.CallExpr:
+		if fn := fv.staticCallee(e); fn != nil {
+			return fv.funcsOf(fn)
+		}
+		return nil
+	case *ast.FuncLit:
+		if sym, ok := fv.litSyms[e]; ok {
+			return []ir.Symbol{sym}
+		}
+		return nil
+	case *ast.Ident:
+		switch obj := fv.info.Uses[e].(type) {
+		case *types.Func:
+			return []ir.Symbol{funcObjSymbol(obj, fv.pkgPath)}
+		case *types.Var:
+			return fv.funcsOf(obj)
+		}
+	case *ast.SelectorExpr:
+		if sel, ok := fv.info.Selections[e]; ok {
+			switch obj := sel.Obj().(type) {
+			case *types.Func:
+				return []ir.Symbol{funcObjSymbol(obj, fv.pkgPath)}
+			case *types.Var:
+				return fv.funcsOf(obj)
+			}
+		} else if fn, ok := fv.info.Uses[e.Sel].(*types.Func); ok {
+			return []ir.Symbol{funcObjSymbol(fn, fv.pkgPath)}
+		}
+	}
+	return nil
+}
+
+// holder returns the variable or field an assignment target writes to.
+// Writes to m[k] or s[i] are attributed to the container m or s; reads
+// through a type assertion x.(T) are attributed to x.
+func (fv *funcValues) holder(expr ast.Expr) types.Object {
+	switch e := ast.Unparen(expr).(type) {
+	case *ast.Ident:
+		if obj := fv.info.Defs[e]; obj != nil {
+			return obj
+		}
+		return fv.info.Uses[e]
+	case *ast.SelectorExpr:
+		if sel, ok := fv.info.Selections[e]; ok {
+			if v, ok := sel.Obj().(*types.Var); ok {
+				return v
+			}
+		}
+		if v, ok := fv.info.Uses[e.Sel].(*types.Var); ok {
+			return v
+		}
+	case *ast.IndexExpr:
+		return fv.holder(e.X)
+	case *ast.TypeAssertExpr:
+		return fv.holder(e.X)
+	}
+	return nil
+}
+
+// calleesOf returns the functions an indirect call through fun may reach.
+// Direct calls to named functions and methods return nil; they are handled
+// by the regular call scan.
+func (fv *funcValues) calleesOf(fun ast.Expr) []ir.Symbol {
+	if fv.info == nil {
+		return nil
+	}
+	switch e := ast.Unparen(fun).(type) {
+	case *ast.IndexExpr, *ast.TypeAssertExpr:
+		if h := fv.holder(e); h != nil {
+			return fv.funcsOf(h)
+		}
+		return nil
+	case *ast.CallExpr:
+		// r.task()(): invoke whatever task returns.
+		return fv.valueOf(e)
+	case *ast.SelectorExpr:
+		// Method call on an interface value: dispatch to every concrete
+		// type stored into the holder it was read from. On a type-parameter
+		// value, also dispatch to every type argument it is instantiated with.
+		if sel, ok := fv.info.Selections[e]; ok && sel.Kind() == types.MethodVal && types.IsInterface(sel.Recv()) {
+			var syms []ir.Symbol
+			if h := fv.holder(e.X); h != nil {
+				syms = fv.dispatch(h, e.Sel.Name)
+			}
+			if tp := typeParamOf(sel.Recv()); tp != nil {
+				for _, named := range fv.typeArgs.concrete(tp) {
+					if sym, ok := fv.methodOf(named, e.Sel.Name); ok && !slices.Contains(syms, sym) {
+						syms = append(syms, sym)
+					}
+				}
+			}
+			return syms
+		}
+		// Method promoted from an interface embedded in a struct
+		// (type Wrapper struct{ Runner }; w.Run()): dispatch through the
+		// embedded field to the concrete types stored into it.
+		if sel, ok := fv.info.Selections[e]; ok && sel.Kind() == types.MethodVal {
+			if field := embeddedInterface(sel); field != nil {
+				return fv.dispatch(field, e.Sel.Name)
+			}
+		}
+	}
+	if _, ok := fv.holder(fun).(*types.Var); ok {
+		return fv.valueOf(fun)
+	}
+	return nil
+}
+
+// callbacks returns the function values passed as arguments to a call
+// that leaves the package, such as once.Do(r.Start) or
+// reflect.MakeFunc(typ, impl). The callee's body is not analyzed here, so
+// each one is assumed to be invoked by it. Same-package callees are skipped
+// because their parameters are bound in collect, as are builtins and
+// conversions, which never call their operands.
+func (fv *funcValues) callbacks(call *ast.CallExpr) []ir.Symbol {
+	if fv.info == nil || fv.staticCallee(call) != nil {
+		return nil
+	}
+	if tv, ok := fv.info.Types[call.Fun]; ok && (tv.IsType() || tv.IsBuiltin()) {
+		return nil
+	}
+	var syms []ir.Symbol
+	for _, arg := range call.Args {
+		if isFunc(fv.info.TypeOf(arg)) {
+			syms = append(syms, fv.valueOf(arg)...)
+		}
+	}
+	return syms
+}
+
+// isFunc reports whether t is a function type, including named ones such
+// as http.HandlerFunc.
+func isFunc(t types.Type) bool {
+	if t == nil {
+		return false
+	}
+	_, ok := t.Underlying().(*types.Signature)
+	return ok
+}
+
+// embeddedInterface returns the embedded interface field a method
+// selection is promoted through, or nil when the method is declared on a
+// concrete type or the receiver is itself an interface.
+func embeddedInterface(sel *types.Selection) *types.Var {
+	path := sel.Index()
+	if len(path) < 2 || types.IsInterface(sel.Recv()) {
+		return nil
+	}
+	t := sel.Recv()
+	var field *types.Var
+	for _, i := range path[:len(path)-1] {
+		st, ok := derefType(t).Underlying().(*types.Struct)
+		if !ok || i >= st.NumFields() {
+			return nil
+		}
+		field = st.Field(i)
+		t = field.Type()
+	}
+	if field == nil || !types.IsInterface(field.Type()) {
+		return nil
+	}
+	return field
+}
+
+// derefType returns the element type of a pointer, or t itself.
+func derefType(t types.Type) types.Type {
+	if ptr, ok := t.(*types.Pointer); ok {
+		return ptr.Elem()
+	}
+	return t
+}
+
+// typeParamOf returns the type parameter t or *t denotes, or nil.
+func typeParamOf(t types.Type) *types.TypeParam {
+	if ptr, ok := t.(*types.Pointer); ok {
+		t = ptr.Elem()
+	}
+	tp, _ := t.(*types.TypeParam)
+	return tp
+}
+
+// funcObjSymbol builds the IR symbol for a named function or method so it
+// matches the keys produced by funcSymbolForPackage.
+func funcObjSymbol(fn *types.Func, pkgPath string) ir.Symbol {
+	if fn.Pkg() != nil {
+		pkgPath = fn.Pkg().Path()
+	}
+	sig, _ := fn.Type().(*types.Signature)
+	if sig != nil && sig.Recv() != nil {
+		recv := sig.Recv().Type()
+		if ptr, ok := recv.(*types.Pointer); ok {
+			recv = ptr.Elem()
+		}
+		if named, ok := recv.(*types.Named); ok {
+			return ir.Symbol{Package: pkgPath, Name: named.Obj().Name() + "." + fn.Name(), Kind: "method"}
+		}
+	}
+	return ir.Symbol{Package: pkgPath, Name: fn.Name(), Kind: "func"}
 }
 
 // funcSymbolForPackage builds a Symbol for an ast.FuncDecl with the given package path.
@@ -220,11 +1033,18 @@ func funcSymbolForPackage(fn *ast.FuncDecl, pkgPath string) ir.Symbol {
 
 	if fn.Recv != nil && len(fn.Recv.List) > 0 {
 		kind = "method"
-		switch t := fn.Recv.List[0].Type.(type) {
-		case *ast.StarExpr:
-			if id, ok := t.X.(*ast.Ident); ok {
-				name = id.Name + "." + name
-			}
+		recv := fn.Recv.List[0].Type
+		if star, ok := recv.(*ast.StarExpr); ok {
+			recv = star.X
+		}
+		// Drop type parameters from generic receivers: Box[T] → Box.
+		switch t := recv.(type) {
+		case *ast.IndexExpr:
+			recv = t.X
+		case *ast.IndexListExpr:
+			recv = t.X
+		}
+		switch t := recv.(type) {
 		case *ast.Ident:
 			name = t.Name + "." + name
 		}
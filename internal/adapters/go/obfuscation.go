@@ -0,0 +1,223 @@
+package goadapter
+
+import (
+	"go/ast"
+	"go/token"
+	"strconv"
+	"strings"
+)
+
+// obfuscationSinks are the calls whose string argument names a program or
+// plugin to run, with the index of that argument.
+var obfuscationSinks = map[string]int{
+	"os/exec.Command":        0,
+	"os/exec.CommandContext": 1,
+	"os.StartProcess":        0,
+	"syscall.Exec":           0,
+	"syscall.ForkExec":       0,
+	"plugin.Open":            0,
+}
+
+// obfuscatedVars returns, for each variable of f assigned an obfuscated
+// string (or the []byte a hex or base64 decode produced), the technique
+// that built it. Variables are keyed by their resolved object, so a
+// same-named variable in another scope is not marked. Assignments are
+// visited in source order, so a variable derived from another obfuscated
+// one is marked too.
+func obfuscatedVars(f *ast.File, aliases map[string]string) map[*ast.Object]string {
+	vars := make(map[*ast.Object]string)
+	mark := func(lhs []ast.Expr, rhs []ast.Expr) {
+		if len(rhs) == 1 && len(lhs) >= 1 {
+			if call, ok := rhs[0].(*ast.CallExpr); ok && len(lhs) == 2 {
+				if tech, ok := decodeCall(call, aliases); ok {
+					if id, ok := lhs[0].(*ast.Ident); ok && id.Obj != nil {
+						vars[id.Obj] = tech
+					}
+					return
+				}
+			}
+		}
+		if len(lhs) != len(rhs) {
+			return
+		}
+		for i, e := range rhs {
+			id, ok := lhs[i].(*ast.Ident)
+			if !ok || id.Obj == nil {
+				continue
+			}
+			if tech, ok := obfuscatedExpr(e, aliases, vars); ok {
+				vars[id.Obj] = tech
+			}
+		}
+	}
+	ast.Inspect(f, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.AssignStmt:
+			mark(n.Lhs, n.Rhs)
+		case *ast.ValueSpec:
+			lhs := make([]ast.Expr, len(n.Names))
+			for i, id := range n.Names {
+				lhs[i] = id
+			}
+			mark(lhs, n.Values)
+		}
+		return true
+	})
+	return vars
+}
+
+// obfuscatedExec reports whether call runs a command or opens a plugin
+// whose name is an obfuscated string, and describes the call.
+func obfuscatedExec(call *ast.CallExpr, aliases map[string]string, vars map[*ast.Object]string) (string, bool) {
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok {
+		return "", false
+	}
+	pkg, ok := sel.X.(*ast.Ident)
+	if !ok {
+		return "", false
+	}
+	path, ok := aliases[pkg.Name]
+	if !ok {
+		return "", false
+	}
+	arg, ok := obfuscationSinks[path+"."+sel.Sel.Name]
+	if !ok || arg >= len(call.Args) {
+		return "", false
+	}
+	tech, ok := obfuscatedExpr(call.Args[arg], aliases, vars)
+	if !ok {
+		return "", false
+	}
+	return pkg.Name + "." + sel.Sel.Name + "(<" + tech + ">)", true
+}
+
+// obfuscatedExpr reports whether e builds a string in a way that hides its
+// value from a reader: from numeric character codes, by joining single
+// characters, or from hex- or base64-decoded bytes.
+func obfuscatedExpr(e ast.Expr, aliases map[string]string, vars map[*ast.Object]string) (string, bool) {
+	switch e := e.(type) {
+	case *ast.ParenExpr:
+		return obfuscatedExpr(e.X, aliases, vars)
+	case *ast.Ident:
+		tech, ok := vars[e.Obj]
+		return tech, ok
+	case *ast.BinaryExpr:
+		if e.Op != token.ADD {
+			return "", false
+		}
+		if n := singleCharConcat(e); n >= 3 {
+			return "single characters joined", true
+		}
+		if tech, ok := obfuscatedExpr(e.X, aliases, vars); ok {
+			return tech, true
+		}
+		return obfuscatedExpr(e.Y, aliases, vars)
+	case *ast.CallExpr:
+		if fn, ok := e.Fun.(*ast.Ident); ok && fn.Name == "string" && len(e.Args) == 1 {
+			switch a := e.Args[0].(type) {
+			case *ast.CompositeLit:
+				if len(a.Elts) >= 2 && allLits(a.Elts, token.INT, token.CHAR) {
+					return "string from character codes", true
+				}
+			case *ast.CallExpr:
+				if conv, ok := a.Fun.(*ast.Ident); ok && (conv.Name == "rune" || conv.Name == "byte") &&
+					len(a.Args) == 1 && allLits(a.Args, token.INT) {
+					return "string from character codes", true
+				}
+			case *ast.Ident:
+				if tech, ok := vars[a.Obj]; ok {
+					return tech, true
+				}
+			}
+			return "", false
+		}
+		sel, ok := e.Fun.(*ast.SelectorExpr)
+		if !ok {
+			return "", false
+		}
+		pkg, ok := sel.X.(*ast.Ident)
+		if !ok || aliases[pkg.Name] != "strings" || sel.Sel.Name != "Join" || len(e.Args) != 2 {
+			return "", false
+		}
+		if lit, ok := e.Args[0].(*ast.CompositeLit); ok && len(lit.Elts) >= 3 && allSingleChars(lit.Elts) {
+			return "single characters joined", true
+		}
+	}
+	return "", false
+}
+
+// decodeCall reports whether call hex- or base64-decodes a string.
+func decodeCall(call *ast.CallExpr, aliases map[string]string) (string, bool) {
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok || sel.Sel.Name != "DecodeString" {
+		return "", false
+	}
+	switch x := sel.X.(type) {
+	case *ast.Ident: // hex.DecodeString
+		if aliases[x.Name] == "encoding/hex" {
+			return "hex-decoded string", true
+		}
+	case *ast.SelectorExpr: // base64.StdEncoding.DecodeString
+		if id, ok := x.X.(*ast.Ident); ok && aliases[id.Name] == "encoding/base64" {
+			return "base64-decoded string", true
+		}
+	}
+	return "", false
+}
+
+// singleCharConcat returns how many one-character string literals e
+// concatenates, or 0 when any operand is something else.
+func singleCharConcat(e ast.Expr) int {
+	switch e := e.(type) {
+	case *ast.BinaryExpr:
+		if e.Op != token.ADD {
+			return 0
+		}
+		l, r := singleCharConcat(e.X), singleCharConcat(e.Y)
+		if l == 0 || r == 0 {
+			return 0
+		}
+		return l + r
+	case *ast.BasicLit:
+		if isSingleChar(e) {
+			return 1
+		}
+	}
+	return 0
+}
+
+func allLits(exprs []ast.Expr, kinds ...token.Token) bool {
+	for _, e := range exprs {
+		lit, ok := e.(*ast.BasicLit)
+		if !ok {
+			return false
+		}
+		found := false
+		for _, k := range kinds {
+			found = found || lit.Kind == k
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func allSingleChars(exprs []ast.Expr) bool {
+	for _, e := range exprs {
+		lit, ok := e.(*ast.BasicLit)
+		if !ok || !isSingleChar(lit) {
+			return false
+		}
+	}
+	return true
+}
+
+func isSingleChar(lit *ast.BasicLit) bool {
+	if lit.Kind != token.STRING {
+		return false
+	}
+	s, err := strconv.Unquote(lit.Value)
+	return err == nil && len([]rune(s)) == 1 && strings.TrimSpace(s) != ""
+}
@@ -1,6 +1,16 @@
 package goadapter
 
-import "github.com/1homsi/gorisk/internal/capability"
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"path/filepath"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/1homsi/gorisk/internal/capability"
+)
 
 // GoPatterns holds the Go capability patterns loaded from languages/go.yaml.
 var GoPatterns = capability.MustLoadPatterns("go")
@@ -14,3 +24,639 @@ func ImportCapabilities(importPath string) []capability.Capability {
 func CallCapabilities(pkgName, funcName string) []capability.Capability {
 	return GoPatterns.CallSites[pkgName+"."+funcName]
 }
+
+// callSiteContext returns the evidence context for a matched call pattern.
+// For filesystem reads with a string-literal path argument the path is
+// appended (e.g. `os.ReadFile("/etc/passwd")`) so downstream analyses such as
+// taint can reason about what was read. Exec calls with a literal command
+// name are recorded the same way (e.g. `exec.Command("git")`) so policy can
+// tell constant commands from dynamic ones. caps are the capabilities the
+// pattern matched.
+func callSiteContext(pattern string, caps []capability.Capability, call *ast.CallExpr) string {
+	arg := 0
+	switch {
+	case slices.Contains(caps, capability.CapFSRead):
+	case slices.Contains(caps, capability.CapExec):
+		// exec.CommandContext(ctx, name, ...) takes the command second.
+		if strings.HasSuffix(pattern, "Context") {
+			arg = 1
+		}
+	default:
+		return pattern
+	}
+	if len(call.Args) <= arg {
+		return pattern
+	}
+	lit, ok := call.Args[arg].(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING {
+		return pattern
+	}
+	return pattern + "(" + lit.Value + ")"
+}
+
+// readsProc reports whether call is a filesystem read of a literal /proc path
+// (e.g. os.ReadFile("/proc/1/cmdline")), which inspects other processes.
+// caps are the capabilities the call's pattern matched.
+func readsProc(caps []capability.Capability, call *ast.CallExpr) bool {
+	if len(call.Args) == 0 || !slices.Contains(caps, capability.CapFSRead) {
+		return false
+	}
+	lit, ok := call.Args[0].(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING {
+		return false
+	}
+	return strings.HasPrefix(strings.Trim(lit.Value, "`\""), "/proc/")
+}
+
+// resolverLookups are the net.Resolver methods that issue DNS queries.
+var resolverLookups = map[string]bool{
+	"LookupAddr": true, "LookupCNAME": true, "LookupHost": true,
+	"LookupIP": true, "LookupIPAddr": true, "LookupMX": true,
+	"LookupNS": true, "LookupNetIP": true, "LookupSRV": true,
+	"LookupTXT": true,
+}
+
+// dnsLookup reports DNS queries not covered by the plain net.Lookup* call
+// sites in go.yaml: net.LookupTXT and Lookup* methods on a net.Resolver
+// (r.LookupTXT, net.DefaultResolver.LookupHost). TXT records are a common
+// command-and-control channel, so TXT lookups carry higher confidence.
+// It returns an evidence context and confidence.
+func dnsLookup(call *ast.CallExpr, importAliases map[string]string) (string, float64, bool) {
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok || !resolverLookups[sel.Sel.Name] {
+		return "", 0, false
+	}
+	ctx := "Resolver." + sel.Sel.Name
+	if ident, ok := sel.X.(*ast.Ident); ok {
+		if path, isPkg := importAliases[ident.Name]; isPkg {
+			// Package-level net.Lookup* other than TXT come from go.yaml.
+			if path != "net" || sel.Sel.Name != "LookupTXT" {
+				return "", 0, false
+			}
+			ctx = "net.LookupTXT"
+		}
+	}
+	if !importsPkg(importAliases, "net") {
+		return "", 0, false
+	}
+	if sel.Sel.Name == "LookupTXT" {
+		return ctx, 0.90, true
+	}
+	return ctx, 0.75, true
+}
+
+// linknames returns every //go:linkname directive in f. A linkname binds a
+// local declaration to an unexported symbol of another package (usually the
+// runtime), bypassing the type system and package boundaries entirely.
+func linknames(f *ast.File) []*ast.Comment {
+	var out []*ast.Comment
+	for _, cg := range f.Comments {
+		for _, c := range cg.List {
+			if strings.HasPrefix(c.Text, "//go:linkname ") {
+				out = append(out, c)
+			}
+		}
+	}
+	return out
+}
+
+// unsafePointerArith reports unsafe.Pointer conversions that do more than
+// reinterpret an existing pointer: converting a uintptr expression back to a
+// pointer (unsafe.Pointer(uintptr(p) + off)) and unsafe.Add. Plain casts
+// such as unsafe.Pointer(&x) are not reported; the unsafe import already
+// covers them. It returns the construct as evidence context.
+func unsafePointerArith(n ast.Node, importAliases map[string]string) (string, bool) {
+	call, ok := n.(*ast.CallExpr)
+	if !ok || len(call.Args) == 0 {
+		return "", false
+	}
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok {
+		return "", false
+	}
+	ident, ok := sel.X.(*ast.Ident)
+	if !ok || importAliases[ident.Name] != "unsafe" {
+		return "", false
+	}
+	switch sel.Sel.Name {
+	case "Add":
+		return ident.Name + ".Add", true
+	case "Pointer":
+		switch arg := ast.Unparen(call.Args[0]).(type) {
+		case *ast.BinaryExpr:
+			return ident.Name + ".Pointer(uintptr arithmetic)", true
+		case *ast.CallExpr:
+			if fn, ok := arg.Fun.(*ast.Ident); ok && fn.Name == "uintptr" {
+				return ident.Name + ".Pointer(uintptr(...))", true
+			}
+		}
+	}
+	return "", false
+}
+
+// dohMarkers identify DNS-over-HTTPS endpoints and wire formats in string
+// literals.
+var dohMarkers = []string{
+	"application/dns-message",
+	"application/dns-json",
+	"/dns-query",
+	"dns.google/resolve",
+}
+
+// dohLiteral reports whether n is a string literal naming a DoH endpoint or
+// content type, e.g. "https://cloudflare-dns.com/dns-query".
+func dohLiteral(n ast.Node) (string, bool) {
+	lit, ok := n.(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING {
+		return "", false
+	}
+	for _, m := range dohMarkers {
+		if strings.Contains(lit.Value, m) {
+			return lit.Value, true
+		}
+	}
+	return "", false
+}
+
+// insecureTLS reports whether n is a tls.Config field setting
+// InsecureSkipVerify to true, which disables certificate verification.
+func insecureTLS(n ast.Node) (string, bool) {
+	kv, ok := n.(*ast.KeyValueExpr)
+	if !ok {
+		return "", false
+	}
+	key, ok := kv.Key.(*ast.Ident)
+	if !ok || key.Name != "InsecureSkipVerify" {
+		return "", false
+	}
+	if val, ok := kv.Value.(*ast.Ident); !ok || val.Name != "true" {
+		return "", false
+	}
+	return "InsecureSkipVerify: true", true
+}
+
+// sqlQueryArg maps database/sql query methods (on *sql.DB, *sql.Tx,
+// *sql.Conn, and their sqlx wrappers) to the index of the query argument.
+var sqlQueryArg = map[string]int{
+	"Query":           0,
+	"QueryRow":        0,
+	"Exec":            0,
+	"Prepare":         0,
+	"QueryContext":    1,
+	"QueryRowContext": 1,
+	"ExecContext":     1,
+	"PrepareContext":  1,
+}
+
+// sqlPackages are the SQL client packages whose query methods sqlInjection
+// inspects.
+var sqlPackages = []string{"database/sql", "github.com/jmoiron/sqlx"}
+
+// sqlInjection reports a query method call in a file importing database/sql
+// (or sqlx) whose query string is built at run time: with +, +=, or
+// fmt.Sprintf, directly or in a variable. A query passed in whole, and the
+// values of a parameterised query or of a prepared statement's Query, are
+// not flagged. consts comes from constStrings and built from builtStrings.
+func sqlInjection(call *ast.CallExpr, importAliases map[string]string, consts, built map[string]bool) (string, bool) {
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok {
+		return "", false
+	}
+	idx, ok := sqlQueryArg[sel.Sel.Name]
+	if !ok || len(call.Args) <= idx {
+		return "", false
+	}
+	if ident, ok := sel.X.(*ast.Ident); ok {
+		if _, isPkg := importAliases[ident.Name]; isPkg {
+			return "", false // a package function, not a query method
+		}
+	}
+	if !slices.ContainsFunc(sqlPackages, func(p string) bool { return importsPkg(importAliases, p) }) {
+		return "", false
+	}
+	if !isBuiltString(call.Args[idx], importAliases, consts, built) {
+		return "", false
+	}
+	return sel.Sel.Name + "(<dynamic query>)", true
+}
+
+// dynamicTemplateParse reports whether call is a text/template or
+// html/template parse of a non-literal template body, e.g.
+// template.New("x").Funcs(m).Parse(userInput), or t.Parse(userInput) where
+// t is one of tmplVars (see templateVars). Templates built from runtime data
+// can invoke any function in their FuncMap, which makes them an
+// eval-equivalent.
+func dynamicTemplateParse(call *ast.CallExpr, importAliases map[string]string, tmplVars map[*ast.Object]bool) bool {
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok || sel.Sel.Name != "Parse" || len(call.Args) != 1 {
+		return false
+	}
+	if lit, ok := call.Args[0].(*ast.BasicLit); ok && lit.Kind == token.STRING {
+		return false
+	}
+	return templateChain(sel.X, importAliases, tmplVars)
+}
+
+// templateChain reports whether x is a method chain rooted at a
+// template.New(...) call or at one of tmplVars.
+func templateChain(x ast.Expr, importAliases map[string]string, tmplVars map[*ast.Object]bool) bool {
+	for {
+		if ident, ok := x.(*ast.Ident); ok {
+			return ident.Obj != nil && tmplVars[ident.Obj]
+		}
+		inner, ok := x.(*ast.CallExpr)
+		if !ok {
+			return false
+		}
+		innerSel, ok := inner.Fun.(*ast.SelectorExpr)
+		if !ok {
+			return false
+		}
+		if ident, ok := innerSel.X.(*ast.Ident); ok && innerSel.Sel.Name == "New" && ident.Obj == nil {
+			path := importAliases[ident.Name]
+			return path == "text/template" || path == "html/template"
+		}
+		x = innerSel.X
+	}
+}
+
+// templateVars returns the variables of f assigned a template.New(...)
+// chain, such as t in t := template.New("x").Funcs(m), so a later
+// t.Parse(body) is recognized. Variables are keyed by their resolved
+// object, so a same-named variable in another function is not marked.
+// Assignments are visited in source order, so t = t.Funcs(m) keeps t marked.
+func templateVars(f *ast.File, importAliases map[string]string) map[*ast.Object]bool {
+	vars := make(map[*ast.Object]bool)
+	mark := func(lhs, rhs ast.Expr) {
+		if id, ok := lhs.(*ast.Ident); ok && id.Obj != nil && templateChain(rhs, importAliases, vars) {
+			vars[id.Obj] = true
+		}
+	}
+	ast.Inspect(f, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.AssignStmt:
+			if len(n.Lhs) == len(n.Rhs) {
+				for i := range n.Lhs {
+					mark(n.Lhs[i], n.Rhs[i])
+				}
+			}
+		case *ast.ValueSpec:
+			if len(n.Names) == len(n.Values) {
+				for i := range n.Names {
+					mark(n.Names[i], n.Values[i])
+				}
+			}
+		}
+		return true
+	})
+	return vars
+}
+
+// secretNameMarkers are identifier words that suggest a value is used as
+// key, token, or nonce material. They match whole camelCase or underscore
+// segments (apiKey, otp_code), so monkey or keyboard do not.
+var secretNameMarkers = []string{"token", "key", "nonce", "secret", "salt", "password", "otp"}
+
+// weakRandUse reports math/rand output flowing into a security-sensitive
+// value: assigned to a variable named like a token/key/nonce, filled via
+// rand.Read into such a buffer, or passed straight to a crypto function.
+// It returns an evidence context such as "rand.Intn → token".
+func weakRandUse(n ast.Node, importAliases map[string]string) (string, bool) {
+	switch n := n.(type) {
+	case *ast.AssignStmt:
+		if len(n.Lhs) != len(n.Rhs) {
+			return "", false
+		}
+		for i, lhs := range n.Lhs {
+			if name, ok := secretName(lhs); ok {
+				if call := findWeakRandCall(n.Rhs[i], importAliases); call != "" {
+					return call + " → " + name, true
+				}
+			}
+		}
+	case *ast.ValueSpec:
+		for i, id := range n.Names {
+			if i >= len(n.Values) {
+				break
+			}
+			if name, ok := secretName(id); ok {
+				if call := findWeakRandCall(n.Values[i], importAliases); call != "" {
+					return call + " → " + name, true
+				}
+			}
+		}
+	case *ast.CallExpr:
+		sel, ok := n.Fun.(*ast.SelectorExpr)
+		if !ok {
+			return "", false
+		}
+		ident, ok := sel.X.(*ast.Ident)
+		if !ok {
+			return "", false
+		}
+		pkgPath := importAliases[ident.Name]
+		switch {
+		case isMathRand(pkgPath) && sel.Sel.Name == "Read" && len(n.Args) == 1:
+			if name, ok := secretName(n.Args[0]); ok {
+				return ident.Name + ".Read → " + name, true
+			}
+		case isCryptoPkg(pkgPath):
+			for _, arg := range n.Args {
+				if call := findWeakRandCall(arg, importAliases); call != "" {
+					return call + " → " + ident.Name + "." + sel.Sel.Name, true
+				}
+			}
+		}
+	}
+	return "", false
+}
+
+// findWeakRandCall returns "rand.Func" for the first math/rand call inside
+// expr, or "" if there is none.
+func findWeakRandCall(expr ast.Expr, importAliases map[string]string) string {
+	var found string
+	ast.Inspect(expr, func(n ast.Node) bool {
+		if found != "" {
+			return false
+		}
+		call, ok := n.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+		if sel, ok := call.Fun.(*ast.SelectorExpr); ok {
+			if ident, ok := sel.X.(*ast.Ident); ok && isMathRand(importAliases[ident.Name]) {
+				found = ident.Name + "." + sel.Sel.Name
+			}
+		}
+		return true
+	})
+	return found
+}
+
+// secretName returns the identifier name of expr (or the field name of a
+// selector) if it looks like key/token material.
+func secretName(expr ast.Expr) (string, bool) {
+	var name string
+	switch e := expr.(type) {
+	case *ast.Ident:
+		name = e.Name
+	case *ast.SelectorExpr:
+		name = e.Sel.Name
+	case *ast.SliceExpr:
+		return secretName(e.X)
+	default:
+		return "", false
+	}
+	for _, word := range identWords(name) {
+		word = strings.ToLower(word)
+		for _, m := range secretNameMarkers {
+			if word == m || word == m+"s" {
+				return name, true
+			}
+		}
+	}
+	return "", false
+}
+
+// identWords splits an identifier into its underscore- and camelCase-separated
+// words: "apiKey" → [api Key], "OTP_code" → [OTP code], "HMACKey" → [HMAC Key].
+func identWords(name string) []string {
+	var words []string
+	for part := range strings.SplitSeq(name, "_") {
+		runes := []rune(part)
+		start := 0
+		for i := 1; i < len(runes); i++ {
+			lowerToUpper := unicode.IsLower(runes[i-1]) && unicode.IsUpper(runes[i])
+			acronymEnd := unicode.IsUpper(runes[i-1]) && unicode.IsUpper(runes[i]) &&
+				i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if lowerToUpper || acronymEnd {
+				words = append(words, string(runes[start:i]))
+				start = i
+			}
+		}
+		if start < len(runes) {
+			words = append(words, string(runes[start:]))
+		}
+	}
+	return words
+}
+
+// payloadExts are embedded file extensions that can be executed once
+// written to disk: shell and interpreter scripts and native binaries.
+var payloadExts = map[string]bool{
+	".sh": true, ".bash": true, ".zsh": true, ".ps1": true, ".bat": true, ".cmd": true,
+	".py": true, ".pl": true, ".rb": true,
+	".exe": true, ".bin": true, ".elf": true, ".so": true, ".dll": true, ".dylib": true,
+}
+
+// embeddedPayloads maps each variable declared under a //go:embed directive
+// naming a script or binary to the first such embedded filename.
+func embeddedPayloads(f *ast.File) map[string]string {
+	payloads := make(map[string]string)
+	for _, decl := range f.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.VAR {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			vs, ok := spec.(*ast.ValueSpec)
+			if !ok || len(vs.Names) != 1 {
+				continue
+			}
+			doc := vs.Doc
+			if doc == nil && len(gen.Specs) == 1 {
+				doc = gen.Doc
+			}
+			if name := embedPayloadName(doc); name != "" {
+				payloads[vs.Names[0].Name] = name
+			}
+		}
+	}
+	return payloads
+}
+
+// embedPayloadName returns the first script or binary pattern listed in a
+// //go:embed directive within doc, or "".
+func embedPayloadName(doc *ast.CommentGroup) string {
+	if doc == nil {
+		return ""
+	}
+	for _, c := range doc.List {
+		rest, ok := strings.CutPrefix(c.Text, "//go:embed ")
+		if !ok {
+			continue
+		}
+		for _, pattern := range strings.Fields(rest) {
+			pattern = strings.Trim(pattern, "`\"")
+			if payloadExts[strings.ToLower(filepath.Ext(pattern))] {
+				return pattern
+			}
+		}
+	}
+	return ""
+}
+
+// droppedPayload reports whether call writes an embedded payload variable
+// to disk (os.WriteFile or ioutil.WriteFile), returning its embedded name.
+func droppedPayload(call *ast.CallExpr, importAliases, payloads map[string]string) (string, bool) {
+	if len(payloads) == 0 || len(call.Args) < 2 {
+		return "", false
+	}
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok || sel.Sel.Name != "WriteFile" {
+		return "", false
+	}
+	ident, ok := sel.X.(*ast.Ident)
+	if !ok {
+		return "", false
+	}
+	if path := importAliases[ident.Name]; path != "os" && path != "io/ioutil" {
+		return "", false
+	}
+	data := call.Args[1]
+	// []byte(script) for string-typed embeds.
+	if conv, ok := data.(*ast.CallExpr); ok && len(conv.Args) == 1 {
+		data = conv.Args[0]
+	}
+	if id, ok := data.(*ast.Ident); ok {
+		if name, ok := payloads[id.Name]; ok {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// payloadDrops records the calls in one file that write an embedded script
+// or binary to disk. A file that does so and also executes commands is
+// dropping a smuggled executable to run it, so each recorded write becomes
+// exec evidence with Via embeddedPayload once the file is known to execute;
+// taint analysis reports it as an fs:write → exec finding. DetectFile,
+// DetectFunctions and the cross-package graph all record through it.
+type payloadDrops struct {
+	aliases  map[string]string
+	payloads map[string]string
+	writes   map[string][]capability.CapabilityEvidence // by caller key
+}
+
+// newPayloadDrops returns the payload recorder for f, or nil if f embeds no
+// script or binary.
+func newPayloadDrops(f *ast.File, importAliases map[string]string) *payloadDrops {
+	payloads := embeddedPayloads(f)
+	if len(payloads) == 0 {
+		return nil
+	}
+	return &payloadDrops{
+		aliases:  importAliases,
+		payloads: payloads,
+		writes:   make(map[string][]capability.CapabilityEvidence),
+	}
+}
+
+// check records call under caller if it writes an embedded payload.
+func (d *payloadDrops) check(fset *token.FileSet, call *ast.CallExpr, caller string) {
+	if d == nil {
+		return
+	}
+	name, ok := droppedPayload(call, d.aliases, d.payloads)
+	if !ok {
+		return
+	}
+	pos := fset.Position(call.Pos())
+	d.writes[caller] = append(d.writes[caller], capability.CapabilityEvidence{
+		File:       pos.Filename,
+		Line:       pos.Line,
+		Context:    "go:embed " + name + " written to disk and executed",
+		Via:        capability.ViaEmbeddedPayload,
+		Confidence: 0.85,
+	})
+}
+
+// each calls fn with every recorded write and the caller it was made in.
+func (d *payloadDrops) each(fn func(caller string, ev capability.CapabilityEvidence)) {
+	if d == nil {
+		return
+	}
+	for caller, evs := range d.writes {
+		for _, ev := range evs {
+			fn(caller, ev)
+		}
+	}
+}
+
+// pathOverride reports whether call is os.Setenv("PATH", v) with a
+// non-constant v, which decides where later bare commands are found.
+func pathOverride(call *ast.CallExpr, importAliases map[string]string, consts map[string]bool) bool {
+	if len(call.Args) != 2 {
+		return false
+	}
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok || sel.Sel.Name != "Setenv" {
+		return false
+	}
+	ident, ok := sel.X.(*ast.Ident)
+	if !ok || importAliases[ident.Name] != "os" {
+		return false
+	}
+	key, ok := call.Args[0].(*ast.BasicLit)
+	if !ok || strings.Trim(key.Value, "`\"") != "PATH" {
+		return false
+	}
+	return !isConstString(call.Args[1], consts)
+}
+
+// pathLookup reports whether call resolves a command through PATH:
+// exec.LookPath, or exec.Command / exec.CommandContext with a literal
+// command name that has no directory part. It returns a short context.
+func pathLookup(call *ast.CallExpr, importAliases map[string]string) (string, bool) {
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok {
+		return "", false
+	}
+	ident, ok := sel.X.(*ast.Ident)
+	if !ok || importAliases[ident.Name] != "os/exec" {
+		return "", false
+	}
+	nameArg := 0
+	switch sel.Sel.Name {
+	case "LookPath":
+		return "exec.LookPath(...)", true
+	case "Command":
+	case "CommandContext":
+		nameArg = 1
+	default:
+		return "", false
+	}
+	if len(call.Args) <= nameArg {
+		return "", false
+	}
+	lit, ok := call.Args[nameArg].(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING {
+		return "", false
+	}
+	name := strings.Trim(lit.Value, "`\"")
+	if name == "" || strings.ContainsAny(name, `/\`) {
+		return "", false
+	}
+	return fmt.Sprintf("exec.%s(%q)", sel.Sel.Name, name), true
+}
+
+// importsPkg reports whether the file imports pkgPath under any name.
+func importsPkg(importAliases map[string]string, pkgPath string) bool {
+	for _, path := range importAliases {
+		if path == pkgPath {
+			return true
+		}
+	}
+	return false
+}
+
+func isMathRand(pkgPath string) bool {
+	return pkgPath == "math/rand" || pkgPath == "math/rand/v2"
+}
+
+func isCryptoPkg(pkgPath string) bool {
+	if pkgPath == "crypto/rand" {
+		return false
+	}
+	return strings.HasPrefix(pkgPath, "crypto/") || strings.HasPrefix(pkgPath, "golang.org/x/crypto/")
+}
@@ -0,0 +1,58 @@
+package goadapter
+
+import (
+	"go/build"
+	"slices"
+
+	"github.com/1homsi/gorisk/internal/capability"
+)
+
+// DefaultPlatforms are the GOOS values PlatformCapabilities compares when
+// none are given.
+var DefaultPlatforms = []string{"darwin", "freebsd", "linux", "windows"}
+
+// PlatformCapabilities detects the capabilities of the Go package in dir
+// separately for each GOOS in goos, choosing its files by build constraints
+// and _GOOS file suffixes as the go command would for that target. cgo
+// files are included, as for a cgo-enabled build, as are assembly files.
+// A GOOS for which the package has no files is omitted.
+func PlatformCapabilities(dir string, goos []string) map[string]capability.CapabilitySet {
+	out := make(map[string]capability.CapabilitySet, len(goos))
+	for _, target := range goos {
+		ctx := build.Default
+		ctx.GOOS = target
+		ctx.CgoEnabled = true
+		bp, err := ctx.ImportDir(dir, 0)
+		if err != nil {
+			continue
+		}
+		files := append(slices.Clip(bp.GoFiles), bp.CgoFiles...)
+		cs, err := DetectPackage(dir, files)
+		if err != nil {
+			continue
+		}
+		if len(bp.CgoFiles) > 0 {
+			cs.MergeWithEvidence(DetectCgo(dir, bp.CgoFiles, append(slices.Clip(bp.CFiles), bp.HFiles...)))
+		}
+		if len(bp.SFiles) > 0 {
+			cs.MergeWithEvidence(DetectAsm(dir, bp.SFiles))
+		}
+		out[target] = cs
+	}
+	return out
+}
+
+// PlatformsByCapability inverts PlatformCapabilities: for each capability,
+// the sorted GOOS values whose build of the package has it.
+func PlatformsByCapability(perOS map[string]capability.CapabilitySet) map[string][]string {
+	out := make(map[string][]string)
+	for goos, cs := range perOS {
+		for _, c := range cs.List() {
+			out[c] = append(out[c], goos)
+		}
+	}
+	for c := range out {
+		slices.Sort(out[c])
+	}
+	return out
+}
@@ -0,0 +1,49 @@
+package goadapter
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestPlatformCapabilitiesLinuxOnlyExec(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"config.go": `package runner
+
+import "os"
+
+func Home() string { return os.Getenv("HOME") }
+`,
+		"exec_linux.go": `package runner
+
+import "os/exec"
+
+func Open(path string) error { return exec.Command("xdg-open", path).Run() }
+`,
+		"open_other.go": `//go:build !linux
+
+package runner
+
+func Open(path string) error { return nil }
+`,
+	}
+	for name, src := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(src), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	perOS := PlatformCapabilities(dir, []string{"darwin", "linux", "windows"})
+	if len(perOS) != 3 {
+		t.Fatalf("analyzed %d platforms, want 3", len(perOS))
+	}
+	byCap := PlatformsByCapability(perOS)
+	if got := byCap["exec"]; !slices.Equal(got, []string{"linux"}) {
+		t.Errorf("exec platforms = %v, want [linux]", got)
+	}
+	if got := byCap["env"]; !slices.Equal(got, []string{"darwin", "linux", "windows"}) {
+		t.Errorf("env platforms = %v, want all three", got)
+	}
+}
@@ -0,0 +1,144 @@
+package goadapter
+
+import (
+	"fmt"
+	"go/ast"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/1homsi/gorisk/internal/capability"
+)
+
+// Compilation targets accepted in Config.Target.
+const (
+	TargetNative = "native"
+	TargetWASM   = "wasm"
+)
+
+// Config selects the build the Go adapter detects capabilities for. The zero
+// value is the native target with no allowed_hosts list.
+type Config struct {
+	// Target is TargetNative ("" is the same) or TargetWASM. The wasm target
+	// (Go's GOOS=wasip1/js and TinyGo) drops capabilities that cannot exist
+	// there: exec, since WebAssembly cannot spawn processes, and filesystem
+	// access through raw syscall packages. It adds host imports — syscall/js
+	// and //go:wasmimport functions — as plugin, since they run code outside
+	// the module. Packages are listed and loaded for GOOS=wasip1 GOARCH=wasm,
+	// so wasm-only files are analyzed and files constrained to other
+	// platforms, including GOOS=js, are not.
+	Target string
+	// AllowedHosts are the hosts literal network targets may name without
+	// being flagged (see normalizeHosts). With a list set, a request to any
+	// other literal host is flagged; without one only raw IP addresses are.
+	AllowedHosts []string
+	// Env is added to the environment packages are listed and loaded with,
+	// after the target's own GOOS and GOARCH.
+	Env []string
+}
+
+// CheckTarget reports an error when target is not a Config.Target the
+// adapter accepts.
+func CheckTarget(target string) error {
+	_, err := Config{Target: target}.detector()
+	return err
+}
+
+// detector is a Config resolved for detection.
+type detector struct {
+	patterns     *capability.PatternSet
+	wasm         bool     // report //go:wasmimport host imports
+	allowedHosts []string // nil when there is no allowlist
+	env          []string // extra environment for go list and packages.Load
+}
+
+// native detects for the zero Config; the package-level Detect functions
+// use it.
+var native = &detector{patterns: GoPatterns}
+
+// wasmGoPatterns derives the wasm target's patterns once, on first use.
+var wasmGoPatterns = sync.OnceValue(func() *capability.PatternSet {
+	return wasmPatterns(GoPatterns)
+})
+
+func (c Config) detector() (*detector, error) {
+	d := &detector{patterns: GoPatterns, allowedHosts: normalizeHosts(c.AllowedHosts)}
+	switch c.Target {
+	case "", TargetNative:
+	case TargetWASM:
+		d.patterns, d.wasm = wasmGoPatterns(), true
+		d.env = []string{"GOOS=wasip1", "GOARCH=wasm"}
+	default:
+		return nil, fmt.Errorf("unknown target %q (want native|wasm)", c.Target)
+	}
+	d.env = append(d.env, c.Env...)
+	return d, nil
+}
+
+// rawSyscallPackages are the packages whose filesystem access goes through
+// raw OS syscalls. WebAssembly has no such syscalls; file access there goes
+// through os and the host's WASI preopens, which stay reported.
+var rawSyscallPackages = map[string]bool{
+	"syscall":                  true,
+	"golang.org/x/sys/unix":    true,
+	"golang.org/x/sys/windows": true,
+}
+
+// hostImportPackages give Go code direct access to functions provided by the
+// WebAssembly host (the JavaScript runtime for GOOS=js and TinyGo).
+var hostImportPackages = []string{"syscall/js"}
+
+// wasmPatterns derives the wasm target's patterns from the native ones.
+func wasmPatterns(native *capability.PatternSet) *capability.PatternSet {
+	ps := &capability.PatternSet{
+		Name:      native.Name,
+		Imports:   make(map[string][]capability.Capability, len(native.Imports)),
+		CallSites: make(map[string][]capability.Capability, len(native.CallSites)),
+	}
+	for path, caps := range native.Imports {
+		if caps = wasmCaps(caps, rawSyscallPackages[path]); len(caps) > 0 {
+			ps.Imports[path] = caps
+		}
+	}
+	for pattern, caps := range native.CallSites {
+		pkg, _, _ := strings.Cut(pattern, ".")
+		raw := pkg == "syscall" || pkg == "unix" || pkg == "windows"
+		if caps = wasmCaps(caps, raw); len(caps) > 0 {
+			ps.CallSites[pattern] = caps
+		}
+	}
+	for _, path := range hostImportPackages {
+		if !slices.Contains(ps.Imports[path], capability.CapPlugin) {
+			ps.Imports[path] = append(ps.Imports[path], capability.CapPlugin)
+		}
+	}
+	return ps
+}
+
+// wasmCaps removes exec, and raw filesystem access when rawSyscall is set.
+func wasmCaps(caps []capability.Capability, rawSyscall bool) []capability.Capability {
+	out := make([]capability.Capability, 0, len(caps))
+	for _, c := range caps {
+		if c == capability.CapExec {
+			continue
+		}
+		if rawSyscall && (c == capability.CapFSRead || c == capability.CapFSWrite) {
+			continue
+		}
+		out = append(out, c)
+	}
+	return out
+}
+
+// wasmImports returns the //go:wasmimport directives in f.
+func wasmImports(f *ast.File) []*ast.Comment {
+	var out []*ast.Comment
+	for _, cg := range f.Comments {
+		for _, c := range cg.List {
+			if strings.HasPrefix(c.Text, "//go:wasmimport ") {
+				out = append(out, c)
+			}
+		}
+	}
+	return out
+}
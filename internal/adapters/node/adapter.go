@@ -14,7 +14,10 @@ import (
 )
 
 // Adapter implements the Analyzer interface for Node.js projects.
-type Adapter struct{}
+type Adapter struct {
+	// SkipDirs are the policy's skip_dirs (see DetectFirstParty).
+	SkipDirs []string
+}
 
 func (a *Adapter) Name() string { return "node" }
 
@@ -56,7 +59,7 @@ func (a *Adapter) Load(dir string) (*graph.DependencyGraph, error) {
 		Name:         rootName,
 		Module:       rootMod,
 		Dir:          dir,
-		Capabilities: Detect(dir),
+		Capabilities: DetectFirstParty(dir, a.SkipDirs),
 	}
 	g.Packages[rootName] = rootPkg
 	rootMod.Packages = append(rootMod.Packages, rootPkg)
@@ -159,7 +162,7 @@ func (a *Adapter) Load(dir string) (*graph.DependencyGraph, error) {
 			Name:         wsName,
 			Module:       wsMod,
 			Dir:          wsDir,
-			Capabilities: Detect(wsDir),
+			Capabilities: DetectFirstParty(wsDir, a.SkipDirs),
 		}
 		interproc.Debugf("[node] ✓ WORKSPACE (%s): %s (score: %d)",
 			wsName, wsPkg.Capabilities.String(), wsPkg.Capabilities.Score)
@@ -177,7 +180,7 @@ func (a *Adapter) Load(dir string) (*graph.DependencyGraph, error) {
 	}
 
 	// Run interprocedural analysis and propagate enhanced capabilities back to packages.
-	if err := runInterproceduralAnalysis(g); err != nil {
+	if err := runInterproceduralAnalysis(g, a.SkipDirs); err != nil {
 		interproc.Warnf("[node] Interprocedural analysis failed: %v", err)
 		// Continue without interprocedural results
 	}
@@ -188,9 +191,9 @@ func (a *Adapter) Load(dir string) (*graph.DependencyGraph, error) {
 
 // runInterproceduralAnalysis builds a function-level call graph, runs the interprocedural
 // engine, and merges the enhanced (transitive) capabilities back into each package.
-func runInterproceduralAnalysis(g *graph.DependencyGraph) error {
+func runInterproceduralAnalysis(g *graph.DependencyGraph, skipDirs []string) error {
 	// Build IRGraph from function-level analysis
-	irGraph := buildNodeFunctionIRGraph(g)
+	irGraph := buildNodeFunctionIRGraph(g, newSkipDirs(skipDirs))
 	if len(irGraph.Functions) == 0 {
 		return nil // Nothing to analyze
 	}
@@ -219,13 +222,14 @@ func runInterproceduralAnalysis(g *graph.DependencyGraph) error {
 }
 
 // BuildIRGraph builds a function-level IR graph for a Node dependency graph.
-func BuildIRGraph(g *graph.DependencyGraph) ir.IRGraph {
-	return buildNodeFunctionIRGraph(g)
+// skipDirs are the policy's skip_dirs (see DetectFirstParty).
+func BuildIRGraph(g *graph.DependencyGraph, skipDirs []string) ir.IRGraph {
+	return buildNodeFunctionIRGraph(g, newSkipDirs(skipDirs))
 }
 
 // buildNodeFunctionIRGraph converts packages into a function-level IRGraph.
 // Uses funcdetector.go to parse JavaScript/TypeScript and build a function-level call graph.
-func buildNodeFunctionIRGraph(g *graph.DependencyGraph) ir.IRGraph {
+func buildNodeFunctionIRGraph(g *graph.DependencyGraph, skip *skipDirs) ir.IRGraph {
 	irGraph := ir.IRGraph{
 		Functions: make(map[string]ir.FunctionCaps),
 		Calls:     []ir.CallEdge{},
@@ -237,13 +241,15 @@ func buildNodeFunctionIRGraph(g *graph.DependencyGraph) ir.IRGraph {
 			continue
 		}
 
-		// Collect JS/TS source files recursively, skipping node_modules.
+		// Collect JS/TS source files recursively, skipping node_modules and,
+		// for the project's own packages, build output and vendored code.
+		firstParty := pkg.Module != nil && pkg.Module.Main
 		var relFiles []string
 		_ = filepath.WalkDir(pkg.Dir, func(path string, d fs.DirEntry, err error) error {
 			if err != nil {
 				return nil
 			}
-			if d.IsDir() && d.Name() == "node_modules" {
+			if d.IsDir() && (d.Name() == "node_modules" || (firstParty && path != pkg.Dir && skip.skip(pkg.Dir, path))) {
 				return filepath.SkipDir
 			}
 			ext := strings.ToLower(filepath.Ext(path))
@@ -166,6 +166,11 @@ func DetectFileAST(path string) (capability.CapabilitySet, error) {
 		}
 	}
 
+	var obf *obfuscationScanner
+	if hasObfuscationHint(string(src)) {
+		obf = newObfuscationScanner(path)
+	}
+
 	// Line-by-line call-site detection using the symbol table.
 	scanner := bufio.NewScanner(strings.NewReader(string(src)))
 	scanner.Buffer(make([]byte, 1024*1024), 1024*1024)
@@ -174,6 +179,10 @@ func DetectFileAST(path string) (capability.CapabilitySet, error) {
 		line := scanner.Text()
 		lineNo++
 
+		if obf != nil {
+			obf.scan(&caps, lineNo, line)
+		}
+
 		// require('module').method() — direct chained call.
 		// Look up capabilities from the import map (we know the exact module).
 		for _, m := range reChainedCall.FindAllStringSubmatch(line, -1) {
@@ -246,8 +255,14 @@ type CallEdge struct {
 	Line       int
 }
 
-// BuildProjectGraph walks all .js/.ts files in dir and builds a project-wide graph.
+// BuildProjectGraph walks all .js/.ts files in dir and builds a project-wide
+// graph. Build output and vendored directories (see DefaultSkipDirs) are
+// skipped.
 func BuildProjectGraph(dir string) (ProjectGraph, error) {
+	return buildProjectGraph(dir, dir, newSkipDirs(nil))
+}
+
+func buildProjectGraph(root, dir string, skip *skipDirs) (ProjectGraph, error) {
 	graph := ProjectGraph{
 		Files:   make(map[string]SymbolTable),
 		Exports: make(map[string]map[string]capability.CapabilitySet),
@@ -261,12 +276,13 @@ func BuildProjectGraph(dir string) (ProjectGraph, error) {
 
 	for _, entry := range entries {
 		if entry.IsDir() {
-			// Skip node_modules and hidden directories
-			if entry.Name() == "node_modules" || strings.HasPrefix(entry.Name(), ".") {
+			// Skip node_modules, hidden directories, and build output
+			sub := filepath.Join(dir, entry.Name())
+			if entry.Name() == "node_modules" || strings.HasPrefix(entry.Name(), ".") || skip.skip(root, sub) {
 				continue
 			}
 			// Recursively process subdirectories
-			subGraph, err := BuildProjectGraph(filepath.Join(dir, entry.Name()))
+			subGraph, err := buildProjectGraph(root, sub, skip)
 			if err == nil {
 				for k, v := range subGraph.Files {
 					graph.Files[k] = v
@@ -291,3 +291,38 @@ func TestPropagateAcrossFiles(t *testing.T) {
 		t.Errorf("expected merged to have CapNetwork")
 	}
 }
+
+func TestDetectFileASTObfuscatedRequire(t *testing.T) {
+	dir := t.TempDir()
+	src := `// child_process, spelled out in character codes
+const m = String.fromCharCode(99, 104, 105, 108, 100, 95, 112, 114, 111, 99, 101, 115, 115);
+const cp = require(m);
+const shell = require('t' + 'e' + 'n'.split('').reverse().join(''));
+const path = require('path');
+const greeting = String.fromCharCode(104, 105);
+console.log(greeting);
+const digits = /\d+/.exec(String.fromCharCode(49, 50));
+require('./setup'); console.log(atob('aGk='));
+`
+	path := filepath.Join(dir, "index.js")
+	if err := os.WriteFile(path, []byte(src), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	caps, err := DetectFileAST(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	evs := caps.Evidence[capability.CapObfuscation]
+	if len(evs) != 2 {
+		// RegExp exec and a technique after the require's closing parenthesis
+		// are not obfuscated sinks.
+		t.Fatalf("obfuscation evidence = %+v, want the two obfuscated requires", evs)
+	}
+	if ev := evs[0]; ev.Line != 3 || ev.Context != "require(<String.fromCharCode>)" || ev.Confidence != 0.50 {
+		t.Errorf("evidence = %+v, want require(<String.fromCharCode>) on line 3 at 0.50", ev)
+	}
+	if ev := evs[1]; ev.Line != 4 || ev.Context != "require(<reversed string>)" {
+		t.Errorf("evidence = %+v, want require(<reversed string>) on line 4", ev)
+	}
+}
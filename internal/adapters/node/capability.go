@@ -23,6 +23,20 @@ var (
 // Detect scans JS/TS source files in dir and returns the combined capability set.
 // It also checks package.json install scripts for network/exec patterns.
 func Detect(dir string) capability.CapabilitySet {
+	return detect(dir, nil)
+}
+
+// DetectFirstParty is Detect for the project's own packages: it also skips
+// build output and vendored directories (DefaultSkipDirs plus the policy's
+// skipDirs; see newSkipDirs), whose bundled code would otherwise be reported
+// as first-party source.
+func DetectFirstParty(dir string, skipDirs []string) capability.CapabilitySet {
+	return detect(dir, newSkipDirs(skipDirs))
+}
+
+// detect scans dir, skipping the directories in skip; skip is nil for a
+// dependency.
+func detect(dir string, skip *skipDirs) capability.CapabilitySet {
 	var caps capability.CapabilitySet
 
 	checkInstallScripts(dir, &caps)
@@ -32,7 +46,7 @@ func Detect(dir string) capability.CapabilitySet {
 			return nil
 		}
 		if info.IsDir() {
-			if info.Name() == "node_modules" {
+			if info.Name() == "node_modules" || (skip != nil && path != dir && skip.skip(dir, path)) {
 				return filepath.SkipDir
 			}
 			return nil
@@ -61,10 +75,12 @@ func scanFile(path string, caps *capability.CapabilitySet) {
 	scanner := bufio.NewScanner(f)
 	scanner.Buffer(make([]byte, 1024*1024), 1024*1024)
 
+	obf := newObfuscationScanner(path)
 	lineNo := 0
 	for scanner.Scan() {
 		line := scanner.Text()
 		lineNo++
+		obf.scan(caps, lineNo, line)
 
 		for _, m := range reRequire.FindAllStringSubmatch(line, -1) {
 			importPath := m[1]
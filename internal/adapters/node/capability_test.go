@@ -205,3 +205,38 @@ helper.readFile("z");
 		t.Fatalf("expected no broad-token detections, got caps=%v", caps.List())
 	}
 }
+
+func TestDetectFirstPartySkipsDist(t *testing.T) {
+	dir := t.TempDir()
+	for _, sub := range []string{"dist", "generated", "src/build"} {
+		if err := os.MkdirAll(filepath.Join(dir, sub), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeTempJSFile(t, dir, "index.js", "module.exports = (a, b) => a + b;\n")
+	writeTempJSFile(t, dir, "dist/bundle.js", "const cp = require('child_process');\ncp.exec('ls');\n")
+	writeTempJSFile(t, dir, "generated/client.js", "const net = require('net');\nnet.connect(80);\n")
+	writeTempJSFile(t, dir, "src/build/plan.js", "const fs = require('fs');\nfs.readFileSync('plan.json');\n")
+
+	if caps := Detect(dir); !caps.Has(capability.CapExec) {
+		t.Errorf("Detect should scan dist/, got %s", caps.String())
+	}
+	caps := DetectFirstParty(dir, nil)
+	if caps.Has(capability.CapExec) {
+		t.Errorf("DetectFirstParty should skip dist/, got %s", caps.String())
+	}
+	if !caps.Has(capability.CapNetwork) {
+		t.Errorf("DetectFirstParty should scan generated/ by default, got %s", caps.String())
+	}
+	if !caps.Has(capability.CapFSRead) {
+		t.Errorf("DetectFirstParty should scan src/build/, only a root build/ is output, got %s", caps.String())
+	}
+
+	if caps := DetectFirstParty(dir, []string{"generated"}); caps.Has(capability.CapNetwork) || caps.Has(capability.CapExec) {
+		t.Errorf("DetectFirstParty with skip_dirs [generated] = %s, want only fs:read", caps.String())
+	}
+
+	if caps := DetectFirstParty(dir, []string{}); !caps.Has(capability.CapExec) {
+		t.Errorf("DetectFirstParty with skip_dirs [] should scan dist/, got %s", caps.String())
+	}
+}
@@ -0,0 +1,129 @@
+package node
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/1homsi/gorisk/internal/capability"
+)
+
+// obfuscationTechniques match the ways malicious packages assemble a module
+// name or command so that it never appears as a literal.
+var obfuscationTechniques = []struct {
+	re   *regexp.Regexp
+	name string
+}{
+	{regexp.MustCompile(`String\.fromCharCode\s*\(`), "String.fromCharCode"},
+	{regexp.MustCompile(`\.split\(\s*(?:''|"")\s*\)\s*\.reverse\(\)`), "reversed string"},
+	{regexp.MustCompile(`Buffer\.from\([^)]*,\s*['"](?:hex|base64)['"]\s*\)`), "decoded Buffer"},
+	{regexp.MustCompile(`\batob\s*\(`), "atob"},
+	{regexp.MustCompile(`['"](?:\\x[0-9a-fA-F]{2}){3,}`), "hex escapes"},
+	{regexp.MustCompile(`\[\s*(?:['"][^'"\\]['"]\s*,\s*){2,}['"][^'"\\]['"]\s*\]\s*\.join\(`), "single characters joined"},
+}
+
+var (
+	// reObfSink matches a call that loads a module or runs code or a
+	// command named by its argument. A member exec call (re.exec) is
+	// RegExp matching and is skipped by scan.
+	reObfSink = regexp.MustCompile(`(?:^|[^\w$])(require|import|eval|Function|exec|execSync|execFile|execFileSync|spawn|spawnSync)\s*\(\s*`)
+	// reObfAssign matches a variable declaration.
+	reObfAssign = regexp.MustCompile(`(?:const|let|var)\s+([\w$]+)\s*=\s*(.+)`)
+	reIdentArg  = regexp.MustCompile(`^([\w$]+)\s*[),]`)
+)
+
+// obfuscationScanner follows one file line by line, remembering variables
+// assigned an obfuscated string so that a later require(name) or
+// exec(name) is caught as well as an inline one.
+type obfuscationScanner struct {
+	path string
+	vars map[string]string // variable -> technique
+}
+
+func newObfuscationScanner(path string) *obfuscationScanner {
+	return &obfuscationScanner{path: path, vars: make(map[string]string)}
+}
+
+// technique returns the obfuscation technique used in s, if any.
+func (o *obfuscationScanner) technique(s string) (string, bool) {
+	for _, t := range obfuscationTechniques {
+		if t.re.MatchString(s) {
+			return t.name, true
+		}
+	}
+	return "", false
+}
+
+// scan records obfuscated assignments on line and adds obfuscation
+// evidence to caps for each sink call on it whose argument is obfuscated.
+func (o *obfuscationScanner) scan(caps *capability.CapabilitySet, lineNo int, line string) {
+	if m := reObfAssign.FindStringSubmatch(line); m != nil {
+		if tech, ok := o.technique(m[2]); ok {
+			o.vars[m[1]] = tech
+		} else if a := reIdentArg.FindStringSubmatch(m[2] + ")"); a != nil {
+			// Aliasing an obfuscated variable keeps the mark.
+			if tech, ok := o.vars[a[1]]; ok {
+				o.vars[m[1]] = tech
+			}
+		}
+	}
+	for _, loc := range reObfSink.FindAllStringSubmatchIndex(line, -1) {
+		sink := line[loc[2]:loc[3]]
+		if sink == "exec" && loc[2] > 0 && line[loc[2]-1] == '.' {
+			continue
+		}
+		arg := callArgs(line[loc[1]:])
+		tech, ok := o.technique(arg)
+		if !ok {
+			if a := reIdentArg.FindStringSubmatch(arg + ")"); a != nil {
+				tech, ok = o.vars[a[1]]
+			}
+		}
+		if !ok {
+			continue
+		}
+		caps.AddWithEvidence(capability.CapObfuscation, capability.CapabilityEvidence{
+			File:       o.path,
+			Line:       lineNo,
+			Context:    sink + "(<" + tech + ">)",
+			Via:        "callSite",
+			Confidence: 0.50,
+		})
+	}
+}
+
+// callArgs returns the arguments of a call whose opening parenthesis
+// precedes s: s up to the matching closing parenthesis, skipping string
+// contents, or all of s when the call continues on a later line.
+func callArgs(s string) string {
+	depth := 0
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0:
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"' || c == '`':
+			quote = c
+		case c == '(':
+			depth++
+		case c == ')':
+			if depth == 0 {
+				return s[:i]
+			}
+			depth--
+		}
+	}
+	return s
+}
+
+// hasObfuscationHint reports whether src could contain an obfuscated sink,
+// so files without any technique skip the per-line scan.
+func hasObfuscationHint(src string) bool {
+	return strings.Contains(src, "fromCharCode") || strings.Contains(src, "reverse()") ||
+		strings.Contains(src, "Buffer.from") || strings.Contains(src, "atob") ||
+		strings.Contains(src, `\x`) || strings.Contains(src, ".join(")
+}
@@ -0,0 +1,58 @@
+package node
+
+import (
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// DefaultSkipDirs are directories of build output and bundled third-party
+// code. They hold generated or vendored JavaScript, not the project's own
+// source, so first-party analysis skips them. They match only at the
+// project root: src/build/ is source, build/ is output.
+var DefaultSkipDirs = []string{"bower_components", "build", "coverage", "dist", "out", "vendor"}
+
+// skipDirs are the directories first-party analysis skips: defaults matched
+// at the project root, and a policy's skip_dirs extras.
+type skipDirs struct {
+	root  []string
+	extra []string
+}
+
+// newSkipDirs adds dirs to DefaultSkipDirs as the directories first-party
+// analysis skips. An entry without a slash matches a directory of that name
+// at any depth; one with a slash matches that path relative to the project
+// root. A non-nil empty dirs clears the defaults, so nothing is skipped; nil
+// keeps them alone.
+func newSkipDirs(dirs []string) *skipDirs {
+	s := &skipDirs{root: DefaultSkipDirs, extra: dirs}
+	if dirs != nil && len(dirs) == 0 {
+		s.root = nil
+	}
+	return s
+}
+
+// skip reports whether path, a directory under the project root, is build
+// output or vendored code excluded from first-party analysis.
+func (s *skipDirs) skip(root, path string) bool {
+	name := filepath.Base(path)
+	rel, err := filepath.Rel(root, path)
+	if err != nil {
+		rel = name
+	}
+	rel = filepath.ToSlash(rel)
+	if slices.Contains(s.root, rel) {
+		return true
+	}
+	for _, d := range s.extra {
+		d = strings.Trim(filepath.ToSlash(d), "/")
+		if strings.Contains(d, "/") {
+			if rel == d {
+				return true
+			}
+		} else if name == d {
+			return true
+		}
+	}
+	return false
+}
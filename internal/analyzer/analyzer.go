@@ -201,20 +201,32 @@ type Analyzer interface {
 	Load(dir string) (*graph.DependencyGraph, error)
 }
 
+// Options are the per-scan settings the Go and Node analyzers detect with.
+// The zero value is what ForLang uses.
+type Options struct {
+	Go       goadapter.Config // Go target, allowed hosts, and build environment
+	SkipDirs []string         // Node skip_dirs (see nodeadapter.DetectFirstParty)
+}
+
 // ForLang returns an Analyzer for the given language specifier.
 // lang may be "auto", "go", "node", "php", "python", "java", "rust", "ruby",
 // "elixir", "dart", "swift", "dotnet", "kotlin", "scala", "cpp", "haskell",
 // "clojure", "erlang", "ocaml", "julia", "r", "perl", or "lua".
 // "auto" detects from lockfile / manifest presence.
 func ForLang(lang, dir string) (Analyzer, error) {
+	return ForLangWith(lang, dir, Options{})
+}
+
+// ForLangWith is ForLang with the Go and Node analyzers configured by opts.
+func ForLangWith(lang, dir string, opts Options) (Analyzer, error) {
 	if lang == "auto" {
 		lang = detect(dir)
 	}
 	switch lang {
 	case "go":
-		return &goadapter.Adapter{}, nil
+		return &goadapter.Adapter{Config: opts.Go}, nil
 	case "node":
-		return &nodeadapter.Adapter{}, nil
+		return &nodeadapter.Adapter{SkipDirs: opts.SkipDirs}, nil
 	case "php":
 		return &phpadapter.Adapter{}, nil
 	case "python":
@@ -256,7 +268,7 @@ func ForLang(lang, dir string) (Analyzer, error) {
 	case "lua":
 		return &luaadapter.Adapter{}, nil
 	case "multi":
-		return &multiAnalyzer{}, nil
+		return &multiAnalyzer{opts: opts}, nil
 	default:
 		return nil, fmt.Errorf("unknown language %q; choose auto|go|node|php|python|java|rust|ruby|elixir|dart|swift|dotnet|kotlin|scala|cpp|haskell|clojure|erlang|ocaml|julia|r|perl|lua", lang)
 	}
@@ -421,14 +433,18 @@ func ResolveLang(lang, dir string) string {
 	return lang
 }
 
-// multiAnalyzer runs both Go and Node analyzers and merges the results.
-type multiAnalyzer struct{}
+// multiAnalyzer runs both Go and Node analyzers (plus PHP when a composer
+// manifest is present) and merges the results. Every module is tagged with
+// the language of the adapter that loaded it.
+type multiAnalyzer struct {
+	opts Options
+}
 
 func (m *multiAnalyzer) Name() string { return "multi" }
 
 func (m *multiAnalyzer) Load(dir string) (*graph.DependencyGraph, error) {
-	goA := &goadapter.Adapter{}
-	nodeA := &nodeadapter.Adapter{}
+	goA := &goadapter.Adapter{Config: m.opts.Go}
+	nodeA := &nodeadapter.Adapter{SkipDirs: m.opts.SkipDirs}
 
 	goG, goErr := goA.Load(dir)
 	nodeG, nodeErr := nodeA.Load(dir)
@@ -436,13 +452,27 @@ func (m *multiAnalyzer) Load(dir string) (*graph.DependencyGraph, error) {
 	if goErr != nil && nodeErr != nil {
 		return nil, fmt.Errorf("go: %w; node: %w", goErr, nodeErr)
 	}
-	if goErr != nil {
-		return nodeG, nil
+	var merged *graph.DependencyGraph
+	switch {
+	case goErr != nil:
+		nodeG.TagLanguage("node")
+		merged = nodeG
+	case nodeErr != nil:
+		goG.TagLanguage("go")
+		merged = goG
+	default:
+		goG.TagLanguage("go")
+		nodeG.TagLanguage("node")
+		merged = mergeGraphs(goG, nodeG)
 	}
-	if nodeErr != nil {
-		return goG, nil
+
+	if fileExists(filepath.Join(dir, "composer.json")) || fileExists(filepath.Join(dir, "composer.lock")) {
+		if phpG, err := (&phpadapter.Adapter{}).Load(dir); err == nil {
+			phpG.TagLanguage("php")
+			merged = mergeGraphs(merged, phpG)
+		}
 	}
-	return mergeGraphs(goG, nodeG), nil
+	return merged, nil
 }
 
 func mergeGraphs(a, b *graph.DependencyGraph) *graph.DependencyGraph {
@@ -482,28 +512,84 @@ func mergeGraphs(a, b *graph.DependencyGraph) *graph.DependencyGraph {
 //   - pnpm-workspace.yaml → pnpm workspace (packages: list)
 //
 // For each workspace member directory, the appropriate language adapter's
-// Load method is called and the resulting graphs are merged.
-func LoadWorkspace(root string) (*graph.DependencyGraph, error) {
+// Load method is called, configured by opts, and the resulting graphs are
+// merged.
+func LoadWorkspace(root string, opts Options) (*graph.DependencyGraph, error) {
 	// Try go.work first
 	if fileExists(filepath.Join(root, "go.work")) {
-		return loadGoWorkspace(root)
+		return loadGoWorkspace(root, opts)
 	}
 
 	// Try pnpm-workspace.yaml
 	if fileExists(filepath.Join(root, "pnpm-workspace.yaml")) {
-		return loadPnpmWorkspace(root)
+		return loadPnpmWorkspace(root, opts)
 	}
 
 	// Try npm workspaces (package.json with "workspaces" field)
 	if fileExists(filepath.Join(root, "package.json")) {
-		return loadNpmWorkspace(root)
+		return loadNpmWorkspace(root, opts)
 	}
 
 	return nil, fmt.Errorf("no workspace file found in %s (looked for go.work, pnpm-workspace.yaml, package.json with workspaces)", root)
 }
 
+// LoadRecursive finds every go.mod beneath root (without requiring a go.work)
+// and merges the module graphs into one. Each member keeps its own main
+// module, so packages stay attributed to the module that owns them, and
+// dependencies shared between members appear once. opts configures the Go
+// analyzer.
+func LoadRecursive(root string, opts Options) (*graph.DependencyGraph, error) {
+	goA := &goadapter.Adapter{Config: opts.Go}
+	return loadRecursive(root, goA.Load)
+}
+
+func loadRecursive(root string, load func(dir string) (*graph.DependencyGraph, error)) (*graph.DependencyGraph, error) {
+	dirs, err := findGoModules(root)
+	if err != nil {
+		return nil, err
+	}
+	if len(dirs) == 0 {
+		return nil, fmt.Errorf("no go.mod found beneath %s", root)
+	}
+
+	merged := graph.NewDependencyGraph()
+	for _, dir := range dirs {
+		g, err := load(dir)
+		if err != nil {
+			return nil, fmt.Errorf("load module %s: %w", dir, err)
+		}
+		g.TagLanguage("go")
+		merged = mergeGraphs(merged, g)
+	}
+	return merged, nil
+}
+
+// findGoModules returns the directories under root containing a go.mod, in
+// lexical order. vendor, testdata, node_modules, and hidden directories are
+// skipped.
+func findGoModules(root string) ([]string, error) {
+	var dirs []string
+	err := filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			name := d.Name()
+			if path != root && (strings.HasPrefix(name, ".") || name == "vendor" || name == "testdata" || name == "node_modules") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.Name() == "go.mod" {
+			dirs = append(dirs, filepath.Dir(path))
+		}
+		return nil
+	})
+	return dirs, err
+}
+
 // loadGoWorkspace parses go.work and loads each member module.
-func loadGoWorkspace(root string) (*graph.DependencyGraph, error) {
+func loadGoWorkspace(root string, opts Options) (*graph.DependencyGraph, error) {
 	goWorkPath := filepath.Join(root, "go.work")
 	f, err := os.Open(goWorkPath)
 	if err != nil {
@@ -541,20 +627,21 @@ func loadGoWorkspace(root string) (*graph.DependencyGraph, error) {
 		return nil, fmt.Errorf("go.work has no 'use' directives")
 	}
 
-	goA := &goadapter.Adapter{}
+	goA := &goadapter.Adapter{Config: opts.Go}
 	merged := graph.NewDependencyGraph()
 	for _, memberDir := range memberDirs {
 		g, err := goA.Load(memberDir)
 		if err != nil {
 			return nil, fmt.Errorf("load workspace member %s: %w", memberDir, err)
 		}
+		g.TagLanguage("go")
 		merged = mergeGraphs(merged, g)
 	}
 	return merged, nil
 }
 
 // loadPnpmWorkspace parses pnpm-workspace.yaml and loads each member.
-func loadPnpmWorkspace(root string) (*graph.DependencyGraph, error) {
+func loadPnpmWorkspace(root string, opts Options) (*graph.DependencyGraph, error) {
 	data, err := os.ReadFile(filepath.Join(root, "pnpm-workspace.yaml"))
 	if err != nil {
 		return nil, fmt.Errorf("read pnpm-workspace.yaml: %w", err)
@@ -597,11 +684,11 @@ func loadPnpmWorkspace(root string) (*graph.DependencyGraph, error) {
 		return nil, fmt.Errorf("resolve pnpm workspace patterns: %w", err)
 	}
 
-	return loadNodeMemberDirs(memberDirs)
+	return loadNodeMemberDirs(memberDirs, opts)
 }
 
 // loadNpmWorkspace parses package.json workspaces field and loads each member.
-func loadNpmWorkspace(root string) (*graph.DependencyGraph, error) {
+func loadNpmWorkspace(root string, opts Options) (*graph.DependencyGraph, error) {
 	data, err := os.ReadFile(filepath.Join(root, "package.json"))
 	if err != nil {
 		return nil, fmt.Errorf("read package.json: %w", err)
@@ -622,7 +709,7 @@ func loadNpmWorkspace(root string) (*graph.DependencyGraph, error) {
 		return nil, fmt.Errorf("resolve npm workspace patterns: %w", err)
 	}
 
-	return loadNodeMemberDirs(memberDirs)
+	return loadNodeMemberDirs(memberDirs, opts)
 }
 
 // resolveGlobPatterns expands glob patterns relative to root into concrete
@@ -663,18 +750,19 @@ func resolveGlobPatterns(root string, patterns []string) ([]string, error) {
 
 // loadNodeMemberDirs loads each member directory with the Node adapter and
 // merges the resulting graphs.
-func loadNodeMemberDirs(memberDirs []string) (*graph.DependencyGraph, error) {
+func loadNodeMemberDirs(memberDirs []string, opts Options) (*graph.DependencyGraph, error) {
 	if len(memberDirs) == 0 {
 		return nil, fmt.Errorf("no workspace members found")
 	}
 
-	nodeA := &nodeadapter.Adapter{}
+	nodeA := &nodeadapter.Adapter{SkipDirs: opts.SkipDirs}
 	merged := graph.NewDependencyGraph()
 	for _, memberDir := range memberDirs {
 		g, err := nodeA.Load(memberDir)
 		if err != nil {
 			return nil, fmt.Errorf("load workspace member %s: %w", memberDir, err)
 		}
+		g.TagLanguage("node")
 		merged = mergeGraphs(merged, g)
 	}
 	return merged, nil
@@ -2,8 +2,10 @@ package analyzer
 
 import (
 	"fmt"
+	"maps"
 	"os"
 	"path/filepath"
+	"slices"
 	"testing"
 
 	"github.com/1homsi/gorisk/internal/graph"
@@ -384,7 +386,7 @@ func TestLoadWorkspaceGoWork(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	g, err := LoadWorkspace(root)
+	g, err := LoadWorkspace(root, Options{})
 	if err != nil {
 		t.Fatalf("LoadWorkspace() error: %v", err)
 	}
@@ -434,7 +436,7 @@ func TestLoadWorkspaceNpmWorkspaces(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	g, err := LoadWorkspace(root)
+	g, err := LoadWorkspace(root, Options{})
 	if err != nil {
 		t.Fatalf("LoadWorkspace() error: %v", err)
 	}
@@ -450,7 +452,7 @@ func TestLoadWorkspaceNpmWorkspaces(t *testing.T) {
 
 func TestLoadWorkspaceNoWorkspaceFile(t *testing.T) {
 	root := t.TempDir()
-	_, err := LoadWorkspace(root)
+	_, err := LoadWorkspace(root, Options{})
 	if err == nil {
 		t.Error("expected error when no workspace file found, got nil")
 	}
@@ -479,7 +481,7 @@ func TestLoadWorkspacePnpm(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	g, err := LoadWorkspace(root)
+	g, err := LoadWorkspace(root, Options{})
 	if err != nil {
 		t.Fatalf("LoadWorkspace() pnpm error: %v", err)
 	}
@@ -487,3 +489,158 @@ func TestLoadWorkspacePnpm(t *testing.T) {
 		t.Fatal("LoadWorkspace() returned nil graph for pnpm workspace")
 	}
 }
+
+func TestLoadRecursiveSiblingModules(t *testing.T) {
+	root := t.TempDir()
+	for _, dir := range []string{"svc-a", "svc-b", "svc-b/vendor/x", ".git/y"} {
+		if err := os.MkdirAll(filepath.Join(root, dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(root, dir, "go.mod"), []byte("module "+dir+"\n"), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	shared := &graph.Module{Path: "golang.org/x/net", Version: "v0.25.0"}
+	load := func(dir string) (*graph.DependencyGraph, error) {
+		name := "example.com/" + filepath.Base(dir)
+		g := graph.NewDependencyGraph()
+		g.Main = &graph.Module{Path: name, Main: true}
+		g.Modules[name] = g.Main
+		g.Modules[shared.Path] = shared
+		g.Packages[name] = &graph.Package{ImportPath: name, Module: g.Main}
+		g.Packages["golang.org/x/net/http2"] = &graph.Package{ImportPath: "golang.org/x/net/http2", Module: shared}
+		return g, nil
+	}
+
+	g, err := loadRecursive(root, load)
+	if err != nil {
+		t.Fatalf("loadRecursive() error: %v", err)
+	}
+
+	for _, name := range []string{"example.com/svc-a", "example.com/svc-b"} {
+		pkg, ok := g.Packages[name]
+		if !ok {
+			t.Errorf("package %s not scanned", name)
+			continue
+		}
+		if pkg.Module == nil || pkg.Module.Path != name {
+			t.Errorf("package %s attributed to %+v, want its own module", name, pkg.Module)
+		}
+	}
+	if _, ok := g.Packages["example.com/x"]; ok {
+		t.Error("module under vendor/ should be skipped")
+	}
+	if len(g.Modules) != 3 {
+		t.Errorf("expected 3 modules (2 members + 1 shared dep), got %d", len(g.Modules))
+	}
+	if len(g.Packages) != 3 {
+		t.Errorf("shared dependency package should appear once, got %d packages", len(g.Packages))
+	}
+}
+
+func TestLoadRecursiveNoModules(t *testing.T) {
+	if _, err := LoadRecursive(t.TempDir(), Options{}); err == nil {
+		t.Error("expected error when no go.mod exists")
+	}
+}
+
+func TestMultiAnalyzerTagsLanguages(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"go.mod":       "module example.com/app\n\ngo 1.22\n",
+		"main.go":      "package main\n\nfunc main() {}\n",
+		"package.json": `{"name":"app","version":"1.0.0","dependencies":{"left-pad":"1.3.0"}}`,
+		"package-lock.json": `{"name":"app","version":"1.0.0","lockfileVersion":3,"packages":{
+			"":{"name":"app","version":"1.0.0","dependencies":{"left-pad":"1.3.0"}},
+			"node_modules/left-pad":{"version":"1.3.0"}}}`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	g, err := (&multiAnalyzer{}).Load(dir)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	langs := make(map[string]bool)
+	for path, mod := range g.Modules {
+		if mod.Language != "go" && mod.Language != "node" {
+			t.Errorf("module %s tagged %q, want go or node", path, mod.Language)
+		}
+		langs[mod.Language] = true
+	}
+	if !langs["node"] {
+		t.Error("expected node modules in the merged graph")
+	}
+}
+
+func TestLoadSubmodules(t *testing.T) {
+	root := t.TempDir()
+	gitmodules := "[submodule \"tool\"]\n\tpath = vendor/tool\n\turl = https://example.com/tool.git\n" +
+		"[submodule \"missing\"]\n\tpath = vendor/missing\n\turl = https://example.com/missing.git\n"
+	files := map[string]string{
+		".gitmodules":              gitmodules,
+		"vendor/tool/package.json": `{"name":"tool","version":"1.0.0","dependencies":{"runner":"1.0.0"}}`,
+		"vendor/tool/package-lock.json": `{"name":"tool","version":"1.0.0","lockfileVersion":3,"packages":{
+			"":{"name":"tool","version":"1.0.0","dependencies":{"runner":"1.0.0"}},
+			"node_modules/runner":{"version":"1.0.0"}}}`,
+		"vendor/tool/node_modules/runner/package.json": `{"name":"runner","version":"1.0.0","main":"index.js"}`,
+		"vendor/tool/node_modules/runner/index.js":     "const { execSync } = require('child_process');\nexecSync('make');\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.MkdirAll(filepath.Join(root, "vendor", "missing"), 0750); err != nil {
+		t.Fatal(err)
+	}
+
+	g, loaded, errs := LoadSubmodules(graph.NewDependencyGraph(), root, Options{})
+	if len(loaded) != 1 || loaded[0].Path != "vendor/tool" {
+		t.Fatalf("loaded = %+v, want vendor/tool", loaded)
+	}
+	if len(errs) != 1 {
+		t.Errorf("expected one error for the unchecked-out submodule, got %v", errs)
+	}
+
+	found := false
+	for path, pkg := range g.Packages {
+		if !pkg.Capabilities.Has("exec") {
+			continue
+		}
+		found = true
+		if pkg.Module == nil || pkg.Module.Submodule != "vendor/tool" || pkg.Module.Language != "node" {
+			t.Errorf("package %s not attributed to submodule vendor/tool: %+v", path, pkg.Module)
+		}
+	}
+	if !found {
+		t.Error("expected exec capability from the submodule's dependency")
+	}
+
+	// A dependency the root project already has keeps the root's entry;
+	// the submodule only adds what is missing.
+	rg := graph.NewDependencyGraph()
+	mod := &graph.Module{Path: "runner", Version: "2.0.0", Language: "node"}
+	pkg := &graph.Package{ImportPath: "runner", Name: "runner", Module: mod}
+	rg.Modules[mod.Path] = mod
+	rg.Packages[pkg.ImportPath] = pkg
+	rg.Edges[pkg.ImportPath] = []string{"root-only"}
+	g, _, _ = LoadSubmodules(rg, root, Options{})
+	if g.Modules["runner"] != mod || g.Packages["runner"] != pkg || mod.Submodule != "" {
+		t.Errorf("runner = %+v, want the root project's entry, untagged", g.Modules["runner"])
+	}
+	if edges := g.Edges["runner"]; len(edges) != 1 || edges[0] != "root-only" {
+		t.Errorf("runner edges = %v, want the root project's", edges)
+	}
+	if _, ok := g.Packages["tool"]; !ok {
+		t.Errorf("submodule package tool missing from the merged graph: %v", slices.Collect(maps.Keys(g.Packages)))
+	}
+}
@@ -0,0 +1,106 @@
+package analyzer
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/1homsi/gorisk/internal/graph"
+)
+
+// Submodule is a git submodule declared in .gitmodules.
+type Submodule struct {
+	Name string
+	Path string // relative to the repository root, slash-separated
+}
+
+// Submodules parses root/.gitmodules. A repository without one has no
+// submodules and returns nil, nil.
+func Submodules(root string) ([]Submodule, error) {
+	f, err := os.Open(filepath.Join(root, ".gitmodules"))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("open .gitmodules: %w", err)
+	}
+	defer f.Close()
+
+	var subs []Submodule
+	var cur *Submodule
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") {
+			cur = nil
+			// [submodule "tools/protoc-gen"]
+			if name, ok := strings.CutPrefix(strings.Trim(line, "[]"), "submodule "); ok {
+				subs = append(subs, Submodule{Name: strings.Trim(strings.TrimSpace(name), `"`)})
+				cur = &subs[len(subs)-1]
+			}
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if cur != nil && ok && strings.TrimSpace(key) == "path" {
+			cur.Path = strings.TrimSpace(value)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read .gitmodules: %w", err)
+	}
+
+	out := subs[:0]
+	for _, s := range subs {
+		if s.Path != "" {
+			out = append(out, s)
+		}
+	}
+	return out, nil
+}
+
+// LoadSubmodules analyzes every git submodule of root as its own project,
+// detecting its language from its manifests, and adds to g the modules,
+// packages, and edges it does not already have. Modules loaded from a
+// submodule are tagged with its path. Submodules that
+// are not checked out or fail to load are skipped and reported in errs. opts
+// configures the Go and Node analyzers as for the root project.
+func LoadSubmodules(g *graph.DependencyGraph, root string, opts Options) (merged *graph.DependencyGraph, loaded []Submodule, errs []error) {
+	subs, err := Submodules(root)
+	if err != nil {
+		return g, nil, []error{err}
+	}
+
+	merged = g
+	for _, s := range subs {
+		dir := filepath.Join(root, filepath.FromSlash(s.Path))
+		if entries, err := os.ReadDir(dir); err != nil || len(entries) == 0 {
+			errs = append(errs, fmt.Errorf("submodule %s is not checked out", s.Path))
+			continue
+		}
+		lang := detect(dir)
+		a, err := ForLangWith(lang, dir, opts)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("submodule %s: %w", s.Path, err))
+			continue
+		}
+		sg, err := a.Load(dir)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("submodule %s: %w", s.Path, err))
+			continue
+		}
+		sg.TagLanguage(a.Name())
+		sg.TagSubmodule(s.Path)
+		// Entries of the root project win: a dependency it shares with the
+		// submodule keeps the root's version, edges, and attribution.
+		mainMod := merged.Main
+		merged = mergeGraphs(sg, merged)
+		merged.Main = mainMod
+		loaded = append(loaded, s)
+	}
+	return merged, loaded, errs
+}
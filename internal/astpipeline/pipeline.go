@@ -27,6 +27,7 @@ import (
 	"github.com/1homsi/gorisk/internal/graph"
 	"github.com/1homsi/gorisk/internal/interproc"
 	"github.com/1homsi/gorisk/internal/ir"
+	"github.com/1homsi/gorisk/internal/taint"
 )
 
 // Result is a command-friendly wrapper around interprocedural analysis outputs.
@@ -36,29 +37,44 @@ type Result struct {
 	Reason        string
 }
 
+// Options configures Analyze. The zero value detects for the native Go
+// target, skips Node's default build directories, checks every taint rule,
+// and keeps function summaries in the on-disk cache.
+type Options struct {
+	Go       goadapter.Config // Go target and allowed hosts
+	SkipDirs []string         // Node skip_dirs (see nodeadapter.DetectFirstParty)
+	Taint    taint.Options
+	Cache    interproc.SummaryCache // replaces the on-disk cache when set
+}
+
 // Analyze tries to run interprocedural AST analysis for the given language.
 // It always returns a Result; callers should fall back when UsedInterproc is false.
-func Analyze(dir, lang string, g *graph.DependencyGraph) Result {
-	irGraph, err := buildIR(dir, lang, g)
+func Analyze(dir, lang string, g *graph.DependencyGraph, opts Options) Result {
+	irGraph, err := buildIR(dir, lang, g, opts)
 	if err != nil {
 		return Result{UsedInterproc: false, Reason: err.Error()}
 	}
 	if len(irGraph.Functions) == 0 {
 		return Result{UsedInterproc: false, Reason: "no function-level IR available"}
 	}
-	bundle, err := interproc.RunBundle(irGraph, interproc.DefaultOptions())
+	analysis := interproc.DefaultOptions()
+	analysis.Taint = opts.Taint
+	if opts.Cache != nil {
+		analysis.Cache = opts.Cache
+	}
+	bundle, err := interproc.RunBundle(irGraph, analysis)
 	if err != nil {
 		return Result{UsedInterproc: false, Reason: err.Error()}
 	}
 	return Result{Bundle: bundle, UsedInterproc: true, Reason: "interproc enabled"}
 }
 
-func buildIR(dir, lang string, g *graph.DependencyGraph) (ir.IRGraph, error) {
+func buildIR(dir, lang string, g *graph.DependencyGraph, opts Options) (ir.IRGraph, error) {
 	switch lang {
 	case "go":
-		return goadapter.BuildIRGraph(dir, g)
+		return goadapter.BuildIRGraph(dir, g, opts.Go)
 	case "node":
-		return nodeadapter.BuildIRGraph(g), nil
+		return nodeadapter.BuildIRGraph(g, opts.SkipDirs), nil
 	case "python":
 		return pythonadapter.BuildIRGraph(g), nil
 	case "ruby":
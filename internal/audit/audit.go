@@ -0,0 +1,133 @@
+// Package audit appends a tamper-evident record of every scan invocation to
+// a JSON Lines file. Each entry carries the SHA-256 of the line before it,
+// so editing or deleting an earlier entry breaks the chain and Verify
+// reports it.
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// Entry is one scan invocation.
+type Entry struct {
+	Timestamp     string `json:"timestamp"`
+	Commit        string `json:"commit,omitempty"`
+	GraphChecksum string `json:"graph_checksum"`
+	Verdict       string `json:"verdict"` // "pass" or "fail"
+	FailReason    string `json:"fail_reason,omitempty"`
+	PrevHash      string `json:"prev_hash,omitempty"` // SHA-256 of the previous line
+}
+
+// Append writes e as a new line at the end of the log at path, creating the
+// file if needed. Timestamp defaults to now and PrevHash is always filled
+// from the current last line. An advisory lock on the log is held from
+// reading that line until the new one is written, so concurrent scans
+// appending to the same log keep the chain intact.
+func Append(path string, e Entry) (err error) {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+	}()
+	if err := lock(f); err != nil {
+		return fmt.Errorf("lock %s: %w", path, err)
+	}
+
+	last, err := lastLine(path)
+	if err != nil {
+		return err
+	}
+	if e.Timestamp == "" {
+		e.Timestamp = time.Now().UTC().Format(time.RFC3339)
+	}
+	e.PrevHash = ""
+	if last != nil {
+		e.PrevHash = hashLine(last)
+	}
+
+	line, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(append(line, '\n'))
+	return err
+}
+
+// Verify checks that every entry in the log at path links to the line
+// before it. It returns the number of entries checked.
+func Verify(path string) (int, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	n := 0
+	prev := ""
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for sc.Scan() {
+		line := sc.Bytes()
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		n++
+		var e Entry
+		if err := json.Unmarshal(line, &e); err != nil {
+			return n, fmt.Errorf("entry %d: %w", n, err)
+		}
+		if e.PrevHash != prev {
+			return n, fmt.Errorf("entry %d: chain broken (prev_hash %q, want %q)", n, e.PrevHash, prev)
+		}
+		prev = hashLine(line)
+	}
+	return n, sc.Err()
+}
+
+// Commit returns the full HEAD commit of the git repository at dir, or ""
+// outside a repository.
+func Commit(dir string) string {
+	out, err := exec.Command("git", "-C", dir, "rev-parse", "HEAD").Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// lastLine returns the last non-empty line of the file at path, or nil
+// when the file is missing or empty.
+func lastLine(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	data = bytes.TrimRight(data, "\r\n\t ")
+	if len(data) == 0 {
+		return nil, nil
+	}
+	if i := bytes.LastIndexByte(data, '\n'); i >= 0 {
+		data = data[i+1:]
+	}
+	return data, nil
+}
+
+func hashLine(line []byte) string {
+	sum := sha256.Sum256(bytes.TrimSpace(line))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,103 @@
+package audit
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestAppendTwoScans(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+
+	if err := Append(path, Entry{Commit: "abc123", GraphChecksum: "sum1", Verdict: "pass"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := Append(path, Entry{Commit: "abc123", GraphChecksum: "sum2", Verdict: "fail", FailReason: "package x has HIGH risk"}); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d lines, want 2:\n%s", len(lines), data)
+	}
+
+	var first, second map[string]any
+	if err := json.Unmarshal([]byte(lines[0]), &first); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal([]byte(lines[1]), &second); err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{"timestamp", "commit", "graph_checksum", "verdict"} {
+		if _, ok := first[key]; !ok {
+			t.Errorf("first entry missing %q: %s", key, lines[0])
+		}
+	}
+	if _, ok := first["prev_hash"]; ok {
+		t.Errorf("first entry should not link to a predecessor: %s", lines[0])
+	}
+	if second["verdict"] != "fail" || second["fail_reason"] != "package x has HIGH risk" || second["graph_checksum"] != "sum2" {
+		t.Errorf("second entry = %s", lines[1])
+	}
+	if second["prev_hash"] != hashLine([]byte(lines[0])) {
+		t.Errorf("second entry prev_hash = %v, want hash of first line", second["prev_hash"])
+	}
+
+	if n, err := Verify(path); err != nil || n != 2 {
+		t.Errorf("Verify = %d, %v; want 2, nil", n, err)
+	}
+}
+
+func TestVerifyDetectsTampering(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	for _, v := range []string{"fail", "pass", "pass"} {
+		if err := Append(path, Entry{GraphChecksum: "sum", Verdict: v}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tampered := strings.Replace(string(data), `"verdict":"fail"`, `"verdict":"pass"`, 1)
+	if err := os.WriteFile(path, []byte(tampered), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Verify(path); err == nil || !strings.Contains(err.Error(), "entry 2") {
+		t.Errorf("Verify error = %v, want chain broken at entry 2", err)
+	}
+}
+
+func TestAppendConcurrent(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+
+	// Each append reads the last line and writes after it under the lock,
+	// so concurrent scans never link to the same predecessor.
+	const scans = 20
+	var wg sync.WaitGroup
+	for range scans {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := Append(path, Entry{GraphChecksum: "sum", Verdict: "pass"}); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	n, err := Verify(path)
+	if err != nil {
+		t.Fatalf("Verify() after concurrent appends: %v", err)
+	}
+	if n != scans {
+		t.Errorf("Verify() checked %d entries, want %d", n, scans)
+	}
+}
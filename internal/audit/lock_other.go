@@ -0,0 +1,11 @@
+//go:build !unix
+
+package audit
+
+import "os"
+
+// lock is a no-op where flock is unavailable; concurrent appends to the
+// same log are then not serialized.
+func lock(f *os.File) error {
+	return nil
+}
@@ -0,0 +1,14 @@
+//go:build unix
+
+package audit
+
+import (
+	"os"
+	"syscall"
+)
+
+// lock takes an exclusive advisory lock on f, blocking until it is free.
+// Closing f releases it.
+func lock(f *os.File) error {
+	return syscall.Flock(int(f.Fd()), syscall.LOCK_EX)
+}
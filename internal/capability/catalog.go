@@ -0,0 +1,116 @@
+package capability
+
+import (
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/1homsi/gorisk/languages"
+)
+
+// capDescriptions is the one-line summary of each capability in capWeights.
+var capDescriptions = map[Capability]string{
+	CapFSRead:         "reads from the filesystem",
+	CapFSWrite:        "writes or deletes files",
+	CapNetwork:        "makes outbound network connections",
+	CapExec:           "spawns subprocesses or shell commands",
+	CapEnv:            "reads environment variables",
+	CapUnsafe:         "bypasses memory or type safety",
+	CapCrypto:         "uses cryptographic primitives",
+	CapReflect:        "uses runtime reflection",
+	CapPlugin:         "loads or executes external code at runtime",
+	CapWeakCrypto:     "uses non-cryptographic randomness for key, token, or nonce material",
+	CapProcessInspect: "enumerates or inspects other processes",
+	CapDNS:            "resolves DNS directly or queries DNS-over-HTTPS",
+	CapInjection:      "builds a SQL query or template from a non-constant string",
+	CapObfuscation:    "hides a required module or executed command behind character codes or encoding",
+}
+
+// maxTriggers caps the example imports, and separately the example call
+// sites, listed per language in a CapInfo.
+const maxTriggers = 2
+
+// CapInfo describes one capability for documentation: its score weight,
+// the risk level it reaches on its own, and, per language, example import
+// and call-site patterns from languages/*.yaml that trigger it.
+type CapInfo struct {
+	Name        Capability          `json:"name"`
+	Weight      int                 `json:"weight"`
+	Risk        string              `json:"risk"`
+	Description string              `json:"description"`
+	Triggers    map[string][]string `json:"triggers,omitempty"` // language → example patterns
+}
+
+// Catalog returns every known capability, sorted by name, built from the
+// weight table and the embedded pattern files so it cannot drift from what
+// the analyzers detect. Capabilities found only by code analysis (such as
+// crypto:weak) have no pattern triggers.
+func Catalog() []CapInfo {
+	patterns := allPatterns()
+	out := make([]CapInfo, 0, len(capWeights))
+	for name, weight := range capWeights {
+		var alone CapabilitySet
+		alone.Add(name)
+		info := CapInfo{
+			Name:        name,
+			Weight:      weight,
+			Risk:        alone.RiskLevel(),
+			Description: capDescriptions[name],
+		}
+		for _, ps := range patterns {
+			if ex := triggersFor(ps, name); len(ex) > 0 {
+				if info.Triggers == nil {
+					info.Triggers = make(map[string][]string)
+				}
+				info.Triggers[ps.Name] = ex
+			}
+		}
+		out = append(out, info)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// allPatterns loads every embedded languages/*.yaml pattern set. Files that
+// fail to load are skipped; LoadPatterns reports them where they are used.
+func allPatterns() []*PatternSet {
+	entries, err := languages.FS.ReadDir(".")
+	if err != nil {
+		return nil
+	}
+	var out []*PatternSet
+	for _, e := range entries {
+		lang, ok := strings.CutSuffix(e.Name(), ".yaml")
+		if !ok {
+			continue
+		}
+		ps, err := LoadPatterns(lang)
+		if err != nil {
+			continue
+		}
+		if ps.Name == "" {
+			ps.Name = lang
+		}
+		out = append(out, ps)
+	}
+	return out
+}
+
+// triggersFor returns the first maxTriggers imports and the first
+// maxTriggers call sites of ps, in sorted order, that map to c.
+func triggersFor(ps *PatternSet, c Capability) []string {
+	pick := func(m map[string][]Capability) []string {
+		var names []string
+		for pattern, caps := range m {
+			if slices.Contains(caps, c) {
+				names = append(names, pattern)
+			}
+		}
+		sort.Strings(names)
+		if len(names) > maxTriggers {
+			names = names[:maxTriggers]
+		}
+		return names
+	}
+	return append(pick(ps.Imports), pick(ps.CallSites)...)
+}
@@ -0,0 +1,32 @@
+package capability
+
+import "testing"
+
+func TestCatalogCoversEveryCapability(t *testing.T) {
+	catalog := Catalog()
+	if len(catalog) != len(capWeights) {
+		t.Fatalf("Catalog() has %d entries, want %d", len(catalog), len(capWeights))
+	}
+	for _, c := range catalog {
+		if c.Weight != capWeights[c.Name] {
+			t.Errorf("%s weight = %d, want %d", c.Name, c.Weight, capWeights[c.Name])
+		}
+		if c.Description == "" {
+			t.Errorf("%s has no description in capDescriptions", c.Name)
+		}
+		if c.Risk == "" {
+			t.Errorf("%s has no standalone risk", c.Name)
+		}
+	}
+
+	byName := make(map[Capability]CapInfo, len(catalog))
+	for _, c := range catalog {
+		byName[c.Name] = c
+	}
+	if ex := byName[CapExec].Triggers["go"]; len(ex) == 0 {
+		t.Error("exec has no Go triggers from go.yaml")
+	}
+	if ex := byName[CapExec].Triggers["node"]; len(ex) == 0 {
+		t.Error("exec has no Node triggers from node.yaml")
+	}
+}
@@ -0,0 +1,69 @@
+package capability
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// DefaultHighRisk are the capabilities whose appearance in a new version
+// escalates a capability diff.
+var DefaultHighRisk = []Capability{CapExec, CapNetwork, CapUnsafe, CapPlugin}
+
+// HighRisk is a set of capabilities that escalate risk. The zero value is
+// DefaultHighRisk, under which risk levels follow the score alone; a set
+// returned by ParseHighRisk for a policy also makes any CapabilitySet holding
+// one of its capabilities HIGH (see Escalates).
+type HighRisk struct {
+	caps       []Capability
+	configured bool
+}
+
+// ParseHighRisk builds the high-risk set named by a policy. Names are
+// lower-cased; unknown names are an error. A nil slice yields the zero value.
+func ParseHighRisk(names []string) (HighRisk, error) {
+	if names == nil {
+		return HighRisk{}, nil
+	}
+	set := make([]Capability, 0, len(names))
+	for _, c := range names {
+		c = strings.ToLower(strings.TrimSpace(c))
+		if !KnownCapability(c) {
+			return HighRisk{}, fmt.Errorf("unknown capability %q", c)
+		}
+		if !slices.Contains(set, c) {
+			set = append(set, c)
+		}
+	}
+	return HighRisk{caps: set, configured: true}, nil
+}
+
+// Has reports whether c is in h.
+func (h HighRisk) Has(c Capability) bool {
+	if !h.configured {
+		return slices.Contains(DefaultHighRisk, c)
+	}
+	return slices.Contains(h.caps, c)
+}
+
+// Any reports whether cs holds any capability in h.
+func (h HighRisk) Any(cs CapabilitySet) bool {
+	return slices.ContainsFunc(cs.caps, h.Has)
+}
+
+// Escalates reports whether cs is HIGH risk because it holds a capability
+// from a configured high-risk set. It is always false for the zero value,
+// where the level follows the score alone.
+func (h HighRisk) Escalates(cs CapabilitySet) bool {
+	return h.configured && h.Any(cs)
+}
+
+// IsHighRisk reports whether c is in DefaultHighRisk.
+func IsHighRisk(c Capability) bool {
+	return HighRisk{}.Has(c)
+}
+
+// HasHighRisk reports whether cs holds any capability in DefaultHighRisk.
+func (cs CapabilitySet) HasHighRisk() bool {
+	return HighRisk{}.Any(cs)
+}
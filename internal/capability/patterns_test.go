@@ -91,6 +91,7 @@ func TestPatternMappingConservative(t *testing.T) {
 		{lang: "go", kind: "call", key: "os.Truncate", want: []Capability{CapFSWrite}},
 		{lang: "go", kind: "call", key: "net.ListenPacket", want: []Capability{CapNetwork}},
 		{lang: "go", kind: "call", key: "net.LookupTXT", want: []Capability{CapNetwork}},
+		{lang: "go", kind: "call", key: "net.LookupHost", want: []Capability{CapNetwork, CapDNS}},
 		{lang: "go", kind: "call", key: "tls.DialWithDialer", want: []Capability{CapNetwork, CapCrypto}},
 		// Node: namespaced close-call additions
 		{lang: "node", kind: "call", key: "http.request(", want: []Capability{CapNetwork}},
@@ -1,6 +1,7 @@
 package capability
 
 import (
+	"fmt"
 	"sort"
 	"strings"
 )
@@ -20,6 +21,27 @@ const (
 	CapCrypto  Capability = "crypto"
 	CapReflect Capability = "reflect"
 	CapPlugin  Capability = "plugin"
+
+	// CapWeakCrypto marks non-cryptographic randomness (math/rand) used for
+	// key, token, or nonce material.
+	CapWeakCrypto Capability = "crypto:weak"
+
+	// CapProcessInspect marks enumeration or inspection of other processes
+	// (/proc reads, gopsutil, ps-list) — reconnaissance behaviour.
+	CapProcessInspect Capability = "process:inspect"
+
+	// CapDNS marks direct DNS resolution or DNS-over-HTTPS queries, a
+	// covert channel distinct from ordinary HTTP networking.
+	CapDNS Capability = "dns"
+
+	// CapInjection marks a SQL query or template built from a non-constant
+	// string, an injection sink when that string carries untrusted input.
+	CapInjection Capability = "injection"
+
+	// CapObfuscation marks a module name or command assembled from
+	// character codes, reversed strings, or encoded data and handed to
+	// require/import or exec, which hides it from static import scanning.
+	CapObfuscation Capability = "obfuscation"
 )
 
 // CapabilityRole classifies capabilities by their role in taint analysis.
@@ -27,7 +49,7 @@ type CapabilityRole int
 
 const (
 	RoleSource    CapabilityRole = iota // env, network, fs:read
-	RoleSink                            // exec, unsafe, fs:write, plugin
+	RoleSink                            // exec, unsafe, fs:write, plugin, dns, injection
 	RoleSanitizer                       // crypto
 	RoleNeutral                         // reflect
 )
@@ -37,7 +59,7 @@ func ClassifyCapability(cap Capability) CapabilityRole {
 	switch cap {
 	case CapEnv, CapNetwork, CapFSRead:
 		return RoleSource
-	case CapExec, CapUnsafe, CapFSWrite, CapPlugin:
+	case CapExec, CapUnsafe, CapFSWrite, CapPlugin, CapDNS, CapInjection:
 		return RoleSink
 	case CapCrypto:
 		return RoleSanitizer
@@ -56,6 +78,12 @@ var capWeights = map[Capability]int{
 	CapCrypto:  5,
 	CapReflect: 5,
 	CapPlugin:  20,
+
+	CapWeakCrypto:     10,
+	CapProcessInspect: 10,
+	CapDNS:            10,
+	CapInjection:      15,
+	CapObfuscation:    10,
 }
 
 // KnownCapability reports whether name is a recognised capability.
@@ -64,6 +92,23 @@ func KnownCapability(name string) bool {
 	return ok
 }
 
+// ParseList parses a comma-separated list of capability names, as accepted
+// by --ignore-capability. Names are lower-cased; unknown names are an error.
+func ParseList(list string) (map[string]bool, error) {
+	out := make(map[string]bool)
+	for _, name := range strings.Split(list, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		if !KnownCapability(name) {
+			return nil, fmt.Errorf("unknown capability %q", name)
+		}
+		out[name] = true
+	}
+	return out, nil
+}
+
 // RiskValue converts a risk-level string to a comparable integer (HIGH=3, MEDIUM=2, LOW=1).
 func RiskValue(level string) int {
 	switch strings.ToLower(level) {
@@ -81,10 +126,35 @@ type CapabilityEvidence struct {
 	File       string  `json:"file,omitempty"`
 	Line       int     `json:"line,omitempty"`
 	Context    string  `json:"context,omitempty"`
-	Via        string  `json:"via,omitempty"`        // "import" | "callSite" | "installScript"
+	Via        string  `json:"via,omitempty"`        // "import" | "callSite" | "installScript" | "runsOnImport" | "directive"
 	Confidence float64 `json:"confidence,omitempty"` // 0.0–1.0
 }
 
+// ViaRunsOnImport marks evidence reached from a package's init function.
+// Such code runs as soon as the package is imported, so the first
+// runs-on-import evidence for a capability counts its weight a second time.
+const ViaRunsOnImport = "runsOnImport"
+
+// ViaDirective marks evidence from a compiler directive comment such as
+// //go:linkname, which binds to unexported symbols in other packages.
+const ViaDirective = "directive"
+
+// ViaEmbeddedPayload marks exec evidence for a //go:embed script or binary
+// that the same file writes to disk and then executes.
+const ViaEmbeddedPayload = "embeddedPayload"
+
+// ViaPathHijack marks exec evidence for a bare command run by a file that
+// also sets PATH to a non-constant value, choosing which binary it finds.
+const ViaPathHijack = "pathHijack"
+
+// ViaPluginExported marks plugin evidence for a package built as a Go
+// plugin or C shared library, whose exported symbols any loader can call.
+const ViaPluginExported = "pluginExported"
+
+// ViaHardcodedHost marks network evidence for a request to a literal raw IP
+// address, or to a literal host outside the policy's allowed_hosts.
+const ViaHardcodedHost = "hardcodedHost"
+
 // CapabilitySet is a sorted, deduplicated set of capabilities with an accumulated score.
 // Value copies are safe; mutations (Add, AddWithEvidence, Merge) require a pointer receiver.
 type CapabilitySet struct {
@@ -111,6 +181,9 @@ func (cs *CapabilitySet) AddWithEvidence(cap Capability, ev CapabilityEvidence)
 		copy(cs.caps[i+1:], cs.caps[i:])
 		cs.caps[i] = cap
 	}
+	if ev.Via == ViaRunsOnImport && !hasVia(cs.Evidence[cap], ViaRunsOnImport) {
+		cs.Score += capWeights[cap]
+	}
 	if ev.File != "" || ev.Context != "" || ev.Via != "" {
 		if cs.Evidence == nil {
 			cs.Evidence = make(map[string][]CapabilityEvidence)
@@ -119,6 +192,15 @@ func (cs *CapabilitySet) AddWithEvidence(cap Capability, ev CapabilityEvidence)
 	}
 }
 
+func hasVia(evs []CapabilityEvidence, via string) bool {
+	for _, ev := range evs {
+		if ev.Via == via {
+			return true
+		}
+	}
+	return false
+}
+
 // Add inserts cap into the set if not already present, accumulating its weight.
 // It is a shortcut for AddWithEvidence with a zero-value evidence (no source location recorded).
 func (cs *CapabilitySet) Add(cap Capability) {
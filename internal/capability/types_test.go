@@ -57,6 +57,33 @@ func TestRiskLevel(t *testing.T) {
 	}
 }
 
+func TestParseHighRisk(t *testing.T) {
+	var reflectOnly CapabilitySet
+	reflectOnly.Add(CapReflect)
+
+	var defaults HighRisk
+	if defaults.Any(reflectOnly) || defaults.Escalates(reflectOnly) {
+		t.Error("zero HighRisk should not treat reflect as high-risk")
+	}
+	if !defaults.Has(CapExec) {
+		t.Error("zero HighRisk should contain the default exec capability")
+	}
+
+	hr, err := ParseHighRisk([]string{"exec", " Reflect "})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !hr.Any(reflectOnly) || !hr.Escalates(reflectOnly) {
+		t.Error("configured HighRisk should escalate a reflect-only set")
+	}
+	if hr.Has(CapNetwork) {
+		t.Error("configured HighRisk should replace the defaults")
+	}
+	if _, err := ParseHighRisk([]string{"reflekt"}); err == nil {
+		t.Error("ParseHighRisk accepted an unknown capability")
+	}
+}
+
 func TestCapabilitySetList(t *testing.T) {
 	var cs CapabilitySet
 	cs.Add(CapFSRead)
@@ -187,3 +214,16 @@ func TestAddKeepsBackwardCompat(t *testing.T) {
 		t.Errorf("Add() should not allocate Evidence map")
 	}
 }
+
+func TestParseList(t *testing.T) {
+	got, err := ParseList(" Crypto, reflect,,")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || !got[CapCrypto] || !got[CapReflect] {
+		t.Errorf("ParseList = %v, want crypto and reflect", got)
+	}
+	if _, err := ParseList("crypto,bogus"); err == nil {
+		t.Error("expected error for unknown capability")
+	}
+}
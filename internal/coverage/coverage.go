@@ -0,0 +1,175 @@
+// Package coverage maps `go test -coverprofile` data onto the functions that
+// use capabilities, so security tests can be prioritised for the risky code
+// paths that no test exercises.
+package coverage
+
+import (
+	"bufio"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io"
+	"path/filepath"
+	"strings"
+
+	goadapter "github.com/1homsi/gorisk/internal/adapters/go"
+	"github.com/1homsi/gorisk/internal/capability"
+)
+
+// Block is one statement block of a cover profile.
+type Block struct {
+	File      string // import path + "/" + file name, as written by go test
+	StartLine int
+	StartCol  int
+	EndLine   int
+	EndCol    int
+	NumStmt   int
+	Count     int
+}
+
+// Function is the coverage of one capability-bearing function.
+type Function struct {
+	Package      string   `json:"package"`
+	Name         string   `json:"name"`
+	File         string   `json:"file"`
+	Line         int      `json:"line"`
+	Capabilities []string `json:"capabilities"`
+	Statements   int      `json:"statements"`
+	Covered      int      `json:"covered"`
+}
+
+// Exercised reports whether any statement of the function ran under test.
+func (f Function) Exercised() bool { return f.Covered > 0 }
+
+// ParseProfile reads a cover profile in any mode (set, count, atomic).
+// Blocks listed more than once, as happens when several test binaries cover
+// the same package, are kept separately; Analyze counts a statement covered
+// if any of them ran it.
+func ParseProfile(r io.Reader) ([]Block, error) {
+	var blocks []Block
+	sc := bufio.NewScanner(r)
+	lineNo := 0
+	for sc.Scan() {
+		lineNo++
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "mode:") {
+			continue
+		}
+		// file.go:startLine.startCol,endLine.endCol numStmt count
+		colon := strings.LastIndex(line, ":")
+		if colon < 0 {
+			return nil, fmt.Errorf("cover profile line %d: malformed %q", lineNo, line)
+		}
+		b := Block{File: line[:colon]}
+		if _, err := fmt.Sscanf(line[colon+1:], "%d.%d,%d.%d %d %d",
+			&b.StartLine, &b.StartCol, &b.EndLine, &b.EndCol, &b.NumStmt, &b.Count); err != nil {
+			return nil, fmt.Errorf("cover profile line %d: malformed %q: %w", lineNo, line, err)
+		}
+		blocks = append(blocks, b)
+	}
+	return blocks, sc.Err()
+}
+
+// AnalyzePackage returns the coverage of every function in the package that
+// has a direct capability call site. importPath must match the paths in the
+// cover profile.
+func AnalyzePackage(importPath, dir string, goFiles []string, blocks []Block) ([]Function, error) {
+	byFile := make(map[string][]Block)
+	for _, b := range blocks {
+		byFile[b.File] = append(byFile[b.File], b)
+	}
+
+	var out []Function
+	fset := token.NewFileSet()
+	for _, name := range goFiles {
+		fpath := filepath.Join(dir, name)
+		caps, err := goadapter.DetectFile(fpath, fset)
+		if err != nil {
+			return nil, err
+		}
+		f, err := parser.ParseFile(fset, fpath, nil, 0)
+		if err != nil {
+			return nil, err
+		}
+		fileBlocks := byFile[importPath+"/"+name]
+
+		for _, decl := range f.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Body == nil {
+				continue
+			}
+			start := fset.Position(fn.Pos()).Line
+			end := fset.Position(fn.End()).Line
+
+			capNames := callSitesWithin(caps, start, end)
+			if len(capNames) == 0 {
+				continue
+			}
+			fc := Function{
+				Package:      importPath,
+				Name:         funcName(fn),
+				File:         fpath,
+				Line:         start,
+				Capabilities: capNames,
+			}
+			fc.Statements, fc.Covered = statementsWithin(fileBlocks, start, end)
+			out = append(out, fc)
+		}
+	}
+	return out, nil
+}
+
+// callSitesWithin returns the capabilities with call-site evidence on lines
+// start..end.
+func callSitesWithin(cs capability.CapabilitySet, start, end int) []string {
+	var names []string
+	for _, c := range cs.List() {
+		for _, ev := range cs.Evidence[c] {
+			if ev.Via == "callSite" && ev.Line >= start && ev.Line <= end {
+				names = append(names, c)
+				break
+			}
+		}
+	}
+	return names
+}
+
+// statementsWithin sums the statements of blocks inside start..end and how
+// many of them ran. A block repeated across profiles counts once, as covered
+// if any copy ran.
+func statementsWithin(blocks []Block, start, end int) (total, covered int) {
+	type span struct{ startLine, startCol, endLine, endCol int }
+	ran := make(map[span]bool)
+	stmts := make(map[span]int)
+	for _, b := range blocks {
+		if b.StartLine < start || b.EndLine > end {
+			continue
+		}
+		s := span{b.StartLine, b.StartCol, b.EndLine, b.EndCol}
+		stmts[s] = b.NumStmt
+		ran[s] = ran[s] || b.Count > 0
+	}
+	for s, n := range stmts {
+		total += n
+		if ran[s] {
+			covered += n
+		}
+	}
+	return total, covered
+}
+
+// funcName returns "Name" for functions and "Type.Name" for methods.
+func funcName(fn *ast.FuncDecl) string {
+	if fn.Recv == nil || len(fn.Recv.List) == 0 {
+		return fn.Name.Name
+	}
+	recv := fn.Recv.List[0].Type
+	if star, ok := recv.(*ast.StarExpr); ok {
+		recv = star.X
+	}
+	if id, ok := recv.(*ast.Ident); ok {
+		return id.Name + "." + fn.Name.Name
+	}
+	return fn.Name.Name
+}
@@ -0,0 +1,75 @@
+package coverage
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/1homsi/gorisk/internal/capability"
+)
+
+func TestAnalyzePackageCoveredAndUncoveredExec(t *testing.T) {
+	const src = `package runner
+
+import "os/exec"
+
+func Build() error {
+	return exec.Command("go", "build").Run()
+}
+
+func Deploy() error {
+	return exec.Command("sh", "deploy.sh").Run()
+}
+
+func Add(a, b int) int {
+	return a + b
+}
+`
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "runner.go"), []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Build and Add ran under test; Deploy never did.
+	const profile = `mode: set
+example.com/runner/runner.go:5.22,7.2 1 1
+example.com/runner/runner.go:9.23,11.2 1 0
+example.com/runner/runner.go:13.24,15.2 1 1
+`
+	blocks, err := ParseProfile(strings.NewReader(profile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(blocks) != 3 {
+		t.Fatalf("ParseProfile returned %d blocks, want 3", len(blocks))
+	}
+
+	funcs, err := AnalyzePackage("example.com/runner", dir, []string{"runner.go"}, blocks)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(funcs) != 2 {
+		t.Fatalf("expected 2 capability-bearing functions, got %+v", funcs)
+	}
+
+	got := make(map[string]Function)
+	for _, f := range funcs {
+		got[f.Name] = f
+		if len(f.Capabilities) != 1 || f.Capabilities[0] != capability.CapExec {
+			t.Errorf("%s: capabilities = %v, want [exec]", f.Name, f.Capabilities)
+		}
+	}
+	if !got["Build"].Exercised() {
+		t.Error("Build should be exercised by tests")
+	}
+	if got["Deploy"].Exercised() || got["Deploy"].Statements != 1 {
+		t.Errorf("Deploy should be uncovered with 1 statement, got %+v", got["Deploy"])
+	}
+}
+
+func TestParseProfileMalformed(t *testing.T) {
+	if _, err := ParseProfile(strings.NewReader("mode: set\nnot a block\n")); err == nil {
+		t.Error("expected an error for a malformed profile line")
+	}
+}
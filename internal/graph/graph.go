@@ -10,12 +10,14 @@ import (
 )
 
 type Module struct {
-	Path     string
-	Version  string
-	Dir      string
-	Main     bool
-	Indirect bool
-	Packages []*Package
+	Path      string
+	Version   string
+	Dir       string
+	Main      bool
+	Indirect  bool
+	Language  string // adapter that loaded the module ("go", "node", …); see TagLanguage
+	Submodule string // path of the git submodule the module was loaded from, if any
+	Packages  []*Package
 }
 
 type Package struct {
@@ -24,6 +26,9 @@ type Package struct {
 	Module       *Module
 	Dir          string
 	GoFiles      []string
+	CgoFiles     []string // Go files that import "C"
+	CFiles       []string // C sources and headers compiled in by cgo
+	SFiles       []string // Go assembly sources
 	Imports      []string
 	Deps         []string
 	Capabilities capability.CapabilitySet
@@ -54,6 +59,98 @@ func (g *DependencyGraph) ReverseEdges() map[string][]string {
 	return rev
 }
 
+// TagLanguage records lang as the source adapter of every module in g that
+// has no language yet. Multi-language loads tag each adapter's graph before
+// merging, so later calls only fill in the gaps.
+func (g *DependencyGraph) TagLanguage(lang string) {
+	for _, mod := range g.Modules {
+		if mod.Language == "" {
+			mod.Language = lang
+		}
+	}
+	for _, pkg := range g.Packages {
+		if pkg.Module != nil && pkg.Module.Language == "" {
+			pkg.Module.Language = lang
+		}
+	}
+}
+
+// TagSubmodule records path as the git submodule every module in g was
+// loaded from.
+func (g *DependencyGraph) TagSubmodule(path string) {
+	for _, mod := range g.Modules {
+		mod.Submodule = path
+	}
+	for _, pkg := range g.Packages {
+		if pkg.Module != nil {
+			pkg.Module.Submodule = path
+		}
+	}
+}
+
+// Language returns the source adapter of pkg's module, or "" if unknown.
+func (p *Package) Language() string {
+	if p.Module == nil {
+		return ""
+	}
+	return p.Module.Language
+}
+
+// StripCapabilities removes the named capabilities (and their evidence and
+// score weight) from every package in g. Used for --ignore-capability.
+func (g *DependencyGraph) StripCapabilities(ignored map[string]bool) {
+	if len(ignored) == 0 {
+		return
+	}
+	for _, pkg := range g.Packages {
+		pkg.Capabilities = pkg.Capabilities.Without(ignored)
+	}
+}
+
+// ModuleDepths returns, for every package reachable from the main module's
+// packages along g.Edges, how many module boundaries the shortest import
+// chain to it crosses: 0 for packages of the main module, 1 for packages of
+// direct dependencies, and so on. Unreachable packages are absent.
+func (g *DependencyGraph) ModuleDepths() map[string]int {
+	depth := make(map[string]int)
+	var front, back []string // 0-1 BFS: same-module edges cost 0, others 1
+	for name, pkg := range g.Packages {
+		if pkg.Module != nil && pkg.Module.Main {
+			depth[name] = 0
+			front = append(front, name)
+		}
+	}
+	sort.Strings(front) // deterministic traversal
+	for len(front) > 0 || len(back) > 0 {
+		if len(front) == 0 {
+			front, back = back, nil
+		}
+		cur := front[0]
+		front = front[1:]
+		from := g.Packages[cur]
+		for _, next := range g.Edges[cur] {
+			to, ok := g.Packages[next]
+			if !ok {
+				continue
+			}
+			d := depth[cur]
+			if from == nil || to.Module != from.Module {
+				d++
+			}
+			if old, seen := depth[next]; seen && old <= d {
+				continue
+			}
+			depth[next] = d
+			if d == depth[cur] {
+				front = append([]string{next}, front...)
+			} else {
+				back = append(back, next)
+			}
+		}
+	}
+	return depth
+}
+
 // Checksum returns a short deterministic SHA-256 digest of the dependency graph.
 // The digest covers module paths, versions, package import paths, capability names,
 // and edge targets — all sorted for stability across runs.
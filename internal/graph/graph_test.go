@@ -1,6 +1,11 @@
 package graph
 
-import "testing"
+import (
+	"strings"
+	"testing"
+
+	"github.com/1homsi/gorisk/internal/capability"
+)
 
 func TestNewDependencyGraph(t *testing.T) {
 	g := NewDependencyGraph()
@@ -108,3 +113,60 @@ func TestChecksumEmptyGraph(t *testing.T) {
 		t.Errorf("expected 16-char checksum for empty graph, got %q", c)
 	}
 }
+
+func TestStripCapabilities(t *testing.T) {
+	g := NewDependencyGraph()
+	pkg := &Package{ImportPath: "example.com/a"}
+	pkg.Capabilities.Add(capability.CapCrypto)
+	pkg.Capabilities.Add(capability.CapExec)
+	g.Packages[pkg.ImportPath] = pkg
+
+	var want capability.CapabilitySet
+	want.Add(capability.CapExec)
+
+	g.StripCapabilities(map[string]bool{capability.CapCrypto: true})
+
+	got := g.Packages["example.com/a"].Capabilities
+	if got.Has(capability.CapCrypto) {
+		t.Error("ignored capability crypto still present")
+	}
+	if !got.Has(capability.CapExec) {
+		t.Error("exec should be kept")
+	}
+	if got.Score != want.Score || got.RiskLevel() != want.RiskLevel() {
+		t.Errorf("score/risk = %d/%s, want %d/%s", got.Score, got.RiskLevel(), want.Score, want.RiskLevel())
+	}
+}
+
+func TestModuleDepths(t *testing.T) {
+	g := NewDependencyGraph()
+	mods := map[string]*Module{
+		"app": {Path: "app", Main: true},
+		"a":   {Path: "a"},
+		"b":   {Path: "b"},
+		"c":   {Path: "c"},
+	}
+	for _, name := range []string{"app", "app/internal", "a", "a/sub", "b", "c", "orphan"} {
+		mod := mods[strings.SplitN(name, "/", 2)[0]]
+		if name == "orphan" {
+			mod = mods["c"]
+		}
+		g.Packages[name] = &Package{ImportPath: name, Module: mod}
+	}
+	g.Edges["app"] = []string{"app/internal", "a"}
+	g.Edges["app/internal"] = []string{"c"}
+	g.Edges["a"] = []string{"a/sub"}
+	g.Edges["a/sub"] = []string{"b"}
+	g.Edges["b"] = []string{"c"}
+
+	depths := g.ModuleDepths()
+	want := map[string]int{"app": 0, "app/internal": 0, "a": 1, "a/sub": 1, "b": 2, "c": 1}
+	for name, d := range want {
+		if got, ok := depths[name]; !ok || got != d {
+			t.Errorf("depth of %s = %d (present %v), want %d", name, got, ok, d)
+		}
+	}
+	if _, ok := depths["orphan"]; ok {
+		t.Error("unreachable package should have no depth")
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"os"
 	"os/exec"
 	"strings"
 )
@@ -22,6 +23,10 @@ type listPackage struct {
 	Name       string      `json:"Name"`
 	Dir        string      `json:"Dir"`
 	GoFiles    []string    `json:"GoFiles"`
+	CgoFiles   []string    `json:"CgoFiles"`
+	CFiles     []string    `json:"CFiles"`
+	HFiles     []string    `json:"HFiles"`
+	SFiles     []string    `json:"SFiles"`
 	Imports    []string    `json:"Imports"`
 	Deps       []string    `json:"Deps"`
 	Module     *listModule `json:"Module"`
@@ -29,9 +34,15 @@ type listPackage struct {
 }
 
 func Load(dir string) (*DependencyGraph, error) {
+	return LoadEnv(dir, nil)
+}
+
+// LoadEnv is Load with env added to the environment of go list, such as
+// GOOS and GOARCH when scanning for a cross-compilation target.
+func LoadEnv(dir string, env []string) (*DependencyGraph, error) {
 	g := NewDependencyGraph()
 
-	pkgs, err := listPackages(dir)
+	pkgs, err := listPackages(dir, env)
 	if err != nil {
 		return nil, fmt.Errorf("go list packages: %w", err)
 	}
@@ -49,6 +60,9 @@ func Load(dir string) (*DependencyGraph, error) {
 			Module:     mod,
 			Dir:        lp.Dir,
 			GoFiles:    lp.GoFiles,
+			CgoFiles:   lp.CgoFiles,
+			CFiles:     append(lp.CFiles, lp.HFiles...),
+			SFiles:     lp.SFiles,
 			Imports:    lp.Imports,
 			Deps:       lp.Deps,
 		}
@@ -90,9 +104,20 @@ func ensureModule(g *DependencyGraph, lm *listModule) *Module {
 	return m
 }
 
-func listPackages(dir string) ([]listPackage, error) {
+// Environ returns the environment Go tooling should run with so that it sees
+// the same files as LoadEnv(dir, env): nil (inherit the process environment)
+// when env is empty.
+func Environ(env []string) []string {
+	if len(env) == 0 {
+		return nil
+	}
+	return append(os.Environ(), env...)
+}
+
+func listPackages(dir string, env []string) ([]listPackage, error) {
 	cmd := exec.Command("go", "list", "-json", "-deps", "./...")
 	cmd.Dir = dir
+	cmd.Env = Environ(env)
 	out, err := cmd.Output()
 	if err != nil {
 		return nil, err
@@ -0,0 +1,136 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/1homsi/gorisk/internal/report"
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/module"
+)
+
+const (
+	// abandonedAfter is how long a module may go without a release before
+	// it is flagged as potentially abandoned.
+	abandonedAfter = 24 * 30 * 24 * time.Hour
+	// abandonedGoLag is how many Go minor releases a module's go directive
+	// may trail the toolchain gorisk was built with.
+	abandonedGoLag = 8
+	// abandonedPenalty is the health score penalty for an abandoned module.
+	abandonedPenalty = -20
+)
+
+type proxyInfo struct {
+	Version string    `json:"Version"`
+	Time    time.Time `json:"Time"`
+}
+
+// fetchLatestRelease returns the latest version of modulePath on the module
+// proxies in goproxy, a GOPROXY list, and when it was published.
+func fetchLatestRelease(ctx context.Context, goproxy, modulePath string) (proxyInfo, error) {
+	var info proxyInfo
+	esc, err := module.EscapePath(modulePath)
+	if err != nil {
+		return info, err
+	}
+	body, err := proxyFetch(ctx, goproxy, esc+"/@latest")
+	if err != nil {
+		return info, err
+	}
+	err = json.Unmarshal(body, &info)
+	return info, err
+}
+
+// fetchGoDirective returns the go directive of modulePath's go.mod at
+// version, or "" when it has none.
+func fetchGoDirective(ctx context.Context, goproxy, modulePath, version string) (string, error) {
+	esc, err := module.EscapePath(modulePath)
+	if err != nil {
+		return "", err
+	}
+	escVer, err := module.EscapeVersion(version)
+	if err != nil {
+		return "", err
+	}
+	body, err := proxyFetch(ctx, goproxy, esc+"/@v/"+escVer+".mod")
+	if err != nil {
+		return "", err
+	}
+	f, err := modfile.ParseLax("go.mod", body, nil)
+	if err != nil {
+		return "", err
+	}
+	if f.Go == nil {
+		return "", nil
+	}
+	return f.Go.Version, nil
+}
+
+// abandonedReason returns why a module whose latest release was published
+// at released and whose go.mod declares goVersion looks unmaintained as of
+// now, or "" when it does not. toolchain is the current Go version
+// (runtime.Version() outside tests).
+func abandonedReason(released time.Time, goVersion, toolchain string, now time.Time) string {
+	var reasons []string
+	if !released.IsZero() && now.Sub(released) > abandonedAfter {
+		reasons = append(reasons, fmt.Sprintf("no release since %s", released.Format("2006-01")))
+	}
+	if mod, ok := goMinor(goVersion); ok {
+		if cur, ok := goMinor(toolchain); ok && cur-mod > abandonedGoLag {
+			reasons = append(reasons, fmt.Sprintf("go.mod targets go %s, %d releases behind", goVersion, cur-mod))
+		}
+	}
+	return strings.Join(reasons, "; ")
+}
+
+// goMinor returns the minor version of a Go 1.x version ("1.21.3",
+// "go1.22rc1"), or false when v is not one.
+func goMinor(v string) (int, bool) {
+	v = strings.TrimPrefix(v, "go")
+	rest, ok := strings.CutPrefix(v, "1.")
+	if !ok {
+		return 0, false
+	}
+	end := 0
+	for end < len(rest) && rest[end] >= '0' && rest[end] <= '9' {
+		end++
+	}
+	n, err := strconv.Atoi(rest[:end])
+	return n, err == nil
+}
+
+// checkAbandoned looks up modulePath on the module proxies in goproxy, the
+// go command's GOPROXY, and returns the abandonment reason, or "" when the
+// module looks maintained or no proxy has an answer.
+func checkAbandoned(ctx context.Context, goproxy, modulePath string, t *HealthTiming) string {
+	t0 := time.Now()
+	defer func() { t.ProxyTime += time.Since(t0) }()
+
+	latest, err := fetchLatestRelease(ctx, goproxy, modulePath)
+	t.ProxyCalls++
+	if err != nil {
+		return ""
+	}
+	goVersion, err := fetchGoDirective(ctx, goproxy, modulePath, latest.Version)
+	t.ProxyCalls++
+	if err != nil {
+		goVersion = ""
+	}
+	return abandonedReason(latest.Time, goVersion, runtime.Version(), time.Now())
+}
+
+// markAbandoned records on hr whether modulePath looks abandoned on the
+// module proxies in goproxy, with the abandoned signal and its penalty.
+func markAbandoned(ctx context.Context, hr *report.HealthReport, goproxy, modulePath string, t *HealthTiming) {
+	if reason := checkAbandoned(ctx, goproxy, modulePath, t); reason != "" {
+		hr.Abandoned = true
+		hr.AbandonedReason = reason
+		hr.Score += abandonedPenalty
+		hr.Signals["abandoned"] = abandonedPenalty
+	}
+}
@@ -1,19 +1,23 @@
 package health
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"os"
 	"strings"
 	"time"
+
+	"golang.org/x/mod/semver"
 )
 
 type ghRepo struct {
-	PushedAt   time.Time `json:"pushed_at"`
-	Archived   bool      `json:"archived"`
-	OpenIssues int       `json:"open_issues_count"`
-	UpdatedAt  time.Time `json:"updated_at"`
+	PushedAt      time.Time `json:"pushed_at"`
+	Archived      bool      `json:"archived"`
+	OpenIssues    int       `json:"open_issues_count"`
+	UpdatedAt     time.Time `json:"updated_at"`
+	DefaultBranch string    `json:"default_branch"`
 }
 
 type ghRelease struct {
@@ -22,18 +26,31 @@ type ghRelease struct {
 
 type osvResponse struct {
 	Vulns []struct {
-		ID      string   `json:"id"`
-		Aliases []string `json:"aliases"`
-		Summary string   `json:"summary"`
+		ID       string   `json:"id"`
+		Aliases  []string `json:"aliases"`
+		Summary  string   `json:"summary"`
+		Affected []struct {
+			Ranges []struct {
+				Events []struct {
+					Fixed string `json:"fixed"`
+				} `json:"events"`
+			} `json:"ranges"`
+		} `json:"affected"`
 	} `json:"vulns"`
 }
 
+// API endpoints; overridden in tests to point at stub servers.
+var (
+	githubAPIBase = "https://api.github.com"
+	osvQueryURL   = "https://api.osv.dev/v1/query"
+)
+
 func githubToken() string {
 	return os.Getenv("GORISK_GITHUB_TOKEN")
 }
 
-func ghRequest(url string) (*http.Response, error) {
-	req, err := http.NewRequest("GET", url, nil)
+func ghRequest(ctx context.Context, url string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -57,9 +74,9 @@ func checkGHStatus(resp *http.Response, context string) error {
 	}
 }
 
-func fetchGHRepo(owner, repo string) (*ghRepo, error) {
-	url := fmt.Sprintf("https://api.github.com/repos/%s/%s", owner, repo)
-	resp, err := ghRequest(url)
+func fetchGHRepo(ctx context.Context, owner, repo string) (*ghRepo, error) {
+	url := fmt.Sprintf("%s/repos/%s/%s", githubAPIBase, owner, repo)
+	resp, err := ghRequest(ctx, url)
 	if err != nil {
 		return nil, err
 	}
@@ -74,9 +91,9 @@ func fetchGHRepo(owner, repo string) (*ghRepo, error) {
 	return &r, nil
 }
 
-func fetchGHReleases(owner, repo string) ([]ghRelease, error) {
-	url := fmt.Sprintf("https://api.github.com/repos/%s/%s/releases?per_page=10", owner, repo)
-	resp, err := ghRequest(url)
+func fetchGHReleases(ctx context.Context, owner, repo string) ([]ghRelease, error) {
+	url := fmt.Sprintf("%s/repos/%s/%s/releases?per_page=10", githubAPIBase, owner, repo)
+	resp, err := ghRequest(ctx, url)
 	if err != nil {
 		return nil, err
 	}
@@ -91,22 +108,45 @@ func fetchGHReleases(owner, repo string) ([]ghRelease, error) {
 	return releases, nil
 }
 
-func fetchOSVVulns(modulePath string) ([]string, error) {
+// fetchOSVVulns returns the OSV advisory IDs for modulePath, their aliases
+// (CVE and GHSA IDs) keyed by advisory ID, and the highest version listed as
+// fixing any of them ("" when no advisory has a fix).
+func fetchOSVVulns(ctx context.Context, modulePath string) ([]string, map[string][]string, string, error) {
 	body := strings.NewReader(fmt.Sprintf(`{"package":{"name":%q,"ecosystem":"Go"}}`, modulePath))
-	resp, err := http.Post("https://api.osv.dev/v1/query", "application/json", body)
+	req, err := http.NewRequestWithContext(ctx, "POST", osvQueryURL, body)
 	if err != nil {
-		return nil, err
+		return nil, nil, "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, nil, "", err
 	}
 	defer resp.Body.Close()
 	var out osvResponse
 	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
-		return nil, err
+		return nil, nil, "", err
 	}
 	ids := make([]string, 0, len(out.Vulns))
+	aliases := make(map[string][]string)
+	fixed := ""
 	for _, v := range out.Vulns {
 		ids = append(ids, v.ID)
+		if len(v.Aliases) > 0 {
+			aliases[v.ID] = v.Aliases
+		}
+		for _, a := range v.Affected {
+			for _, r := range a.Ranges {
+				for _, e := range r.Events {
+					// OSV's Go ecosystem omits the "v" prefix.
+					if ver := "v" + strings.TrimPrefix(e.Fixed, "v"); e.Fixed != "" && semver.IsValid(ver) && semver.Compare(ver, fixed) > 0 {
+						fixed = ver
+					}
+				}
+			}
+		}
 	}
-	return ids, nil
+	return ids, aliases, fixed, nil
 }
 
 func githubOwnerRepo(modulePath string) (string, string, bool) {
@@ -0,0 +1,80 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// defaultGoProxy is GOPROXY when the go command has none configured.
+const defaultGoProxy = "https://proxy.golang.org,direct"
+
+// errNoProxy is returned when GOPROXY sends a lookup to "off" or "direct"
+// before any proxy answers: gorisk does not fetch from version control.
+var errNoProxy = errors.New("no module proxy to ask")
+
+// goProxy returns the GOPROXY the go command is configured with, including
+// a value saved with `go env -w`.
+func goProxy() string {
+	if v := strings.TrimSpace(goEnv("GOPROXY")[0]); v != "" {
+		return v
+	}
+	return defaultGoProxy
+}
+
+// proxyFetch fetches path (e.g. "<escaped module>/@latest") from the
+// module proxies in list, a GOPROXY value, with the go command's rules:
+// proxies are tried in order; after one separated by a comma the next is
+// tried only on a 404 or 410, after a pipe on any error. "off" and
+// "direct" end the lookup with errNoProxy.
+func proxyFetch(ctx context.Context, list, path string) ([]byte, error) {
+	err := errNoProxy
+	for list != "" {
+		var entry string
+		fallbackOnError := false
+		if i := strings.IndexAny(list, ",|"); i >= 0 {
+			entry, fallbackOnError, list = list[:i], list[i] == '|', list[i+1:]
+		} else {
+			entry, list = list, ""
+		}
+		switch entry = strings.TrimSpace(entry); entry {
+		case "":
+			continue
+		case "off", "direct":
+			return nil, fmt.Errorf("GOPROXY %s: %w", entry, errNoProxy)
+		}
+		var body []byte
+		var notFound bool
+		body, notFound, err = proxyRequest(ctx, strings.TrimSuffix(entry, "/")+"/"+path)
+		if err == nil {
+			return body, nil
+		}
+		if !notFound && !fallbackOnError {
+			return nil, err
+		}
+	}
+	return nil, err
+}
+
+// proxyRequest fetches url from one module proxy. notFound reports a 404 or
+// 410, the answers after which GOPROXY falls back to the next proxy.
+func proxyRequest(ctx context.Context, url string) (body []byte, notFound bool, err error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, false, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, false, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		gone := resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone
+		return nil, gone, fmt.Errorf("module proxy %d for %s", resp.StatusCode, url)
+	}
+	body, err = io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	return body, false, err
+}
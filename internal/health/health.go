@@ -1,6 +1,7 @@
 package health
 
 import (
+	"context"
 	"crypto/sha256"
 	"encoding/json"
 	"fmt"
@@ -9,6 +10,7 @@ import (
 
 	"github.com/1homsi/gorisk/internal/cache"
 	"github.com/1homsi/gorisk/internal/report"
+	"golang.org/x/mod/module"
 )
 
 type ModuleRef struct {
@@ -21,10 +23,14 @@ type HealthTiming struct {
 	Total       time.Duration
 	GithubCalls int
 	OsvCalls    int
+	ProxyCalls  int
 	GithubTime  time.Duration
 	OsvTime     time.Duration
+	ProxyTime   time.Duration
 	Workers     int
 	ModuleCount int
+	Scored      int  // modules that finished scoring before cancellation
+	TimedOut    bool // ctx was cancelled or its deadline passed; results are partial
 }
 
 const healthCacheTTL = 24 * time.Hour
@@ -37,7 +43,10 @@ func healthCacheKey(modulePath, version string) string {
 }
 
 // ScoreAll scores all modules in parallel and returns health reports with timing data.
-func ScoreAll(mods []ModuleRef) ([]report.HealthReport, HealthTiming) {
+// When ctx is cancelled or its deadline passes, in-flight API calls are aborted,
+// queued modules are skipped, and only the reports completed so far are returned
+// with TimedOut set.
+func ScoreAll(ctx context.Context, mods []ModuleRef) ([]report.HealthReport, HealthTiming) {
 	if len(mods) == 0 {
 		return nil, HealthTiming{}
 	}
@@ -46,6 +55,7 @@ func ScoreAll(mods []ModuleRef) ([]report.HealthReport, HealthTiming) {
 		idx    int
 		hr     report.HealthReport
 		timing HealthTiming
+		ok     bool
 	}
 
 	results := make([]report.HealthReport, len(mods))
@@ -63,6 +73,7 @@ func ScoreAll(mods []ModuleRef) ([]report.HealthReport, HealthTiming) {
 	}
 
 	t0 := time.Now()
+	private, goproxy := privatePatterns(), goProxy()
 
 	var wg sync.WaitGroup
 	wg.Add(workers)
@@ -70,8 +81,11 @@ func ScoreAll(mods []ModuleRef) ([]report.HealthReport, HealthTiming) {
 		go func() {
 			defer wg.Done()
 			for i := range jobs {
-				hr, t := scoreWithTiming(mods[i].Path, mods[i].Version)
-				resChan <- result{idx: i, hr: hr, timing: t}
+				if ctx.Err() != nil {
+					continue
+				}
+				hr, t := scoreWithTiming(ctx, mods[i].Path, mods[i].Version, private, goproxy)
+				resChan <- result{idx: i, hr: hr, timing: t, ok: ctx.Err() == nil}
 			}
 		}()
 	}
@@ -82,23 +96,51 @@ func ScoreAll(mods []ModuleRef) ([]report.HealthReport, HealthTiming) {
 	}()
 
 	var total HealthTiming
+	done := make([]bool, len(mods))
 	for r := range resChan {
 		results[r.idx] = r.hr
+		done[r.idx] = r.ok
 		total.GithubCalls += r.timing.GithubCalls
 		total.OsvCalls += r.timing.OsvCalls
 		total.GithubTime += r.timing.GithubTime
 		total.OsvTime += r.timing.OsvTime
+		total.ProxyCalls += r.timing.ProxyCalls
+		total.ProxyTime += r.timing.ProxyTime
 	}
 	total.Total = time.Since(t0)
 	total.Workers = workers
 	total.ModuleCount = len(mods)
 
+	if ctx.Err() != nil {
+		total.TimedOut = true
+		partial := make([]report.HealthReport, 0, len(mods))
+		for i, ok := range done {
+			if ok {
+				partial = append(partial, results[i])
+			}
+		}
+		results = partial
+	}
+	total.Scored = len(results)
+
 	return results, total
 }
 
 // scoreWithTiming scores a single module, consulting the file-backed cache first.
-// On a cache miss it fetches from GitHub/OSV and stores the result for 24 h.
-func scoreWithTiming(modulePath, version string) (report.HealthReport, HealthTiming) {
+// On a cache miss it fetches from GitHub, the module proxy, and OSV and stores
+// the result for 24 h.
+// Results computed while ctx was cancelled are not cached. Modules matching
+// the private patterns skip the GitHub and OSV lookups and are reported as
+// unknown, but are still checked for abandonment on goproxy, the GOPROXY
+// list release lookups go through, which may be an internal proxy.
+func scoreWithTiming(ctx context.Context, modulePath, version, private, goproxy string) (report.HealthReport, HealthTiming) {
+	if isPrivate(private, modulePath) {
+		var t HealthTiming
+		hr := unknownHealth(modulePath, version)
+		markAbandoned(ctx, &hr, goproxy, modulePath, &t)
+		return hr, t
+	}
+
 	key := healthCacheKey(modulePath, version)
 
 	// Cache read — return immediately on hit.
@@ -121,7 +163,7 @@ func scoreWithTiming(modulePath, version string) (report.HealthReport, HealthTim
 	owner, repo, isGH := githubOwnerRepo(modulePath)
 	if isGH {
 		t0 := time.Now()
-		ghRepo, err := fetchGHRepo(owner, repo)
+		ghRepo, err := fetchGHRepo(ctx, owner, repo)
 		t.GithubTime += time.Since(t0)
 		t.GithubCalls++
 
@@ -149,7 +191,7 @@ func scoreWithTiming(modulePath, version string) (report.HealthReport, HealthTim
 			hr.Signals["commit_age"] = agePenalty
 
 			t1 := time.Now()
-			releases, err := fetchGHReleases(owner, repo)
+			releases, err := fetchGHReleases(ctx, owner, repo)
 			t.GithubTime += time.Since(t1)
 			t.GithubCalls++
 
@@ -166,18 +208,28 @@ func scoreWithTiming(modulePath, version string) (report.HealthReport, HealthTim
 				hr.Score += releaseBonus
 				hr.Signals["release_frequency"] = releaseBonus
 			}
+
+			if module.IsPseudoVersion(version) {
+				checkPinnedCommit(ctx, &hr, owner, repo, ghRepo.DefaultBranch, &t)
+			}
 		}
 	}
 
+	markAbandoned(ctx, &hr, goproxy, modulePath, &t)
+
 	t2 := time.Now()
-	cveIDs, err := fetchOSVVulns(modulePath)
+	cveIDs, aliases, fixed, err := fetchOSVVulns(ctx, modulePath)
 	t.OsvTime += time.Since(t2)
 	t.OsvCalls++
 
 	if err == nil {
 		hr.CVECount = len(cveIDs)
 		hr.CVEs = cveIDs
-		penalty := -30 * len(cveIDs)
+		hr.FixedVersion = fixed
+		if len(aliases) > 0 {
+			hr.Aliases = aliases
+		}
+		penalty := cvePenalty * len(cveIDs)
 		hr.Score += penalty
 		hr.Signals["cve_count"] = penalty
 	}
@@ -189,6 +241,10 @@ func scoreWithTiming(modulePath, version string) (report.HealthReport, HealthTim
 		hr.Score = 100
 	}
 
+	if ctx.Err() != nil {
+		return hr, t
+	}
+
 	// Cache write — best-effort; ignore errors.
 	if encoded, err := json.Marshal(hr); err == nil {
 		_ = cache.Set(key, encoded, healthCacheTTL)
@@ -199,6 +255,6 @@ func scoreWithTiming(modulePath, version string) (report.HealthReport, HealthTim
 
 // Score is the public single-module scorer (kept for external callers).
 func Score(modulePath, version string) report.HealthReport {
-	hr, _ := scoreWithTiming(modulePath, version)
+	hr, _ := scoreWithTiming(context.Background(), modulePath, version, privatePatterns(), goProxy())
 	return hr
 }
@@ -1,12 +1,21 @@
 package health
 
 import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
+
+	"golang.org/x/mod/module"
 )
 
 func TestScoreAllEmptyModules(t *testing.T) {
-	reports, timing := ScoreAll(nil)
+	reports, timing := ScoreAll(context.Background(), nil)
 	if reports != nil {
 		t.Errorf("expected nil reports for empty input, got %v", reports)
 	}
@@ -20,7 +29,7 @@ func TestScoreAllReturnsTiming(t *testing.T) {
 	mods := []ModuleRef{
 		{Path: "example.com/nonexistent-for-testing-12345", Version: "v1.0.0"},
 	}
-	reports, timing := ScoreAll(mods)
+	reports, timing := ScoreAll(context.Background(), mods)
 
 	if len(reports) != 1 {
 		t.Fatalf("expected 1 report, got %d", len(reports))
@@ -42,7 +51,7 @@ func TestScoreAllWorkerCount(t *testing.T) {
 	for i := range mods {
 		mods[i] = ModuleRef{Path: "example.com/test", Version: "v1.0.0"}
 	}
-	_, timing := ScoreAll(mods)
+	_, timing := ScoreAll(context.Background(), mods)
 	if timing.Workers != 3 {
 		t.Errorf("expected Workers=3 for 3 modules, got %d", timing.Workers)
 	}
@@ -54,7 +63,7 @@ func TestScoreAllParallelTiming(t *testing.T) {
 		mods[i] = ModuleRef{Path: "example.com/t", Version: "v1.0.0"}
 	}
 	start := time.Now()
-	reports, timing := ScoreAll(mods)
+	reports, timing := ScoreAll(context.Background(), mods)
 	elapsed := time.Since(start)
 
 	if len(reports) != 5 {
@@ -72,3 +81,263 @@ func TestHealthTimingFields(t *testing.T) {
 		t.Error("zero-value HealthTiming should have zero call counts")
 	}
 }
+
+func TestScoreAllHonorsDeadline(t *testing.T) {
+	t.Setenv("HOME", t.TempDir()) // isolate the file-backed cache
+
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-release:
+		}
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	origGH, origOSV := githubAPIBase, osvQueryURL
+	githubAPIBase, osvQueryURL = srv.URL, srv.URL
+	defer func() { githubAPIBase, osvQueryURL = origGH, origOSV }()
+
+	mods := make([]ModuleRef, 25)
+	for i := range mods {
+		mods[i] = ModuleRef{Path: "github.com/stub/slow", Version: "v1.0.0"}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	reports, timing := ScoreAll(ctx, mods)
+	elapsed := time.Since(start)
+
+	if elapsed > 2*time.Second {
+		t.Errorf("ScoreAll took %v, want it to stop near the 100ms deadline", elapsed)
+	}
+	if !timing.TimedOut {
+		t.Error("expected TimedOut to be set")
+	}
+	if len(reports) != 0 || timing.Scored != 0 {
+		t.Errorf("expected no completed reports, got %d (scored=%d)", len(reports), timing.Scored)
+	}
+}
+
+func TestScoreAllSkipsPrivateModules(t *testing.T) {
+	t.Setenv("HOME", t.TempDir()) // isolate the file-backed cache
+	t.Setenv("GOPRIVATE", "*.corp.example.com,github.com/acme/*")
+	t.Setenv("GONOPROXY", "")
+	t.Setenv("GONOSUMDB", "")
+	t.Setenv("GOFLAGS", "")
+
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer srv.Close()
+
+	// The configured GOPROXY, e.g. an internal proxy, is still asked.
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/github.com/acme/billing/@latest":
+			fmt.Fprint(w, `{"Version":"v1.2.0","Time":"2019-03-01T10:00:00Z"}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer proxy.Close()
+	t.Setenv("GOPROXY", proxy.URL)
+
+	origGH, origOSV := githubAPIBase, osvQueryURL
+	githubAPIBase, osvQueryURL = srv.URL, srv.URL
+	defer func() { githubAPIBase, osvQueryURL = origGH, origOSV }()
+
+	mods := []ModuleRef{
+		{Path: "github.com/acme/billing", Version: "v1.2.0"},
+		{Path: "git.corp.example.com/team/lib", Version: "v0.3.0"},
+	}
+	reports, timing := ScoreAll(context.Background(), mods)
+
+	if n := calls.Load(); n != 0 {
+		t.Errorf("private modules made %d public API calls, want 0", n)
+	}
+	if len(reports) != len(mods) {
+		t.Fatalf("expected %d reports, got %d", len(mods), len(reports))
+	}
+	for _, hr := range reports {
+		if !hr.Unknown {
+			t.Errorf("%s: expected health unknown, got score %d", hr.Module, hr.Score)
+		}
+	}
+	if timing.ProxyCalls == 0 {
+		t.Error("private modules made no GOPROXY lookups")
+	}
+	if billing := reports[0]; !billing.Abandoned || !strings.Contains(billing.AbandonedReason, "no release since 2019-03") {
+		t.Errorf("private module with an old release on GOPROXY: Abandoned=%v reason=%q", billing.Abandoned, billing.AbandonedReason)
+	}
+	if reports[1].Abandoned {
+		t.Errorf("private module unknown to GOPROXY flagged abandoned: %s", reports[1].AbandonedReason)
+	}
+
+	if isPrivate(privatePatterns(), "github.com/other/pkg") {
+		t.Error("github.com/other/pkg should not match GOPRIVATE")
+	}
+}
+
+func TestProxyFetchFollowsGOPROXY(t *testing.T) {
+	status := func(code int) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(code)
+			fmt.Fprint(w, `{"Version":"v1.0.0"}`)
+		}))
+	}
+	ok, missing, broken := status(http.StatusOK), status(http.StatusNotFound), status(http.StatusInternalServerError)
+	defer ok.Close()
+	defer missing.Close()
+	defer broken.Close()
+
+	tests := []struct {
+		name   string
+		list   string
+		wantOK bool
+	}{
+		{"single proxy", ok.URL, true},
+		{"comma falls back on 404", missing.URL + "," + ok.URL, true},
+		{"comma stops on other errors", broken.URL + "," + ok.URL, false},
+		{"pipe falls back on any error", broken.URL + "|" + ok.URL, true},
+		{"off", "off", false},
+		{"direct", "direct," + ok.URL, false},
+		{"direct after a miss", missing.URL + ",direct", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, err := proxyFetch(context.Background(), tt.list, "example.com/lib/@latest")
+			if (err == nil) != tt.wantOK {
+				t.Fatalf("proxyFetch(%q) = %q, %v; want ok=%v", tt.list, body, err, tt.wantOK)
+			}
+		})
+	}
+
+	t.Setenv("GOPROXY", "off")
+	if got := goProxy(); got != "off" {
+		t.Errorf("goProxy() = %q, want the GOPROXY setting off", got)
+	}
+}
+
+func TestScoreAllFlagsAbandonedModule(t *testing.T) {
+	t.Setenv("HOME", t.TempDir()) // isolate the file-backed cache
+	t.Setenv("GOPRIVATE", "")
+	t.Setenv("GONOPROXY", "")
+	t.Setenv("GONOSUMDB", "")
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/example.com/old/lib/@latest":
+			fmt.Fprint(w, `{"Version":"v1.4.0","Time":"2019-03-01T10:00:00Z"}`)
+		case "/example.com/old/lib/@v/v1.4.0.mod":
+			fmt.Fprint(w, "module example.com/old/lib\n\ngo 1.12\n")
+		case "/example.com/new/lib/@latest":
+			fmt.Fprintf(w, `{"Version":"v2.0.0","Time":%q}`, time.Now().Add(-30*24*time.Hour).Format(time.RFC3339))
+		case "/example.com/new/lib/@v/v2.0.0.mod":
+			fmt.Fprintf(w, "module example.com/new/lib\n\ngo %s\n", strings.TrimPrefix(runtime.Version(), "go"))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	t.Setenv("GOPROXY", srv.URL)
+	origGH, origOSV := githubAPIBase, osvQueryURL
+	githubAPIBase, osvQueryURL = srv.URL, srv.URL
+	defer func() { githubAPIBase, osvQueryURL = origGH, origOSV }()
+
+	reports, timing := ScoreAll(context.Background(), []ModuleRef{
+		{Path: "example.com/old/lib", Version: "v1.4.0"},
+		{Path: "example.com/new/lib", Version: "v2.0.0"},
+	})
+	if timing.ProxyCalls != 4 {
+		t.Errorf("ProxyCalls = %d, want 4", timing.ProxyCalls)
+	}
+
+	old := reports[0]
+	if !old.Abandoned || old.Signals["abandoned"] != abandonedPenalty {
+		t.Fatalf("old module: Abandoned=%v signals=%v, want abandoned signal", old.Abandoned, old.Signals)
+	}
+	if !strings.Contains(old.AbandonedReason, "no release since 2019-03") || !strings.Contains(old.AbandonedReason, "go 1.12") {
+		t.Errorf("old module reason = %q", old.AbandonedReason)
+	}
+	if old.Score != 100+abandonedPenalty {
+		t.Errorf("old module score = %d, want %d", old.Score, 100+abandonedPenalty)
+	}
+
+	if fresh := reports[1]; fresh.Abandoned {
+		t.Errorf("recently released module flagged as abandoned: %s", fresh.AbandonedReason)
+	}
+
+	// GOPROXY=off keeps release lookups off the network, as it does for the
+	// go command.
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("GOPROXY", "off")
+	reports, _ = ScoreAll(context.Background(), []ModuleRef{{Path: "example.com/old/lib", Version: "v1.4.0"}})
+	if reports[0].Abandoned {
+		t.Errorf("GOPROXY=off: module flagged abandoned from a proxy lookup: %s", reports[0].AbandonedReason)
+	}
+}
+
+func TestScoreAllPseudoVersionCommitSignals(t *testing.T) {
+	t.Setenv("HOME", t.TempDir()) // isolate the file-backed cache
+	t.Setenv("GOPRIVATE", "")
+	t.Setenv("GONOPROXY", "")
+	t.Setenv("GONOSUMDB", "")
+
+	stale := module.PseudoVersion("v0", "", time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC), "abcdef123456")
+	fresh := module.PseudoVersion("v1", "v1.2.0", time.Now().Add(-48*time.Hour).UTC(), "0123456789ab")
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/acme/stale", "/repos/acme/fresh":
+			fmt.Fprintf(w, `{"pushed_at":%q,"default_branch":"main"}`, time.Now().Format(time.RFC3339))
+		case "/repos/acme/stale/releases", "/repos/acme/fresh/releases":
+			fmt.Fprint(w, `[]`)
+		case "/repos/acme/stale/commits/abcdef123456":
+			fmt.Fprint(w, `{"sha":"abcdef1234567890","commit":{"committer":{"date":"2022-12-31T23:00:00Z"}}}`)
+		case "/repos/acme/stale/compare/main...abcdef123456":
+			fmt.Fprint(w, `{"status":"diverged"}`)
+		case "/repos/acme/fresh/compare/main...0123456789ab":
+			fmt.Fprint(w, `{"status":"behind"}`)
+		default:
+			// The fresh commit lookup fails: its date comes from the
+			// pseudo-version instead.
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	t.Setenv("GOPROXY", srv.URL)
+	origGH, origOSV := githubAPIBase, osvQueryURL
+	githubAPIBase, osvQueryURL = srv.URL, srv.URL
+	defer func() { githubAPIBase, osvQueryURL = origGH, origOSV }()
+
+	reports, _ := ScoreAll(context.Background(), []ModuleRef{
+		{Path: "github.com/acme/stale", Version: stale},
+		{Path: "github.com/acme/fresh", Version: fresh},
+	})
+
+	old := reports[0]
+	if old.PinnedCommit != "abcdef123456" || !old.OffBranch {
+		t.Errorf("stale: PinnedCommit=%q OffBranch=%v, want abcdef123456 off the default branch", old.PinnedCommit, old.OffBranch)
+	}
+	if old.Signals["pinned_commit_age"] != -30 || old.Signals["pinned_commit_branch"] != pinnedCommitOffBranchPenalty {
+		t.Errorf("stale: signals = %v, want pinned_commit_age -30 and pinned_commit_branch %d", old.Signals, pinnedCommitOffBranchPenalty)
+	}
+
+	recent := reports[1]
+	if recent.PinnedCommit != "0123456789ab" || recent.OffBranch {
+		t.Errorf("fresh: PinnedCommit=%q OffBranch=%v, want 0123456789ab on the default branch", recent.PinnedCommit, recent.OffBranch)
+	}
+	if age, ok := recent.Signals["pinned_commit_age"]; !ok || age != 0 {
+		t.Errorf("fresh: signals = %v, want pinned_commit_age 0 from the pseudo-version timestamp", recent.Signals)
+	}
+	if branch, ok := recent.Signals["pinned_commit_branch"]; !ok || branch != 0 {
+		t.Errorf("fresh: signals = %v, want pinned_commit_branch 0", recent.Signals)
+	}
+}
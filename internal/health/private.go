@@ -0,0 +1,64 @@
+package health
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/1homsi/gorisk/internal/report"
+	"golang.org/x/mod/module"
+)
+
+// privateEnvVars are the go command settings that mark module paths as
+// private. Such modules are not on the public proxy, checksum database,
+// OSV, or (usually) public GitHub. GONOSUMCHECK is the pre-release name of
+// GONOSUMDB and is still honoured when set in the environment.
+var privateEnvVars = []string{"GOPRIVATE", "GONOPROXY", "GONOSUMDB"}
+
+// goEnv returns the go command's settings for names, one value per name,
+// including values saved with `go env -w`. It falls back to the process
+// environment when the go command is missing.
+func goEnv(names ...string) []string {
+	if out, err := exec.Command("go", append([]string{"env"}, names...)...).Output(); err == nil {
+		if vals := strings.Split(string(out), "\n"); len(vals) >= len(names) {
+			return vals[:len(names)]
+		}
+	}
+	vals := make([]string, len(names))
+	for i, name := range names {
+		vals[i] = os.Getenv(name)
+	}
+	return vals
+}
+
+// privatePatterns returns the comma-separated private module path patterns
+// configured for the go command.
+func privatePatterns() string {
+	vals := append(goEnv(privateEnvVars...), os.Getenv("GONOSUMCHECK"))
+
+	var patterns []string
+	for _, v := range vals {
+		if v = strings.TrimSpace(v); v != "" {
+			patterns = append(patterns, v)
+		}
+	}
+	return strings.Join(patterns, ",")
+}
+
+// isPrivate reports whether modulePath matches one of the glob patterns,
+// using the go command's prefix matching (e.g. "*.corp.example.com,
+// github.com/acme/*").
+func isPrivate(patterns, modulePath string) bool {
+	return patterns != "" && module.MatchPrefixPatterns(patterns, modulePath)
+}
+
+// unknownHealth is the report for a private module: GitHub and OSV lookups
+// would 404 and mis-score it, so it is marked unknown instead of scored.
+func unknownHealth(modulePath, version string) report.HealthReport {
+	return report.HealthReport{
+		Module:  modulePath,
+		Version: version,
+		Unknown: true,
+		Signals: map[string]int{},
+	}
+}
@@ -0,0 +1,129 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/1homsi/gorisk/internal/report"
+	"golang.org/x/mod/module"
+)
+
+const (
+	// pinnedCommitOffBranchPenalty is the health score penalty for a
+	// pseudo-version whose commit is not on the repository's default
+	// branch: a fork, a feature branch, or a commit since force-pushed away.
+	pinnedCommitOffBranchPenalty = -10
+)
+
+type ghCommit struct {
+	SHA    string `json:"sha"`
+	Commit struct {
+		Committer struct {
+			Date time.Time `json:"date"`
+		} `json:"committer"`
+	} `json:"commit"`
+}
+
+type ghCompare struct {
+	Status string `json:"status"` // ahead | behind | identical | diverged
+}
+
+func fetchGHCommit(ctx context.Context, owner, repo, rev string) (*ghCommit, error) {
+	url := fmt.Sprintf("%s/repos/%s/%s/commits/%s", githubAPIBase, owner, repo, rev)
+	resp, err := ghRequest(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if err := checkGHStatus(resp, url); err != nil {
+		return nil, err
+	}
+	var c ghCommit
+	if err := json.NewDecoder(resp.Body).Decode(&c); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// fetchGHCompare returns how rev relates to base: "behind" or "identical"
+// means rev is reachable from base.
+func fetchGHCompare(ctx context.Context, owner, repo, base, rev string) (string, error) {
+	url := fmt.Sprintf("%s/repos/%s/%s/compare/%s...%s", githubAPIBase, owner, repo, base, rev)
+	resp, err := ghRequest(ctx, url)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if err := checkGHStatus(resp, url); err != nil {
+		return "", err
+	}
+	var c ghCompare
+	if err := json.NewDecoder(resp.Body).Decode(&c); err != nil {
+		return "", err
+	}
+	return c.Status, nil
+}
+
+// pinnedCommitAgePenalty scores how old the commit a pseudo-version pins
+// is, on the same scale as the repository's own commit_age.
+func pinnedCommitAgePenalty(committed, now time.Time) int {
+	switch days := int(now.Sub(committed).Hours() / 24); {
+	case days > 730:
+		return -30
+	case days > 365:
+		return -15
+	case days > 180:
+		return -5
+	default:
+		return 0
+	}
+}
+
+// checkPinnedCommit adds the pinned_commit_age and pinned_commit_branch
+// signals for a module required at a pseudo-version, which has no release
+// for the other signals to describe. The commit's date comes from GitHub,
+// or from the timestamp embedded in the pseudo-version when GitHub has no
+// answer. Whether the commit is on defaultBranch, where releases are cut,
+// is asked of GitHub's compare API and skipped when it has no answer.
+func checkPinnedCommit(ctx context.Context, hr *report.HealthReport, owner, repo, defaultBranch string, t *HealthTiming) {
+	rev, err := module.PseudoVersionRev(hr.Version)
+	if err != nil {
+		return
+	}
+	committed, err := module.PseudoVersionTime(hr.Version)
+	if err != nil {
+		return
+	}
+	hr.PinnedCommit = rev
+
+	t0 := time.Now()
+	c, err := fetchGHCommit(ctx, owner, repo, rev)
+	t.GithubTime += time.Since(t0)
+	t.GithubCalls++
+	if err == nil && !c.Commit.Committer.Date.IsZero() {
+		committed = c.Commit.Committer.Date
+	}
+	penalty := pinnedCommitAgePenalty(committed, time.Now())
+	hr.Score += penalty
+	hr.Signals["pinned_commit_age"] = penalty
+
+	if defaultBranch == "" {
+		return
+	}
+	t1 := time.Now()
+	status, err := fetchGHCompare(ctx, owner, repo, defaultBranch, rev)
+	t.GithubTime += time.Since(t1)
+	t.GithubCalls++
+	if err != nil {
+		return
+	}
+	branchPenalty := 0
+	if status != "behind" && status != "identical" {
+		hr.OffBranch = true
+		branchPenalty = pinnedCommitOffBranchPenalty
+	}
+	hr.Score += branchPenalty
+	hr.Signals["pinned_commit_branch"] = branchPenalty
+}
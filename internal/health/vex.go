@@ -0,0 +1,59 @@
+package health
+
+import (
+	"github.com/1homsi/gorisk/internal/report"
+	"github.com/1homsi/gorisk/internal/vex"
+)
+
+// cvePenalty is the health-score adjustment per known vulnerability.
+const cvePenalty = -30
+
+// ApplyVEX moves every CVE that doc declares not_affected for a module's
+// version from CVEs to NotAffected, and rescores the module without it.
+// Statements match on the OSV ID or any of its aliases. It returns the
+// number of CVEs suppressed.
+func ApplyVEX(reports []report.HealthReport, doc *vex.Document) int {
+	suppressed := 0
+	for i := range reports {
+		hr := &reports[i]
+		if hr.Unknown || len(hr.CVEs) == 0 {
+			continue
+		}
+		var kept []string
+		for _, id := range hr.CVEs {
+			ids := append([]string{id}, hr.Aliases[id]...)
+			st, ok := doc.NotAffected(ids, hr.Module, hr.Version)
+			if !ok {
+				kept = append(kept, id)
+				continue
+			}
+			hr.NotAffected = append(hr.NotAffected, report.VEXSuppression{
+				ID:            id,
+				Justification: st.Justification,
+				Impact:        st.Impact,
+			})
+			suppressed++
+		}
+		if len(kept) == len(hr.CVEs) {
+			continue
+		}
+		hr.CVEs = kept
+		hr.CVECount = len(kept)
+		rescore(hr)
+	}
+	return suppressed
+}
+
+// rescore recomputes the score of hr from its signals after the CVE count
+// changed.
+func rescore(hr *report.HealthReport) {
+	if hr.Signals == nil {
+		hr.Signals = make(map[string]int)
+	}
+	hr.Signals["cve_count"] = cvePenalty * hr.CVECount
+	score := 100
+	for _, v := range hr.Signals {
+		score += v
+	}
+	hr.Score = min(max(score, 0), 100)
+}
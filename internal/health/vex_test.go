@@ -0,0 +1,54 @@
+package health
+
+import (
+	"testing"
+
+	"github.com/1homsi/gorisk/internal/report"
+	"github.com/1homsi/gorisk/internal/vex"
+)
+
+func TestApplyVEXSuppressesNotAffectedCVE(t *testing.T) {
+	doc, err := vex.Parse([]byte(`{
+	  "@context": "https://openvex.dev/ns/v0.2.0",
+	  "statements": [{
+	    "vulnerability": {"name": "CVE-2026-1111"},
+	    "products": [{"@id": "pkg:golang/github.com/foo/bar@v1.2.3"}],
+	    "status": "not_affected",
+	    "justification": "vulnerable_code_not_in_execute_path"
+	  }]
+	}`))
+	if err != nil {
+		t.Fatalf("vex.Parse: %v", err)
+	}
+
+	// Two advisories cost 60 points; only GO-2026-0001 (CVE-2026-1111) is
+	// covered by the VEX statement.
+	reports := []report.HealthReport{{
+		Module:   "github.com/foo/bar",
+		Version:  "v1.2.3",
+		Score:    40,
+		CVECount: 2,
+		CVEs:     []string{"GO-2026-0001", "GO-2026-0002"},
+		Aliases:  map[string][]string{"GO-2026-0001": {"CVE-2026-1111"}},
+		Signals:  map[string]int{"cve_count": 2 * cvePenalty},
+	}}
+	const minHealthScore = 50
+	if reports[0].Score >= minHealthScore {
+		t.Fatal("fixture should fail the health gate before VEX")
+	}
+
+	if n := ApplyVEX(reports, doc); n != 1 {
+		t.Fatalf("ApplyVEX suppressed %d CVEs, want 1", n)
+	}
+	hr := reports[0]
+	if hr.CVECount != 1 || len(hr.CVEs) != 1 || hr.CVEs[0] != "GO-2026-0002" {
+		t.Errorf("remaining CVEs = %v (count %d), want [GO-2026-0002]", hr.CVEs, hr.CVECount)
+	}
+	if hr.Score != 70 || hr.Score < minHealthScore {
+		t.Errorf("score = %d, want 70 (passes the gate)", hr.Score)
+	}
+	if len(hr.NotAffected) != 1 || hr.NotAffected[0].ID != "GO-2026-0001" ||
+		hr.NotAffected[0].Justification != "vulnerable_code_not_in_execute_path" {
+		t.Errorf("NotAffected = %+v, want GO-2026-0001 kept as informational", hr.NotAffected)
+	}
+}
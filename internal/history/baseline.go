@@ -0,0 +1,156 @@
+package history
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"time"
+)
+
+// BaselineSchema identifies the portable baseline format written by
+// Export. Its layout is independent of the .gorisk-history.json store, so
+// a baseline exported by one gorisk release imports into another.
+const BaselineSchema = "gorisk-baseline/v1"
+
+// Baseline is a standalone, versioned export of history snapshots, for
+// sharing and caching baselines across machines. Its types mirror Snapshot
+// and ModuleSnapshot field by field, so changes to the store do not change
+// the exported format.
+type Baseline struct {
+	Schema     string             `json:"schema"`
+	ExportedAt string             `json:"exported_at"`
+	Snapshots  []BaselineSnapshot `json:"snapshots"`
+}
+
+// BaselineSnapshot is a Snapshot in the baseline format.
+type BaselineSnapshot struct {
+	Timestamp string           `json:"timestamp"`
+	Commit    string           `json:"commit,omitempty"`
+	Modules   []BaselineModule `json:"modules"`
+}
+
+// BaselineModule is a ModuleSnapshot in the baseline format.
+type BaselineModule struct {
+	Module         string   `json:"module"`
+	Version        string   `json:"version,omitempty"`
+	RiskLevel      string   `json:"risk_level"`
+	EffectiveScore int      `json:"effective_score"`
+	Capabilities   []string `json:"capabilities,omitempty"`
+}
+
+// Export returns the last n snapshots of h as a Baseline; n <= 0 exports
+// them all.
+func (h *History) Export(n int) Baseline {
+	snaps := h.Snapshots
+	if n > 0 && len(snaps) > n {
+		snaps = snaps[len(snaps)-n:]
+	}
+	b := Baseline{
+		Schema:     BaselineSchema,
+		ExportedAt: time.Now().UTC().Format(time.RFC3339),
+		Snapshots:  make([]BaselineSnapshot, 0, len(snaps)),
+	}
+	for _, s := range snaps {
+		bs := BaselineSnapshot{Timestamp: s.Timestamp, Commit: s.Commit, Modules: make([]BaselineModule, 0, len(s.Modules))}
+		for _, m := range s.Modules {
+			bs.Modules = append(bs.Modules, BaselineModule{
+				Module:         m.Module,
+				Version:        m.Version,
+				RiskLevel:      m.RiskLevel,
+				EffectiveScore: m.EffectiveScore,
+				Capabilities:   slices.Clone(m.Capabilities),
+			})
+		}
+		b.Snapshots = append(b.Snapshots, bs)
+	}
+	return b
+}
+
+// snapshot converts s to the store format, with its timestamp in UTC.
+func (s BaselineSnapshot) snapshot() Snapshot {
+	out := Snapshot{Timestamp: s.Timestamp, Commit: s.Commit, Modules: make([]ModuleSnapshot, 0, len(s.Modules))}
+	if t, err := time.Parse(time.RFC3339, s.Timestamp); err == nil {
+		out.Timestamp = t.UTC().Format(time.RFC3339)
+	}
+	for _, m := range s.Modules {
+		out.Modules = append(out.Modules, ModuleSnapshot{
+			Module:         m.Module,
+			Version:        m.Version,
+			RiskLevel:      m.RiskLevel,
+			EffectiveScore: m.EffectiveScore,
+			Capabilities:   slices.Clone(m.Capabilities),
+		})
+	}
+	return out
+}
+
+// WriteBaseline encodes b as indented JSON.
+func WriteBaseline(w io.Writer, b Baseline) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(b)
+}
+
+// ReadBaseline decodes a baseline written by WriteBaseline. A missing or
+// different schema, or a malformed timestamp, is an error. Fields the schema
+// does not define are ignored, so a baseline exported by a newer release
+// that adds optional fields still imports.
+func ReadBaseline(r io.Reader) (Baseline, error) {
+	var b Baseline
+	if err := json.NewDecoder(r).Decode(&b); err != nil {
+		return b, fmt.Errorf("invalid baseline: %w", err)
+	}
+	if b.Schema != BaselineSchema {
+		return b, fmt.Errorf("unsupported baseline schema %q (want %q)", b.Schema, BaselineSchema)
+	}
+	for i, s := range b.Snapshots {
+		if _, err := time.Parse(time.RFC3339, s.Timestamp); err != nil {
+			return b, fmt.Errorf("snapshot %d: invalid timestamp %q", i+1, s.Timestamp)
+		}
+	}
+	return b, nil
+}
+
+// Import adds the snapshots of b to h and returns how many were new. A
+// snapshot already in h, by time and commit, is skipped. With replace, h
+// holds exactly the snapshots of b instead. Imported timestamps are stored
+// in UTC; snapshots are kept in time order and capped like Record.
+func (h *History) Import(b Baseline, replace bool) int {
+	if replace {
+		h.Snapshots = nil
+	}
+	type key struct {
+		ts     time.Time
+		commit string
+	}
+	seen := make(map[key]bool, len(h.Snapshots))
+	for _, s := range h.Snapshots {
+		seen[key{snapshotTime(s), s.Commit}] = true
+	}
+	added := 0
+	for _, bs := range b.Snapshots {
+		s := bs.snapshot()
+		k := key{snapshotTime(s), s.Commit}
+		if seen[k] {
+			continue
+		}
+		seen[k] = true
+		h.Snapshots = append(h.Snapshots, s)
+		added++
+	}
+	sort.SliceStable(h.Snapshots, func(i, j int) bool {
+		return snapshotTime(h.Snapshots[i]).Before(snapshotTime(h.Snapshots[j]))
+	})
+	if len(h.Snapshots) > 100 {
+		h.Snapshots = h.Snapshots[len(h.Snapshots)-100:]
+	}
+	return added
+}
+
+// snapshotTime parses the timestamp of s; an unparsable one sorts first.
+func snapshotTime(s Snapshot) time.Time {
+	t, _ := time.Parse(time.RFC3339, s.Timestamp)
+	return t.UTC()
+}
@@ -4,9 +4,14 @@ import (
 	"encoding/json"
 	"os"
 	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/1homsi/gorisk/internal/capability"
+	"github.com/1homsi/gorisk/internal/graph"
+	"github.com/1homsi/gorisk/internal/transitive"
 )
 
 const fileName = ".gorisk-history.json"
@@ -29,6 +34,40 @@ type History struct {
 	Snapshots []Snapshot `json:"snapshots"`
 }
 
+// SnapshotOf builds a snapshot of every dependency module in g: its
+// version, transitive risk, and the capabilities of its packages.
+func SnapshotOf(g *graph.DependencyGraph, commit string) Snapshot {
+	capsByModule := make(map[string][]string)
+	versionByModule := make(map[string]string)
+	for _, mod := range g.Modules {
+		if !mod.Main {
+			versionByModule[mod.Path] = mod.Version
+		}
+	}
+	for _, pkg := range g.Packages {
+		if pkg.Module == nil || pkg.Module.Main {
+			continue
+		}
+		for _, c := range pkg.Capabilities.List() {
+			if !slices.Contains(capsByModule[pkg.Module.Path], c) {
+				capsByModule[pkg.Module.Path] = append(capsByModule[pkg.Module.Path], c)
+			}
+		}
+	}
+
+	var modules []ModuleSnapshot
+	for _, r := range transitive.ComputeTransitiveRisk(g) {
+		modules = append(modules, ModuleSnapshot{
+			Module:         r.Module,
+			Version:        versionByModule[r.Module],
+			RiskLevel:      r.RiskLevel,
+			EffectiveScore: r.EffectiveScore,
+			Capabilities:   capsByModule[r.Module],
+		})
+	}
+	return Snapshot{Commit: commit, Modules: modules}
+}
+
 func Load(dir string) (*History, error) {
 	path := filepath.Join(dir, fileName)
 	data, err := os.ReadFile(path)
@@ -115,3 +154,71 @@ func Diff(old, cur Snapshot) []ModuleDiff {
 
 	return diffs
 }
+
+// CapabilityTimeline records when a module's capability was first and last
+// observed across the recorded snapshots. Snapshot indices are 1-based to
+// match the numbering used by `gorisk history show`.
+type CapabilityTimeline struct {
+	Module     string `json:"module"`
+	Capability string `json:"capability"`
+	FirstSeen  string `json:"first_seen"`
+	FirstIndex int    `json:"first_snapshot"`
+	LastSeen   string `json:"last_seen"`
+	LastIndex  int    `json:"last_snapshot"`
+	Present    bool   `json:"present"` // present in the most recent snapshot
+}
+
+// CapabilityTimelines walks all snapshots in order and returns one entry per
+// (module, capability) pair, sorted by module then capability.
+func (h *History) CapabilityTimelines() []CapabilityTimeline {
+	byKey := make(map[string]*CapabilityTimeline)
+	last := len(h.Snapshots) - 1
+
+	for i, snap := range h.Snapshots {
+		for _, m := range snap.Modules {
+			for _, c := range m.Capabilities {
+				key := m.Module + "|" + c
+				tl, ok := byKey[key]
+				if !ok {
+					tl = &CapabilityTimeline{
+						Module:     m.Module,
+						Capability: c,
+						FirstSeen:  snap.Timestamp,
+						FirstIndex: i + 1,
+					}
+					byKey[key] = tl
+				}
+				tl.LastSeen = snap.Timestamp
+				tl.LastIndex = i + 1
+				tl.Present = i == last
+			}
+		}
+	}
+
+	out := make([]CapabilityTimeline, 0, len(byKey))
+	for _, tl := range byKey {
+		out = append(out, *tl)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Module != out[j].Module {
+			return out[i].Module < out[j].Module
+		}
+		return out[i].Capability < out[j].Capability
+	})
+	return out
+}
+
+// FirstWithVersion returns the index of the first snapshot in which a
+// module accepted by match is at version, or -1 if none is. Versions are
+// compared without a leading "v", so "1.2.0" matches "v1.2.0".
+func (h *History) FirstWithVersion(version string, match func(module string) bool) int {
+	want := strings.TrimPrefix(version, "v")
+	for i, snap := range h.Snapshots {
+		for _, m := range snap.Modules {
+			if match(m.Module) && strings.TrimPrefix(m.Version, "v") == want {
+				return i
+			}
+		}
+	}
+	return -1
+}
@@ -1,6 +1,9 @@
 package history
 
 import (
+	"bytes"
+	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -125,3 +128,153 @@ func TestDiffUnchanged(t *testing.T) {
 		}
 	}
 }
+
+func TestCapabilityTimelinesFirstSeen(t *testing.T) {
+	h := &History{}
+	h.Record(Snapshot{Timestamp: "2026-01-01T00:00:00Z", Modules: []ModuleSnapshot{
+		{Module: "example.com/a", RiskLevel: "LOW", Capabilities: []string{"fs:read"}},
+	}})
+	h.Record(Snapshot{Timestamp: "2026-02-01T00:00:00Z", Modules: []ModuleSnapshot{
+		{Module: "example.com/a", RiskLevel: "HIGH", Capabilities: []string{"exec", "fs:read"}},
+	}})
+
+	timelines := h.CapabilityTimelines()
+	if len(timelines) != 2 {
+		t.Fatalf("expected 2 timeline entries, got %d: %+v", len(timelines), timelines)
+	}
+
+	exec := timelines[0]
+	if exec.Capability != "exec" {
+		t.Fatalf("expected exec first (sorted), got %q", exec.Capability)
+	}
+	if exec.FirstIndex != 2 || exec.FirstSeen != "2026-02-01T00:00:00Z" {
+		t.Errorf("exec first seen = #%d %s, want #2 2026-02-01T00:00:00Z", exec.FirstIndex, exec.FirstSeen)
+	}
+	if !exec.Present {
+		t.Error("exec should be present in the latest snapshot")
+	}
+
+	fsRead := timelines[1]
+	if fsRead.FirstIndex != 1 || fsRead.LastIndex != 2 {
+		t.Errorf("fs:read seen #%d..#%d, want #1..#2", fsRead.FirstIndex, fsRead.LastIndex)
+	}
+}
+
+func TestCapabilityTimelinesDropped(t *testing.T) {
+	h := &History{}
+	h.Record(Snapshot{Modules: []ModuleSnapshot{
+		{Module: "example.com/a", Capabilities: []string{"network"}},
+	}})
+	h.Record(Snapshot{Modules: []ModuleSnapshot{
+		{Module: "example.com/a"},
+	}})
+
+	timelines := h.CapabilityTimelines()
+	if len(timelines) != 1 {
+		t.Fatalf("expected 1 timeline entry, got %d", len(timelines))
+	}
+	if timelines[0].Present {
+		t.Error("network should not be present after being dropped")
+	}
+	if timelines[0].LastIndex != 1 {
+		t.Errorf("last seen = #%d, want #1", timelines[0].LastIndex)
+	}
+}
+
+func TestFirstWithVersion(t *testing.T) {
+	h := &History{Snapshots: []Snapshot{
+		{Modules: []ModuleSnapshot{{Module: "example.com/a", Version: "v1.0.0"}, {Module: "example.com/b", Version: "v1.1.0"}}},
+		{Modules: []ModuleSnapshot{{Module: "example.com/a", Version: "v1.1.0"}}},
+		{Modules: []ModuleSnapshot{{Module: "example.com/a", Version: "v1.1.0"}}},
+	}}
+	onlyA := func(mod string) bool { return mod == "example.com/a" }
+	all := func(string) bool { return true }
+
+	if got := h.FirstWithVersion("v1.1.0", onlyA); got != 1 {
+		t.Errorf("FirstWithVersion(v1.1.0, a) = %d, want 1", got)
+	}
+	if got := h.FirstWithVersion("1.1.0", all); got != 0 {
+		t.Errorf("FirstWithVersion(1.1.0, any) = %d, want 0", got)
+	}
+	if got := h.FirstWithVersion("v2.0.0", all); got != -1 {
+		t.Errorf("FirstWithVersion(v2.0.0) = %d, want -1", got)
+	}
+}
+
+func TestBaselineRoundTrip(t *testing.T) {
+	src := &History{}
+	src.Record(Snapshot{
+		Timestamp: "2026-03-01T10:00:00Z",
+		Commit:    "abc1234",
+		Modules: []ModuleSnapshot{
+			{Module: "example.com/a", Version: "v1.2.0", RiskLevel: "HIGH", EffectiveScore: 45, Capabilities: []string{"exec", "network"}},
+		},
+	})
+
+	var buf bytes.Buffer
+	if err := WriteBaseline(&buf, src.Export(0)); err != nil {
+		t.Fatal(err)
+	}
+	b, err := ReadBaseline(&buf)
+	if err != nil {
+		t.Fatalf("ReadBaseline() error = %v", err)
+	}
+
+	dst := &History{}
+	if n := dst.Import(b, false); n != 1 {
+		t.Fatalf("Import() added %d snapshots, want 1", n)
+	}
+	if !reflect.DeepEqual(dst.Snapshots, src.Snapshots) {
+		t.Errorf("imported snapshots = %+v, want %+v", dst.Snapshots, src.Snapshots)
+	}
+	if n := dst.Import(b, false); n != 0 || len(dst.Snapshots) != 1 {
+		t.Errorf("re-import added %d (total %d), want 0 (total 1)", n, len(dst.Snapshots))
+	}
+}
+
+func TestReadBaselineRejectsSchema(t *testing.T) {
+	for _, in := range []string{
+		`{"schema":"gorisk-baseline/v2","snapshots":[]}`,
+		`{"snapshots":[]}`,
+		`{"schema":"gorisk-baseline/v1","snapshots":[{"timestamp":"yesterday","modules":[]}]}`,
+	} {
+		if _, err := ReadBaseline(strings.NewReader(in)); err == nil {
+			t.Errorf("ReadBaseline(%s) accepted an invalid baseline", in)
+		}
+	}
+}
+
+func TestReadBaselineIgnoresUnknownFields(t *testing.T) {
+	in := `{"schema":"gorisk-baseline/v1","tool":"gorisk 9.0","snapshots":[
+		{"timestamp":"2026-03-01T10:00:00Z","branch":"main","modules":[{"module":"example.com/a","risk_level":"LOW","effective_score":3,"license":"MIT"}]}]}`
+	b, err := ReadBaseline(strings.NewReader(in))
+	if err != nil {
+		t.Fatalf("ReadBaseline() error = %v", err)
+	}
+	if len(b.Snapshots) != 1 || len(b.Snapshots[0].Modules) != 1 || b.Snapshots[0].Modules[0].Module != "example.com/a" {
+		t.Errorf("ReadBaseline() = %+v", b)
+	}
+}
+
+func TestImportOrdersByTime(t *testing.T) {
+	h := &History{}
+	h.Record(Snapshot{Timestamp: "2026-03-01T10:30:00Z", Commit: "b"})
+	b := Baseline{Schema: BaselineSchema, Snapshots: []BaselineSnapshot{
+		// 11:00 UTC: after the local snapshot, though it sorts before it as a string.
+		{Timestamp: "2026-03-01T12:00:00+01:00", Commit: "c"},
+		// The local snapshot again, in another zone.
+		{Timestamp: "2026-03-01T11:30:00+01:00", Commit: "b"},
+		{Timestamp: "2026-03-01T10:00:00Z", Commit: "a"},
+	}}
+	if n := h.Import(b, false); n != 2 {
+		t.Errorf("Import() added %d snapshots, want 2", n)
+	}
+	var got []string
+	for _, s := range h.Snapshots {
+		got = append(got, s.Commit+"@"+s.Timestamp)
+	}
+	want := []string{"a@2026-03-01T10:00:00Z", "b@2026-03-01T10:30:00Z", "c@2026-03-01T11:00:00Z"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("snapshots = %v, want %v", got, want)
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/1homsi/gorisk/internal/capability"
 	"github.com/1homsi/gorisk/internal/ir"
 )
 
@@ -36,12 +37,85 @@ func (k CacheKey) Hash() string {
 	return fmt.Sprintf("%x", h.Sum(nil))[:16]
 }
 
+// cacheVersion tags cache entries; entries written by another version are
+// misses.
+const cacheVersion = "gorisk/v3"
+
 // CacheEntry stores a serialized function summary.
 type CacheEntry struct {
-	Key       CacheKey           `json:"key"`
-	Summary   ir.FunctionSummary `json:"summary"`
-	Timestamp time.Time          `json:"timestamp"`
-	Version   string             `json:"version"` // gorisk version
+	Key       CacheKey      `json:"key"`
+	Summary   cachedSummary `json:"summary"`
+	Timestamp time.Time     `json:"timestamp"`
+	Version   string        `json:"version"` // gorisk version
+}
+
+// cachedSet is the serialized form of a capability.CapabilitySet, whose
+// capability list is unexported and would not survive JSON.
+type cachedSet struct {
+	Caps     []string                                   `json:"caps,omitempty"`
+	Evidence map[string][]capability.CapabilityEvidence `json:"evidence,omitempty"`
+}
+
+func toCachedSet(cs capability.CapabilitySet) cachedSet {
+	return cachedSet{Caps: cs.List(), Evidence: cs.Evidence}
+}
+
+func (c cachedSet) set() capability.CapabilitySet {
+	var cs capability.CapabilitySet
+	for _, name := range c.Caps {
+		evs := c.Evidence[name]
+		if len(evs) == 0 {
+			cs.Add(name)
+		}
+		for _, ev := range evs {
+			cs.AddWithEvidence(name, ev)
+		}
+	}
+	return cs
+}
+
+// cachedSummary is the serialized form of an ir.FunctionSummary.
+type cachedSummary struct {
+	Node       ir.ContextNode `json:"node"`
+	Sources    cachedSet      `json:"sources"`
+	Sinks      cachedSet      `json:"sinks"`
+	Sanitizers cachedSet      `json:"sanitizers"`
+	Effects    cachedSet      `json:"effects"`
+	Transitive cachedSet      `json:"transitive"`
+	Depth      int            `json:"depth"`
+	Confidence float64        `json:"confidence"`
+	CallStack  []ir.CallEdge  `json:"call_stack,omitempty"`
+	Iteration  int            `json:"iteration"`
+}
+
+func toCachedSummary(s ir.FunctionSummary) cachedSummary {
+	return cachedSummary{
+		Node:       s.Node,
+		Sources:    toCachedSet(s.Sources),
+		Sinks:      toCachedSet(s.Sinks),
+		Sanitizers: toCachedSet(s.Sanitizers),
+		Effects:    toCachedSet(s.Effects),
+		Transitive: toCachedSet(s.Transitive),
+		Depth:      s.Depth,
+		Confidence: s.Confidence,
+		CallStack:  s.CallStack,
+		Iteration:  s.Iteration,
+	}
+}
+
+func (c cachedSummary) summary() ir.FunctionSummary {
+	return ir.FunctionSummary{
+		Node:       c.Node,
+		Sources:    c.Sources.set(),
+		Sinks:      c.Sinks.set(),
+		Sanitizers: c.Sanitizers.set(),
+		Effects:    c.Effects.set(),
+		Transitive: c.Transitive.set(),
+		Depth:      c.Depth,
+		Confidence: c.Confidence,
+		CallStack:  c.CallStack,
+		Iteration:  c.Iteration,
+	}
 }
 
 // Cache manages persistent function summary caching.
@@ -111,7 +185,7 @@ func (c *Cache) Load(key CacheKey) (ir.FunctionSummary, bool) {
 	}
 
 	// Validate cache entry
-	if entry.Key.Hash() != key.Hash() {
+	if entry.Version != cacheVersion || entry.Key.Hash() != key.Hash() {
 		c.mu.RUnlock()
 		c.mu.Lock()
 		c.misses++
@@ -126,7 +200,7 @@ func (c *Cache) Load(key CacheKey) (ir.FunctionSummary, bool) {
 	c.mu.Unlock()
 	c.mu.RLock()
 
-	return entry.Summary, true
+	return entry.Summary.summary(), true
 }
 
 // Store saves a summary to the cache.
@@ -137,9 +211,9 @@ func (c *Cache) Store(key CacheKey, summary ir.FunctionSummary) {
 
 	entry := CacheEntry{
 		Key:       key,
-		Summary:   summary,
+		Summary:   toCachedSummary(summary),
 		Timestamp: time.Now(),
-		Version:   "gorisk/v2",
+		Version:   cacheVersion,
 	}
 
 	data, err := json.MarshalIndent(entry, "", "  ")
@@ -199,20 +273,34 @@ func ComputeCodeHash(dir string, files []string) string {
 	return fmt.Sprintf("%x", h.Sum(nil))[:16]
 }
 
-// Stats logs cache hit/miss statistics.
-func (c *Cache) Stats() {
-	if !c.enabled {
-		return
+// CacheStats reports whether a Cache was enabled, where it stores
+// summaries, and how its lookups went.
+type CacheStats struct {
+	Enabled bool   `json:"enabled"`
+	Dir     string `json:"dir,omitempty"`
+	Hits    int    `json:"hits"`
+	Misses  int    `json:"misses"`
+}
+
+// HitRate returns hits as a percentage of all lookups, or 0 when there
+// were none.
+func (s CacheStats) HitRate() float64 {
+	total := s.Hits + s.Misses
+	if total == 0 {
+		return 0
 	}
+	return float64(s.Hits) / float64(total) * 100
+}
 
+// Stats returns the cache hit/miss statistics and logs them when there
+// were any lookups.
+func (c *Cache) Stats() CacheStats {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 
-	total := c.hits + c.misses
-	if total == 0 {
-		return
+	st := CacheStats{Enabled: c.enabled, Dir: c.dir, Hits: c.hits, Misses: c.misses}
+	if st.Enabled && st.Hits+st.Misses > 0 {
+		Infof("[cache] Cache stats: %d hits, %d misses (%.1f%% hit rate)", st.Hits, st.Misses, st.HitRate())
 	}
-
-	hitRate := float64(c.hits) / float64(total) * 100
-	Infof("[cache] Cache stats: %d hits, %d misses (%.1f%% hit rate)", c.hits, c.misses, hitRate)
+	return st
 }
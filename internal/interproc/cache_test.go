@@ -128,15 +128,25 @@ func TestCacheStats(t *testing.T) {
 	c.Store(k, ir.FunctionSummary{})
 	c.Load(k)
 
-	// Stats should not panic
-	c.Stats()
+	st := c.Stats()
+	if !st.Enabled || st.Dir != dir {
+		t.Errorf("Stats() = %+v, want enabled with dir %q", st, dir)
+	}
+	if st.Hits != 1 || st.Misses != 1 {
+		t.Errorf("hits/misses = %d/%d, want 1/1", st.Hits, st.Misses)
+	}
+	if st.HitRate() != 50 {
+		t.Errorf("HitRate() = %.1f, want 50", st.HitRate())
+	}
 }
 
 func TestCacheStatsZeroTotal(t *testing.T) {
 	dir := t.TempDir()
 	c := NewCache(dir)
 	// No loads/stores - Stats should be a no-op
-	c.Stats()
+	if rate := c.Stats().HitRate(); rate != 0 {
+		t.Errorf("HitRate() with no lookups = %.1f, want 0", rate)
+	}
 }
 
 // ── ComputeCodeHash ───────────────────────────────────────────────────────────
@@ -212,3 +222,103 @@ func TestCacheLocalPackage(t *testing.T) {
 		t.Errorf("loaded depth = %d, want 1", loaded.Depth)
 	}
 }
+
+func TestRunBundleCacheStats(t *testing.T) {
+	run := ir.Symbol{Package: "app", Name: "Run", Kind: "func"}
+	shell := ir.Symbol{Package: "app", Name: "shell", Kind: "func"}
+	var execCaps capability.CapabilitySet
+	execCaps.Add(capability.CapExec)
+	g := ir.IRGraph{
+		Calls: []ir.CallEdge{{Caller: run, Callee: shell}},
+		Functions: map[string]ir.FunctionCaps{
+			run.String():   {Symbol: run},
+			shell.String(): {Symbol: shell, DirectCaps: execCaps},
+		},
+	}
+	opts := DefaultOptions()
+	opts.CacheDir = t.TempDir()
+
+	cold, err := RunBundle(g, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	first := cold.CacheStats
+	if !first.Enabled || first.Dir != opts.CacheDir {
+		t.Errorf("stats = %+v, want enabled with dir %q", first, opts.CacheDir)
+	}
+	if first.Hits != 0 || first.Misses == 0 {
+		t.Errorf("first run hits/misses = %d/%d, want 0/>0", first.Hits, first.Misses)
+	}
+
+	// An unchanged graph is served from the summaries stored above, and
+	// the summaries read back from disk match the computed ones.
+	warm, err := RunBundle(g, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second := warm.CacheStats
+	if second.Hits != first.Misses || second.Misses != 0 {
+		t.Errorf("second run hits/misses = %d/%d, want %d/0", second.Hits, second.Misses, first.Misses)
+	}
+	for key, want := range cold.CallGraph.Summaries {
+		got := warm.CallGraph.Summaries[key]
+		if got.Effects.String() != want.Effects.String() || got.Transitive.String() != want.Transitive.String() ||
+			got.Sinks.String() != want.Sinks.String() || got.Depth != want.Depth {
+			t.Errorf("cached summary of %s = %+v, want %+v", key, got, want)
+		}
+	}
+}
+
+// fixedCache serves summary for every key and counts stores.
+type fixedCache struct {
+	summary ir.FunctionSummary
+	stores  int
+}
+
+func (c *fixedCache) Load(CacheKey) (ir.FunctionSummary, bool) { return c.summary, true }
+func (c *fixedCache) Store(CacheKey, ir.FunctionSummary)       { c.stores++ }
+func (c *fixedCache) Stats() CacheStats                        { return CacheStats{Enabled: true} }
+
+func TestComputeFixpointCachedSkipsHits(t *testing.T) {
+	run := ir.Symbol{Package: "app", Name: "Run", Kind: "func"}
+	shell := ir.Symbol{Package: "app", Name: "shell", Kind: "func"}
+	var execCaps capability.CapabilitySet
+	execCaps.Add(capability.CapExec)
+	g := ir.IRGraph{
+		Calls: []ir.CallEdge{{Caller: run, Callee: shell}},
+		Functions: map[string]ir.FunctionCaps{
+			run.String():   {Symbol: run},
+			shell.String(): {Symbol: shell, DirectCaps: execCaps},
+		},
+	}
+	cg := BuildCSCallGraph(g, 1)
+	DetectSCCs(cg)
+
+	// Every node hits, so no summary is computed or stored: each keeps
+	// the cached one.
+	cache := &fixedCache{summary: ir.FunctionSummary{Depth: 7}}
+	if err := ComputeFixpointCached(cg, cache, 100); err != nil {
+		t.Fatal(err)
+	}
+	for key, s := range cg.Summaries {
+		if s.Depth != 7 || s.Node.String() != key {
+			t.Errorf("summary of %s = %+v, want the cached one with its node", key, s)
+		}
+	}
+	if cache.stores != 0 {
+		t.Errorf("stored %d summaries, want 0", cache.stores)
+	}
+
+	// The key is known before the fixpoint runs and changes when a callee's
+	// direct capabilities do.
+	before := summaryCacheKeys(BuildCSCallGraph(g, 1))
+	var netCaps capability.CapabilitySet
+	netCaps.Add(capability.CapNetwork)
+	g.Functions[shell.String()] = ir.FunctionCaps{Symbol: shell, DirectCaps: netCaps}
+	after := summaryCacheKeys(BuildCSCallGraph(g, 1))
+	for key := range before {
+		if before[key].Hash() == after[key].Hash() {
+			t.Errorf("key of %s did not change with its callee's capabilities", key)
+		}
+	}
+}
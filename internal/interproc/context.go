@@ -19,6 +19,7 @@ func BuildCSCallGraph(irGraph ir.IRGraph, k int) *ir.CSCallGraph {
 		Summaries:    make(map[string]ir.FunctionSummary),
 		SCCs:         make(map[int]*ir.SCC),
 		NodeToSCC:    make(map[string]int),
+		CallSites:    make(map[string]ir.CallEdge),
 	}
 
 	// Build caller → callees map for BFS traversal
@@ -105,8 +106,13 @@ func BuildCSCallGraph(irGraph ir.IRGraph, k int) *ir.CSCallGraph {
 			}
 			calleeKey := calleeNode.String()
 
-			// Add edge
+			// Add edge, keeping the source position of the call
 			cg.Edges[nodeKey] = append(cg.Edges[nodeKey], calleeNode)
+			if siteKey := ir.CallSiteKey(item.function, edge.Callee); edge.File != "" {
+				if _, seen := cg.CallSites[siteKey]; !seen {
+					cg.CallSites[siteKey] = edge
+				}
+			}
 			cg.ReverseEdges[calleeKey] = append(cg.ReverseEdges[calleeKey], node)
 
 			// Enqueue callee if not visited
@@ -0,0 +1,201 @@
+package interproc
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/1homsi/gorisk/internal/capability"
+	"github.com/1homsi/gorisk/internal/ir"
+)
+
+// CallGraphSchemaVersion is bumped whenever the exported JSON shape changes
+// incompatibly.
+const CallGraphSchemaVersion = "1"
+
+// CallGraphExport is the stable JSON form of a CSCallGraph, written by
+// gorisk graph --export-callgraph. Node IDs are ContextNode.String() values
+// ("pkg.Func@caller"); edges and summaries refer to nodes by ID. All slices
+// are sorted so exports of the same graph are byte-identical.
+type CallGraphExport struct {
+	SchemaVersion string            `json:"schema_version"`
+	Nodes         []ExportedNode    `json:"nodes"`
+	Edges         []ExportedEdge    `json:"edges"`
+	Summaries     []ExportedSummary `json:"summaries"`
+}
+
+// ExportedNode is one context-sensitive function node.
+type ExportedNode struct {
+	ID      string `json:"id"`
+	Package string `json:"package"`
+	Name    string `json:"name"`
+	Kind    string `json:"kind,omitempty"`
+	Context string `json:"context"` // calling function, or "<entry>"
+
+	// CallerPackage and CallerName identify the calling function (k=1
+	// context); both are empty for entry nodes.
+	CallerPackage string `json:"caller_package,omitempty"`
+	CallerName    string `json:"caller_name,omitempty"`
+	CallerKind    string `json:"caller_kind,omitempty"`
+}
+
+// ExportedEdge is a call from one node to another.
+type ExportedEdge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// ExportedSummary is the capability summary computed for one node.
+type ExportedSummary struct {
+	Node       string   `json:"node"`
+	Sources    []string `json:"sources,omitempty"`
+	Sinks      []string `json:"sinks,omitempty"`
+	Sanitizers []string `json:"sanitizers,omitempty"`
+	Effects    []string `json:"effects,omitempty"`
+	Transitive []string `json:"transitive,omitempty"`
+	Depth      int      `json:"depth"`
+	Confidence float64  `json:"confidence"`
+}
+
+// ExportCallGraph converts cg to its stable JSON form.
+func ExportCallGraph(cg *ir.CSCallGraph) CallGraphExport {
+	out := CallGraphExport{
+		SchemaVersion: CallGraphSchemaVersion,
+		Nodes:         []ExportedNode{},
+		Edges:         []ExportedEdge{},
+		Summaries:     []ExportedSummary{},
+	}
+	seen := make(map[string]bool, len(cg.Nodes))
+	addNode := func(id string, n ir.ContextNode) {
+		if seen[id] {
+			return
+		}
+		seen[id] = true
+		out.Nodes = append(out.Nodes, ExportedNode{
+			ID:      id,
+			Package: n.Function.Package,
+			Name:    n.Function.Name,
+			Kind:    n.Function.Kind,
+			Context: n.Context.String(),
+
+			CallerPackage: n.Context.Caller.Package,
+			CallerName:    n.Context.Caller.Name,
+			CallerKind:    n.Context.Caller.Kind,
+		})
+	}
+	for id, n := range cg.Nodes {
+		addNode(id, n)
+	}
+	// Every edge endpoint is exported as a node, so consumers never see a
+	// dangling reference. Edges are keyed by caller ID only; a caller missing
+	// from cg.Nodes is recovered from the callee's reverse edges, and an edge
+	// whose caller cannot be recovered is dropped.
+	for from, callees := range cg.Edges {
+		for _, to := range callees {
+			if !seen[from] {
+				n, ok := callerNode(cg, from, to)
+				if !ok {
+					continue
+				}
+				addNode(from, n)
+			}
+			addNode(to.String(), to)
+			out.Edges = append(out.Edges, ExportedEdge{From: from, To: to.String()})
+		}
+	}
+	for id, s := range cg.Summaries {
+		addNode(id, s.Node)
+		out.Summaries = append(out.Summaries, ExportedSummary{
+			Node:       id,
+			Sources:    s.Sources.List(),
+			Sinks:      s.Sinks.List(),
+			Sanitizers: s.Sanitizers.List(),
+			Effects:    s.Effects.List(),
+			Transitive: s.Transitive.List(),
+			Depth:      s.Depth,
+			Confidence: s.Confidence,
+		})
+	}
+
+	sort.Slice(out.Nodes, func(i, j int) bool { return out.Nodes[i].ID < out.Nodes[j].ID })
+	sort.Slice(out.Edges, func(i, j int) bool {
+		if out.Edges[i].From != out.Edges[j].From {
+			return out.Edges[i].From < out.Edges[j].From
+		}
+		return out.Edges[i].To < out.Edges[j].To
+	})
+	sort.Slice(out.Summaries, func(i, j int) bool { return out.Summaries[i].Node < out.Summaries[j].Node })
+	return out
+}
+
+// callerNode returns the node with ID from among the callers of to.
+func callerNode(cg *ir.CSCallGraph, from string, to ir.ContextNode) (ir.ContextNode, bool) {
+	for _, n := range cg.ReverseEdges[to.String()] {
+		if n.String() == from {
+			return n, true
+		}
+	}
+	return ir.ContextNode{}, false
+}
+
+// WriteCallGraphJSON writes the exported form of cg as indented JSON.
+func WriteCallGraphJSON(w io.Writer, cg *ir.CSCallGraph) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(ExportCallGraph(cg))
+}
+
+// ReadCallGraphJSON rebuilds a CSCallGraph from an export: nodes, edges in
+// both directions, and summaries. SCCs are not exported and are left empty.
+func ReadCallGraphJSON(r io.Reader) (*ir.CSCallGraph, error) {
+	var exp CallGraphExport
+	if err := json.NewDecoder(r).Decode(&exp); err != nil {
+		return nil, fmt.Errorf("decode call graph: %w", err)
+	}
+	if exp.SchemaVersion != CallGraphSchemaVersion {
+		return nil, fmt.Errorf("unsupported call graph schema version %q (want %q)", exp.SchemaVersion, CallGraphSchemaVersion)
+	}
+
+	cg := ir.NewCSCallGraph()
+	for _, n := range exp.Nodes {
+		cg.Nodes[n.ID] = ir.ContextNode{
+			Function: ir.Symbol{Package: n.Package, Name: n.Name, Kind: n.Kind},
+			Context:  ir.Context{Caller: ir.Symbol{Package: n.CallerPackage, Name: n.CallerName, Kind: n.CallerKind}},
+		}
+	}
+	for _, e := range exp.Edges {
+		from, okFrom := cg.Nodes[e.From]
+		to, okTo := cg.Nodes[e.To]
+		if !okFrom || !okTo {
+			return nil, fmt.Errorf("edge %s → %s references an unknown node", e.From, e.To)
+		}
+		cg.Edges[e.From] = append(cg.Edges[e.From], to)
+		cg.ReverseEdges[e.To] = append(cg.ReverseEdges[e.To], from)
+	}
+	for _, s := range exp.Summaries {
+		node, ok := cg.Nodes[s.Node]
+		if !ok {
+			return nil, fmt.Errorf("summary references unknown node %s", s.Node)
+		}
+		cg.Summaries[s.Node] = ir.FunctionSummary{
+			Node:       node,
+			Sources:    capSet(s.Sources),
+			Sinks:      capSet(s.Sinks),
+			Sanitizers: capSet(s.Sanitizers),
+			Effects:    capSet(s.Effects),
+			Transitive: capSet(s.Transitive),
+			Depth:      s.Depth,
+			Confidence: s.Confidence,
+		}
+	}
+	return cg, nil
+}
+
+func capSet(caps []string) capability.CapabilitySet {
+	var cs capability.CapabilitySet
+	for _, c := range caps {
+		cs.Add(c)
+	}
+	return cs
+}
@@ -0,0 +1,142 @@
+package interproc
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/1homsi/gorisk/internal/capability"
+	"github.com/1homsi/gorisk/internal/ir"
+)
+
+func TestCallGraphJSONRoundTrip(t *testing.T) {
+	main := ir.ContextNode{Function: ir.Symbol{Package: "app", Name: "main", Kind: "func"}}
+	run := ir.ContextNode{
+		Function: ir.Symbol{Package: "app/cmd", Name: "Run", Kind: "func"},
+		Context:  ir.Context{Caller: main.Function},
+	}
+	// exec is only reached as a callee; it has no entry in Nodes.
+	exec := ir.ContextNode{
+		Function: ir.Symbol{Package: "app/cmd", Name: "exec", Kind: "func"},
+		Context:  ir.Context{Caller: run.Function},
+	}
+
+	cg := ir.NewCSCallGraph()
+	cg.Nodes[main.String()] = main
+	cg.Nodes[run.String()] = run
+	cg.Edges[main.String()] = []ir.ContextNode{run}
+	cg.Edges[run.String()] = []ir.ContextNode{exec}
+	cg.ReverseEdges[run.String()] = []ir.ContextNode{main}
+	cg.ReverseEdges[exec.String()] = []ir.ContextNode{run}
+
+	var sources, sinks, transitive capability.CapabilitySet
+	sources.Add(capability.CapEnv)
+	sinks.Add(capability.CapExec)
+	transitive.Add(capability.CapExec)
+	cg.Summaries[run.String()] = ir.FunctionSummary{
+		Node:       run,
+		Sources:    sources,
+		Sinks:      sinks,
+		Transitive: transitive,
+		Depth:      1,
+		Confidence: 0.75,
+	}
+
+	var buf bytes.Buffer
+	if err := WriteCallGraphJSON(&buf, cg); err != nil {
+		t.Fatalf("WriteCallGraphJSON: %v", err)
+	}
+
+	var exp CallGraphExport
+	if err := json.Unmarshal(buf.Bytes(), &exp); err != nil {
+		t.Fatalf("export is not valid JSON: %v", err)
+	}
+	if exp.SchemaVersion != CallGraphSchemaVersion {
+		t.Errorf("schema_version = %q, want %q", exp.SchemaVersion, CallGraphSchemaVersion)
+	}
+	if len(exp.Nodes) != 3 {
+		t.Errorf("exported %d nodes, want 3 (edge targets included)", len(exp.Nodes))
+	}
+
+	got, err := ReadCallGraphJSON(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatalf("ReadCallGraphJSON: %v", err)
+	}
+
+	for from, want := range cg.Edges {
+		callees := got.Edges[from]
+		if len(callees) != len(want) {
+			t.Fatalf("edges from %s = %v, want %v", from, callees, want)
+		}
+		for i := range want {
+			if callees[i] != want[i] {
+				t.Errorf("edge %s → %v, want %v", from, callees[i], want[i])
+			}
+		}
+	}
+	if callers := got.ReverseEdges[exec.String()]; len(callers) != 1 || callers[0] != run {
+		t.Errorf("reverse edges of exec = %v, want [%v]", callers, run)
+	}
+
+	s, ok := got.Summaries[run.String()]
+	if !ok {
+		t.Fatal("summary for Run was not restored")
+	}
+	if !s.Sources.Has(capability.CapEnv) || !s.Sinks.Has(capability.CapExec) || !s.Transitive.Has(capability.CapExec) {
+		t.Errorf("summary caps = sources %v sinks %v transitive %v", s.Sources.List(), s.Sinks.List(), s.Transitive.List())
+	}
+	if s.Confidence != 0.75 || s.Depth != 1 {
+		t.Errorf("summary confidence/depth = %v/%d, want 0.75/1", s.Confidence, s.Depth)
+	}
+	if s.Node != run {
+		t.Errorf("summary node = %v, want %v", s.Node, run)
+	}
+}
+
+func TestReadCallGraphJSONRejectsUnknownSchema(t *testing.T) {
+	_, err := ReadCallGraphJSON(strings.NewReader(`{"schema_version":"99"}`))
+	if err == nil || !strings.Contains(err.Error(), "schema version") {
+		t.Errorf("expected schema version error, got %v", err)
+	}
+}
+
+func TestExportCallGraphDanglingCallers(t *testing.T) {
+	run := ir.ContextNode{Function: ir.Symbol{Package: "app/cmd", Name: "Run", Kind: "func"}}
+	exec := ir.ContextNode{
+		Function: ir.Symbol{Package: "app/cmd", Name: "exec", Kind: "func"},
+		Context:  ir.Context{Caller: run.Function},
+	}
+	lost := ir.ContextNode{
+		Function: ir.Symbol{Package: "app/cmd", Name: "lost", Kind: "func"},
+		Context:  ir.Context{Caller: run.Function},
+	}
+
+	// Neither caller is in Nodes; only Run is known from a reverse edge.
+	cg := ir.NewCSCallGraph()
+	cg.Edges[run.String()] = []ir.ContextNode{exec}
+	cg.Edges["app/cmd.ghost@<entry>"] = []ir.ContextNode{lost}
+	cg.ReverseEdges[exec.String()] = []ir.ContextNode{run}
+
+	exp := ExportCallGraph(cg)
+	ids := make(map[string]bool)
+	for _, n := range exp.Nodes {
+		ids[n.ID] = true
+	}
+	for _, e := range exp.Edges {
+		if !ids[e.From] || !ids[e.To] {
+			t.Errorf("edge %s → %s references a node that was not exported", e.From, e.To)
+		}
+	}
+	if len(exp.Edges) != 1 || exp.Edges[0].From != run.String() {
+		t.Errorf("edges = %+v, want only %s → %s", exp.Edges, run, exec)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteCallGraphJSON(&buf, cg); err != nil {
+		t.Fatalf("WriteCallGraphJSON: %v", err)
+	}
+	if _, err := ReadCallGraphJSON(&buf); err != nil {
+		t.Errorf("export does not read back: %v", err)
+	}
+}
@@ -11,6 +11,12 @@ import (
 // It logs a warning if the maximum number of iterations is exceeded but does not
 // return an error — the partial analysis remains a valid over-approximation.
 func ComputeFixpoint(cg *ir.CSCallGraph, maxIterations int) error {
+	return computeFixpoint(cg, maxIterations, nil)
+}
+
+// computeFixpoint is ComputeFixpoint over every node not in done, whose
+// summaries are already final and are neither recomputed nor re-enqueued.
+func computeFixpoint(cg *ir.CSCallGraph, maxIterations int, done map[string]bool) error {
 	Debugf("[fixpoint] Starting fixpoint computation with max %d iterations", maxIterations)
 
 	// Initialize pending with all nodes in reverse topological order (leaves first).
@@ -18,7 +24,9 @@ func ComputeFixpoint(cg *ir.CSCallGraph, maxIterations int) error {
 	order := TopologicalSort(cg)
 	pending := make(map[string]bool, len(order))
 	for _, node := range order {
-		pending[node.String()] = true
+		if !done[node.String()] {
+			pending[node.String()] = true
+		}
 	}
 
 	// popWorklist returns the lexicographically-smallest pending key deterministically.
@@ -70,7 +78,7 @@ func ComputeFixpoint(cg *ir.CSCallGraph, maxIterations int) error {
 
 					// Re-enqueue callers of this node that are outside the SCC
 					for _, caller := range cg.ReverseEdges[sccNodeKey] {
-						if callerSCCID, ok := cg.NodeToSCC[caller.String()]; !ok || callerSCCID != sccID {
+						if callerSCCID, ok := cg.NodeToSCC[caller.String()]; (!ok || callerSCCID != sccID) && !done[caller.String()] {
 							pending[caller.String()] = true
 						}
 					}
@@ -103,6 +111,9 @@ func ComputeFixpoint(cg *ir.CSCallGraph, maxIterations int) error {
 			if len(callers) > 0 {
 				Debugf("[fixpoint]   → Re-enqueuing %d callers", len(callers))
 				for _, caller := range callers {
+					if done[caller.String()] {
+						continue
+					}
 					Debugf("[fixpoint]     ← %s", caller.Function.String())
 					pending[caller.String()] = true
 				}
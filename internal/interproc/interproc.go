@@ -3,6 +3,12 @@
 package interproc
 
 import (
+	"crypto/sha256"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/1homsi/gorisk/internal/capability"
 	"github.com/1homsi/gorisk/internal/ir"
 	"github.com/1homsi/gorisk/internal/taint"
 )
@@ -13,6 +19,13 @@ type AnalysisOptions struct {
 	MaxIterations      int    // Max fixpoint iterations (default: 5000)
 	EnableCache        bool   // Enable persistent caching (default: true)
 	CacheDir           string // Cache directory (default: $HOME/.cache/gorisk)
+
+	// Cache, when set, is used instead of the on-disk cache that
+	// EnableCache and CacheDir describe.
+	Cache SummaryCache
+
+	// Taint selects the taint rules checked over the call graph.
+	Taint taint.Options
 }
 
 // ResultBundle is the stable output of interprocedural analysis for command consumers.
@@ -21,6 +34,7 @@ type ResultBundle struct {
 	TaintFindings     []taint.TaintFinding
 	ReachabilityHints map[string]bool // package -> has reachable sink/source signal
 	Diagnostics       []string
+	CacheStats        CacheStats // summary cache lookups of this run
 }
 
 // DefaultOptions returns the default analysis configuration.
@@ -37,6 +51,13 @@ func DefaultOptions() AnalysisOptions {
 // It returns a context-sensitive call graph with computed summaries
 // and interprocedural taint findings.
 func RunAnalysis(irGraph ir.IRGraph, opts AnalysisOptions) (*ir.CSCallGraph, []taint.TaintFinding, error) {
+	csGraph, findings, _, err := runAnalysis(irGraph, opts)
+	return csGraph, findings, err
+}
+
+// runAnalysis is RunAnalysis that also returns the summary cache
+// statistics of the run.
+func runAnalysis(irGraph ir.IRGraph, opts AnalysisOptions) (*ir.CSCallGraph, []taint.TaintFinding, CacheStats, error) {
 	Infof("=== Starting Interprocedural Analysis ===")
 	Debugf("[analysis] Options: k=%d, maxIter=%d, cache=%v", opts.ContextSensitivity, opts.MaxIterations, opts.EnableCache)
 
@@ -57,12 +78,16 @@ func RunAnalysis(irGraph ir.IRGraph, opts AnalysisOptions) (*ir.CSCallGraph, []t
 
 	// Step 3: Create cache manager
 	Infof("[analysis] Step 3: Initializing cache (enabled=%v)", opts.EnableCache)
-	var cache *Cache
-	if opts.EnableCache {
+	var cache SummaryCache
+	switch {
+	case opts.Cache != nil:
+		cache = opts.Cache
+	case opts.EnableCache:
 		cache = NewCache(opts.CacheDir)
-	} else {
+	default:
 		cache = NewCacheDisabled()
 	}
+	counted := &countingCache{SummaryCache: cache}
 
 	// Step 4: Compute fixpoint with caching
 	maxIter := opts.MaxIterations
@@ -71,27 +96,29 @@ func RunAnalysis(irGraph ir.IRGraph, opts AnalysisOptions) (*ir.CSCallGraph, []t
 	}
 
 	Infof("[analysis] Step 4: Computing fixpoint")
-	if err := ComputeFixpointCached(csGraph, cache, maxIter); err != nil {
-		return nil, nil, err
+	if err := ComputeFixpointCached(csGraph, counted, maxIter); err != nil {
+		return nil, nil, CacheStats{}, err
 	}
 
-	// Log cache statistics
-	cache.Stats()
+	// Log cache statistics. A shared cache counts the lookups of every run,
+	// including concurrent ones, so report only the lookups made here.
+	st := cache.Stats()
+	st.Hits, st.Misses = counted.hits, counted.misses
 
 	// Step 5: Run interprocedural taint analysis
 	Infof("[analysis] Step 5: Running taint analysis")
-	taintAnalysis := taint.NewInterprocedural(csGraph)
+	taintAnalysis := taint.NewInterprocedural(csGraph, opts.Taint)
 	findings := taintAnalysis.AnalyzeInterprocedural()
 
 	Infof("[analysis] Found %d interprocedural taint flows", len(findings))
 	Infof("=== Analysis Complete ===")
 
-	return csGraph, findings, nil
+	return csGraph, findings, st, nil
 }
 
 // RunBundle executes interprocedural analysis and returns a stable result bundle.
 func RunBundle(irGraph ir.IRGraph, opts AnalysisOptions) (ResultBundle, error) {
-	csGraph, findings, err := RunAnalysis(irGraph, opts)
+	csGraph, findings, st, err := runAnalysis(irGraph, opts)
 	if err != nil {
 		return ResultBundle{}, err
 	}
@@ -109,13 +136,141 @@ func RunBundle(irGraph ir.IRGraph, opts AnalysisOptions) (ResultBundle, error) {
 		TaintFindings:     findings,
 		ReachabilityHints: reach,
 		Diagnostics:       []string{"interproc analysis active"},
+		CacheStats:        st,
 	}, nil
 }
 
-// ComputeFixpointCached is a wrapper around ComputeFixpoint that uses caching.
-// Currently, caching is implemented but the LoadOrCompute integration is deferred.
-func ComputeFixpointCached(cg *ir.CSCallGraph, cache *Cache, maxIterations int) error {
-	// For now, just run the regular fixpoint without per-node caching
-	// Full caching integration requires more sophisticated invalidation
-	return ComputeFixpoint(cg, maxIterations)
+// ComputeFixpointCached is ComputeFixpoint with summaries reused from
+// cache. Each node is keyed on its function, context, direct capabilities
+// and their evidence, and the keys of everything it calls, so a hit means
+// neither the function nor anything it reaches has changed since the run
+// that stored it. Hits are seeded before iterating and never recomputed;
+// an SCC is seeded only when every member hits. The remaining summaries
+// are computed and stored for the next run.
+func ComputeFixpointCached(cg *ir.CSCallGraph, cache SummaryCache, maxIterations int) error {
+	keys := summaryCacheKeys(cg)
+	nodeKeys := make([]string, 0, len(cg.Nodes))
+	for k := range cg.Nodes {
+		nodeKeys = append(nodeKeys, k)
+	}
+	sort.Strings(nodeKeys)
+
+	hits := make(map[string]ir.FunctionSummary)
+	for _, nodeKey := range nodeKeys {
+		if s, ok := cache.Load(keys[nodeKey]); ok {
+			s.Node = cg.Nodes[nodeKey]
+			hits[nodeKey] = s
+		}
+	}
+	done := make(map[string]bool, len(hits))
+	for nodeKey, s := range hits {
+		if id, ok := cg.NodeToSCC[nodeKey]; ok && !allHit(cg.SCCs[id], hits) {
+			continue
+		}
+		cg.Summaries[nodeKey] = s
+		done[nodeKey] = true
+	}
+	if len(done) > 0 {
+		Infof("[cache] Seeded %d of %d summaries from the cache", len(done), len(nodeKeys))
+	}
+
+	if err := computeFixpoint(cg, maxIterations, done); err != nil {
+		return err
+	}
+	for _, nodeKey := range nodeKeys {
+		if !done[nodeKey] {
+			cache.Store(keys[nodeKey], cg.Summaries[nodeKey])
+		}
+	}
+	return nil
+}
+
+// countingCache counts the lookups one analysis makes in a cache that
+// other analyses may share.
+type countingCache struct {
+	SummaryCache
+	hits, misses int
+}
+
+func (c *countingCache) Load(key CacheKey) (ir.FunctionSummary, bool) {
+	s, ok := c.SummaryCache.Load(key)
+	if ok {
+		c.hits++
+	} else {
+		c.misses++
+	}
+	return s, ok
+}
+
+// allHit reports whether every member of scc has a cached summary.
+func allHit(scc *ir.SCC, hits map[string]ir.FunctionSummary) bool {
+	for _, n := range scc.Nodes {
+		if _, ok := hits[n.String()]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// summaryCacheKeys returns the cache key of every node of cg, computed from
+// the analysis inputs alone so it is known before the fixpoint runs. A
+// node's key covers its own direct capabilities and the keys of its
+// callees; the members of an SCC share one digest over the whole cycle.
+func summaryCacheKeys(cg *ir.CSCallGraph) map[string]CacheKey {
+	digests := make(map[string]string, len(cg.Nodes))
+	var digest func(nodeKey string) string
+	digest = func(nodeKey string) string {
+		if d, ok := digests[nodeKey]; ok {
+			return d
+		}
+		members := []string{nodeKey}
+		if id, ok := cg.NodeToSCC[nodeKey]; ok {
+			members = members[:0]
+			for _, n := range cg.SCCs[id].Nodes {
+				members = append(members, n.String())
+			}
+		}
+		inCycle := make(map[string]bool, len(members))
+		for _, m := range members {
+			inCycle[m] = true
+			digests[m] = "" // breaks recursion through the cycle
+		}
+		var parts []string
+		for _, m := range members {
+			parts = append(parts, m+"="+effectsFingerprint(cg.Summaries[m].Effects))
+			for _, callee := range cg.Edges[m] {
+				if ck := callee.String(); !inCycle[ck] {
+					parts = append(parts, m+"→"+ck+"="+digest(ck))
+				}
+			}
+		}
+		sort.Strings(parts)
+		h := sha256.Sum256([]byte(strings.Join(parts, "\n")))
+		d := fmt.Sprintf("%x", h[:8])
+		for _, m := range members {
+			digests[m] = d
+		}
+		return d
+	}
+
+	keys := make(map[string]CacheKey, len(cg.Nodes))
+	for nodeKey, node := range cg.Nodes {
+		keys[nodeKey] = CacheKey{
+			Function:     node.Function,
+			Context:      node.Context,
+			DirectCaps:   effectsFingerprint(cg.Summaries[nodeKey].Effects),
+			CalleeHashes: []string{digest(nodeKey)},
+		}
+	}
+	return keys
+}
+
+// effectsFingerprint renders cs with its evidence, so a summary whose
+// evidence moved is not served from the cache.
+func effectsFingerprint(cs capability.CapabilitySet) string {
+	var b strings.Builder
+	for _, c := range cs.List() {
+		fmt.Fprintf(&b, "%s%v;", c, cs.Evidence[c])
+	}
+	return b.String()
 }
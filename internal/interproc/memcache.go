@@ -0,0 +1,98 @@
+package interproc
+
+import (
+	"container/list"
+	"sync"
+
+	"github.com/1homsi/gorisk/internal/ir"
+)
+
+// SummaryCache stores converged function summaries between analyses.
+// Cache keeps them on disk; MemoryCache keeps them in the process.
+type SummaryCache interface {
+	Load(key CacheKey) (ir.FunctionSummary, bool)
+	Store(key CacheKey, summary ir.FunctionSummary)
+	Stats() CacheStats
+}
+
+// MemoryCache is a SummaryCache held in memory, for long-running processes
+// that embed gorisk and analyze many projects without disk I/O. It is safe
+// for concurrent use and evicts the least recently used summary once it
+// holds maxEntries.
+type MemoryCache struct {
+	mu         sync.Mutex
+	maxEntries int
+	order      *list.List // front = most recently used; values are *memEntry
+	entries    map[string]*list.Element
+	hits       int
+	misses     int
+}
+
+type memEntry struct {
+	hash    string
+	summary ir.FunctionSummary
+}
+
+// DefaultMemoryCacheEntries bounds a MemoryCache created with a
+// non-positive size.
+const DefaultMemoryCacheEntries = 10000
+
+// NewMemoryCache returns an empty MemoryCache holding at most maxEntries
+// summaries (DefaultMemoryCacheEntries when maxEntries <= 0).
+func NewMemoryCache(maxEntries int) *MemoryCache {
+	if maxEntries <= 0 {
+		maxEntries = DefaultMemoryCacheEntries
+	}
+	return &MemoryCache{
+		maxEntries: maxEntries,
+		order:      list.New(),
+		entries:    make(map[string]*list.Element),
+	}
+}
+
+// Load returns the summary stored under key, marking it recently used.
+func (c *MemoryCache) Load(key CacheKey) (ir.FunctionSummary, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.entries[key.Hash()]
+	if !ok {
+		c.misses++
+		return ir.FunctionSummary{}, false
+	}
+	c.hits++
+	c.order.MoveToFront(el)
+	return el.Value.(*memEntry).summary, true
+}
+
+// Store saves summary under key, evicting the least recently used entry
+// when the cache is full.
+func (c *MemoryCache) Store(key CacheKey, summary ir.FunctionSummary) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	hash := key.Hash()
+	if el, ok := c.entries[hash]; ok {
+		el.Value.(*memEntry).summary = summary
+		c.order.MoveToFront(el)
+		return
+	}
+	c.entries[hash] = c.order.PushFront(&memEntry{hash: hash, summary: summary})
+	for c.order.Len() > c.maxEntries {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*memEntry).hash)
+	}
+}
+
+// Len returns the number of summaries held.
+func (c *MemoryCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+// Stats returns the cache's hit and miss counts since it was created.
+func (c *MemoryCache) Stats() CacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return CacheStats{Enabled: true, Dir: "memory", Hits: c.hits, Misses: c.misses}
+}
@@ -0,0 +1,85 @@
+package interproc
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/1homsi/gorisk/internal/capability"
+	"github.com/1homsi/gorisk/internal/ir"
+)
+
+func TestMemoryCacheStoreLoadAndEvict(t *testing.T) {
+	c := NewMemoryCache(2)
+	a, b, d := makeCacheKey("p", "A"), makeCacheKey("p", "B"), makeCacheKey("p", "D")
+
+	if _, ok := c.Load(a); ok {
+		t.Fatal("expected miss before store")
+	}
+	c.Store(a, ir.FunctionSummary{Depth: 1})
+	c.Store(b, ir.FunctionSummary{Depth: 2})
+	got, ok := c.Load(a)
+	if !ok || got.Depth != 1 {
+		t.Fatalf("Load(A) = %+v, %v; want depth 1", got, ok)
+	}
+
+	// A was just used, so storing a third summary evicts B.
+	c.Store(d, ir.FunctionSummary{Depth: 3})
+	if c.Len() != 2 {
+		t.Errorf("Len() = %d, want 2", c.Len())
+	}
+	if _, ok := c.Load(b); ok {
+		t.Error("expected B to be evicted")
+	}
+	for _, k := range []CacheKey{a, d} {
+		if _, ok := c.Load(k); !ok {
+			t.Errorf("expected %s to be kept", k.Function.Name)
+		}
+	}
+
+	st := c.Stats()
+	if !st.Enabled || st.Hits != 3 || st.Misses != 2 {
+		t.Errorf("Stats() = %+v, want 3 hits, 2 misses", st)
+	}
+}
+
+func TestRunBundleSharedMemoryCacheStats(t *testing.T) {
+	run := ir.Symbol{Package: "app", Name: "Run", Kind: "func"}
+	shell := ir.Symbol{Package: "app", Name: "shell", Kind: "func"}
+	var execCaps capability.CapabilitySet
+	execCaps.Add(capability.CapExec)
+	g := ir.IRGraph{
+		Calls: []ir.CallEdge{{Caller: run, Callee: shell}},
+		Functions: map[string]ir.FunctionCaps{
+			run.String():   {Symbol: run},
+			shell.String(): {Symbol: shell, DirectCaps: execCaps},
+		},
+	}
+	opts := DefaultOptions()
+	opts.Cache = NewMemoryCache(0)
+
+	first, err := RunBundle(g, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	nodes := len(first.CallGraph.Nodes)
+
+	// Concurrent runs over the shared cache each report only their own
+	// lookups, all of them hits.
+	const runs = 8
+	var wg sync.WaitGroup
+	for range runs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			b, err := RunBundle(g, opts)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if st := b.CacheStats; st.Hits != nodes || st.Misses != 0 {
+				t.Errorf("hits/misses = %d/%d, want %d/0", st.Hits, st.Misses, nodes)
+			}
+		}()
+	}
+	wg.Wait()
+}
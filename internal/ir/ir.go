@@ -93,6 +93,13 @@ type CSCallGraph struct {
 	Summaries    map[string]FunctionSummary // key: ContextNode.String()
 	SCCs         map[int]*SCC               // SCC ID → SCC
 	NodeToSCC    map[string]int             // ContextNode → SCC ID
+	CallSites    map[string]CallEdge        // CallSiteKey(caller, callee) → first call site seen
+}
+
+// CallSiteKey identifies the calls from caller to callee, regardless of
+// calling context.
+func CallSiteKey(caller, callee Symbol) string {
+	return caller.String() + "→" + callee.String()
 }
 
 // NewCSCallGraph creates an empty context-sensitive call graph.
@@ -104,5 +111,6 @@ func NewCSCallGraph() *CSCallGraph {
 		Summaries:    make(map[string]FunctionSummary),
 		SCCs:         make(map[int]*SCC),
 		NodeToSCC:    make(map[string]int),
+		CallSites:    make(map[string]CallEdge),
 	}
 }
@@ -5,6 +5,7 @@ import (
 	"bytes"
 	"encoding/json"
 	"os/exec"
+	"slices"
 	"strings"
 
 	goadapter "github.com/1homsi/gorisk/internal/adapters/go"
@@ -102,7 +103,7 @@ func goScanModuleCaps(modulePath, version string) capability.CapabilitySet {
 	for _, pkg := range g.Packages {
 		if pkg.Module != nil && pkg.Module.Path == modulePath {
 			// Populate capabilities since graph.Load no longer does it.
-			caps, err := goadapter.DetectPackage(pkg.Dir, pkg.GoFiles)
+			caps, err := goadapter.DetectPackage(pkg.Dir, append(slices.Clip(pkg.GoFiles), pkg.CgoFiles...))
 			if err == nil {
 				combined.Merge(caps)
 			}
@@ -1,6 +1,11 @@
 package prdiff
 
-import "github.com/1homsi/gorisk/internal/capability"
+import (
+	"slices"
+	"sort"
+
+	"github.com/1homsi/gorisk/internal/capability"
+)
 
 // ModuleDiff describes a single dependency change in a PR.
 type ModuleDiff struct {
@@ -13,9 +18,58 @@ type ModuleDiff struct {
 
 // PRDiffReport summarises all dependency changes introduced by a PR.
 type PRDiffReport struct {
-	Added   []ModuleDiff
-	Removed []string
-	Updated []ModuleDiff
+	Added    []ModuleDiff
+	Removed  []string
+	Updated  []ModuleDiff
+	Packages []PackageCapDiff `json:",omitempty"`
+}
+
+// PackageCapDiff describes a package whose capability set differs between
+// the base and head trees, whether or not its module version changed.
+type PackageCapDiff struct {
+	Package     string
+	OldCaps     capability.CapabilitySet
+	Caps        capability.CapabilitySet
+	AddedCaps   []string
+	RemovedCaps []string
+	Escalated   bool // head risk level is higher than base
+}
+
+// DiffCapabilities compares per-package capabilities of the base and head
+// trees. Packages only present on one side count as gaining or losing all
+// of their capabilities. Results are sorted by package.
+func DiffCapabilities(base, head map[string]capability.CapabilitySet) []PackageCapDiff {
+	pkgs := make(map[string]bool, len(head))
+	for p := range base {
+		pkgs[p] = true
+	}
+	for p := range head {
+		pkgs[p] = true
+	}
+
+	var out []PackageCapDiff
+	for pkg := range pkgs {
+		oldCaps, newCaps := base[pkg], head[pkg]
+		d := PackageCapDiff{Package: pkg, OldCaps: oldCaps, Caps: newCaps}
+		for _, c := range newCaps.List() {
+			if !slices.Contains(oldCaps.List(), c) {
+				d.AddedCaps = append(d.AddedCaps, c)
+			}
+		}
+		for _, c := range oldCaps.List() {
+			if !slices.Contains(newCaps.List(), c) {
+				d.RemovedCaps = append(d.RemovedCaps, c)
+			}
+		}
+		if len(d.AddedCaps) == 0 && len(d.RemovedCaps) == 0 {
+			continue
+		}
+		d.Escalated = len(d.AddedCaps) > 0 &&
+			capability.RiskValue(newCaps.RiskLevel()) > capability.RiskValue(oldCaps.RiskLevel())
+		out = append(out, d)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Package < out[j].Package })
+	return out
 }
 
 // Differ compares dependency changes between two git refs.
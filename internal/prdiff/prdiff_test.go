@@ -297,3 +297,37 @@ func TestParsePackageJSONDepLine(t *testing.T) {
 		})
 	}
 }
+
+func TestDiffCapabilities(t *testing.T) {
+	var fsOnly, withExec, netOnly capability.CapabilitySet
+	fsOnly.Add(capability.CapFSRead)
+	withExec.Add(capability.CapFSRead)
+	withExec.Add(capability.CapExec)
+	withExec.Add(capability.CapNetwork)
+	netOnly.Add(capability.CapNetwork)
+
+	base := map[string]capability.CapabilitySet{
+		"example.com/app":     fsOnly,
+		"example.com/same":    netOnly,
+		"example.com/removed": netOnly,
+	}
+	head := map[string]capability.CapabilitySet{
+		"example.com/app":  withExec,
+		"example.com/same": netOnly,
+	}
+
+	got := DiffCapabilities(base, head)
+	if len(got) != 2 {
+		t.Fatalf("got %d changed packages, want 2: %+v", len(got), got)
+	}
+	app, removed := got[0], got[1]
+	if app.Package != "example.com/app" || !app.Escalated {
+		t.Errorf("app diff = %+v, want escalated", app)
+	}
+	if len(app.AddedCaps) != 2 || len(app.RemovedCaps) != 0 {
+		t.Errorf("app added=%v removed=%v, want exec and network added", app.AddedCaps, app.RemovedCaps)
+	}
+	if removed.Package != "example.com/removed" || removed.Escalated || len(removed.RemovedCaps) != 1 {
+		t.Errorf("removed diff = %+v", removed)
+	}
+}
@@ -1,8 +1,13 @@
 package reachability
 
 import (
+	"go/ast"
 	"go/token"
+	"go/types"
 	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
 
 	goadapter "github.com/1homsi/gorisk/internal/adapters/go"
 	"github.com/1homsi/gorisk/internal/capability"
@@ -23,7 +28,8 @@ func analyzeGo(dir, entryFile string) ([]ReachabilityReport, error) {
 			packages.NeedDeps |
 			packages.NeedTypes |
 			packages.NeedSyntax |
-			packages.NeedTypesInfo,
+			packages.NeedTypesInfo |
+			packages.NeedModule,
 		Fset: token.NewFileSet(),
 	}
 
@@ -36,9 +42,11 @@ func analyzeGo(dir, entryFile string) ([]ReachabilityReport, error) {
 	prog.Build()
 
 	var mains []*ssa.Package
-	for _, p := range ssaPkgs {
+	var mainPkgs []*packages.Package
+	for i, p := range ssaPkgs {
 		if p != nil && p.Pkg.Name() == "main" {
 			mains = append(mains, p)
+			mainPkgs = append(mainPkgs, pkgs[i])
 		}
 	}
 
@@ -51,6 +59,7 @@ func analyzeGo(dir, entryFile string) ([]ReachabilityReport, error) {
 		absEntry = filepath.Clean(absEntry)
 
 		var filteredMains []*ssa.Package
+		var filteredPkgs []*packages.Package
 		for i, lp := range pkgs {
 			matched := false
 			for _, f := range lp.GoFiles {
@@ -61,41 +70,49 @@ func analyzeGo(dir, entryFile string) ([]ReachabilityReport, error) {
 			}
 			if matched && i < len(ssaPkgs) && ssaPkgs[i] != nil {
 				filteredMains = append(filteredMains, ssaPkgs[i])
+				filteredPkgs = append(filteredPkgs, lp)
 			}
 		}
 		if len(filteredMains) > 0 {
-			mains = filteredMains
+			mains, mainPkgs = filteredMains, filteredPkgs
 		}
 	}
 
 	reachablePkgs := make(map[string]bool)
+	var paths *callPaths
 
-	if len(mains) > 0 {
-		var roots []*ssa.Function
-		for _, m := range mains {
-			if f := m.Func("main"); f != nil {
-				roots = append(roots, f)
-			}
-			if f := m.Func("init"); f != nil {
-				roots = append(roots, f)
-			}
+	var roots []*ssa.Function
+	for _, m := range mains {
+		if f := m.Func("main"); f != nil {
+			roots = append(roots, f)
+		}
+		if f := m.Func("init"); f != nil {
+			roots = append(roots, f)
 		}
+	}
+	// A library has no main: every package it loads can be linked in.
+	linked := mainPkgs
+	if len(linked) == 0 {
+		linked = pkgs
+	}
+	roots = append(roots, interfaceEntryPoints(prog, linked)...)
+	roots = append(roots, importInits(prog, linked)...)
 
-		if len(roots) > 0 {
-			result := rta.Analyze(roots, true)
-			for fn := range result.Reachable {
-				if fn.Package() != nil {
-					reachablePkgs[fn.Package().Pkg.Path()] = true
-				}
+	if len(roots) > 0 {
+		result := rta.Analyze(roots, true)
+		for fn := range result.Reachable {
+			if fn.Package() != nil {
+				reachablePkgs[fn.Package().Pkg.Path()] = true
 			}
-			result.CallGraph.DeleteSyntheticNodes()
-			callgraph.GraphVisitEdges(result.CallGraph, func(e *callgraph.Edge) error {
-				if e.Callee.Func.Package() != nil {
-					reachablePkgs[e.Callee.Func.Package().Pkg.Path()] = true
-				}
-				return nil
-			})
 		}
+		result.CallGraph.DeleteSyntheticNodes()
+		paths = walkCallPaths(result.CallGraph, roots)
+		callgraph.GraphVisitEdges(result.CallGraph, func(e *callgraph.Edge) error {
+			if e.Callee.Func.Package() != nil {
+				reachablePkgs[e.Callee.Func.Package().Pkg.Path()] = true
+			}
+			return nil
+		})
 	}
 
 	seen := make(map[string]bool)
@@ -119,14 +136,270 @@ func analyzeGo(dir, entryFile string) ([]ReachabilityReport, error) {
 				return true
 			}
 
-			reports = append(reports, ReachabilityReport{
+			r := ReachabilityReport{
 				Package:       p.PkgPath,
 				ReachableCaps: cs,
 				Reachable:     reachablePkgs[p.PkgPath],
-			})
+			}
+			if r.Reachable && paths != nil {
+				r.Paths = paths.forPackage(p.PkgPath, p.Imports)
+			}
+			reports = append(reports, r)
 			return true
 		}, nil)
 	}
 
 	return reports, nil
 }
+
+// entryInterfaces are standard interfaces whose methods a framework calls
+// on values it was handed: an http.Handler registered with a router, a
+// driver registered with database/sql. The call happens outside the
+// analyzed code, so the call graph alone never reaches the method.
+// Ubiquitous interfaces such as error, fmt.Stringer, io.Reader, or
+// json.Marshaler are left out: nearly every type implements one, and
+// rooting them would make almost every method reachable.
+var entryInterfaces = []struct{ pkg, name string }{
+	{"net/http", "Handler"},
+	{"net/http", "RoundTripper"},
+	{"database/sql/driver", "Driver"},
+	{"database/sql/driver", "Connector"},
+}
+
+// importInits returns the init functions that run merely because linked
+// code is loaded: the package initializer of each linked package (a library
+// has no main.init to call it) and that of every package it blank-imports.
+// A blank import such as _ "github.com/lib/pq" exists only for the side
+// effects of its init, typically registering a driver, so those are
+// reachable even though nothing calls into the package.
+func importInits(prog *ssa.Program, linked []*packages.Package) []*ssa.Function {
+	seen := make(map[*ssa.Function]bool)
+	var inits []*ssa.Function
+	add := func(tp *types.Package) {
+		sp := prog.Package(tp)
+		if sp == nil {
+			return
+		}
+		if f := sp.Func("init"); f != nil && !seen[f] {
+			seen[f] = true
+			inits = append(inits, f)
+		}
+	}
+	for _, lp := range linked {
+		if lp.Types == nil {
+			continue
+		}
+		add(lp.Types)
+		for _, file := range lp.Syntax {
+			for _, spec := range file.Imports {
+				if spec.Name == nil || spec.Name.Name != "_" {
+					continue
+				}
+				path, err := strconv.Unquote(spec.Path.Value)
+				if err != nil {
+					continue
+				}
+				if imp := lp.Imports[path]; imp != nil && imp.Types != nil {
+					add(imp.Types)
+				}
+			}
+		}
+	}
+	return inits
+}
+
+// interfaceEntryPoints returns, sorted, the methods through which non-standard
+// types in linked (and their dependencies) satisfy an entryInterfaces
+// interface or a registered gRPC service interface. They are analysis
+// roots, so a handler's exec counts as reachable even though only the
+// framework calls ServeHTTP or SayHello.
+func interfaceEntryPoints(prog *ssa.Program, linked []*packages.Package) []*ssa.Function {
+	var ifaces []*types.Interface
+	for _, ei := range entryInterfaces {
+		p := prog.ImportedPackage(ei.pkg)
+		if p == nil {
+			continue // not linked, so nothing can satisfy it
+		}
+		if obj, ok := p.Pkg.Scope().Lookup(ei.name).(*types.TypeName); ok {
+			if iface, ok := obj.Type().Underlying().(*types.Interface); ok {
+				ifaces = append(ifaces, iface)
+			}
+		}
+	}
+	ifaces = append(ifaces, grpcServices(linked)...)
+
+	// Only third-party and first-party packages; the standard library is
+	// the framework side of the call.
+	var candidates []*ssa.Package
+	packages.Visit(linked, nil, func(p *packages.Package) {
+		if p.Module == nil || p.Types == nil {
+			return
+		}
+		if sp := prog.Package(p.Types); sp != nil {
+			candidates = append(candidates, sp)
+		}
+	})
+
+	seen := make(map[*ssa.Function]bool)
+	var entries []*ssa.Function
+	for _, sp := range candidates {
+		for _, mem := range sp.Members {
+			t, ok := mem.(*ssa.Type)
+			if !ok {
+				continue
+			}
+			named, ok := t.Type().(*types.Named)
+			if !ok || types.IsInterface(named) || named.TypeParams().Len() > 0 {
+				continue
+			}
+			for _, iface := range ifaces {
+				var recv types.Type = named
+				if !types.Implements(recv, iface) {
+					recv = types.NewPointer(named)
+					if !types.Implements(recv, iface) {
+						continue
+					}
+				}
+				mset := prog.MethodSets.MethodSet(recv)
+				for i := 0; i < iface.NumMethods(); i++ {
+					m := iface.Method(i)
+					sel := mset.Lookup(m.Pkg(), m.Name())
+					if sel == nil {
+						continue
+					}
+					if fn := prog.MethodValue(sel); fn != nil && !seen[fn] {
+						seen[fn] = true
+						entries = append(entries, fn)
+					}
+				}
+			}
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].String() < entries[j].String() })
+	return entries
+}
+
+// grpcServices returns the service interfaces registered in linked (and
+// its non-standard dependencies) through protoc-gen-go-grpc's generated
+// RegisterXServer(s grpc.ServiceRegistrar, srv XServer) functions. grpc-go
+// dispatches to the registered implementation through a handler table, so
+// its methods are entry points just like ServeHTTP.
+func grpcServices(linked []*packages.Package) []*types.Interface {
+	seen := make(map[*types.Interface]bool)
+	var services []*types.Interface
+	packages.Visit(linked, nil, func(p *packages.Package) {
+		if p.Module == nil || p.TypesInfo == nil {
+			return
+		}
+		for _, file := range p.Syntax {
+			ast.Inspect(file, func(n ast.Node) bool {
+				call, ok := n.(*ast.CallExpr)
+				if !ok {
+					return true
+				}
+				var id *ast.Ident
+				switch fun := ast.Unparen(call.Fun).(type) {
+				case *ast.Ident:
+					id = fun
+				case *ast.SelectorExpr:
+					id = fun.Sel
+				}
+				if id == nil {
+					return true
+				}
+				fn, ok := p.TypesInfo.Uses[id].(*types.Func)
+				if !ok || !strings.HasPrefix(fn.Name(), "Register") || !strings.HasSuffix(fn.Name(), "Server") {
+					return true
+				}
+				sig := fn.Type().(*types.Signature)
+				if sig.Recv() != nil || sig.Params().Len() != 2 {
+					return true
+				}
+				named, ok := sig.Params().At(1).Type().(*types.Named)
+				if !ok {
+					return true
+				}
+				if iface, ok := named.Underlying().(*types.Interface); ok && !seen[iface] {
+					seen[iface] = true
+					services = append(services, iface)
+				}
+				return true
+			})
+		}
+	})
+	return services
+}
+
+// callPaths records the shortest call paths found by a breadth-first walk
+// of the call graph from the entrypoint roots.
+type callPaths struct {
+	// calls maps "caller pkg\x00callee pkg" to the path ending in the first
+	// visited call from one package into the other.
+	calls map[string][]string
+}
+
+func walkCallPaths(cg *callgraph.Graph, roots []*ssa.Function) *callPaths {
+	cp := &callPaths{calls: make(map[string][]string)}
+	pathTo := make(map[*callgraph.Node][]string)
+	var queue []*callgraph.Node
+	for _, fn := range roots {
+		if n := cg.Nodes[fn]; n != nil && pathTo[n] == nil {
+			pathTo[n] = []string{fn.String()}
+			queue = append(queue, n)
+		}
+	}
+	for len(queue) > 0 {
+		n := queue[0]
+		queue = queue[1:]
+		callerPkg := funcPkgPath(n.Func)
+		for _, e := range n.Out {
+			callee := e.Callee
+			path := append(append([]string(nil), pathTo[n]...), callee.Func.String())
+			if calleePkg := funcPkgPath(callee.Func); callerPkg != "" && calleePkg != "" && calleePkg != callerPkg {
+				key := callerPkg + "\x00" + calleePkg
+				if _, ok := cp.calls[key]; !ok {
+					cp.calls[key] = path
+				}
+			}
+			if pathTo[callee] == nil {
+				pathTo[callee] = path
+				queue = append(queue, callee)
+			}
+		}
+	}
+	return cp
+}
+
+// forPackage returns a call path per capability of pkg, ending at the first
+// call into an import that confers it. A capability with no such call has no
+// path: a path into some other part of pkg would not explain it.
+func (cp *callPaths) forPackage(pkg string, imports map[string]*packages.Package) map[string][]string {
+	importPaths := make([]string, 0, len(imports))
+	for imp := range imports {
+		importPaths = append(importPaths, imp)
+	}
+	sort.Strings(importPaths)
+
+	paths := make(map[string][]string)
+	for _, imp := range importPaths {
+		for _, c := range goadapter.ImportCapabilities(imp) {
+			if _, done := paths[c]; done {
+				continue
+			}
+			if path, ok := cp.calls[pkg+"\x00"+imp]; ok {
+				paths[c] = path
+			}
+		}
+	}
+	if len(paths) == 0 {
+		return nil
+	}
+	return paths
+}
+
+func funcPkgPath(fn *ssa.Function) string {
+	if fn == nil || fn.Package() == nil {
+		return ""
+	}
+	return fn.Package().Pkg.Path()
+}
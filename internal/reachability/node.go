@@ -5,6 +5,7 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strings"
 
 	node "github.com/1homsi/gorisk/internal/adapters/node"
@@ -41,6 +42,11 @@ func analyzeNode(dir, entryFile string) ([]ReachabilityReport, error) {
 	// Walk all packages in the graph; a package is "reachable" if the project
 	// directly imports it (or transitively via another reachable package).
 	reachable := resolveReachable(imported, g.Edges)
+	chains := importChains(imported, g.Edges)
+	root := g.Main.Path
+	if entryFile != "" {
+		root = entryFile
+	}
 
 	seen := make(map[string]bool)
 	var reports []ReachabilityReport
@@ -57,11 +63,21 @@ func analyzeNode(dir, entryFile string) ([]ReachabilityReport, error) {
 		}
 		seen[pkgName] = true
 
-		reports = append(reports, ReachabilityReport{
+		r := ReachabilityReport{
 			Package:       pkgName,
 			ReachableCaps: pkg.Capabilities,
 			Reachable:     reachable[pkgName],
-		})
+		}
+		// Node reachability is import-level, so every capability of a
+		// package shares the import chain that reaches it.
+		if chain, ok := chains[pkgName]; ok {
+			path := append([]string{root}, chain...)
+			r.Paths = make(map[string][]string)
+			for _, c := range pkg.Capabilities.List() {
+				r.Paths[c] = path
+			}
+		}
+		reports = append(reports, r)
 	}
 
 	return reports, nil
@@ -163,6 +179,36 @@ func resolveReachable(seed map[string]bool, edges map[string][]string) map[strin
 	return reachable
 }
 
+// importChains does the same BFS as resolveReachable but records, for each
+// reached package, the chain of packages leading to it from a seed. Seeds
+// are visited in sorted order so the chosen chain is stable across runs.
+func importChains(seed map[string]bool, edges map[string][]string) map[string][]string {
+	chains := make(map[string][]string)
+	queue := make([]string, 0, len(seed))
+	for pkg := range seed {
+		queue = append(queue, pkg)
+	}
+	sort.Strings(queue)
+	for _, pkg := range queue {
+		if _, ok := chains[pkg]; !ok {
+			chains[pkg] = []string{pkg}
+		}
+	}
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+		for _, dep := range edges[cur] {
+			if _, ok := chains[dep]; ok {
+				continue
+			}
+			chain := append(append([]string(nil), chains[cur]...), dep)
+			chains[dep] = chain
+			queue = append(queue, dep)
+		}
+	}
+	return chains
+}
+
 // bareModuleName strips subpath exports to get the root package name.
 // e.g. "lodash/fp" → "lodash", "@scope/pkg/utils" → "@scope/pkg"
 func bareModuleName(spec string) string {
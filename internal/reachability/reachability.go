@@ -8,6 +8,10 @@ type ReachabilityReport struct {
 	Package       string
 	ReachableCaps capability.CapabilitySet
 	Reachable     bool
+	// Paths maps each reachable capability to the call path from the
+	// entrypoint to the capability site, outermost caller first. Analyzers
+	// that do not record paths leave it nil.
+	Paths map[string][]string
 }
 
 // Analyzer is the interface that language implementations satisfy.
@@ -3,7 +3,11 @@ package reachability
 import (
 	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
+
+	"golang.org/x/tools/go/packages"
 )
 
 func TestGoAnalyzer(t *testing.T) {
@@ -56,6 +60,17 @@ go 1.22
 	if !foundExec {
 		t.Error("Expected to find os/exec as reachable")
 	}
+
+	// The main package's exec capability is explained by a call path.
+	for _, r := range reports {
+		if r.Package != "test" {
+			continue
+		}
+		path := r.Paths["exec"]
+		if len(path) < 2 || path[0] != "test.main" {
+			t.Errorf("exec path for test = %v, want it to start at test.main", path)
+		}
+	}
 }
 
 func TestGoAnalyzerWithEntryFile(t *testing.T) {
@@ -109,6 +124,239 @@ go 1.22
 	}
 }
 
+func TestGoAnalyzerInterfaceEntryPoints(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	// A library with no main: only a router would ever call ServeHTTP.
+	dir := t.TempDir()
+	handlerGo := `package handler
+
+import (
+	"net/http"
+	"os/exec"
+)
+
+type Handler struct{}
+
+func (Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	exec.Command(r.URL.Query().Get("cmd")).Run()
+}
+`
+	if err := os.WriteFile(filepath.Join(dir, "handler.go"), []byte(handlerGo), 0600); err != nil {
+		t.Fatal(err)
+	}
+	goMod := `module example.com/handler
+go 1.22
+`
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte(goMod), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	reports, err := GoAnalyzer{}.Analyze(dir)
+	if err != nil {
+		t.Fatalf("Analyze() error = %v", err)
+	}
+	for _, r := range reports {
+		if r.Package != "example.com/handler" {
+			continue
+		}
+		if !r.Reachable {
+			t.Fatal("handler package not reachable through http.Handler.ServeHTTP")
+		}
+		path := r.Paths["exec"]
+		if len(path) < 2 || !strings.HasSuffix(path[0], ".ServeHTTP") || path[len(path)-1] != "os/exec.Command" {
+			t.Errorf("exec path = %v, want ServeHTTP → os/exec.Command", path)
+		}
+		return
+	}
+	t.Fatal("no report for example.com/handler")
+}
+
+func TestGoAnalyzerIgnoresUbiquitousInterfaces(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	// Implementing fmt.Stringer or error does not make a method an entry
+	// point: nothing here ever formats a Shell.
+	dir := t.TempDir()
+	shellGo := `package shell
+
+import "os/exec"
+
+type Shell struct{ cmd string }
+
+func (s Shell) String() string {
+	out, _ := exec.Command(s.cmd).Output()
+	return string(out)
+}
+
+func (s Shell) Error() string { return s.String() }
+`
+	if err := os.WriteFile(filepath.Join(dir, "shell.go"), []byte(shellGo), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/shell\ngo 1.22\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	reports, err := GoAnalyzer{}.Analyze(dir)
+	if err != nil {
+		t.Fatalf("Analyze() error = %v", err)
+	}
+	for _, r := range reports {
+		if r.Package == "example.com/shell" && len(r.Paths["exec"]) > 0 {
+			t.Errorf("exec reachable through %v, want no String or Error entry point", r.Paths["exec"])
+		}
+	}
+}
+
+func TestGoAnalyzerGRPCServiceEntryPoints(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	// main registers a handler it never calls: only the gRPC server would,
+	// through the generated service descriptor.
+	dir := t.TempDir()
+	files := map[string]string{
+		"go.mod": `module example.com/svc
+go 1.22
+`,
+		"grpc/grpc.go": `package grpc
+
+type ServiceRegistrar interface {
+	RegisterService(desc, impl any)
+}
+`,
+		"pb/greeter_grpc.pb.go": `package pb
+
+import "example.com/svc/grpc"
+
+type GreeterServer interface {
+	SayHello(name string) (string, error)
+}
+
+func RegisterGreeterServer(s grpc.ServiceRegistrar, srv GreeterServer) {
+	s.RegisterService(nil, srv)
+}
+`,
+		"handler/handler.go": `package handler
+
+import "os/exec"
+
+type Greeter struct{}
+
+func (*Greeter) SayHello(name string) (string, error) {
+	out, err := exec.Command("echo", name).Output()
+	return string(out), err
+}
+`,
+		"main.go": `package main
+
+import (
+	"example.com/svc/handler"
+	"example.com/svc/pb"
+)
+
+type registrar struct{}
+
+func (registrar) RegisterService(desc, impl any) {}
+
+func main() {
+	pb.RegisterGreeterServer(registrar{}, &handler.Greeter{})
+}
+`,
+	}
+	for name, src := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(src), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	reports, err := GoAnalyzer{}.Analyze(dir)
+	if err != nil {
+		t.Fatalf("Analyze() error = %v", err)
+	}
+	for _, r := range reports {
+		if r.Package != "example.com/svc/handler" {
+			continue
+		}
+		if !r.Reachable {
+			t.Fatal("handler package not reachable through the registered GreeterServer")
+		}
+		path := r.Paths["exec"]
+		if len(path) < 2 || !strings.HasSuffix(path[0], ".SayHello") || path[len(path)-1] != "os/exec.Command" {
+			t.Errorf("exec path = %v, want SayHello → os/exec.Command", path)
+		}
+		return
+	}
+	t.Fatal("no report for example.com/svc/handler")
+}
+
+func TestGoAnalyzerBlankImportInit(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	// A library that blank-imports a driver: nothing calls into the driver,
+	// but its init runs, and dials out, as soon as the library is linked.
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "driver"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	driverGo := `package driver
+
+import "net"
+
+func init() {
+	net.Dial("tcp", "collector.example.com:443")
+}
+`
+	if err := os.WriteFile(filepath.Join(dir, "driver", "driver.go"), []byte(driverGo), 0600); err != nil {
+		t.Fatal(err)
+	}
+	libGo := `package lib
+
+import _ "example.com/lib/driver"
+
+func Version() string { return "1.0" }
+`
+	if err := os.WriteFile(filepath.Join(dir, "lib.go"), []byte(libGo), 0600); err != nil {
+		t.Fatal(err)
+	}
+	goMod := `module example.com/lib
+go 1.22
+`
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte(goMod), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	reports, err := GoAnalyzer{}.Analyze(dir)
+	if err != nil {
+		t.Fatalf("Analyze() error = %v", err)
+	}
+	for _, r := range reports {
+		if r.Package != "example.com/lib/driver" {
+			continue
+		}
+		if !r.Reachable {
+			t.Fatal("blank-imported driver package not reachable through its init")
+		}
+		if !r.ReachableCaps.Has("network") {
+			t.Errorf("driver caps = %v, want network", r.ReachableCaps.List())
+		}
+		return
+	}
+	t.Fatal("no report for example.com/lib/driver")
+}
+
 func TestNodeAnalyzer(t *testing.T) {
 	if testing.Short() {
 		t.Skip("Skipping integration test in short mode")
@@ -226,6 +474,24 @@ crypto.createHash('sha256');
 	}
 }
 
+func TestCallPathsForPackageOnlyCalledImports(t *testing.T) {
+	cp := &callPaths{calls: map[string][]string{
+		"example.com/app\x00os/exec": {"example.com/app.main", "os/exec.Command"},
+	}}
+	imports := map[string]*packages.Package{"os/exec": nil, "net/http": nil}
+
+	paths := cp.forPackage("example.com/app", imports)
+	if got := paths["exec"]; !reflect.DeepEqual(got, []string{"example.com/app.main", "os/exec.Command"}) {
+		t.Errorf("exec path = %v, want main → os/exec.Command", got)
+	}
+	if got, ok := paths["network"]; ok {
+		t.Errorf("network path = %v, want none: net/http is never called", got)
+	}
+	if paths := cp.forPackage("example.com/other", imports); paths != nil {
+		t.Errorf("paths for a package without calls = %v, want nil", paths)
+	}
+}
+
 func TestReachabilityReport(t *testing.T) {
 	// Test ReachabilityReport struct
 	report := ReachabilityReport{
@@ -0,0 +1,67 @@
+package report
+
+import "os"
+
+// Palette holds the ANSI escape sequences used by text output. Every field
+// is empty when color is disabled, so callers can interpolate them
+// unconditionally.
+type Palette struct {
+	Reset  string
+	Red    string
+	Yellow string
+	Green  string
+	Cyan   string
+	Gray   string
+	Bold   string
+}
+
+var ansi = Palette{
+	Reset:  "\033[0m",
+	Red:    "\033[31m",
+	Yellow: "\033[33m",
+	Green:  "\033[32m",
+	Cyan:   "\033[36m",
+	Gray:   "\033[90m",
+	Bold:   "\033[1m",
+}
+
+var palette = ansi
+
+var (
+	colorReset  = ansi.Reset
+	colorRed    = ansi.Red
+	colorYellow = ansi.Yellow
+	colorGreen  = ansi.Green
+	colorBold   = ansi.Bold
+	colorCyan   = ansi.Cyan
+)
+
+// SetColor turns ANSI colors on or off for every text writer in this
+// package and for commands that read Colors.
+func SetColor(enabled bool) {
+	palette = Palette{}
+	if enabled {
+		palette = ansi
+	}
+	colorReset, colorRed, colorYellow = palette.Reset, palette.Red, palette.Yellow
+	colorGreen, colorBold, colorCyan = palette.Green, palette.Bold, palette.Cyan
+}
+
+// Colors returns the active palette.
+func Colors() Palette {
+	return palette
+}
+
+// ShouldColor reports whether output written to out should be colored:
+// not when noColor is set, when the NO_COLOR environment variable is
+// non-empty (https://no-color.org), or when out is not a terminal.
+func ShouldColor(out *os.File, noColor bool) bool {
+	if noColor || os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	fi, err := out.Stat()
+	if err != nil {
+		return false
+	}
+	return fi.Mode()&os.ModeCharDevice != 0
+}
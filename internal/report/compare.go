@@ -0,0 +1,275 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/1homsi/gorisk/internal/capability"
+)
+
+// FindingTransition describes how a single finding changed between a
+// baseline scan and the current one. Change uses the same vocabulary as
+// history.Diff: "new", "resolved", "escalated", or "improved".
+type FindingTransition struct {
+	Kind    string `json:"kind"` // "capability" | "taint"
+	Package string `json:"package"`
+	Finding string `json:"finding"` // capability list or "source→sink"
+	OldRisk string `json:"old_risk,omitempty"`
+	NewRisk string `json:"new_risk,omitempty"`
+	Change  string `json:"change"`
+}
+
+type findingState struct {
+	kind, pkg, finding, risk string
+	score                    int
+}
+
+// Baseline strategies decide when a finding of the current scan is the same
+// finding as one in the baseline.
+const (
+	// BaselineExact matches package, capability or rule, and source line
+	// (file:line of the evidence or taint steps), so a finding that moved,
+	// even a line down, is new (and its old location resolved).
+	BaselineExact = "exact"
+	// BaselineRule matches package and rule, ignoring lines. The default.
+	BaselineRule = "rule"
+	// BaselineModule matches per module: a module's findings are new only
+	// when the baseline had no finding of that kind in the module.
+	BaselineModule = "module"
+)
+
+// ValidBaselineStrategy reports whether s names a baseline strategy.
+func ValidBaselineStrategy(s string) bool {
+	switch s {
+	case BaselineExact, BaselineRule, BaselineModule:
+		return true
+	}
+	return false
+}
+
+// CompareFindings compares base and cur finding by finding: each package's
+// capability report and each package source→sink taint finding. Unchanged
+// findings are omitted. A finding at the same level escalates or improves
+// when its capability score moved.
+func CompareFindings(base, cur ScanReport) []FindingTransition {
+	return CompareFindingsBy(base, cur, BaselineRule)
+}
+
+// CompareFindingsBy is CompareFindings with the matching granularity of
+// strategy (BaselineExact, BaselineRule, or BaselineModule). Under
+// BaselineModule a transition's Package is the module path and its Finding
+// lists every finding of that kind in the module.
+func CompareFindingsBy(base, cur ScanReport, strategy string) []FindingTransition {
+	oldF := collectFindings(base, strategy)
+	newF := collectFindings(cur, strategy)
+
+	var out []FindingTransition
+	for key, n := range newF {
+		o, existed := oldF[key]
+		t := FindingTransition{Kind: n.kind, Package: n.pkg, Finding: n.finding, NewRisk: n.risk}
+		if !existed {
+			t.Change = "new"
+			out = append(out, t)
+			continue
+		}
+		t.OldRisk = o.risk
+		ov, nv := capability.RiskValue(o.risk), capability.RiskValue(n.risk)
+		switch {
+		case nv > ov, nv == ov && n.score > o.score:
+			t.Change = "escalated"
+		case nv < ov, nv == ov && n.score < o.score:
+			t.Change = "improved"
+		default:
+			continue
+		}
+		out = append(out, t)
+	}
+	for key, o := range oldF {
+		if _, ok := newF[key]; !ok {
+			out = append(out, FindingTransition{Kind: o.kind, Package: o.pkg, Finding: o.finding, OldRisk: o.risk, Change: "resolved"})
+		}
+	}
+
+	order := map[string]int{"escalated": 0, "new": 1, "improved": 2, "resolved": 3}
+	sort.Slice(out, func(i, j int) bool {
+		a, b := out[i], out[j]
+		if order[a.Change] != order[b.Change] {
+			return order[a.Change] < order[b.Change]
+		}
+		if a.Package != b.Package {
+			return a.Package < b.Package
+		}
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		return a.Finding < b.Finding
+	})
+	return out
+}
+
+func collectFindings(r ScanReport, strategy string) map[string]findingState {
+	out := make(map[string]findingState)
+	add := func(key string, f findingState, module string) {
+		switch strategy {
+		case BaselineExact:
+			out[key] = f
+		case BaselineModule:
+			if module == "" {
+				module = f.pkg
+			}
+			key = f.kind + "\x00" + module
+			prev, ok := out[key]
+			if !ok {
+				out[key] = findingState{kind: f.kind, pkg: module, finding: f.finding, risk: f.risk, score: f.score}
+				return
+			}
+			prev.finding = mergeFindingNames(prev.finding, f.finding)
+			if capability.RiskValue(f.risk) > capability.RiskValue(prev.risk) {
+				prev.risk = f.risk
+			}
+			prev.score = max(prev.score, f.score)
+			out[key] = prev
+		default:
+			out[key] = f
+		}
+	}
+	for _, cr := range r.Capabilities {
+		names := capNames(cr.Capabilities)
+		if cr.Capabilities.Score == 0 && len(names) == 0 {
+			continue
+		}
+		if strategy == BaselineExact {
+			for _, name := range names {
+				var lines []string
+				for _, ev := range cr.Capabilities.Evidence[name] {
+					lines = append(lines, sourceLine(ev.File, ev.Line))
+				}
+				lines = distinctLines(lines)
+				if len(lines) == 0 {
+					lines = []string{""}
+				}
+				for _, line := range lines {
+					finding := name
+					if line != "" {
+						finding += " (" + line + ")"
+					}
+					add("capability\x00"+cr.Package+"\x00"+name+"\x00"+line, findingState{
+						kind:    "capability",
+						pkg:     cr.Package,
+						finding: finding,
+						risk:    cr.RiskLevel,
+						score:   cr.Capabilities.Score,
+					}, cr.Module)
+				}
+			}
+			continue
+		}
+		add("capability\x00"+cr.Package, findingState{
+			kind:    "capability",
+			pkg:     cr.Package,
+			finding: strings.Join(names, ", "),
+			risk:    cr.RiskLevel,
+			score:   cr.Capabilities.Score,
+		}, cr.Module)
+	}
+	for _, tf := range r.TaintFindings {
+		pair := tf.Source + "→" + tf.Sink
+		key := "taint\x00" + tf.Package + "\x00" + pair
+		if strategy == BaselineExact {
+			lines := make([]string, 0, len(tf.Steps))
+			for _, st := range tf.Steps {
+				lines = append(lines, sourceLine(st.File, st.Line))
+			}
+			key += "\x00" + strings.Join(distinctLines(lines), ",")
+		}
+		add(key, findingState{
+			kind:    "taint",
+			pkg:     tf.Package,
+			finding: pair,
+			risk:    tf.Risk,
+		}, tf.Module)
+	}
+	return out
+}
+
+// sourceLine formats a file:line location; it is empty without a file and
+// just the file without a line.
+func sourceLine(file string, line int) string {
+	if file == "" || line <= 0 {
+		return file
+	}
+	return fmt.Sprintf("%s:%d", file, line)
+}
+
+// distinctLines returns the sorted, distinct non-empty entries of lines.
+func distinctLines(lines []string) []string {
+	seen := make(map[string]bool)
+	var out []string
+	for _, f := range lines {
+		if f != "" && !seen[f] {
+			seen[f] = true
+			out = append(out, f)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// mergeFindingNames joins two comma-separated finding lists, sorted and
+// without duplicates.
+func mergeFindingNames(a, b string) string {
+	seen := make(map[string]bool)
+	var out []string
+	for _, name := range strings.Split(a+", "+b, ", ") {
+		if name != "" && !seen[name] {
+			seen[name] = true
+			out = append(out, name)
+		}
+	}
+	sort.Strings(out)
+	return strings.Join(out, ", ")
+}
+
+// capNames returns the capability names of cs. A set decoded from JSON has
+// lost its unexported list, so fall back to the evidence keys.
+func capNames(cs capability.CapabilitySet) []string {
+	if names := cs.List(); len(names) > 0 {
+		return names
+	}
+	names := make([]string, 0, len(cs.Evidence))
+	for name := range cs.Evidence {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// WriteFindingTransitions prints finding-level severity transitions with
+// the same markers as `gorisk history diff`.
+func WriteFindingTransitions(w io.Writer, transitions []FindingTransition) {
+	fmt.Fprintf(w, "%s%s=== Baseline Comparison ===%s\n\n", colorBold, colorCyan, colorReset)
+	if len(transitions) == 0 {
+		fmt.Fprintln(w, "no finding changes since baseline")
+		return
+	}
+
+	counts := make(map[string]int)
+	for _, t := range transitions {
+		counts[t.Change]++
+		label := t.Package + "  " + t.Finding
+		switch t.Change {
+		case "new":
+			fmt.Fprintf(w, "  %s+  %-70s  %s%s\n", riskColor(t.NewRisk), label, t.NewRisk, colorReset)
+		case "resolved":
+			fmt.Fprintf(w, "  %s-  %-70s  %s%s\n", colorGreen, label, t.OldRisk, colorReset)
+		case "escalated":
+			fmt.Fprintf(w, "  %s↑  %-70s  %s → %s%s\n", riskColor(t.NewRisk), label, t.OldRisk, t.NewRisk, colorReset)
+		case "improved":
+			fmt.Fprintf(w, "  %s↓  %-70s  %s → %s%s\n", colorGreen, label, t.OldRisk, t.NewRisk, colorReset)
+		}
+	}
+	fmt.Fprintf(w, "\n  new=%d  resolved=%d  escalated=%d  improved=%d\n",
+		counts["new"], counts["resolved"], counts["escalated"], counts["improved"])
+}
@@ -4,26 +4,49 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"sort"
 )
 
 type CapDiffReport struct {
 	Module     string
+	NewModule  string `json:",omitempty"` // set when the new version moved to another module path (e.g. /v2)
 	OldVersion string
 	NewVersion string
 	Diffs      []PackageCapDiff
+	Breaking   []BreakingChange `json:",omitempty"` // exported API changes, when comparing local module sources
 	Escalated  bool
 }
 
 type PackageCapDiff struct {
-	Package   string
-	Added     []string
-	Removed   []string
-	Escalated bool
+	Package    string
+	OldPackage string `json:",omitempty"` // import path in the old version, when it changed
+	Added      []string
+	Removed    []string
+	Escalated  bool
 }
 
 func WriteCapDiff(w io.Writer, r CapDiffReport) {
 	fmt.Fprintf(w, "%s%s=== Capability Diff ===%s\n", colorBold, colorCyan, colorReset)
-	fmt.Fprintf(w, "%s → %s  (%s)\n\n", r.OldVersion, r.NewVersion, r.Module)
+	fmt.Fprintf(w, "%s → %s  (%s)\n", r.OldVersion, r.NewVersion, r.Module)
+	if r.NewModule != "" && r.NewModule != r.Module {
+		fmt.Fprintf(w, "%sImport path changed: %s → %s — update imports when upgrading%s\n",
+			colorYellow, r.Module, r.NewModule, colorReset)
+	}
+	fmt.Fprintln(w)
+
+	if len(r.Breaking) > 0 {
+		fmt.Fprintf(w, "%sBreaking Changes:%s\n", colorBold, colorReset)
+		for _, b := range r.Breaking {
+			fmt.Fprintf(w, "  %s[%s]%s %s\n", colorRed, b.Kind, colorReset, b.Symbol)
+			if b.OldSig != "" {
+				fmt.Fprintf(w, "    old: %s\n", b.OldSig)
+			}
+			if b.NewSig != "" {
+				fmt.Fprintf(w, "    new: %s\n", b.NewSig)
+			}
+		}
+		fmt.Fprintln(w)
+	}
 
 	if len(r.Diffs) == 0 {
 		fmt.Fprintf(w, "%sNo capability changes.%s\n", colorGreen, colorReset)
@@ -35,7 +58,11 @@ func WriteCapDiff(w io.Writer, r CapDiffReport) {
 		if d.Escalated {
 			prefix = colorRed + "⚠" + colorReset
 		}
-		fmt.Fprintf(w, "%s %s%s%s\n", prefix, colorBold, d.Package, colorReset)
+		fmt.Fprintf(w, "%s %s%s%s", prefix, colorBold, d.Package, colorReset)
+		if d.OldPackage != "" {
+			fmt.Fprintf(w, "  (was %s)", d.OldPackage)
+		}
+		fmt.Fprintln(w)
 		for _, a := range d.Added {
 			fmt.Fprintf(w, "    %s+ %s%s\n", colorRed, a, colorReset)
 		}
@@ -50,6 +77,40 @@ func WriteCapDiff(w io.Writer, r CapDiffReport) {
 	}
 }
 
+// WriteCapDiffUnified writes the capability diff as a git-style unified diff:
+// one file section per package, with removed capabilities as "-" lines and
+// added ones as "+" lines. Output is uncoloured so it can be pasted or parsed.
+func WriteCapDiffUnified(w io.Writer, r CapDiffReport) {
+	diffs := make([]PackageCapDiff, len(r.Diffs))
+	copy(diffs, r.Diffs)
+	sort.Slice(diffs, func(i, j int) bool { return diffs[i].Package < diffs[j].Package })
+
+	for _, d := range diffs {
+		oldPkg := d.Package
+		if d.OldPackage != "" {
+			oldPkg = d.OldPackage
+		}
+		fmt.Fprintf(w, "diff --gorisk a/%s b/%s\n", oldPkg, d.Package)
+		fmt.Fprintf(w, "--- a/%s@%s\n", oldPkg, r.OldVersion)
+		fmt.Fprintf(w, "+++ b/%s@%s\n", d.Package, r.NewVersion)
+		fmt.Fprintf(w, "@@ %s %s @@\n", hunkRange("-", len(d.Removed)), hunkRange("+", len(d.Added)))
+		for _, rm := range d.Removed {
+			fmt.Fprintf(w, "-%s\n", rm)
+		}
+		for _, a := range d.Added {
+			fmt.Fprintf(w, "+%s\n", a)
+		}
+	}
+}
+
+// hunkRange formats one side of a hunk header; an empty side is "-0,0".
+func hunkRange(sign string, n int) string {
+	if n == 0 {
+		return sign + "0,0"
+	}
+	return fmt.Sprintf("%s1,%d", sign, n)
+}
+
 func WriteCapDiffJSON(w io.Writer, r CapDiffReport) error {
 	enc := json.NewEncoder(w)
 	enc.SetIndent("", "  ")
@@ -0,0 +1,153 @@
+package report
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/1homsi/gorisk/internal/capability"
+)
+
+// gitlabIssue is one entry of a GitLab Code Quality report.
+type gitlabIssue struct {
+	Description string         `json:"description"`
+	CheckName   string         `json:"check_name"`
+	Fingerprint string         `json:"fingerprint"`
+	Severity    string         `json:"severity"` // info | minor | major | critical | blocker
+	Categories  []string       `json:"categories"`
+	Location    gitlabLocation `json:"location"`
+}
+
+type gitlabLocation struct {
+	Path  string      `json:"path"`
+	Lines gitlabLines `json:"lines"`
+}
+
+type gitlabLines struct {
+	Begin int `json:"begin"`
+}
+
+// WriteScanGitLab writes r as a GitLab Code Quality report: a JSON array of
+// issues, one per capability of each package, per taint finding, and per
+// module with a low health score. Fingerprints hash what the issue is
+// about, not where, so an issue keeps its fingerprint when code moves and
+// GitLab does not report it as new.
+func WriteScanGitLab(w io.Writer, r ScanReport) error {
+	issues := make([]gitlabIssue, 0)
+
+	for _, cr := range r.Capabilities {
+		for _, c := range capNames(cr.Capabilities) {
+			path, line := gitlabEvidenceLocation(cr.Capabilities.Evidence[c], r.manifest(cr.Package))
+			issues = append(issues, gitlabIssue{
+				Description: fmt.Sprintf("Package %s has capability %s (%s risk, score %d)", cr.Package, c, cr.RiskLevel, cr.Capabilities.Score),
+				CheckName:   "gorisk/capability/" + c,
+				Fingerprint: gitlabFingerprint("capability", cr.Package, c),
+				Severity:    gitlabSeverity(cr.RiskLevel),
+				Categories:  []string{"Security"},
+				Location:    gitlabLocation{Path: path, Lines: gitlabLines{Begin: line}},
+			})
+		}
+	}
+
+	for _, tf := range r.TaintFindings {
+		path, line := r.manifest(tf.Package), 1
+		if len(tf.Steps) > 0 {
+			sink := tf.Steps[len(tf.Steps)-1]
+			path, line = relPath(sink.File), max(sink.Line, 1)
+		}
+		issues = append(issues, gitlabIssue{
+			Description: fmt.Sprintf("Package %s: %s → %s (%s)", tf.Package, tf.Source, tf.Sink, tf.Note),
+			CheckName:   "gorisk/taint/" + tf.Source + "-" + tf.Sink,
+			Fingerprint: gitlabFingerprint("taint", tf.Package, tf.Source, tf.Sink),
+			Severity:    gitlabSeverity(tf.Risk),
+			Categories:  []string{"Security"},
+			Location:    gitlabLocation{Path: path, Lines: gitlabLines{Begin: line}},
+		})
+	}
+
+	for _, hr := range r.Health {
+		if hr.Unknown || hr.Score >= 40 {
+			continue
+		}
+		severity := "info"
+		if hr.CVECount > 0 || hr.Archived {
+			severity = "major"
+		}
+		issues = append(issues, gitlabIssue{
+			Description: fmt.Sprintf("Module %s has low health score: %d", hr.Module, hr.Score),
+			CheckName:   "gorisk/health",
+			Fingerprint: gitlabFingerprint("health", hr.Module),
+			Severity:    severity,
+			Categories:  []string{"Security"},
+			Location:    gitlabLocation{Path: r.manifest(hr.Module), Lines: gitlabLines{Begin: 1}},
+		})
+	}
+
+	return NewJSONEncoder(w).Encode(issues)
+}
+
+// gitlabSeverity maps a risk level to a Code Quality severity.
+func gitlabSeverity(risk string) string {
+	switch strings.ToUpper(risk) {
+	case "HIGH":
+		return "critical"
+	case "MEDIUM":
+		return "major"
+	case "LOW":
+		return "minor"
+	default:
+		return "info"
+	}
+}
+
+// gitlabFingerprint returns a stable hex SHA-256 of parts.
+func gitlabFingerprint(parts ...string) string {
+	sum := sha256.Sum256([]byte(strings.Join(parts, "\x00")))
+	return hex.EncodeToString(sum[:])
+}
+
+// manifests are the project manifests issues without a source location
+// point at, by language; anything else falls back to go.mod.
+var manifests = map[string]string{
+	"node":   "package.json",
+	"php":    "composer.json",
+	"rust":   "Cargo.toml",
+	"ruby":   "Gemfile",
+	"dart":   "pubspec.yaml",
+	"elixir": "mix.exs",
+	"swift":  "Package.swift",
+}
+
+// manifest returns the manifest of the language of the package or module
+// path, for issues that have no file of their own.
+func (r ScanReport) manifest(path string) string {
+	if m, ok := manifests[r.Languages[path]]; ok {
+		return m
+	}
+	return "go.mod"
+}
+
+// gitlabEvidenceLocation returns the first file and line, in path order,
+// of evs. Evidence without a file (install scripts, import-only matches
+// in some languages) falls back to line 1 of manifest.
+func gitlabEvidenceLocation(evs []capability.CapabilityEvidence, manifest string) (string, int) {
+	located := make([]capability.CapabilityEvidence, 0, len(evs))
+	for _, ev := range evs {
+		if ev.File != "" {
+			located = append(located, ev)
+		}
+	}
+	if len(located) == 0 {
+		return manifest, 1
+	}
+	sort.Slice(located, func(i, j int) bool {
+		if located[i].File != located[j].File {
+			return located[i].File < located[j].File
+		}
+		return located[i].Line < located[j].Line
+	})
+	return relPath(located[0].File), max(located[0].Line, 1)
+}
@@ -3,34 +3,46 @@ package report
 import (
 	"encoding/json"
 	"io"
+	"sync/atomic"
 )
 
-func WriteCapabilitiesJSON(w io.Writer, reports []CapabilityReport) error {
+// compactJSON selects single-line JSON output; indented is the default.
+var compactJSON atomic.Bool
+
+// SetCompactJSON makes NewJSONEncoder, and every Write*JSON function built on
+// it, emit each document on a single line without indentation, for log
+// storage and machine consumption. SetCompactJSON(false) restores indented
+// output.
+func SetCompactJSON(compact bool) {
+	compactJSON.Store(compact)
+}
+
+// NewJSONEncoder returns an encoder for w that indents by two spaces unless
+// compact output was selected with SetCompactJSON.
+func NewJSONEncoder(w io.Writer) *json.Encoder {
 	enc := json.NewEncoder(w)
-	enc.SetIndent("", "  ")
-	return enc.Encode(reports)
+	if !compactJSON.Load() {
+		enc.SetIndent("", "  ")
+	}
+	return enc
+}
+
+func WriteCapabilitiesJSON(w io.Writer, reports []CapabilityReport) error {
+	return NewJSONEncoder(w).Encode(reports)
 }
 
 func WriteHealthJSON(w io.Writer, reports []HealthReport) error {
-	enc := json.NewEncoder(w)
-	enc.SetIndent("", "  ")
-	return enc.Encode(reports)
+	return NewJSONEncoder(w).Encode(reports)
 }
 
 func WriteUpgradeJSON(w io.Writer, r UpgradeReport) error {
-	enc := json.NewEncoder(w)
-	enc.SetIndent("", "  ")
-	return enc.Encode(r)
+	return NewJSONEncoder(w).Encode(r)
 }
 
 func WriteImpactJSON(w io.Writer, r ImpactReport) error {
-	enc := json.NewEncoder(w)
-	enc.SetIndent("", "  ")
-	return enc.Encode(r)
+	return NewJSONEncoder(w).Encode(r)
 }
 
 func WriteScanJSON(w io.Writer, r ScanReport) error {
-	enc := json.NewEncoder(w)
-	enc.SetIndent("", "  ")
-	return enc.Encode(r)
+	return NewJSONEncoder(w).Encode(r)
 }
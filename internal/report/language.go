@@ -0,0 +1,76 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/1homsi/gorisk/internal/taint"
+)
+
+// LanguageReport is the slice of a scan belonging to one source adapter in
+// a multi-language project, with its own pass/fail verdict.
+type LanguageReport struct {
+	Language      string               `json:"language"`
+	Capabilities  []CapabilityReport   `json:"capabilities"`
+	TaintFindings []taint.TaintFinding `json:"taint_findings,omitempty"`
+	Passed        bool                 `json:"passed"`
+	FailReason    string               `json:"fail_reason,omitempty"`
+}
+
+// SplitByLanguage groups the capability reports and taint findings of sr by
+// the language of their package (pkgLang maps import path to language;
+// unknown packages fall under "unknown"). failures holds the first failure
+// reason per language; a language without one passed. Sections are sorted
+// by language name.
+func SplitByLanguage(sr ScanReport, pkgLang, failures map[string]string) []LanguageReport {
+	byLang := make(map[string]*LanguageReport)
+	section := func(lang string) *LanguageReport {
+		if lang == "" {
+			lang = "unknown"
+		}
+		lr, ok := byLang[lang]
+		if !ok {
+			lr = &LanguageReport{Language: lang, Passed: true}
+			byLang[lang] = lr
+		}
+		return lr
+	}
+	for _, cr := range sr.Capabilities {
+		lr := section(pkgLang[cr.Package])
+		lr.Capabilities = append(lr.Capabilities, cr)
+	}
+	for _, tf := range sr.TaintFindings {
+		lr := section(pkgLang[tf.Package])
+		lr.TaintFindings = append(lr.TaintFindings, tf)
+	}
+	for lang, reason := range failures {
+		lr := section(lang)
+		lr.Passed = false
+		lr.FailReason = reason
+	}
+
+	out := make([]LanguageReport, 0, len(byLang))
+	for _, lr := range byLang {
+		out = append(out, *lr)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Language < out[j].Language })
+	return out
+}
+
+// WriteLanguageSections prints one labeled capability and taint section per
+// language, each ending with that language's verdict.
+func WriteLanguageSections(w io.Writer, sections []LanguageReport) {
+	for _, lr := range sections {
+		fmt.Fprintf(w, "%s%s##### %s #####%s\n\n", colorBold, colorCyan, strings.ToUpper(lr.Language), colorReset)
+		WriteCapabilities(w, lr.Capabilities)
+		fmt.Fprintln(w)
+		WriteTaintFindings(w, lr.TaintFindings)
+		if lr.Passed {
+			fmt.Fprintf(w, "%s%s✓ %s PASSED%s\n\n", colorBold, colorGreen, lr.Language, colorReset)
+		} else {
+			fmt.Fprintf(w, "%s%s✗ %s FAILED%s: %s\n\n", colorBold, colorRed, lr.Language, colorReset, lr.FailReason)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// WriteScanMetrics writes the scan report as OpenMetrics text exposition,
+// suitable for pushing to a Prometheus Pushgateway. Per-module series are
+// emitted for capability score and CVE count; everything else is aggregate.
+func WriteScanMetrics(w io.Writer, r ScanReport) error {
+	var sb strings.Builder
+
+	gauge := func(name, help string) {
+		fmt.Fprintf(&sb, "# TYPE %s gauge\n", name)
+		fmt.Fprintf(&sb, "# HELP %s %s\n", name, help)
+	}
+
+	riskCounts := map[string]int{"HIGH": 0, "MEDIUM": 0, "LOW": 0}
+	moduleScores := make(map[string]int)
+	totalScore := 0
+	for _, cr := range r.Capabilities {
+		riskCounts[cr.RiskLevel]++
+		moduleScores[cr.Module] += cr.Capabilities.Score
+		totalScore += cr.Capabilities.Score
+	}
+
+	gauge("gorisk_packages", "Number of packages by capability risk level.")
+	for _, level := range []string{"HIGH", "MEDIUM", "LOW"} {
+		fmt.Fprintf(&sb, "gorisk_packages{risk=%q} %d\n", level, riskCounts[level])
+	}
+
+	gauge("gorisk_capability_score", "Sum of package capability scores.")
+	fmt.Fprintf(&sb, "gorisk_capability_score %d\n", totalScore)
+
+	gauge("gorisk_module_capability_score", "Sum of package capability scores per module.")
+	for _, mod := range sortedKeys(moduleScores) {
+		fmt.Fprintf(&sb, "gorisk_module_capability_score{module=\"%s\"} %d\n", escapeLabel(mod), moduleScores[mod])
+	}
+
+	totalCVEs := 0
+	for _, hr := range r.Health {
+		totalCVEs += hr.CVECount
+	}
+	gauge("gorisk_cves", "Known CVEs across scored modules (requires --online).")
+	fmt.Fprintf(&sb, "gorisk_cves %d\n", totalCVEs)
+
+	gauge("gorisk_module_cves", "Known CVEs per module (requires --online).")
+	for _, hr := range r.Health {
+		fmt.Fprintf(&sb, "gorisk_module_cves{module=\"%s\"} %d\n", escapeLabel(hr.Module), hr.CVECount)
+	}
+
+	taintCounts := map[string]int{"HIGH": 0, "MEDIUM": 0, "LOW": 0}
+	for _, tf := range r.TaintFindings {
+		taintCounts[tf.Risk]++
+	}
+	gauge("gorisk_taint_findings", "Number of taint findings by risk level.")
+	for _, level := range []string{"HIGH", "MEDIUM", "LOW"} {
+		fmt.Fprintf(&sb, "gorisk_taint_findings{risk=%q} %d\n", level, taintCounts[level])
+	}
+
+	passed := 0
+	if r.Passed {
+		passed = 1
+	}
+	gauge("gorisk_scan_passed", "1 if the scan passed the configured gate, 0 otherwise.")
+	fmt.Fprintf(&sb, "gorisk_scan_passed %d\n", passed)
+
+	sb.WriteString("# EOF\n")
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// labelEscaper escapes a label value per the OpenMetrics text format.
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabel(s string) string {
+	return labelEscaper.Replace(s)
+}
+
+func sortedKeys(m map[string]int) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
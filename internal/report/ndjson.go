@@ -0,0 +1,126 @@
+package report
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/1homsi/gorisk/internal/taint"
+)
+
+// NDJSON line types, carried in each line's "type" field.
+const (
+	NDJSONCapability = "capability"
+	NDJSONHealth     = "health"
+	NDJSONTaint      = "taint"
+	NDJSONSummary    = "summary"
+)
+
+type ndjsonCapability struct {
+	Type string `json:"type"`
+	CapabilityReport
+}
+
+type ndjsonHealth struct {
+	Type string `json:"type"`
+	HealthReport
+}
+
+type ndjsonTaint struct {
+	Type string `json:"type"`
+	taint.TaintFinding
+}
+
+type ndjsonSummary struct {
+	Type          string `json:"type"`
+	SchemaVersion string `json:"schema_version,omitempty"`
+	GraphChecksum string `json:"graph_checksum,omitempty"`
+	Passed        bool   `json:"passed"`
+	FailReason    string `json:"fail_reason,omitempty"`
+}
+
+// NDJSONWriter streams a scan report as newline-delimited JSON, one object
+// per line with a "type" discriminator, so a scan can emit each part as
+// its phase finishes. When the underlying writer can be flushed (e.g. a
+// bufio.Writer), it is flushed after every line so consumers see findings
+// as they arrive.
+type NDJSONWriter struct {
+	enc   *json.Encoder
+	flush func() error
+}
+
+// NewNDJSONWriter returns an NDJSONWriter writing to w.
+func NewNDJSONWriter(w io.Writer) *NDJSONWriter {
+	n := &NDJSONWriter{enc: json.NewEncoder(w)}
+	if f, ok := w.(interface{ Flush() error }); ok {
+		n.flush = f.Flush
+	}
+	return n
+}
+
+func (n *NDJSONWriter) emit(v any) error {
+	if err := n.enc.Encode(v); err != nil {
+		return err
+	}
+	if n.flush != nil {
+		return n.flush()
+	}
+	return nil
+}
+
+// Capabilities writes one "capability" line per report.
+func (n *NDJSONWriter) Capabilities(reports []CapabilityReport) error {
+	for _, cr := range reports {
+		if err := n.emit(ndjsonCapability{Type: NDJSONCapability, CapabilityReport: cr}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Taint writes one "taint" line per finding.
+func (n *NDJSONWriter) Taint(findings []taint.TaintFinding) error {
+	for _, tf := range findings {
+		if err := n.emit(ndjsonTaint{Type: NDJSONTaint, TaintFinding: tf}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Health writes one "health" line per report.
+func (n *NDJSONWriter) Health(reports []HealthReport) error {
+	for _, hr := range reports {
+		if err := n.emit(ndjsonHealth{Type: NDJSONHealth, HealthReport: hr}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Summary writes the closing "summary" line with the verdict of r.
+func (n *NDJSONWriter) Summary(r ScanReport) error {
+	return n.emit(ndjsonSummary{
+		Type:          NDJSONSummary,
+		SchemaVersion: r.SchemaVersion,
+		GraphChecksum: r.GraphChecksum,
+		Passed:        r.Passed,
+		FailReason:    r.FailReason,
+	})
+}
+
+// WriteScanNDJSON writes a finished scan report as newline-delimited JSON:
+// its capability reports, taint findings and health reports, in the order
+// a streaming scan emits them, followed by a summary line.
+func WriteScanNDJSON(w io.Writer, r ScanReport) error {
+	n := NewNDJSONWriter(w)
+	if err := n.Capabilities(r.Capabilities); err != nil {
+		return err
+	}
+	if err := n.Taint(r.TaintFindings); err != nil {
+		return err
+	}
+	if err := n.Health(r.Health); err != nil {
+		return err
+	}
+	return n.Summary(r)
+}
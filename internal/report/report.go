@@ -13,16 +13,45 @@ type CapabilityReport struct {
 	Module       string
 	Capabilities capability.CapabilitySet
 	RiskLevel    string
+	Depth        int `json:",omitempty"` // module hops from the main module to the package (0 = main module or unreached)
 }
 
 type HealthReport struct {
-	Module   string
-	Version  string
-	Score    int
-	Archived bool
-	CVECount int
-	CVEs     []string
-	Signals  map[string]int
+	Module          string
+	Version         string
+	Score           int
+	Archived        bool
+	Abandoned       bool   `json:",omitempty"` // no recent release or a long-outdated go directive
+	AbandonedReason string `json:",omitempty"`
+	CVECount        int
+	CVEs            []string
+	FixedVersion    string              `json:",omitempty"` // highest OSV fixed version across CVEs
+	Aliases         map[string][]string `json:",omitempty"` // OSV ID -> CVE/GHSA aliases
+	NotAffected     []VEXSuppression    `json:",omitempty"` // CVEs a VEX document marks not_affected (--vex)
+	PinnedCommit    string              `json:",omitempty"` // commit a pseudo-version pins
+	OffBranch       bool                `json:",omitempty"` // PinnedCommit is not on the default branch
+	Signals         map[string]int
+	Unknown         bool `json:",omitempty"` // private module (GOPRIVATE); not scored
+}
+
+// VEXSuppression is a vulnerability a VEX document declared not exploitable
+// in a module. It is excluded from CVECount and the health score but kept in
+// the report for visibility.
+type VEXSuppression struct {
+	ID            string `json:"id"`
+	Justification string `json:"justification,omitempty"`
+	Impact        string `json:"impact,omitempty"`
+}
+
+// ExceptionRecord is a policy exception applied during a scan, with who
+// approved it and why.
+type ExceptionRecord struct {
+	Package      string   `json:"package"`
+	Capabilities []string `json:"capabilities,omitempty"`
+	Taint        []string `json:"taint,omitempty"`
+	Expires      string   `json:"expires,omitempty"`
+	Owner        string   `json:"owner,omitempty"`
+	Reason       string   `json:"reason,omitempty"`
 }
 
 type UpgradeReport struct {
@@ -52,14 +81,24 @@ type ImpactReport struct {
 }
 
 type ScanReport struct {
-	SchemaVersion string `json:"schema_version,omitempty"`
-	GraphChecksum string `json:"graph_checksum,omitempty"`
-	Capabilities  []CapabilityReport
-	Health        []HealthReport             // only populated with --online
-	TaintFindings []taint.TaintFinding       `json:"taint_findings,omitempty"`
-	Topology      *topology.TopologyReport   `json:"topology,omitempty"`
-	Integrity     *integrity.IntegrityReport `json:"integrity,omitempty"`
-	VersionDiff   *versiondiff.DiffReport    `json:"version_diff,omitempty"`
-	Passed        bool
-	FailReason    string
+	SchemaVersion       string `json:"schema_version,omitempty"`
+	GraphChecksum       string `json:"graph_checksum,omitempty"`
+	Capabilities        []CapabilityReport
+	Health              []HealthReport             // only populated with --online
+	TaintFindings       []taint.TaintFinding       `json:"taint_findings,omitempty"`
+	Topology            *topology.TopologyReport   `json:"topology,omitempty"`
+	Integrity           *integrity.IntegrityReport `json:"integrity,omitempty"`
+	VersionDiff         *versiondiff.DiffReport    `json:"version_diff,omitempty"`
+	Informational       []CapabilityReport         `json:"informational,omitempty"`        // --strict-confidence demotions
+	Trusted             []CapabilityReport         `json:"trusted,omitempty"`              // policy trusted_prefixes, not gated
+	BaselineTransitions []FindingTransition        `json:"baseline_transitions,omitempty"` // --baseline-compare
+	Suggestions         []Suggestion               `json:"suggestions,omitempty"`          // --suggest
+	TaintGroups         []TaintGroup               `json:"taint_groups,omitempty"`         // --group-findings
+	Exceptions          []ExceptionRecord          `json:"exceptions,omitempty"`           // applied policy exceptions
+	ByLanguage          []LanguageReport           `json:"by_language,omitempty"`          // --by-language
+	Submodules          []SubmoduleReport          `json:"submodules,omitempty"`           // --include-submodules
+	OmittedFindings     int                        `json:"-"`                              // text-only: hidden by --max-findings
+	Languages           map[string]string          `json:"-"`                              // package and module path → language, for fallback locations
+	Passed              bool
+	FailReason          string
 }
@@ -3,6 +3,8 @@ package report
 import (
 	"bytes"
 	"encoding/json"
+	"os"
+	"slices"
 	"strings"
 	"testing"
 
@@ -180,6 +182,42 @@ func TestWriteScanJSON(t *testing.T) {
 	}
 }
 
+func TestWriteScanJSONCompact(t *testing.T) {
+	r := ScanReport{
+		GraphChecksum: "abc123",
+		Capabilities:  []CapabilityReport{{Package: "test", Module: "test", RiskLevel: "LOW"}},
+		TaintFindings: []taint.TaintFinding{{Package: "test", Source: "network", Sink: "exec", Risk: "HIGH"}},
+		Passed:        true,
+	}
+
+	SetCompactJSON(true)
+	defer SetCompactJSON(false)
+	var buf bytes.Buffer
+	if err := WriteScanJSON(&buf, r); err != nil {
+		t.Fatalf("WriteScanJSON() error = %v", err)
+	}
+	doc := strings.TrimSuffix(buf.String(), "\n")
+	if strings.Contains(doc, "\n") || strings.Contains(doc, "  ") {
+		t.Errorf("compact JSON spans lines or is indented:\n%s", doc)
+	}
+	var decoded ScanReport
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("compact JSON does not decode: %v", err)
+	}
+	if decoded.GraphChecksum != "abc123" || len(decoded.TaintFindings) != 1 {
+		t.Errorf("decoded = %+v", decoded)
+	}
+
+	SetCompactJSON(false)
+	buf.Reset()
+	if err := WriteScanJSON(&buf, r); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Count(buf.String(), "\n") < 2 {
+		t.Error("default JSON output is not indented")
+	}
+}
+
 func TestWriteCapDiff(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -248,6 +286,68 @@ func TestWriteCapDiff(t *testing.T) {
 	}
 }
 
+func TestWriteCapDiffUnified(t *testing.T) {
+	r := CapDiffReport{
+		Module:     "example.com/mod",
+		OldVersion: "v1.0.0",
+		NewVersion: "v2.0.0",
+		Diffs: []PackageCapDiff{
+			{Package: "example.com/mod/b", Added: []string{"network"}},
+			{Package: "example.com/mod/a", Added: []string{"exec"}, Removed: []string{"crypto"}},
+		},
+	}
+
+	var buf bytes.Buffer
+	WriteCapDiffUnified(&buf, r)
+
+	want := `diff --gorisk a/example.com/mod/a b/example.com/mod/a
+--- a/example.com/mod/a@v1.0.0
++++ b/example.com/mod/a@v2.0.0
+@@ -1,1 +1,1 @@
+-crypto
++exec
+diff --gorisk a/example.com/mod/b b/example.com/mod/b
+--- a/example.com/mod/b@v1.0.0
++++ b/example.com/mod/b@v2.0.0
+@@ -0,0 +1,1 @@
++network
+`
+	if got := buf.String(); got != want {
+		t.Errorf("unified diff mismatch\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestWriteCapDiffMajorVersionPath(t *testing.T) {
+	r := CapDiffReport{
+		Module:     "example.com/mod",
+		NewModule:  "example.com/mod/v2",
+		OldVersion: "v1.4.0",
+		NewVersion: "v2.0.0",
+		Diffs: []PackageCapDiff{
+			{Package: "example.com/mod/v2/run", OldPackage: "example.com/mod/run", Added: []string{"exec"}},
+		},
+	}
+
+	var buf bytes.Buffer
+	WriteCapDiff(&buf, r)
+	out := buf.String()
+	for _, want := range []string{
+		"Import path changed: example.com/mod → example.com/mod/v2",
+		"example.com/mod/v2/run",
+		"(was example.com/mod/run)",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+
+	buf.Reset()
+	WriteCapDiffUnified(&buf, r)
+	if !strings.HasPrefix(buf.String(), "diff --gorisk a/example.com/mod/run b/example.com/mod/v2/run\n--- a/example.com/mod/run@v1.4.0\n") {
+		t.Errorf("unified diff should pair old and new paths:\n%s", buf.String())
+	}
+}
+
 func TestWriteCapDiffJSON(t *testing.T) {
 	report := CapDiffReport{
 		Module:     "test",
@@ -335,6 +435,244 @@ func TestWriteHealthText(t *testing.T) {
 	}
 }
 
+func TestWriteHealthBreakdown(t *testing.T) {
+	reports := []HealthReport{
+		{
+			Module:  "github.com/foo/bar",
+			Version: "v1.2.0",
+			Score:   0,
+			Signals: map[string]int{
+				"archived":   -50,
+				"commit_age": -30,
+				"cve_count":  -60,
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	WriteHealthBreakdown(&buf, reports)
+	output := buf.String()
+
+	for _, want := range []string{
+		"github.com/foo/bar v1.2.0",
+		"→ 0",
+		"base                  +100",
+		"archived               -50",
+		"commit_age             -30",
+		"cve_count              -60",
+		"clamp (0-100)          +40",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("breakdown missing %q:\n%s", want, output)
+		}
+	}
+}
+
+func TestWriteScanNDJSON(t *testing.T) {
+	r := ScanReport{
+		GraphChecksum: "abc123",
+		Capabilities: []CapabilityReport{
+			{Package: "a", Module: "a", RiskLevel: "LOW"},
+			{Package: "b", Module: "b", RiskLevel: "HIGH"},
+		},
+		Health: []HealthReport{
+			{Module: "a", Score: 90},
+		},
+		TaintFindings: []taint.TaintFinding{
+			{Package: "b", Source: "network", Sink: "exec", Risk: "HIGH"},
+		},
+		Passed:     false,
+		FailReason: "package b has HIGH risk",
+	}
+
+	var buf bytes.Buffer
+	if err := WriteScanNDJSON(&buf, r); err != nil {
+		t.Fatalf("WriteScanNDJSON() error = %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	wantTypes := []string{NDJSONCapability, NDJSONCapability, NDJSONTaint, NDJSONHealth, NDJSONSummary}
+	if len(lines) != len(wantTypes) {
+		t.Fatalf("got %d lines, want %d:\n%s", len(lines), len(wantTypes), buf.String())
+	}
+	for i, line := range lines {
+		var obj map[string]any
+		if err := json.Unmarshal([]byte(line), &obj); err != nil {
+			t.Fatalf("line %d does not decode: %v\n%s", i, err, line)
+		}
+		if obj["type"] != wantTypes[i] {
+			t.Errorf("line %d type = %v, want %s", i, obj["type"], wantTypes[i])
+		}
+	}
+
+	var tf taint.TaintFinding
+	if err := json.Unmarshal([]byte(lines[2]), &tf); err != nil || tf.Sink != "exec" {
+		t.Errorf("taint line should decode as a TaintFinding, got %+v (err %v)", tf, err)
+	}
+	if !strings.Contains(lines[4], `"passed":false`) {
+		t.Errorf("summary line missing pass state: %s", lines[4])
+	}
+}
+
+// flushCounter counts the flushes of a buffered writer.
+type flushCounter struct {
+	bytes.Buffer
+	flushes int
+}
+
+func (f *flushCounter) Flush() error {
+	f.flushes++
+	return nil
+}
+
+func TestNDJSONWriterFlushesEachLine(t *testing.T) {
+	var w flushCounter
+	n := NewNDJSONWriter(&w)
+	if err := n.Capabilities([]CapabilityReport{{Package: "a"}, {Package: "b"}}); err != nil {
+		t.Fatal(err)
+	}
+	if w.flushes != 2 || strings.Count(w.String(), "\n") != 2 {
+		t.Fatalf("after two capability lines: %d flushes, output %q", w.flushes, w.String())
+	}
+	if err := n.Summary(ScanReport{Passed: true}); err != nil {
+		t.Fatal(err)
+	}
+	if w.flushes != 3 {
+		t.Errorf("flushes = %d, want 3", w.flushes)
+	}
+}
+
+func TestCompareFindingsEscalation(t *testing.T) {
+	var execCaps capability.CapabilitySet
+	execCaps.AddWithEvidence(capability.CapExec, capability.CapabilityEvidence{File: "a.go", Via: "import", Confidence: 0.90})
+
+	base := ScanReport{
+		Capabilities: []CapabilityReport{
+			{Package: "example.com/a", RiskLevel: "MEDIUM", Capabilities: execCaps},
+			{Package: "example.com/gone", RiskLevel: "LOW", Capabilities: execCaps},
+		},
+		TaintFindings: []taint.TaintFinding{
+			{Package: "example.com/a", Source: "env", Sink: "exec", Risk: "MEDIUM"},
+		},
+	}
+	cur := ScanReport{
+		Capabilities: []CapabilityReport{
+			{Package: "example.com/a", RiskLevel: "HIGH", Capabilities: execCaps},
+		},
+		TaintFindings: []taint.TaintFinding{
+			{Package: "example.com/a", Source: "env", Sink: "exec", Risk: "HIGH"},
+			{Package: "example.com/b", Source: "network", Sink: "exec", Risk: "HIGH"},
+		},
+	}
+
+	// The baseline is read back from --json output, as scan does.
+	var buf bytes.Buffer
+	if err := WriteScanJSON(&buf, base); err != nil {
+		t.Fatalf("WriteScanJSON() error = %v", err)
+	}
+	var decoded ScanReport
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("decode baseline: %v", err)
+	}
+
+	got := CompareFindings(decoded, cur)
+	want := []FindingTransition{
+		{Kind: "capability", Package: "example.com/a", Finding: "exec", OldRisk: "MEDIUM", NewRisk: "HIGH", Change: "escalated"},
+		{Kind: "taint", Package: "example.com/a", Finding: "env→exec", OldRisk: "MEDIUM", NewRisk: "HIGH", Change: "escalated"},
+		{Kind: "taint", Package: "example.com/b", Finding: "network→exec", NewRisk: "HIGH", Change: "new"},
+		{Kind: "capability", Package: "example.com/gone", Finding: "exec", OldRisk: "LOW", Change: "resolved"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("CompareFindings() = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("transition[%d] = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+
+	var out bytes.Buffer
+	WriteFindingTransitions(&out, got)
+	for _, want := range []string{"↑", "MEDIUM → HIGH", "new=1  resolved=1  escalated=2  improved=0"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output missing %q:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestCompareFindingsStrategies(t *testing.T) {
+	execAt := func(file string, line int) capability.CapabilitySet {
+		var cs capability.CapabilitySet
+		cs.AddWithEvidence(capability.CapExec, capability.CapabilityEvidence{File: file, Line: line, Via: "callSite", Confidence: 0.75})
+		return cs
+	}
+	// Between the scans a line was inserted above the exec call in
+	// example.com/mod/a, and example.com/mod/b gained the same finding.
+	base := ScanReport{
+		Capabilities: []CapabilityReport{
+			{Package: "example.com/mod/a", Module: "example.com/mod", RiskLevel: "HIGH", Capabilities: execAt("a.go", 10)},
+		},
+	}
+	cur := ScanReport{
+		Capabilities: []CapabilityReport{
+			{Package: "example.com/mod/a", Module: "example.com/mod", RiskLevel: "HIGH", Capabilities: execAt("a.go", 11)},
+			{Package: "example.com/mod/b", Module: "example.com/mod", RiskLevel: "HIGH", Capabilities: execAt("b.go", 3)},
+		},
+	}
+
+	tests := []struct {
+		strategy string
+		want     []string // "change package"
+	}{
+		{BaselineExact, []string{"new example.com/mod/a", "new example.com/mod/b", "resolved example.com/mod/a"}},
+		{BaselineRule, []string{"new example.com/mod/b"}},
+		{BaselineModule, nil},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, tr := range CompareFindingsBy(base, cur, tt.strategy) {
+			got = append(got, tr.Change+" "+tr.Package)
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("%s: transitions = %q, want %q", tt.strategy, got, tt.want)
+		}
+	}
+
+	// Moving the exec call, to another line or another file, is a new
+	// finding (and a resolved one) only under the exact strategy.
+	var shifted []string
+	for _, tr := range CompareFindingsBy(base, cur, BaselineExact) {
+		if tr.Package == "example.com/mod/a" {
+			shifted = append(shifted, tr.Change+" "+tr.Finding)
+		}
+	}
+	if want := []string{"new exec (a.go:11)", "resolved exec (a.go:10)"}; !slices.Equal(shifted, want) {
+		t.Errorf("exact after line shift: transitions = %q, want %q", shifted, want)
+	}
+	moved := cur
+	moved.Capabilities = []CapabilityReport{
+		{Package: "example.com/mod/a", Module: "example.com/mod", RiskLevel: "HIGH", Capabilities: execAt("z.go", 10)},
+	}
+	var got []string
+	for _, tr := range CompareFindingsBy(base, moved, BaselineExact) {
+		got = append(got, tr.Change+" "+tr.Finding)
+	}
+	if want := []string{"new exec (z.go:10)", "resolved exec (a.go:10)"}; !slices.Equal(got, want) {
+		t.Errorf("exact after move: transitions = %q, want %q", got, want)
+	}
+	if tr := CompareFindingsBy(base, moved, BaselineRule); len(tr) != 0 {
+		t.Errorf("rule after move: transitions = %+v, want none", tr)
+	}
+
+	// A finding in a module the baseline had no findings for is new under
+	// every strategy.
+	cur.Capabilities = append(cur.Capabilities, CapabilityReport{Package: "example.com/other", Module: "example.com/other", RiskLevel: "HIGH", Capabilities: execAt("c.go", 1)})
+	other := CompareFindingsBy(base, cur, BaselineModule)
+	if len(other) != 1 || other[0].Change != "new" || other[0].Package != "example.com/other" {
+		t.Errorf("module: transitions = %+v, want example.com/other new", other)
+	}
+}
+
 func TestWriteUpgradeText(t *testing.T) {
 	report := UpgradeReport{
 		Module: "test",
@@ -404,3 +742,472 @@ func TestWriteScanSARIF(t *testing.T) {
 		t.Error("Expected SARIF output to contain results")
 	}
 }
+
+func TestWriteScanGitLab(t *testing.T) {
+	build := func(line int) ScanReport {
+		var caps capability.CapabilitySet
+		caps.AddWithEvidence(capability.CapExec, capability.CapabilityEvidence{File: "run/run.go", Line: line, Via: "callSite", Confidence: 0.75})
+		caps.AddWithEvidence(capability.CapExec, capability.CapabilityEvidence{File: "run/a.go", Line: 3, Via: "import", Confidence: 0.90})
+		caps.Add(capability.CapEnv)
+		return ScanReport{
+			Capabilities: []CapabilityReport{
+				{Package: "example.com/run", Module: "example.com/run", Capabilities: caps, RiskLevel: "HIGH"},
+			},
+			TaintFindings: []taint.TaintFinding{
+				{Package: "example.com/run", Source: capability.CapEnv, Sink: capability.CapExec, Risk: "MEDIUM", Note: "env var → exec",
+					Steps: []taint.TaintStep{{File: "run/run.go", Line: line}}},
+			},
+			Health: []HealthReport{
+				{Module: "example.com/old", Score: 20, Archived: true},
+				{Module: "example.com/fine", Score: 90},
+			},
+		}
+	}
+
+	decode := func(r ScanReport) []map[string]any {
+		t.Helper()
+		var buf bytes.Buffer
+		if err := WriteScanGitLab(&buf, r); err != nil {
+			t.Fatal(err)
+		}
+		var issues []map[string]any
+		if err := json.Unmarshal(buf.Bytes(), &issues); err != nil {
+			t.Fatalf("output is not a JSON array: %v\n%s", err, buf.String())
+		}
+		return issues
+	}
+
+	issues := decode(build(10))
+	if len(issues) != 4 {
+		t.Fatalf("got %d issues, want exec, env, taint, and health:\n%+v", len(issues), issues)
+	}
+	severities := map[string]bool{"info": true, "minor": true, "major": true, "critical": true, "blocker": true}
+	for _, is := range issues {
+		for _, key := range []string{"description", "check_name", "fingerprint", "severity"} {
+			if v, ok := is[key].(string); !ok || v == "" {
+				t.Errorf("issue %v: %s missing or not a string", is, key)
+			}
+		}
+		if !severities[is["severity"].(string)] {
+			t.Errorf("issue %v: severity %q is not a GitLab severity", is, is["severity"])
+		}
+		if len(is["fingerprint"].(string)) != 64 {
+			t.Errorf("fingerprint %q is not a hex SHA-256", is["fingerprint"])
+		}
+		loc, _ := is["location"].(map[string]any)
+		lines, _ := loc["lines"].(map[string]any)
+		if p, ok := loc["path"].(string); !ok || p == "" {
+			t.Errorf("issue %v: location.path missing", is)
+		}
+		if b, ok := lines["begin"].(float64); !ok || b < 1 {
+			t.Errorf("issue %v: location.lines.begin missing or < 1", is)
+		}
+	}
+
+	exec := issues[1] // capabilities are listed by name: env, exec
+	if exec["check_name"] != "gorisk/capability/exec" || exec["severity"] != "critical" {
+		t.Errorf("exec issue = %v, want gorisk/capability/exec at critical", exec)
+	}
+	if loc := exec["location"].(map[string]any); loc["path"] != "run/a.go" || loc["lines"].(map[string]any)["begin"] != 3.0 {
+		t.Errorf("exec location = %v, want run/a.go:3 (first evidence by path)", loc)
+	}
+	if issues[2]["severity"] != "major" || issues[3]["severity"] != "major" {
+		t.Errorf("taint and archived-module issues should be major: %v, %v", issues[2], issues[3])
+	}
+
+	// Moving the code changes locations but not fingerprints.
+	moved := decode(build(42))
+	for i := range issues {
+		if issues[i]["fingerprint"] != moved[i]["fingerprint"] {
+			t.Errorf("fingerprint of %s changed when its line moved", issues[i]["check_name"])
+		}
+	}
+	seen := make(map[any]bool)
+	for _, is := range issues {
+		if seen[is["fingerprint"]] {
+			t.Errorf("duplicate fingerprint %v", is["fingerprint"])
+		}
+		seen[is["fingerprint"]] = true
+	}
+
+	// Issues without a file point at the manifest of their language.
+	var nodeCaps capability.CapabilitySet
+	nodeCaps.AddWithEvidence(capability.CapExec, capability.CapabilityEvidence{Context: "postinstall", Via: "installScript", Confidence: 0.85})
+	node := decode(ScanReport{
+		Capabilities: []CapabilityReport{{Package: "left-pad", Module: "left-pad", Capabilities: nodeCaps, RiskLevel: "HIGH"}},
+		Health:       []HealthReport{{Module: "left-pad", Score: 10}},
+		Languages:    map[string]string{"left-pad": "node"},
+	})
+	for _, is := range node {
+		if loc := is["location"].(map[string]any); loc["path"] != "package.json" {
+			t.Errorf("%s location = %v, want package.json", is["check_name"], loc["path"])
+		}
+	}
+	if loc := issues[3]["location"].(map[string]any); loc["path"] != "go.mod" {
+		t.Errorf("health location = %v, want go.mod for a module of unknown language", loc["path"])
+	}
+
+	var empty bytes.Buffer
+	if err := WriteScanGitLab(&empty, ScanReport{}); err != nil || strings.TrimSpace(empty.String()) != "[]" {
+		t.Errorf("empty report = %q (err %v), want []", empty.String(), err)
+	}
+}
+
+func TestWriteScanSARIFCodeFlows(t *testing.T) {
+	r := ScanReport{
+		TaintFindings: []taint.TaintFinding{
+			{
+				Package: "example.com/app/cmd",
+				Source:  capability.CapEnv,
+				Sink:    capability.CapExec,
+				Risk:    "HIGH",
+				Note:    "env var → exec — injection risk",
+				Steps: []taint.TaintStep{
+					{Function: "example.com/app/cmd.main", File: "cmd/main.go", Line: 12, Note: "env source"},
+					{Function: "example.com/app/cmd.main", File: "cmd/main.go", Line: 14, Note: "calls example.com/app/run.Do"},
+					{Function: "example.com/app/run.Do", File: "run/do.go", Line: 7, Note: "exec sink"},
+				},
+			},
+			{Package: "example.com/flat", Source: capability.CapEnv, Sink: capability.CapExec, Risk: "MEDIUM"},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteScanSARIF(&buf, r); err != nil {
+		t.Fatal(err)
+	}
+	var out sarifOutput
+	if err := json.Unmarshal(buf.Bytes(), &out); err != nil {
+		t.Fatal(err)
+	}
+	results := out.Runs[0].Results
+	if len(results) != 2 {
+		t.Fatalf("got %d results, want 2 taint results", len(results))
+	}
+
+	multi := results[0]
+	if multi.RuleID != "GORISK003" || multi.Level != "error" {
+		t.Errorf("rule/level = %s/%s, want GORISK003/error", multi.RuleID, multi.Level)
+	}
+	if len(multi.CodeFlows) != 1 || len(multi.CodeFlows[0].ThreadFlows) != 1 {
+		t.Fatalf("codeFlows = %+v, want one thread flow", multi.CodeFlows)
+	}
+	locs := multi.CodeFlows[0].ThreadFlows[0].Locations
+	if len(locs) != 3 {
+		t.Fatalf("thread flow has %d locations, want 3", len(locs))
+	}
+	last := locs[2].Location.PhysicalLocation
+	if last.ArtifactLocation.URI != "run/do.go" || last.Region == nil || last.Region.StartLine != 7 {
+		t.Errorf("sink hop = %+v, want run/do.go:7", last)
+	}
+	if got := multi.Locations[0].PhysicalLocation.ArtifactLocation.URI; got != "run/do.go" {
+		t.Errorf("result location = %s, want the sink file", got)
+	}
+
+	if flat := results[1]; len(flat.CodeFlows) != 0 || flat.Level != "warning" {
+		t.Errorf("finding without steps = %+v, want no codeFlows and warning level", flat)
+	}
+}
+
+func TestWriteScanMetrics(t *testing.T) {
+	exec := capability.CapabilitySet{}
+	exec.Add(capability.CapExec)
+	exec.Add(capability.CapNetwork)
+	env := capability.CapabilitySet{}
+	env.Add(capability.CapEnv)
+
+	r := ScanReport{
+		Capabilities: []CapabilityReport{
+			{Package: "a/cmd", Module: "a", Capabilities: exec, RiskLevel: "HIGH"},
+			{Package: "b/cfg", Module: "b", Capabilities: env, RiskLevel: "LOW"},
+		},
+		Health: []HealthReport{
+			{Module: "a", CVECount: 2},
+		},
+		TaintFindings: []taint.TaintFinding{
+			{Package: "a/cmd", Source: capability.CapNetwork, Sink: capability.CapExec, Risk: "HIGH"},
+		},
+		Passed: false,
+	}
+
+	var buf bytes.Buffer
+	if err := WriteScanMetrics(&buf, r); err != nil {
+		t.Fatalf("WriteScanMetrics() error = %v", err)
+	}
+
+	typed := make(map[string]bool)
+	samples := make(map[string]string)
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if lines[len(lines)-1] != "# EOF" {
+		t.Errorf("expected output to end with # EOF, got %q", lines[len(lines)-1])
+	}
+	for _, line := range lines[:len(lines)-1] {
+		if strings.HasPrefix(line, "# TYPE ") {
+			fields := strings.Fields(line)
+			if len(fields) != 4 || fields[3] != "gauge" {
+				t.Errorf("malformed TYPE line: %q", line)
+				continue
+			}
+			typed[fields[2]] = true
+			continue
+		}
+		if strings.HasPrefix(line, "# HELP ") {
+			continue
+		}
+		idx := strings.LastIndex(line, " ")
+		if idx < 0 {
+			t.Errorf("malformed sample line: %q", line)
+			continue
+		}
+		series, value := line[:idx], line[idx+1:]
+		name := series
+		if i := strings.Index(series, "{"); i >= 0 {
+			if !strings.HasSuffix(series, "}") {
+				t.Errorf("unterminated label set: %q", line)
+			}
+			name = series[:i]
+		}
+		if !typed[name] {
+			t.Errorf("sample %q has no preceding # TYPE declaration", name)
+		}
+		samples[series] = value
+	}
+
+	want := map[string]string{
+		`gorisk_packages{risk="HIGH"}`:               "1",
+		`gorisk_packages{risk="LOW"}`:                "1",
+		`gorisk_capability_score`:                    "40",
+		`gorisk_module_capability_score{module="a"}`: "35",
+		`gorisk_cves`:                                "2",
+		`gorisk_taint_findings{risk="HIGH"}`:         "1",
+		`gorisk_scan_passed`:                         "0",
+	}
+	for series, v := range want {
+		if samples[series] != v {
+			t.Errorf("%s = %q, want %q", series, samples[series], v)
+		}
+	}
+}
+
+func TestSuggestRemediations(t *testing.T) {
+	var tlsCaps capability.CapabilitySet
+	tlsCaps.AddWithEvidence(capability.CapNetwork, capability.CapabilityEvidence{
+		File: "client.go", Line: 12, Context: "InsecureSkipVerify: true",
+	})
+	var randCaps capability.CapabilitySet
+	randCaps.AddWithEvidence(capability.CapWeakCrypto, capability.CapabilityEvidence{
+		File: "token.go", Line: 7, Context: "token := rand.Int63()",
+	})
+	sr := ScanReport{
+		Capabilities: []CapabilityReport{
+			{Package: "example.com/client", Capabilities: tlsCaps},
+			{Package: "example.com/token", Capabilities: randCaps},
+		},
+		TaintFindings: []taint.TaintFinding{
+			{Package: "example.com/token", Source: capability.CapWeakCrypto, Sink: capability.CapCrypto},
+		},
+		Health: []HealthReport{
+			{Module: "example.com/vuln", Version: "v1.2.0", CVECount: 1, CVEs: []string{"GO-2024-0001"}, FixedVersion: "v1.2.3"},
+			{Module: "example.com/patched", Version: "v1.3.0", CVECount: 1, CVEs: []string{"GO-2024-0002"}, FixedVersion: "v1.2.3"},
+		},
+	}
+
+	got := make(map[string]Suggestion)
+	for _, s := range Suggest(sr) {
+		got[s.Kind+" "+s.Package] = s
+	}
+	if len(got) != 3 {
+		t.Fatalf("expected 3 suggestions, got %+v", got)
+	}
+	if s := got[SuggestInsecureTLS+" example.com/client"]; s.Location != "client.go:12" || !strings.Contains(s.Hint, "certificate verification") {
+		t.Errorf("insecure TLS suggestion = %+v", s)
+	}
+	if s := got[SuggestWeakRand+" example.com/token"]; s.Location != "token.go:7" || !strings.Contains(s.Hint, "crypto/rand") {
+		t.Errorf("weak rand suggestion = %+v", s)
+	}
+	if s := got[SuggestVulnerable+" example.com/vuln"]; !strings.Contains(s.Hint, "go get example.com/vuln@v1.2.3") {
+		t.Errorf("vulnerable module suggestion = %+v", s)
+	}
+
+	var buf bytes.Buffer
+	WriteSuggestions(&buf, Suggest(sr))
+	if !strings.Contains(buf.String(), "example.com/client  client.go:12") {
+		t.Errorf("unexpected suggestions output:\n%s", buf.String())
+	}
+}
+
+func TestGroupTaintFindings(t *testing.T) {
+	findings := []taint.TaintFinding{
+		{Package: "example.com/c", Source: capability.CapEnv, Sink: capability.CapExec, Risk: "HIGH", Note: "env var → exec — injection risk"},
+		{Package: "example.com/a", Source: capability.CapEnv, Sink: capability.CapExec, Risk: "HIGH", Note: "env var → exec — injection risk"},
+		{Package: "example.com/b", Source: capability.CapEnv, Sink: capability.CapExec, Risk: "HIGH", Note: "env var → exec — injection risk"},
+		{Package: "example.com/a", Source: capability.CapNetwork, Sink: capability.CapFSWrite, Risk: "MEDIUM", Note: "network data written to disk"},
+	}
+
+	groups := GroupTaintFindings(findings)
+	if len(groups) != 2 {
+		t.Fatalf("expected 2 groups, got %+v", groups)
+	}
+	g := groups[0]
+	if g.Source != capability.CapEnv || g.Sink != capability.CapExec || g.Count != 3 {
+		t.Fatalf("first group = %+v, want env→exec with 3 packages", g)
+	}
+	if want := []string{"example.com/a", "example.com/b", "example.com/c"}; strings.Join(g.Packages, ",") != strings.Join(want, ",") {
+		t.Errorf("packages = %v, want %v", g.Packages, want)
+	}
+
+	var buf bytes.Buffer
+	WriteTaintGroups(&buf, groups)
+	out := buf.String()
+	if strings.Count(out, "env → exec") != 1 || !strings.Contains(out, "3 packages") {
+		t.Errorf("expected one grouped env → exec entry:\n%s", out)
+	}
+}
+
+func TestWriteScanByLanguage(t *testing.T) {
+	var goCaps, nodeCaps capability.CapabilitySet
+	goCaps.Add(capability.CapNetwork)
+	nodeCaps.Add(capability.CapExec)
+	sr := ScanReport{
+		Capabilities: []CapabilityReport{
+			{Package: "github.com/acme/api", Module: "github.com/acme/api", Capabilities: goCaps, RiskLevel: "MEDIUM"},
+			{Package: "left-pad", Module: "left-pad", Capabilities: nodeCaps, RiskLevel: "HIGH"},
+		},
+		TaintFindings: []taint.TaintFinding{
+			{Package: "left-pad", Module: "left-pad", Source: capability.CapEnv, Sink: capability.CapExec, Risk: "HIGH"},
+		},
+		FailReason: "package left-pad has HIGH AST-aware risk (score: 40.0)",
+	}
+	pkgLang := map[string]string{"github.com/acme/api": "go", "left-pad": "node"}
+	failures := map[string]string{"node": sr.FailReason}
+
+	sr.ByLanguage = SplitByLanguage(sr, pkgLang, failures)
+	if len(sr.ByLanguage) != 2 {
+		t.Fatalf("expected go and node sections, got %+v", sr.ByLanguage)
+	}
+	goSec, nodeSec := sr.ByLanguage[0], sr.ByLanguage[1]
+	if goSec.Language != "go" || !goSec.Passed || len(goSec.Capabilities) != 1 {
+		t.Errorf("go section = %+v", goSec)
+	}
+	if nodeSec.Language != "node" || nodeSec.Passed || len(nodeSec.TaintFindings) != 1 {
+		t.Errorf("node section = %+v", nodeSec)
+	}
+
+	var buf bytes.Buffer
+	WriteScan(&buf, sr)
+	out := buf.String()
+	for _, want := range []string{"##### GO #####", "##### NODE #####", "go PASSED", "node FAILED"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Index(out, "##### GO #####") > strings.Index(out, "left-pad") {
+		t.Error("node package listed under the go section")
+	}
+}
+
+func TestTruncateFindings(t *testing.T) {
+	var low, high capability.CapabilitySet
+	low.Add(capability.CapFSRead)
+	high.Add(capability.CapExec)
+	high.Add(capability.CapNetwork)
+	sr := ScanReport{
+		Capabilities: []CapabilityReport{
+			{Package: "example.com/a", Capabilities: low, RiskLevel: "LOW"},
+			{Package: "example.com/b", Capabilities: low, RiskLevel: "LOW"},
+			{Package: "example.com/danger", Capabilities: high, RiskLevel: "HIGH"},
+			{Package: "example.com/c", Capabilities: low, RiskLevel: "LOW"},
+		},
+		TaintFindings: []taint.TaintFinding{
+			{Package: "example.com/d", Source: capability.CapEnv, Sink: capability.CapExec, Risk: "MEDIUM", Confidence: 0.6},
+		},
+		Passed:     false,
+		FailReason: "package example.com/c uses denied capability: fs:read",
+	}
+
+	TruncateFindings(&sr, 2)
+	if sr.OmittedFindings != 3 {
+		t.Errorf("OmittedFindings = %d, want 3", sr.OmittedFindings)
+	}
+	if len(sr.Capabilities) != 1 || sr.Capabilities[0].Package != "example.com/danger" {
+		t.Errorf("kept capabilities = %+v, want only example.com/danger", sr.Capabilities)
+	}
+	if len(sr.TaintFindings) != 1 {
+		t.Errorf("kept taint findings = %+v, want the MEDIUM flow", sr.TaintFindings)
+	}
+
+	var buf bytes.Buffer
+	WriteScan(&buf, sr)
+	out := buf.String()
+	if !strings.Contains(out, "… and 3 more findings (use --json for all)") {
+		t.Errorf("output missing truncation notice:\n%s", out)
+	}
+	if caps, _, _ := strings.Cut(out, "=== Health Report ==="); strings.Contains(caps, "example.com/c") {
+		t.Errorf("hidden package printed in findings:\n%s", out)
+	}
+	// The verdict was decided on the full set, so the failure caused by a
+	// hidden finding must survive truncation.
+	if !strings.Contains(out, "FAILED") || !strings.Contains(out, sr.FailReason) {
+		t.Errorf("fail decision lost after truncation:\n%s", out)
+	}
+}
+
+func TestTruncateFindingsUnderCap(t *testing.T) {
+	sr := ScanReport{Capabilities: []CapabilityReport{{Package: "example.com/a", RiskLevel: "LOW"}}}
+	TruncateFindings(&sr, 5)
+	if sr.OmittedFindings != 0 || len(sr.Capabilities) != 1 {
+		t.Errorf("report changed under the cap: %+v", sr)
+	}
+	var buf bytes.Buffer
+	WriteScan(&buf, sr)
+	if strings.Contains(buf.String(), "more finding") {
+		t.Errorf("unexpected truncation notice:\n%s", buf.String())
+	}
+}
+
+func TestWriteScanNoColor(t *testing.T) {
+	SetColor(false)
+	t.Cleanup(func() { SetColor(true) })
+
+	var cs capability.CapabilitySet
+	cs.Add(capability.CapExec)
+	sr := ScanReport{
+		Capabilities: []CapabilityReport{{Package: "example.com/a", Capabilities: cs, RiskLevel: "MEDIUM"}},
+		TaintFindings: []taint.TaintFinding{
+			{Package: "example.com/a", Source: capability.CapEnv, Sink: capability.CapExec, Risk: "HIGH"},
+		},
+		Passed:     false,
+		FailReason: "example.com/a has HIGH risk",
+	}
+	var buf bytes.Buffer
+	WriteScan(&buf, sr)
+	if strings.Contains(buf.String(), "\033[") {
+		t.Errorf("escape sequence in uncolored output:\n%q", buf.String())
+	}
+	if !strings.Contains(buf.String(), "✗ FAILED: example.com/a has HIGH risk") {
+		t.Errorf("verdict missing from uncolored output:\n%s", buf.String())
+	}
+	if p := Colors(); p != (Palette{}) {
+		t.Errorf("Colors() = %+v, want empty palette", p)
+	}
+}
+
+func TestShouldColor(t *testing.T) {
+	f, err := os.CreateTemp(t.TempDir(), "out")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	t.Setenv("NO_COLOR", "")
+	if ShouldColor(f, false) {
+		t.Error("regular file: expected color off")
+	}
+	if ShouldColor(f, true) {
+		t.Error("--no-color: expected color off")
+	}
+	t.Setenv("NO_COLOR", "1")
+	if ShouldColor(os.Stdout, false) {
+		t.Error("NO_COLOR set: expected color off")
+	}
+}
@@ -4,6 +4,11 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/1homsi/gorisk/internal/taint"
 )
 
 type sarifOutput struct {
@@ -40,14 +45,34 @@ type sarifResult struct {
 	Level     string          `json:"level"`
 	Message   sarifMessage    `json:"message"`
 	Locations []sarifLocation `json:"locations,omitempty"`
+	CodeFlows []sarifCodeFlow `json:"codeFlows,omitempty"`
 }
 
 type sarifLocation struct {
 	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+	Message          *sarifMessage         `json:"message,omitempty"`
 }
 
 type sarifPhysicalLocation struct {
 	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifRegion struct {
+	StartLine int `json:"startLine"`
+}
+
+// sarifCodeFlow renders a taint path as a navigable sequence of locations.
+type sarifCodeFlow struct {
+	ThreadFlows []sarifThreadFlow `json:"threadFlows"`
+}
+
+type sarifThreadFlow struct {
+	Locations []sarifThreadFlowLocation `json:"locations"`
+}
+
+type sarifThreadFlowLocation struct {
+	Location sarifLocation `json:"location"`
 }
 
 type sarifArtifactLocation struct {
@@ -62,6 +87,7 @@ func WriteScanSARIF(w io.Writer, r ScanReport) error {
 	rules := []sarifRule{
 		{ID: "GORISK001", Name: "HighRiskCapability", ShortDescription: sarifMessage{Text: "Package has high-risk capabilities"}},
 		{ID: "GORISK002", Name: "UnhealthyDependency", ShortDescription: sarifMessage{Text: "Dependency has poor health score"}},
+		{ID: "GORISK003", Name: "TaintFlow", ShortDescription: sarifMessage{Text: "Capability source flows into a dangerous sink"}},
 	}
 
 	results := make([]sarifResult, 0)
@@ -88,7 +114,7 @@ func WriteScanSARIF(w io.Writer, r ScanReport) error {
 	}
 
 	for _, hr := range r.Health {
-		if hr.Score >= 40 {
+		if hr.Unknown || hr.Score >= 40 {
 			continue
 		}
 		results = append(results, sarifResult{
@@ -101,6 +127,27 @@ func WriteScanSARIF(w io.Writer, r ScanReport) error {
 		})
 	}
 
+	for _, tf := range r.TaintFindings {
+		res := sarifResult{
+			RuleID: "GORISK003",
+			Level:  sarifLevel(tf.Risk),
+			Message: sarifMessage{
+				Text: fmt.Sprintf("Package %s: %s → %s (%s)", tf.Package, tf.Source, tf.Sink, tf.Note),
+			},
+			Locations: gomodLoc,
+		}
+		if len(tf.Steps) > 0 {
+			flow := sarifThreadFlow{Locations: make([]sarifThreadFlowLocation, 0, len(tf.Steps))}
+			for _, step := range tf.Steps {
+				flow.Locations = append(flow.Locations, sarifThreadFlowLocation{Location: sarifStepLocation(step)})
+			}
+			res.CodeFlows = []sarifCodeFlow{{ThreadFlows: []sarifThreadFlow{flow}}}
+			sink := tf.Steps[len(tf.Steps)-1]
+			res.Locations = []sarifLocation{sarifStepLocation(sink)}
+		}
+		results = append(results, res)
+	}
+
 	out := sarifOutput{
 		Version: "2.1.0",
 		Schema:  "https://raw.githubusercontent.com/oasis-tcs/sarif-spec/master/Schemata/sarif-schema-2.1.0.json",
@@ -123,3 +170,42 @@ func WriteScanSARIF(w io.Writer, r ScanReport) error {
 	enc.SetIndent("", "  ")
 	return enc.Encode(out)
 }
+
+func sarifLevel(risk string) string {
+	switch risk {
+	case "HIGH":
+		return "error"
+	case "MEDIUM":
+		return "warning"
+	default:
+		return "note"
+	}
+}
+
+// sarifStepLocation places one taint hop at its file and line.
+func sarifStepLocation(step taint.TaintStep) sarifLocation {
+	loc := sarifLocation{
+		PhysicalLocation: sarifPhysicalLocation{
+			ArtifactLocation: sarifArtifactLocation{URI: relPath(step.File)},
+		},
+		Message: &sarifMessage{Text: step.Function + ": " + step.Note},
+	}
+	if step.Line > 0 {
+		loc.PhysicalLocation.Region = &sarifRegion{StartLine: step.Line}
+	}
+	return loc
+}
+
+// relPath makes a path under the working directory relative, with forward
+// slashes, so code scanning and code quality tools can match it to
+// repository files.
+func relPath(file string) string {
+	if filepath.IsAbs(file) {
+		if wd, err := os.Getwd(); err == nil {
+			if rel, err := filepath.Rel(wd, file); err == nil && !strings.HasPrefix(rel, "..") {
+				file = rel
+			}
+		}
+	}
+	return filepath.ToSlash(file)
+}
@@ -0,0 +1,56 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"sort"
+)
+
+// SubmoduleReport holds the capability reports for packages loaded from one
+// git submodule (--include-submodules).
+type SubmoduleReport struct {
+	Path         string             `json:"path"`
+	Language     string             `json:"language,omitempty"`
+	Capabilities []CapabilityReport `json:"capabilities"`
+}
+
+// SplitSubmodules picks the capability reports of packages that came from a
+// git submodule (pkgSubmodule maps import path to submodule path) and groups
+// them per submodule, sorted by path. langs maps submodule path to language.
+func SplitSubmodules(reports []CapabilityReport, pkgSubmodule, langs map[string]string) []SubmoduleReport {
+	bySub := make(map[string]*SubmoduleReport)
+	for _, cr := range reports {
+		path := pkgSubmodule[cr.Package]
+		if path == "" {
+			continue
+		}
+		sr, ok := bySub[path]
+		if !ok {
+			sr = &SubmoduleReport{Path: path, Language: langs[path]}
+			bySub[path] = sr
+		}
+		sr.Capabilities = append(sr.Capabilities, cr)
+	}
+
+	out := make([]SubmoduleReport, 0, len(bySub))
+	for _, sr := range bySub {
+		out = append(out, *sr)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Path < out[j].Path })
+	return out
+}
+
+// WriteSubmodules prints the capability report of each submodule under its
+// own heading.
+func WriteSubmodules(w io.Writer, subs []SubmoduleReport) {
+	fmt.Fprintf(w, "%s%s=== Submodules ===%s\n\n", colorBold, colorCyan, colorReset)
+	if len(subs) == 0 {
+		fmt.Fprintln(w, "no capabilities found in submodules")
+		return
+	}
+	for _, sub := range subs {
+		fmt.Fprintf(w, "%ssubmodule %s%s (%s)\n\n", colorBold, sub.Path, colorReset, sub.Language)
+		WriteCapabilities(w, sub.Capabilities)
+		fmt.Fprintln(w)
+	}
+}
@@ -0,0 +1,126 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"golang.org/x/mod/semver"
+
+	"github.com/1homsi/gorisk/internal/capability"
+)
+
+// Suggestion kinds, one per finding type with canned remediation advice.
+const (
+	SuggestInsecureTLS = "insecure-tls"
+	SuggestWeakRand    = "weak-rand"
+	SuggestVulnerable  = "vulnerable-module"
+	SuggestArchived    = "archived-module"
+	SuggestAbandoned   = "abandoned-module"
+)
+
+// Suggestion is a concrete remediation hint for a single finding.
+type Suggestion struct {
+	Kind     string `json:"kind"`
+	Package  string `json:"package"` // package import path, or module path for module findings
+	Location string `json:"location,omitempty"`
+	Hint     string `json:"hint"`
+}
+
+// Suggest maps the findings in sr to remediation hints: disabled TLS
+// verification, math/rand used for secrets, modules with known
+// vulnerabilities (upgrading to the OSV fixed version when there is one),
+// and archived modules. Results are sorted by package, then kind.
+func Suggest(sr ScanReport) []Suggestion {
+	var out []Suggestion
+	weakRandAt := make(map[string]string)
+	for _, cr := range sr.Capabilities {
+		if evs := cr.Capabilities.Evidence[capability.CapWeakCrypto]; len(evs) > 0 {
+			weakRandAt[cr.Package] = evidenceLocation(evs[0])
+		}
+		for _, ev := range cr.Capabilities.Evidence[capability.CapNetwork] {
+			if !strings.Contains(ev.Context, "InsecureSkipVerify") {
+				continue
+			}
+			out = append(out, Suggestion{
+				Kind:     SuggestInsecureTLS,
+				Package:  cr.Package,
+				Location: evidenceLocation(ev),
+				Hint:     "enable certificate verification: remove InsecureSkipVerify, and trust private CAs via tls.Config.RootCAs instead",
+			})
+		}
+	}
+	for _, tf := range sr.TaintFindings {
+		if tf.Source != capability.CapWeakCrypto {
+			continue
+		}
+		out = append(out, Suggestion{
+			Kind:     SuggestWeakRand,
+			Package:  tf.Package,
+			Location: weakRandAt[tf.Package],
+			Hint:     "generate keys, tokens, and nonces with crypto/rand (rand.Read, rand.Text) instead of math/rand",
+		})
+	}
+	for _, hr := range sr.Health {
+		switch {
+		case hr.Unknown:
+		case hr.CVECount > 0 && hr.FixedVersion != "" && semver.Compare(hr.Version, hr.FixedVersion) < 0:
+			out = append(out, Suggestion{
+				Kind:    SuggestVulnerable,
+				Package: hr.Module,
+				Hint: fmt.Sprintf("upgrade to %s, which fixes %s: go get %s@%s",
+					hr.FixedVersion, strings.Join(hr.CVEs, ", "), hr.Module, hr.FixedVersion),
+			})
+		case hr.CVECount > 0 && hr.FixedVersion == "":
+			out = append(out, Suggestion{
+				Kind:    SuggestVulnerable,
+				Package: hr.Module,
+				Hint:    fmt.Sprintf("no fixed version is published for %s; replace the module or confirm the vulnerable code is unreachable", strings.Join(hr.CVEs, ", ")),
+			})
+		}
+		if hr.Archived {
+			out = append(out, Suggestion{
+				Kind:    SuggestArchived,
+				Package: hr.Module,
+				Hint:    "the repository is archived and will not receive fixes; migrate to a maintained fork or alternative",
+			})
+		} else if hr.Abandoned {
+			out = append(out, Suggestion{
+				Kind:    SuggestAbandoned,
+				Package: hr.Module,
+				Hint:    "the module looks unmaintained (" + hr.AbandonedReason + "); check for a maintained fork or alternative",
+			})
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		if out[i].Package != out[j].Package {
+			return out[i].Package < out[j].Package
+		}
+		return out[i].Kind < out[j].Kind
+	})
+	return out
+}
+
+func evidenceLocation(ev capability.CapabilityEvidence) string {
+	if ev.File == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s:%d", ev.File, ev.Line)
+}
+
+// WriteSuggestions prints one remediation hint per finding.
+func WriteSuggestions(w io.Writer, suggestions []Suggestion) {
+	fmt.Fprintf(w, "%s%s=== Suggestions ===%s\n\n", colorBold, colorCyan, colorReset)
+	if len(suggestions) == 0 {
+		fmt.Fprintln(w, "no suggestions")
+		return
+	}
+	for _, s := range suggestions {
+		label := s.Package
+		if s.Location != "" {
+			label += "  " + s.Location
+		}
+		fmt.Fprintf(w, "  %s[%s]%s %s\n      → %s\n", colorYellow, s.Kind, colorReset, label, s.Hint)
+	}
+}
@@ -3,20 +3,14 @@ package report
 import (
 	"fmt"
 	"io"
+	"slices"
+	"sort"
 	"strings"
 
+	"github.com/1homsi/gorisk/internal/capability"
 	"github.com/1homsi/gorisk/internal/taint"
 )
 
-const (
-	colorReset  = "\033[0m"
-	colorRed    = "\033[31m"
-	colorYellow = "\033[33m"
-	colorGreen  = "\033[32m"
-	colorBold   = "\033[1m"
-	colorCyan   = "\033[36m"
-)
-
 func riskColor(level string) string {
 	switch level {
 	case "HIGH":
@@ -126,9 +120,20 @@ func WriteHealth(w io.Writer, reports []HealthReport) {
 			mod = mod[:modW-3] + "..."
 		}
 
+		if r.Unknown {
+			status := "PRIVATE"
+			if r.Abandoned {
+				status = "STALE"
+			}
+			fmt.Fprintf(w, "%-*s  %-12s  %5s  %4s  %-8s\n", modW, mod, r.Version, "?", "?", status)
+			continue
+		}
+
 		status := "OK"
 		if r.Archived {
 			status = "ARCHIVED"
+		} else if r.Abandoned {
+			status = "STALE"
 		}
 
 		fmt.Fprintf(w, "%-*s  %-12s  %5d  %4d  %s%-8s%s\n",
@@ -140,10 +145,19 @@ func WriteHealth(w io.Writer, reports []HealthReport) {
 	}
 
 	// CVE details table — only printed when at least one vuln exists
-	var vulnRows []struct{ module, id string }
+	type vulnRow struct{ module, id, note string }
+	var vulnRows []vulnRow
 	for _, r := range reports {
 		for _, id := range r.CVEs {
-			vulnRows = append(vulnRows, struct{ module, id string }{r.Module, id})
+			vulnRows = append(vulnRows, vulnRow{module: r.Module, id: id})
+		}
+		// VEX-suppressed CVEs stay visible but do not count against the module.
+		for _, na := range r.NotAffected {
+			note := "not affected (VEX)"
+			if na.Justification != "" {
+				note += ": " + na.Justification
+			}
+			vulnRows = append(vulnRows, vulnRow{module: r.Module, id: na.ID, note: note})
 		}
 	}
 	if len(vulnRows) == 0 {
@@ -170,10 +184,48 @@ func WriteHealth(w io.Writer, reports []HealthReport) {
 		if len(mod) > cveModW {
 			mod = mod[:cveModW-3] + "..."
 		}
+		if row.note != "" {
+			fmt.Fprintf(w, "%-*s  %-20s  %s\n", cveModW, mod, row.id, row.note)
+			continue
+		}
 		fmt.Fprintf(w, "%-*s  %s%s%s\n", cveModW, mod, colorRed, row.id, colorReset)
 	}
 }
 
+// healthBaseScore is the starting score every module's signals adjust.
+const healthBaseScore = 100
+
+// WriteHealthBreakdown prints, per module, how each health signal moved the
+// score from the base of 100 to the final value. Any difference left after
+// summing the signals is the clamp to the 0–100 range.
+func WriteHealthBreakdown(w io.Writer, reports []HealthReport) {
+	fmt.Fprintf(w, "%s%s=== Health Score Breakdown ===%s\n", colorBold, colorCyan, colorReset)
+
+	for _, r := range reports {
+		if r.Unknown {
+			fmt.Fprintf(w, "\n%s%s %s%s  → unknown (private module, not scored)\n", colorBold, r.Module, r.Version, colorReset)
+			continue
+		}
+		fmt.Fprintf(w, "\n%s%s %s%s  → %d\n", colorBold, r.Module, r.Version, colorReset, r.Score)
+		fmt.Fprintf(w, "  %-20s %+5d\n", "base", healthBaseScore)
+
+		names := make([]string, 0, len(r.Signals))
+		for name := range r.Signals {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		total := healthBaseScore
+		for _, name := range names {
+			fmt.Fprintf(w, "  %-20s %+5d\n", name, r.Signals[name])
+			total += r.Signals[name]
+		}
+		if clamp := r.Score - total; clamp != 0 {
+			fmt.Fprintf(w, "  %-20s %+5d\n", "clamp (0-100)", clamp)
+		}
+	}
+}
+
 func WriteUpgrade(w io.Writer, r UpgradeReport) {
 	fmt.Fprintf(w, "%s%s=== Upgrade Report ===%s\n\n", colorBold, colorCyan, colorReset)
 	color := riskColor(r.Risk)
@@ -303,12 +355,96 @@ func WriteTaintFindings(w io.Writer, findings []taint.TaintFinding) {
 	fmt.Fprintln(w)
 }
 
-func WriteScan(w io.Writer, r ScanReport) {
-	WriteCapabilities(w, r.Capabilities)
-	fmt.Fprintln(w)
-	WriteHealth(w, r.Health)
+// TaintGroup collapses every taint finding for one source→sink rule.
+type TaintGroup struct {
+	Source   capability.Capability `json:"source"`
+	Sink     capability.Capability `json:"sink"`
+	Risk     string                `json:"risk"` // highest risk among the grouped findings
+	Note     string                `json:"note"`
+	Count    int                   `json:"count"`
+	Packages []string              `json:"packages"`
+}
+
+// GroupTaintFindings collapses findings by source→sink rule, listing the
+// affected packages under each. Groups are ordered by risk (HIGH first),
+// then by package count, then by rule.
+func GroupTaintFindings(findings []taint.TaintFinding) []TaintGroup {
+	byRule := make(map[string]*TaintGroup)
+	var groups []*TaintGroup
+	for _, f := range findings {
+		rule := f.Source + "→" + f.Sink
+		g, ok := byRule[rule]
+		if !ok {
+			g = &TaintGroup{Source: f.Source, Sink: f.Sink, Risk: f.Risk, Note: f.Note}
+			byRule[rule] = g
+			groups = append(groups, g)
+		}
+		if capability.RiskValue(f.Risk) > capability.RiskValue(g.Risk) {
+			g.Risk, g.Note = f.Risk, f.Note
+		}
+		if !slices.Contains(g.Packages, f.Package) {
+			g.Packages = append(g.Packages, f.Package)
+		}
+	}
+
+	out := make([]TaintGroup, 0, len(groups))
+	for _, g := range groups {
+		sort.Strings(g.Packages)
+		g.Count = len(g.Packages)
+		out = append(out, *g)
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		ri, rj := capability.RiskValue(out[i].Risk), capability.RiskValue(out[j].Risk)
+		if ri != rj {
+			return ri > rj
+		}
+		if out[i].Count != out[j].Count {
+			return out[i].Count > out[j].Count
+		}
+		return out[i].Source+out[i].Sink < out[j].Source+out[j].Sink
+	})
+	return out
+}
+
+// WriteTaintGroups prints the taint flow section with one entry per rule
+// and the affected packages listed beneath it.
+func WriteTaintGroups(w io.Writer, groups []TaintGroup) {
+	if len(groups) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "%s%s=== Taint Flows (grouped) ===%s\n\n", colorBold, colorCyan, colorReset)
+	for _, g := range groups {
+		noun := "packages"
+		if g.Count == 1 {
+			noun = "package"
+		}
+		fmt.Fprintf(w, "  %s%-6s%s  %-18s  %d %s  %s\n",
+			riskColor(g.Risk), g.Risk, colorReset,
+			g.Source+" → "+g.Sink, g.Count, noun, g.Note)
+		for _, pkg := range g.Packages {
+			fmt.Fprintf(w, "           %s\n", pkg)
+		}
+	}
 	fmt.Fprintln(w)
-	WriteTaintFindings(w, r.TaintFindings)
+}
+
+func WriteScan(w io.Writer, r ScanReport) {
+	if len(r.ByLanguage) > 0 {
+		WriteLanguageSections(w, r.ByLanguage)
+		WriteHealth(w, r.Health)
+		fmt.Fprintln(w)
+	} else {
+		WriteCapabilities(w, r.Capabilities)
+		fmt.Fprintln(w)
+		WriteHealth(w, r.Health)
+		fmt.Fprintln(w)
+		if r.TaintGroups != nil {
+			WriteTaintGroups(w, r.TaintGroups)
+		} else {
+			WriteTaintFindings(w, r.TaintFindings)
+		}
+		writeOmittedNotice(w, r.OmittedFindings)
+	}
 
 	if r.Passed {
 		fmt.Fprintf(w, "%s%s✓ PASSED%s\n", colorBold, colorGreen, colorReset)
@@ -0,0 +1,84 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/1homsi/gorisk/internal/capability"
+	"github.com/1homsi/gorisk/internal/taint"
+)
+
+// TruncateFindings keeps only the max most severe findings of sr, counting
+// capability reports and taint findings together, and records how many were
+// dropped in sr.OmittedFindings. Findings rank by risk level, then taint
+// flows ahead of bare capabilities, then capability score or taint
+// confidence. Kept findings stay in their original order. It only trims what
+// is printed: call it after the pass/fail decision.
+func TruncateFindings(sr *ScanReport, max int) {
+	total := len(sr.Capabilities) + len(sr.TaintFindings)
+	if max <= 0 || total <= max {
+		return
+	}
+
+	type ranked struct {
+		taint bool
+		idx   int
+		risk  int
+		score float64
+	}
+	items := make([]ranked, 0, total)
+	for i, cr := range sr.Capabilities {
+		items = append(items, ranked{idx: i, risk: capability.RiskValue(cr.RiskLevel), score: float64(cr.Capabilities.Score)})
+	}
+	for i, tf := range sr.TaintFindings {
+		items = append(items, ranked{taint: true, idx: i, risk: capability.RiskValue(tf.Risk), score: tf.Confidence})
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		a, b := items[i], items[j]
+		if a.risk != b.risk {
+			return a.risk > b.risk
+		}
+		if a.taint != b.taint {
+			return a.taint
+		}
+		return a.score > b.score
+	})
+
+	keepCap := make(map[int]bool)
+	keepTaint := make(map[int]bool)
+	for _, it := range items[:max] {
+		if it.taint {
+			keepTaint[it.idx] = true
+		} else {
+			keepCap[it.idx] = true
+		}
+	}
+	var caps []CapabilityReport
+	for i, cr := range sr.Capabilities {
+		if keepCap[i] {
+			caps = append(caps, cr)
+		}
+	}
+	var findings []taint.TaintFinding
+	for i, tf := range sr.TaintFindings {
+		if keepTaint[i] {
+			findings = append(findings, tf)
+		}
+	}
+	sr.Capabilities = caps
+	sr.TaintFindings = findings
+	sr.OmittedFindings += total - max
+}
+
+// writeOmittedNotice tells the reader how many findings --max-findings hid.
+func writeOmittedNotice(w io.Writer, omitted int) {
+	if omitted == 0 {
+		return
+	}
+	noun := "findings"
+	if omitted == 1 {
+		noun = "finding"
+	}
+	fmt.Fprintf(w, "… and %d more %s (use --json for all)\n\n", omitted, noun)
+}
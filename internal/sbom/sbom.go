@@ -1,7 +1,9 @@
 package sbom
 
 import (
+	"encoding/json"
 	"fmt"
+	"slices"
 	"strings"
 	"time"
 
@@ -27,12 +29,51 @@ type BOMTool struct {
 	Version string `json:"version"`
 }
 
+// BOMLifecycle is a CycloneDX 1.5+ metadata lifecycle entry.
+type BOMLifecycle struct {
+	Phase string `json:"phase"`
+}
+
 type BOMMetadata struct {
-	Timestamp string    `json:"timestamp"`
-	Tools     []BOMTool `json:"tools"`
+	Timestamp  string         `json:"timestamp"`
+	Lifecycles []BOMLifecycle `json:"lifecycles,omitempty"`
+	Tools      []BOMTool      `json:"tools"`
+
+	// toolComponents switches Tools to the CycloneDX 1.5+ object form
+	// ({"components": [...]}); the bare array is deprecated there.
+	toolComponents bool
+}
+
+// MarshalJSON emits Tools in the structure required by the BOM's spec version.
+func (m BOMMetadata) MarshalJSON() ([]byte, error) {
+	type toolComponent struct {
+		Type    string `json:"type"`
+		Name    string `json:"name"`
+		Version string `json:"version"`
+	}
+	out := struct {
+		Timestamp  string         `json:"timestamp"`
+		Lifecycles []BOMLifecycle `json:"lifecycles,omitempty"`
+		Tools      any            `json:"tools"`
+	}{
+		Timestamp:  m.Timestamp,
+		Lifecycles: m.Lifecycles,
+		Tools:      m.Tools,
+	}
+	if m.toolComponents {
+		comps := make([]toolComponent, 0, len(m.Tools))
+		for _, t := range m.Tools {
+			comps = append(comps, toolComponent{Type: "application", Name: t.Name, Version: t.Version})
+		}
+		out.Tools = struct {
+			Components []toolComponent `json:"components"`
+		}{comps}
+	}
+	return json.Marshal(out)
 }
 
 type BOM struct {
+	Schema      string      `json:"$schema,omitempty"`
 	BOMFormat   string      `json:"bomFormat"`
 	SpecVersion string      `json:"specVersion"`
 	Version     int         `json:"version"`
@@ -40,7 +81,27 @@ type BOM struct {
 	Components  []Component `json:"components"`
 }
 
+// DefaultSpecVersion is the CycloneDX version emitted unless another is
+// requested; older consumers only understand 1.4.
+const DefaultSpecVersion = "1.4"
+
+// SupportedSpecVersions lists the CycloneDX versions GenerateVersion emits.
+var SupportedSpecVersions = []string{"1.4", "1.5", "1.6"}
+
+// Generate builds a CycloneDX DefaultSpecVersion BOM.
 func Generate(g *graph.DependencyGraph, capReports []report.CapabilityReport, healthReports []report.HealthReport) BOM {
+	bom, _ := GenerateVersion(DefaultSpecVersion, g, capReports, healthReports)
+	return bom
+}
+
+// GenerateVersion builds a BOM for the given CycloneDX spec version. From
+// 1.5 on, metadata carries a build lifecycle, tools use the component form,
+// and the document declares its $schema.
+func GenerateVersion(specVersion string, g *graph.DependencyGraph, capReports []report.CapabilityReport, healthReports []report.HealthReport) (BOM, error) {
+	if !slices.Contains(SupportedSpecVersions, specVersion) {
+		return BOM{}, fmt.Errorf("unsupported CycloneDX spec version %q (supported: %s)", specVersion, strings.Join(SupportedSpecVersions, ", "))
+	}
+
 	capsByModule := make(map[string][]string)
 	riskByModule := make(map[string]string)
 	for _, cr := range capReports {
@@ -57,6 +118,9 @@ func Generate(g *graph.DependencyGraph, capReports []report.CapabilityReport, he
 
 	healthByModule := make(map[string]int)
 	for _, hr := range healthReports {
+		if hr.Unknown {
+			continue // private module: no health score to report
+		}
 		healthByModule[hr.Module] = hr.Score
 	}
 
@@ -108,9 +172,9 @@ func Generate(g *graph.DependencyGraph, capReports []report.CapabilityReport, he
 		components = []Component{}
 	}
 
-	return BOM{
+	bom := BOM{
 		BOMFormat:   "CycloneDX",
-		SpecVersion: "1.4",
+		SpecVersion: specVersion,
 		Version:     1,
 		Metadata: BOMMetadata{
 			Timestamp: time.Now().UTC().Format(time.RFC3339),
@@ -120,6 +184,12 @@ func Generate(g *graph.DependencyGraph, capReports []report.CapabilityReport, he
 		},
 		Components: components,
 	}
+	if specVersion != "1.4" {
+		bom.Schema = "http://cyclonedx.org/schema/bom-" + specVersion + ".schema.json"
+		bom.Metadata.Lifecycles = []BOMLifecycle{{Phase: "build"}}
+		bom.Metadata.toolComponents = true
+	}
+	return bom, nil
 }
 
 func riskValue(level string) int {
@@ -1,6 +1,7 @@
 package sbom
 
 import (
+	"encoding/json"
 	"strings"
 	"testing"
 
@@ -245,3 +246,79 @@ func TestGenerateRiskPriority(t *testing.T) {
 		t.Errorf("risk_level = %q, want %q (should prioritize highest risk)", riskLevel, "HIGH")
 	}
 }
+
+func TestGenerateVersion(t *testing.T) {
+	g := graph.NewDependencyGraph()
+	g.Modules["github.com/foo/bar"] = &graph.Module{Path: "github.com/foo/bar", Version: "v1.2.3"}
+
+	tests := []struct {
+		spec          string
+		wantSchema    bool
+		wantLifecycle bool
+		toolsIsArray  bool
+	}{
+		{"1.4", false, false, true},
+		{"1.5", true, true, false},
+		{"1.6", true, true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			bom, err := GenerateVersion(tt.spec, g, nil, nil)
+			if err != nil {
+				t.Fatalf("GenerateVersion(%s): %v", tt.spec, err)
+			}
+			data, err := json.Marshal(bom)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var doc struct {
+				Schema      string `json:"$schema"`
+				SpecVersion string `json:"specVersion"`
+				Metadata    struct {
+					Lifecycles []map[string]string `json:"lifecycles"`
+					Tools      json.RawMessage     `json:"tools"`
+				} `json:"metadata"`
+			}
+			if err := json.Unmarshal(data, &doc); err != nil {
+				t.Fatal(err)
+			}
+
+			if doc.SpecVersion != tt.spec {
+				t.Errorf("specVersion = %q, want %q", doc.SpecVersion, tt.spec)
+			}
+			if got := doc.Schema != ""; got != tt.wantSchema {
+				t.Errorf("$schema present = %v, want %v (%q)", got, tt.wantSchema, doc.Schema)
+			}
+			if tt.wantSchema && !strings.Contains(doc.Schema, "bom-"+tt.spec) {
+				t.Errorf("$schema = %q, want bom-%s schema", doc.Schema, tt.spec)
+			}
+			if got := len(doc.Metadata.Lifecycles) > 0; got != tt.wantLifecycle {
+				t.Errorf("lifecycles present = %v, want %v", got, tt.wantLifecycle)
+			}
+
+			if tt.toolsIsArray {
+				var tools []BOMTool
+				if err := json.Unmarshal(doc.Metadata.Tools, &tools); err != nil || len(tools) != 1 || tools[0].Name != "gorisk" {
+					t.Errorf("1.4 tools should be an array with gorisk, got %s", doc.Metadata.Tools)
+				}
+			} else {
+				var tools struct {
+					Components []struct {
+						Type string `json:"type"`
+						Name string `json:"name"`
+					} `json:"components"`
+				}
+				if err := json.Unmarshal(doc.Metadata.Tools, &tools); err != nil || len(tools.Components) != 1 ||
+					tools.Components[0].Name != "gorisk" || tools.Components[0].Type != "application" {
+					t.Errorf("%s tools should use the components form, got %s", tt.spec, doc.Metadata.Tools)
+				}
+			}
+		})
+	}
+
+	if _, err := GenerateVersion("1.3", g, nil, nil); err == nil {
+		t.Error("expected error for unsupported spec version")
+	}
+}
@@ -37,11 +37,12 @@ type TaintAnalysis struct {
 	Rules     []taintRule
 }
 
-// NewInterprocedural creates a new interprocedural taint analyzer.
-func NewInterprocedural(cg *ir.CSCallGraph) *TaintAnalysis {
+// NewInterprocedural creates a new interprocedural taint analyzer checking the
+// rules opts leaves enabled.
+func NewInterprocedural(cg *ir.CSCallGraph, opts Options) *TaintAnalysis {
 	return &TaintAnalysis{
 		CallGraph: cg,
-		Rules:     taintRules,
+		Rules:     opts.rules(),
 	}
 }
 
@@ -71,11 +72,7 @@ func (ta *TaintAnalysis) AnalyzeInterprocedural() []TaintFinding {
 					sinkConf := ta.getConfidence(summary, rule.Sink)
 					conf := min(sourceConf, sinkConf)
 
-					// Downgrade severity if low confidence
-					risk := rule.Risk
-					if conf > 0 && conf < 0.70 {
-						risk = downgradeSeverity(risk)
-					}
+					risk, note := findingRisk(rule, conf, summary.Effects, summary.Sources, summary.Transitive)
 
 					// Extract package name from node
 					pkg := node.Function.Package
@@ -89,7 +86,7 @@ func (ta *TaintAnalysis) AnalyzeInterprocedural() []TaintFinding {
 						Source:            rule.Source,
 						Sink:              rule.Sink,
 						Risk:              risk,
-						Note:              rule.Note,
+						Note:              note,
 						Confidence:        conf,
 						ConfidenceReason:  "min(source_confidence, sink_confidence)",
 						Sanitized:         flow.Sanitized,
@@ -102,6 +99,7 @@ func (ta *TaintAnalysis) AnalyzeInterprocedural() []TaintFinding {
 						SourceFunc: flow.SourceFunction.String(),
 						SinkFunc:   flow.SinkFunction.String(),
 						CallStack:  ta.formatCallStack(flow.CallPath),
+						Steps:      flow.Steps,
 					}
 
 					// Log the taint flow discovery
@@ -136,7 +134,8 @@ type TaintFlow struct {
 	SourceFunction ir.Symbol
 	SinkFunction   ir.Symbol
 	CallPath       []ir.CallEdge
-	Sanitized      bool // crypto/validation in path
+	Steps          []TaintStep // located hops, when positions are known
+	Sanitized      bool        // crypto/validation in path
 	Uncertainty    bool
 	Reason         string
 }
@@ -199,6 +198,7 @@ func (ta *TaintAnalysis) traceTaintFlow(startNode ir.ContextNode, source, sink c
 				SourceFunction: srcFunc,
 				SinkFunction:   item.node.Function,
 				CallPath:       item.path,
+				Steps:          flowSteps(startSummary, item.path, summary, source, sink),
 				Sanitized:      sanitized,
 				Uncertainty:    false,
 			}
@@ -215,6 +215,10 @@ func (ta *TaintAnalysis) traceTaintFlow(startNode ir.ContextNode, source, sink c
 				Caller: item.node.Function,
 				Callee: callee.Function,
 			}
+			if site, ok := ta.CallGraph.CallSites[ir.CallSiteKey(item.node.Function, callee.Function)]; ok {
+				newPath[len(item.path)].File = site.File
+				newPath[len(item.path)].Line = site.Line
+			}
 			queue = append(queue, bfsItem{node: callee, path: newPath})
 		}
 	}
@@ -230,6 +234,53 @@ func (ta *TaintAnalysis) traceTaintFlow(startNode ir.ContextNode, source, sink c
 	}
 }
 
+// flowSteps lists the located hops of a multi-hop flow: where the source
+// capability is used, each call on the path, and where the sink is used.
+// Hops without a known position are left out.
+func flowSteps(src ir.FunctionSummary, path []ir.CallEdge, snk ir.FunctionSummary, source, sink capability.Capability) []TaintStep {
+	var steps []TaintStep
+	if ev, ok := locatedEvidence(src, source); ok {
+		steps = append(steps, TaintStep{
+			Function: src.Node.Function.String(),
+			File:     ev.File,
+			Line:     ev.Line,
+			Note:     source + " source",
+		})
+	}
+	for _, edge := range path {
+		if edge.File == "" {
+			continue
+		}
+		steps = append(steps, TaintStep{
+			Function: edge.Caller.String(),
+			File:     edge.File,
+			Line:     edge.Line,
+			Note:     "calls " + edge.Callee.String(),
+		})
+	}
+	if ev, ok := locatedEvidence(snk, sink); ok {
+		steps = append(steps, TaintStep{
+			Function: snk.Node.Function.String(),
+			File:     ev.File,
+			Line:     ev.Line,
+			Note:     sink + " sink",
+		})
+	}
+	return steps
+}
+
+// locatedEvidence returns the first evidence for c in s that has a position.
+func locatedEvidence(s ir.FunctionSummary, c capability.Capability) (capability.CapabilityEvidence, bool) {
+	for _, set := range []capability.CapabilitySet{s.Sources, s.Sinks, s.Effects} {
+		for _, ev := range set.Evidence[c] {
+			if ev.File != "" {
+				return ev, true
+			}
+		}
+	}
+	return capability.CapabilityEvidence{}, false
+}
+
 // getConfidence returns the confidence for a capability in a summary.
 func (ta *TaintAnalysis) getConfidence(summary ir.FunctionSummary, cap capability.Capability) float64 {
 	// Check direct effects first
@@ -72,7 +72,7 @@ func TestDebugAndInfoLogging(t *testing.T) {
 
 func TestNewInterprocedural(t *testing.T) {
 	cg := ir.NewCSCallGraph()
-	ta := NewInterprocedural(cg)
+	ta := NewInterprocedural(cg, Options{})
 	if ta == nil {
 		t.Fatal("NewInterprocedural returned nil")
 	}
@@ -86,7 +86,7 @@ func TestNewInterprocedural(t *testing.T) {
 
 func TestAnalyzeInterproceduralEmpty(t *testing.T) {
 	cg := ir.NewCSCallGraph()
-	ta := NewInterprocedural(cg)
+	ta := NewInterprocedural(cg, Options{})
 	findings := ta.AnalyzeInterprocedural()
 	if len(findings) != 0 {
 		t.Errorf("expected no findings for empty call graph, got %d", len(findings))
@@ -98,7 +98,7 @@ func TestAnalyzeInterproceduralEnvExec(t *testing.T) {
 		[]capability.Capability{capability.CapEnv},
 		[]capability.Capability{capability.CapExec},
 	)
-	ta := NewInterprocedural(cg)
+	ta := NewInterprocedural(cg, Options{})
 	findings := ta.AnalyzeInterprocedural()
 
 	found := false
@@ -127,7 +127,7 @@ func TestAnalyzeInterproceduralVerbose(t *testing.T) {
 		[]capability.Capability{capability.CapNetwork},
 		[]capability.Capability{capability.CapExec},
 	)
-	ta := NewInterprocedural(cg)
+	ta := NewInterprocedural(cg, Options{})
 	findings := ta.AnalyzeInterprocedural()
 
 	if len(findings) == 0 {
@@ -156,7 +156,7 @@ func TestAnalyzeInterproceduralTransitiveSources(t *testing.T) {
 	})
 	cg.Summaries[node.String()] = summary
 
-	ta := NewInterprocedural(cg)
+	ta := NewInterprocedural(cg, Options{})
 	findings := ta.AnalyzeInterprocedural()
 
 	found := false
@@ -208,7 +208,7 @@ func TestAnalyzeInterproceduralCalleeSource(t *testing.T) {
 	cg.Summaries[callerNode.String()] = callerSummary
 	cg.Summaries[calleeNode.String()] = calleeSummary
 
-	ta := NewInterprocedural(cg)
+	ta := NewInterprocedural(cg, Options{})
 	findings := ta.AnalyzeInterprocedural()
 	_ = findings // Just verify it doesn't panic
 }
@@ -251,7 +251,7 @@ func TestAnalyzeInterproceduralCalleeSink(t *testing.T) {
 	cg.Summaries[callerNode.String()] = callerSummary
 	cg.Summaries[calleeNode.String()] = calleeSummary
 
-	ta := NewInterprocedural(cg)
+	ta := NewInterprocedural(cg, Options{})
 	findings := ta.AnalyzeInterprocedural()
 	_ = findings // Just verify it doesn't panic
 }
@@ -353,7 +353,7 @@ func TestTraceTaintFlowBFS(t *testing.T) {
 	cg.Summaries[midNode.String()] = midSummary
 	cg.Summaries[snkNode.String()] = snkSummary
 
-	ta := NewInterprocedural(cg)
+	ta := NewInterprocedural(cg, Options{})
 	flow := ta.traceTaintFlow(srcNode, capability.CapNetwork, capability.CapExec)
 
 	if flow == nil {
@@ -368,13 +368,61 @@ func TestTraceTaintFlowBFS(t *testing.T) {
 	}
 }
 
+func TestTraceTaintFlowSteps(t *testing.T) {
+	cg := ir.NewCSCallGraph()
+
+	srcSym := ir.Symbol{Package: "test", Name: "src", Kind: "func"}
+	midSym := ir.Symbol{Package: "test", Name: "mid", Kind: "func"}
+	snkSym := ir.Symbol{Package: "test", Name: "snk", Kind: "func"}
+
+	srcNode := ir.ContextNode{Function: srcSym}
+	midNode := ir.ContextNode{Function: midSym, Context: ir.Context{Caller: srcSym}}
+	snkNode := ir.ContextNode{Function: snkSym, Context: ir.Context{Caller: midSym}}
+	for _, n := range []ir.ContextNode{srcNode, midNode, snkNode} {
+		cg.Nodes[n.String()] = n
+	}
+	cg.Edges[srcNode.String()] = []ir.ContextNode{midNode}
+	cg.Edges[midNode.String()] = []ir.ContextNode{snkNode}
+	cg.CallSites[ir.CallSiteKey(srcSym, midSym)] = ir.CallEdge{Caller: srcSym, Callee: midSym, File: "a.go", Line: 5}
+	cg.CallSites[ir.CallSiteKey(midSym, snkSym)] = ir.CallEdge{Caller: midSym, Callee: snkSym, File: "b.go", Line: 9}
+
+	srcSummary := ir.FunctionSummary{Node: srcNode}
+	srcSummary.Sources.AddWithEvidence(capability.CapEnv, capability.CapabilityEvidence{
+		File: "a.go", Line: 3, Via: "callSite", Confidence: 0.75,
+	})
+	srcSummary.Transitive.Add(capability.CapExec)
+	snkSummary := ir.FunctionSummary{Node: snkNode}
+	snkSummary.Sinks.AddWithEvidence(capability.CapExec, capability.CapabilityEvidence{
+		File: "c.go", Line: 20, Via: "callSite", Confidence: 0.75,
+	})
+	cg.Summaries[srcNode.String()] = srcSummary
+	cg.Summaries[midNode.String()] = ir.FunctionSummary{Node: midNode}
+	cg.Summaries[snkNode.String()] = snkSummary
+
+	flow := NewInterprocedural(cg, Options{}).traceTaintFlow(srcNode, capability.CapEnv, capability.CapExec)
+	want := []TaintStep{
+		{Function: "test.src", File: "a.go", Line: 3, Note: "env source"},
+		{Function: "test.src", File: "a.go", Line: 5, Note: "calls test.mid"},
+		{Function: "test.mid", File: "b.go", Line: 9, Note: "calls test.snk"},
+		{Function: "test.snk", File: "c.go", Line: 20, Note: "exec sink"},
+	}
+	if len(flow.Steps) != len(want) {
+		t.Fatalf("Steps = %+v, want %+v", flow.Steps, want)
+	}
+	for i := range want {
+		if flow.Steps[i] != want[i] {
+			t.Errorf("Steps[%d] = %+v, want %+v", i, flow.Steps[i], want[i])
+		}
+	}
+}
+
 // TestTraceTaintFlowDirectSameFn verifies flows within a single function.
 func TestTraceTaintFlowDirectSameFn(t *testing.T) {
 	cg := buildCSCallGraph("test/pkg", "doAll",
 		[]capability.Capability{capability.CapEnv},
 		[]capability.Capability{capability.CapExec},
 	)
-	ta := NewInterprocedural(cg)
+	ta := NewInterprocedural(cg, Options{})
 
 	node := cg.Nodes[func() string {
 		for k := range cg.Nodes {
@@ -4,6 +4,10 @@
 package taint
 
 import (
+	"fmt"
+	"path"
+	"strings"
+
 	"github.com/1homsi/gorisk/internal/capability"
 	"github.com/1homsi/gorisk/internal/graph"
 )
@@ -30,9 +34,18 @@ type TaintFinding struct {
 	UncertaintyReason string                `json:"uncertainty_reason,omitempty"`
 
 	// Interprocedural fields (optional, populated by interprocedural analysis)
-	SourceFunc string   `json:"source_func,omitempty"` // Function where source originates
-	SinkFunc   string   `json:"sink_func,omitempty"`   // Function where sink occurs
-	CallStack  []string `json:"call_stack,omitempty"`  // Call path from source to sink
+	SourceFunc string      `json:"source_func,omitempty"` // Function where source originates
+	SinkFunc   string      `json:"sink_func,omitempty"`   // Function where sink occurs
+	CallStack  []string    `json:"call_stack,omitempty"`  // Call path from source to sink
+	Steps      []TaintStep `json:"steps,omitempty"`       // Located hops: source site, each call, sink site
+}
+
+// TaintStep is one located hop of an interprocedural taint flow.
+type TaintStep struct {
+	Function string `json:"function"`
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+	Note     string `json:"note"` // e.g. "env source", "calls pkg.run", "exec sink"
 }
 
 type taintRule struct {
@@ -60,11 +73,259 @@ var taintRules = []taintRule{
 	{capability.CapNetwork, capability.CapReflect, "MEDIUM", "runtime behavior from network"},
 	{capability.CapFSRead, capability.CapUnsafe, "HIGH", "attacker-controlled memory ops"},
 	{capability.CapEnv, capability.CapNetwork, "MEDIUM", "env-configured exfil endpoint"},
+	{capability.CapProcessInspect, capability.CapNetwork, "MEDIUM", "process reconnaissance exfiltration"},
+	{capability.CapEnv, capability.CapDNS, "MEDIUM", "env data exfiltrated via DNS queries"},
+	{capability.CapNetwork, capability.CapInjection, "HIGH", "network input → SQL/template injection"},
+}
+
+// weakRandRule is the key of the math/rand misuse finding, which is not part
+// of taintRules but can be disabled the same way.
+const weakRandRule = capability.CapWeakCrypto + "→" + capability.CapCrypto
+
+// embeddedPayloadRule is the key of the dropped go:embed payload finding,
+// which is not part of taintRules but can be disabled the same way.
+const embeddedPayloadRule = capability.CapFSWrite + "→" + capability.CapExec
+
+// WithPackageLevel returns the interprocedural findings plus those of
+// pkgFindings (from Analyze) whose rules the call graph does not model:
+// math/rand misuse and dropped go:embed payloads, which are decided from a
+// package's evidence rather than from a flow between functions.
+func WithPackageLevel(interproc, pkgFindings []TaintFinding) []TaintFinding {
+	out := interproc
+	for _, f := range pkgFindings {
+		if key := f.Source + "→" + f.Sink; key == weakRandRule || key == embeddedPayloadRule {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+// Options configures an Analyze or AnalyzeInterprocedural run. The zero value
+// checks every rule.
+type Options struct {
+	// Disabled holds the "source→sink" keys of rules to skip, as returned by
+	// DisabledRules for the policy's disabled_taint_rules.
+	Disabled map[string]bool
+}
+
+// ruleKey normalises a "source→sink" (or "source->sink") rule name.
+func ruleKey(s string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(s), "->", "→"))
+}
+
+// DisabledRules returns the keys of the named "source→sink" rules for
+// Options.Disabled. Names that match no built-in rule are rejected so typos
+// don't silently keep a rule enabled.
+func DisabledRules(names []string) (map[string]bool, error) {
+	known := map[string]bool{weakRandRule: true, embeddedPayloadRule: true}
+	for _, r := range taintRules {
+		known[r.Source+"→"+r.Sink] = true
+	}
+	disabled := make(map[string]bool)
+	for _, name := range names {
+		key := ruleKey(name)
+		if !known[key] {
+			return nil, fmt.Errorf("unknown taint rule %q", name)
+		}
+		disabled[key] = true
+	}
+	return disabled, nil
+}
+
+// rules returns taintRules minus any disabled in o.
+func (o Options) rules() []taintRule {
+	if len(o.Disabled) == 0 {
+		return taintRules
+	}
+	var out []taintRule
+	for _, r := range taintRules {
+		if !o.Disabled[r.Source+"→"+r.Sink] {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+// secretPathMarkers are path fragments that identify credential or key
+// material. A file read whose evidence mentions one of these escalates the
+// fs:read→network exfiltration rule to HIGH.
+var secretPathMarkers = []string{
+	".ssh/",
+	"id_rsa",
+	"id_ed25519",
+	".aws/credentials",
+	".aws/config",
+	".docker/config.json",
+	".kube/config",
+	".netrc",
+	".npmrc",
+	".pypirc",
+	".git-credentials",
+	".gnupg/",
+	"/etc/shadow",
+}
+
+// secretFileEvidence returns the first fs:read evidence context across sets
+// that references a secret-looking path, or "" if none does.
+func secretFileEvidence(sets ...capability.CapabilitySet) string {
+	for _, cs := range sets {
+		for _, ev := range cs.Evidence[capability.CapFSRead] {
+			ctx := strings.ToLower(ev.Context)
+			if dotenvFile(ctx) {
+				return ev.Context
+			}
+			for _, m := range secretPathMarkers {
+				if strings.Contains(ctx, m) {
+					return ev.Context
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// dotenvFile reports whether the read evidence context ctx, such as
+// os.ReadFile(".env.production"), names a dotenv file: one whose base name
+// is .env or .env.<suffix>, unlike .envrc or .environment.
+func dotenvFile(ctx string) bool {
+	_, arg, ok := strings.Cut(ctx, "(")
+	if !ok {
+		return false
+	}
+	arg = strings.Trim(strings.TrimSuffix(arg, ")"), "\"`")
+	base := path.Base(strings.ReplaceAll(arg, `\`, "/"))
+	return base == ".env" || strings.HasPrefix(base, ".env.")
+}
+
+// escalate upgrades a finding whose evidence shows a specific attack: an
+// fs:read→network read of credential material, an env→exec package that
+// sets PATH before running a bare command (a PATH hijack), or env or file
+// data sent to a hardcoded host. Other network flows in a package with a
+// hardcoded host keep their risk and name the host in the note. It returns
+// the adjusted risk and note.
+func escalate(rule taintRule, sets ...capability.CapabilitySet) (string, string) {
+	risk := rule.Risk
+	switch {
+	case rule.Source == capability.CapFSRead && rule.Sink == capability.CapNetwork:
+		if ctx := secretFileEvidence(sets...); ctx != "" {
+			return "HIGH", "credential file exfiltration: " + ctx
+		}
+	case rule.Source == capability.CapEnv && rule.Sink == capability.CapExec:
+		if ctx := pathHijackEvidence(sets...); ctx != "" {
+			return "HIGH", "PATH hijack: " + ctx
+		}
+	}
+	if rule.Source == capability.CapNetwork || rule.Sink == capability.CapNetwork {
+		if ctx := hardcodedHostEvidence(sets...); ctx != "" {
+			if rule.Sink == capability.CapNetwork && (rule.Source == capability.CapEnv || rule.Source == capability.CapFSRead) {
+				return "HIGH", "hardcoded network target: " + ctx
+			}
+			return risk, rule.Note + "; hardcoded network target: " + ctx
+		}
+	}
+	return risk, rule.Note
+}
+
+// hardcodedHostEvidence returns the first hardcoded-host network evidence
+// context across sets, or "" if there is none.
+func hardcodedHostEvidence(sets ...capability.CapabilitySet) string {
+	for _, cs := range sets {
+		for _, ev := range cs.Evidence[capability.CapNetwork] {
+			if ev.Via == capability.ViaHardcodedHost {
+				return ev.Context
+			}
+		}
+	}
+	return ""
+}
+
+// pathHijackEvidence returns the first path-hijack exec evidence context
+// across sets, or "" if there is none.
+func pathHijackEvidence(sets ...capability.CapabilitySet) string {
+	for _, cs := range sets {
+		for _, ev := range cs.Evidence[capability.CapExec] {
+			if ev.Via == capability.ViaPathHijack {
+				return ev.Context
+			}
+		}
+	}
+	return ""
+}
+
+// loadsPlugins reports whether cs loads external code at runtime. Plugin
+// evidence that only marks the package as built to be loaded
+// (ViaPluginExported) is not a sink: a flow into it injects nothing.
+func loadsPlugins(cs capability.CapabilitySet) bool {
+	if !cs.Has(capability.CapPlugin) {
+		return false
+	}
+	evs := cs.Evidence[capability.CapPlugin]
+	if len(evs) == 0 {
+		return true
+	}
+	for _, ev := range evs {
+		if ev.Via != capability.ViaPluginExported {
+			return true
+		}
+	}
+	return false
+}
+
+// weakRandFinding reports math/rand output used as key, token, or nonce
+// material. The detector only records crypto:weak where the random value
+// reaches such a use, so the capability alone is the source→sink flow.
+func weakRandFinding(pkgPath, modPath string, caps capability.CapabilitySet) (TaintFinding, bool) {
+	if !caps.Has(capability.CapWeakCrypto) {
+		return TaintFinding{}, false
+	}
+	evs := caps.Evidence[capability.CapWeakCrypto]
+	note := "math/rand used for security-sensitive value — use crypto/rand"
+	if len(evs) > 0 && evs[0].Context != "" {
+		note += ": " + evs[0].Context
+	}
+	conf := caps.Confidence(capability.CapWeakCrypto)
+	return TaintFinding{
+		Package:    pkgPath,
+		Module:     modPath,
+		Source:     capability.CapWeakCrypto,
+		Sink:       capability.CapCrypto,
+		Risk:       "MEDIUM",
+		Note:       note,
+		Confidence: conf,
+		EvidenceChain: []TaintEvidence{
+			{Capability: capability.CapWeakCrypto, Confidence: conf},
+		},
+	}, true
+}
+
+// embeddedPayloadFinding reports a //go:embed script or binary that the
+// package writes to disk and executes. The detector only records
+// embeddedPayload exec evidence for that combination.
+func embeddedPayloadFinding(pkgPath, modPath string, caps capability.CapabilitySet) (TaintFinding, bool) {
+	for _, ev := range caps.Evidence[capability.CapExec] {
+		if ev.Via != capability.ViaEmbeddedPayload {
+			continue
+		}
+		return TaintFinding{
+			Package:    pkgPath,
+			Module:     modPath,
+			Source:     capability.CapFSWrite,
+			Sink:       capability.CapExec,
+			Risk:       "HIGH",
+			Note:       "embedded payload dropped and executed: " + ev.Context,
+			Confidence: ev.Confidence,
+			EvidenceChain: []TaintEvidence{
+				{Capability: capability.CapFSWrite, Confidence: caps.Confidence(capability.CapFSWrite)},
+				{Capability: capability.CapExec, Confidence: ev.Confidence},
+			},
+		}, true
+	}
+	return TaintFinding{}, false
 }
 
 // Analyze inspects all packages in the dependency graph and returns a list of
 // source→sink taint findings ordered by risk level (HIGH first).
-func Analyze(pkgs map[string]*graph.Package) []TaintFinding {
+func Analyze(pkgs map[string]*graph.Package, opts Options) []TaintFinding {
 	var findings []TaintFinding
 
 	for _, pkg := range pkgs {
@@ -74,7 +335,10 @@ func Analyze(pkgs map[string]*graph.Package) []TaintFinding {
 			modPath = pkg.Module.Path
 		}
 
-		for _, rule := range taintRules {
+		for _, rule := range opts.rules() {
+			if rule.Sink == capability.CapPlugin && !loadsPlugins(caps) {
+				continue
+			}
 			if caps.Has(rule.Source) && caps.Has(rule.Sink) {
 				// Compute confidence as min(source_conf, sink_conf)
 				sourceConf := caps.Confidence(rule.Source)
@@ -86,11 +350,7 @@ func Analyze(pkgs map[string]*graph.Package) []TaintFinding {
 					conf = 0.0
 				}
 
-				// Downgrade severity one level if confidence < 0.70
-				risk := rule.Risk
-				if conf > 0 && conf < 0.70 {
-					risk = downgradeSeverity(risk)
-				}
+				risk, note := findingRisk(rule, conf, caps)
 
 				finding := TaintFinding{
 					Package:    pkg.ImportPath,
@@ -98,7 +358,7 @@ func Analyze(pkgs map[string]*graph.Package) []TaintFinding {
 					Source:     rule.Source,
 					Sink:       rule.Sink,
 					Risk:       risk,
-					Note:       rule.Note,
+					Note:       note,
 					Confidence: conf,
 					EvidenceChain: []TaintEvidence{
 						{Capability: rule.Source, Confidence: sourceConf},
@@ -108,6 +368,13 @@ func Analyze(pkgs map[string]*graph.Package) []TaintFinding {
 				findings = append(findings, finding)
 			}
 		}
+
+		if f, ok := weakRandFinding(pkg.ImportPath, modPath, caps); ok && !opts.Disabled[weakRandRule] {
+			findings = append(findings, f)
+		}
+		if f, ok := embeddedPayloadFinding(pkg.ImportPath, modPath, caps); ok && !opts.Disabled[embeddedPayloadRule] {
+			findings = append(findings, f)
+		}
 	}
 
 	// Sort: HIGH first, then MEDIUM, then LOW; within risk level sort by package.
@@ -115,6 +382,17 @@ func Analyze(pkgs map[string]*graph.Package) []TaintFinding {
 	return findings
 }
 
+// findingRisk returns the risk and note of a rule's finding with confidence
+// conf: escalated by the evidence in sets, then downgraded one level when
+// conf is below 0.70, so low-confidence evidence never yields a certain HIGH.
+func findingRisk(rule taintRule, conf float64, sets ...capability.CapabilitySet) (string, string) {
+	risk, note := escalate(rule, sets...)
+	if conf > 0 && conf < 0.70 {
+		risk = downgradeSeverity(risk)
+	}
+	return risk, note
+}
+
 // downgradeSeverity downgrades the severity level by one step.
 func downgradeSeverity(level string) string {
 	switch level {
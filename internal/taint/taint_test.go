@@ -1,6 +1,8 @@
 package taint
 
 import (
+	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/1homsi/gorisk/internal/capability"
@@ -23,7 +25,7 @@ func TestAnalyzeEnvExec(t *testing.T) {
 	pkgs := map[string]*graph.Package{
 		"foo/shell": makePackage("foo/shell", "foo", capability.CapEnv, capability.CapExec),
 	}
-	findings := Analyze(pkgs)
+	findings := Analyze(pkgs, Options{})
 	if len(findings) == 0 {
 		t.Fatal("expected at least one taint finding")
 	}
@@ -42,7 +44,7 @@ func TestAnalyzeNetworkExec(t *testing.T) {
 	pkgs := map[string]*graph.Package{
 		"foo/runner": makePackage("foo/runner", "foo", capability.CapNetwork, capability.CapExec),
 	}
-	findings := Analyze(pkgs)
+	findings := Analyze(pkgs, Options{})
 	found := false
 	for _, f := range findings {
 		if f.Source == capability.CapNetwork && f.Sink == capability.CapExec && f.Risk == "HIGH" {
@@ -58,7 +60,7 @@ func TestAnalyzeNoFindings(t *testing.T) {
 	pkgs := map[string]*graph.Package{
 		"foo/safe": makePackage("foo/safe", "foo", capability.CapFSRead),
 	}
-	findings := Analyze(pkgs)
+	findings := Analyze(pkgs, Options{})
 	// fs:read alone should not trigger any HIGH findings
 	for _, f := range findings {
 		if f.Risk == "HIGH" {
@@ -73,7 +75,7 @@ func TestAnalyzeSortedHighFirst(t *testing.T) {
 		"b/high": makePackage("b/high", "b", capability.CapNetwork, capability.CapExec),   // HIGH
 		"c/med":  makePackage("c/med", "c", capability.CapNetwork, capability.CapFSWrite), // MEDIUM
 	}
-	findings := Analyze(pkgs)
+	findings := Analyze(pkgs, Options{})
 	if len(findings) == 0 {
 		t.Fatal("expected taint findings")
 	}
@@ -88,7 +90,7 @@ func TestAnalyzeMultiplePairs(t *testing.T) {
 	pkgs := map[string]*graph.Package{
 		"foo/multi": makePackage("foo/multi", "foo", capability.CapEnv, capability.CapNetwork, capability.CapExec),
 	}
-	findings := Analyze(pkgs)
+	findings := Analyze(pkgs, Options{})
 
 	hasPair := func(src, sink string) bool {
 		for _, f := range findings {
@@ -111,7 +113,7 @@ func TestAnalyzeFields(t *testing.T) {
 	pkgs := map[string]*graph.Package{
 		"foo/pkg": makePackage("foo/pkg", "foo/mod", capability.CapFSRead, capability.CapNetwork),
 	}
-	findings := Analyze(pkgs)
+	findings := Analyze(pkgs, Options{})
 	for _, f := range findings {
 		if f.Package == "" {
 			t.Error("expected non-empty Package")
@@ -129,7 +131,7 @@ func TestAnalyzeFields(t *testing.T) {
 }
 
 func TestAnalyzeEmptyPackages(t *testing.T) {
-	findings := Analyze(map[string]*graph.Package{})
+	findings := Analyze(map[string]*graph.Package{}, Options{})
 	if len(findings) != 0 {
 		t.Errorf("expected no findings for empty packages, got: %+v", findings)
 	}
@@ -155,7 +157,7 @@ func TestAnalyzeConfidence(t *testing.T) {
 	})
 
 	pkgs := map[string]*graph.Package{"test/pkg": pkg}
-	findings := Analyze(pkgs)
+	findings := Analyze(pkgs, Options{})
 
 	// Should find env→exec taint
 	var envExecFinding *TaintFinding
@@ -201,7 +203,7 @@ func TestAnalyzeConfidenceDowngrade(t *testing.T) {
 	})
 
 	pkgs := map[string]*graph.Package{"test/pkg": pkg}
-	findings := Analyze(pkgs)
+	findings := Analyze(pkgs, Options{})
 
 	// Should find network→exec taint
 	var netExecFinding *TaintFinding
@@ -242,7 +244,7 @@ func TestAnalyzeNewRules(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			pkg := makePackage("test/pkg", "test", tt.source, tt.sink)
 			pkgs := map[string]*graph.Package{"test/pkg": pkg}
-			findings := Analyze(pkgs)
+			findings := Analyze(pkgs, Options{})
 
 			found := false
 			for _, f := range findings {
@@ -257,3 +259,227 @@ func TestAnalyzeNewRules(t *testing.T) {
 		})
 	}
 }
+
+func TestAnalyzeSecretFileExfiltration(t *testing.T) {
+	tests := []struct {
+		name     string
+		context  string
+		wantRisk string
+	}{
+		{"ssh key", `os.ReadFile("/home/u/.ssh/id_rsa")`, "HIGH"},
+		{"aws credentials", `os.ReadFile("/home/u/.aws/credentials")`, "HIGH"},
+		{"plain config", `os.ReadFile("config.yaml")`, "MEDIUM"},
+		{"dotenv", `os.ReadFile("/srv/app/.env")`, "HIGH"},
+		{"dotenv variant", `os.ReadFile(".env.production")`, "HIGH"},
+		{"direnv file", `os.ReadFile(".envrc")`, "MEDIUM"},
+		{"env-prefixed dir", `os.ReadFile("deploy/.environment/settings.json")`, "MEDIUM"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pkg := makePackage("test/pkg", "test", capability.CapNetwork)
+			pkg.Capabilities.AddWithEvidence(capability.CapFSRead, capability.CapabilityEvidence{
+				Context:    tt.context,
+				Via:        "callSite",
+				Confidence: 0.75,
+			})
+			findings := Analyze(map[string]*graph.Package{"test/pkg": pkg}, Options{})
+
+			found := false
+			for _, f := range findings {
+				if f.Source == capability.CapFSRead && f.Sink == capability.CapNetwork {
+					found = true
+					if f.Risk != tt.wantRisk {
+						t.Errorf("risk = %s, want %s", f.Risk, tt.wantRisk)
+					}
+				}
+			}
+			if !found {
+				t.Errorf("expected fs:read→network finding, got: %+v", findings)
+			}
+		})
+	}
+}
+
+func TestAnalyzeLowConfidenceEscalation(t *testing.T) {
+	// An escalated finding is still downgraded when its evidence is weak.
+	pkg := makePackage("test/pkg", "test")
+	pkg.Capabilities.AddWithEvidence(capability.CapNetwork, capability.CapabilityEvidence{
+		Context: "http.Post", Via: "callSite", Confidence: 0.90,
+	})
+	pkg.Capabilities.AddWithEvidence(capability.CapFSRead, capability.CapabilityEvidence{
+		Context: `os.ReadFile("/home/u/.ssh/id_rsa")`, Via: "callSite", Confidence: 0.60,
+	})
+	found := false
+	for _, f := range Analyze(map[string]*graph.Package{"test/pkg": pkg}, Options{}) {
+		if f.Source == capability.CapFSRead && f.Sink == capability.CapNetwork {
+			found = true
+			if f.Risk != "MEDIUM" || !strings.Contains(f.Note, "credential file exfiltration") {
+				t.Errorf("low-confidence credential exfiltration = %s (%q), want MEDIUM with the escalation note", f.Risk, f.Note)
+			}
+		}
+	}
+	if !found {
+		t.Error("expected fs:read→network finding")
+	}
+}
+
+func TestAnalyzeHardcodedHostEscalation(t *testing.T) {
+	tests := []struct {
+		name     string
+		evidence capability.CapabilityEvidence
+		wantRisk string
+	}{
+		{"raw IP", capability.CapabilityEvidence{
+			Context: "http.Get to raw IP 185.243.115.84", Via: capability.ViaHardcodedHost, Confidence: 0.85,
+		}, "HIGH"},
+		{"non-allowlisted host", capability.CapabilityEvidence{
+			Context: "http.Get to non-allowlisted host paste.evil.io", Via: capability.ViaHardcodedHost, Confidence: 0.85,
+		}, "HIGH"},
+		// An allowlisted or dynamic host is plain network evidence.
+		{"allowed host", capability.CapabilityEvidence{
+			Context: `http.Get("https://api.github.com/repos")`, Via: "callSite", Confidence: 0.85,
+		}, "MEDIUM"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pkg := makePackage("test/pkg", "test", capability.CapEnv)
+			pkg.Capabilities.AddWithEvidence(capability.CapNetwork, tt.evidence)
+			findings := Analyze(map[string]*graph.Package{"test/pkg": pkg}, Options{})
+
+			found := false
+			for _, f := range findings {
+				if f.Source == capability.CapEnv && f.Sink == capability.CapNetwork {
+					found = true
+					if f.Risk != tt.wantRisk {
+						t.Errorf("risk = %s, want %s (note %q)", f.Risk, tt.wantRisk, f.Note)
+					}
+					if tt.wantRisk == "HIGH" && !strings.Contains(f.Note, tt.evidence.Context) {
+						t.Errorf("note = %q, want it to name %q", f.Note, tt.evidence.Context)
+					}
+				}
+			}
+			if !found {
+				t.Errorf("expected env→network finding, got: %+v", findings)
+			}
+		})
+	}
+
+	// A hardcoded host raises only flows that send data to it; network
+	// data written to disk keeps its rule risk and names the host.
+	pkg := makePackage("test/pkg", "test", capability.CapFSWrite)
+	pkg.Capabilities.AddWithEvidence(capability.CapNetwork, tests[0].evidence)
+	found := false
+	for _, f := range Analyze(map[string]*graph.Package{"test/pkg": pkg}, Options{}) {
+		if f.Source == capability.CapNetwork && f.Sink == capability.CapFSWrite {
+			found = true
+			if f.Risk != "MEDIUM" {
+				t.Errorf("network→fs:write risk = %s, want MEDIUM", f.Risk)
+			}
+			if !strings.Contains(f.Note, tests[0].evidence.Context) {
+				t.Errorf("network→fs:write note = %q, want it to name the host", f.Note)
+			}
+		}
+	}
+	if !found {
+		t.Error("expected network→fs:write finding")
+	}
+}
+
+func TestAnalyzePluginExportedIsNotASink(t *testing.T) {
+	// A package built as a plugin that also talks to the network loads no
+	// code, so network→plugin does not apply.
+	pkg := makePackage("test/pkg", "test", capability.CapNetwork)
+	pkg.Capabilities.AddWithEvidence(capability.CapPlugin, capability.CapabilityEvidence{
+		Context: "plugin-exported: //export Run", Via: capability.ViaPluginExported, Confidence: 0.80,
+	})
+	for _, f := range Analyze(map[string]*graph.Package{"test/pkg": pkg}, Options{}) {
+		if f.Sink == capability.CapPlugin {
+			t.Errorf("unexpected plugin finding for a plugin-exported package: %+v", f)
+		}
+	}
+
+	// Loading a plugin as well restores the rule.
+	pkg.Capabilities.AddWithEvidence(capability.CapPlugin, capability.CapabilityEvidence{
+		Context: "plugin.Open", Via: "callSite", Confidence: 0.75,
+	})
+	found := false
+	for _, f := range Analyze(map[string]*graph.Package{"test/pkg": pkg}, Options{}) {
+		found = found || (f.Source == capability.CapNetwork && f.Sink == capability.CapPlugin)
+	}
+	if !found {
+		t.Error("expected network→plugin once the package calls plugin.Open")
+	}
+}
+
+func TestDisabledRules(t *testing.T) {
+	pkgs := map[string]*graph.Package{
+		"example.com/cfg": makePackage("example.com/cfg", "example.com/cfg",
+			capability.CapEnv, capability.CapCrypto, capability.CapExec),
+	}
+
+	disabled, err := DisabledRules([]string{"env->crypto"})
+	if err != nil {
+		t.Fatalf("DisabledRules: %v", err)
+	}
+	findings := Analyze(pkgs, Options{Disabled: disabled})
+	for _, f := range findings {
+		if f.Source == capability.CapEnv && f.Sink == capability.CapCrypto {
+			t.Errorf("env→crypto finding reported despite rule being disabled: %+v", f)
+		}
+	}
+	foundExec := false
+	for _, f := range findings {
+		if f.Source == capability.CapEnv && f.Sink == capability.CapExec {
+			foundExec = true
+		}
+	}
+	if !foundExec {
+		t.Error("other rules (env→exec) should still fire")
+	}
+
+	if _, err := DisabledRules([]string{"env→nope"}); err == nil {
+		t.Error("expected error for unknown rule")
+	}
+
+	enabled := false
+	for _, f := range Analyze(pkgs, Options{}) {
+		if f.Source == capability.CapEnv && f.Sink == capability.CapCrypto {
+			enabled = true
+		}
+	}
+	if !enabled {
+		t.Error("the zero Options should check env→crypto")
+	}
+}
+
+func TestWithPackageLevelKeepsEmbeddedPayload(t *testing.T) {
+	pkg := makePackage("test/dropper", "test", capability.CapEnv, capability.CapFSWrite, capability.CapWeakCrypto)
+	pkg.Capabilities.AddWithEvidence(capability.CapExec, capability.CapabilityEvidence{
+		Context:    "go:embed payload.sh written to disk and executed",
+		Via:        capability.ViaEmbeddedPayload,
+		Confidence: 0.85,
+	})
+	pkgFindings := Analyze(map[string]*graph.Package{"test/dropper": pkg}, Options{})
+
+	// The same package also has an env→exec flow through its call graph.
+	interproc := []TaintFinding{{
+		Package: "test/dropper", Source: capability.CapEnv, Sink: capability.CapExec, Risk: "HIGH",
+		SourceFunc: "test/dropper.Install", SinkFunc: "test/dropper.run",
+	}}
+
+	got := WithPackageLevel(interproc, pkgFindings)
+	rules := make(map[string]int)
+	for _, f := range got {
+		rules[f.Source+"→"+f.Sink]++
+	}
+	want := map[string]int{
+		capability.CapEnv + "→" + capability.CapExec:          1,
+		capability.CapFSWrite + "→" + capability.CapExec:      1,
+		capability.CapWeakCrypto + "→" + capability.CapCrypto: 1,
+	}
+	if !reflect.DeepEqual(rules, want) {
+		t.Errorf("merged rules = %v, want %v", rules, want)
+	}
+}
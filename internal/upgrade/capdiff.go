@@ -6,6 +6,9 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
 
 	goadapter "github.com/1homsi/gorisk/internal/adapters/go"
 	nodeadapter "github.com/1homsi/gorisk/internal/adapters/node"
@@ -13,10 +16,11 @@ import (
 )
 
 type CapDiff struct {
-	Package   string
-	Added     capability.CapabilitySet
-	Removed   capability.CapabilitySet
-	Escalated bool
+	Package    string
+	OldPackage string // import path in the old version, when it differs (major-version paths)
+	Added      capability.CapabilitySet
+	Removed    capability.CapabilitySet
+	Escalated  bool
 }
 
 // CapDiffer compares the capability sets of two versions of a package.
@@ -24,11 +28,24 @@ type CapDiffer interface {
 	DiffCapabilities(modulePath, oldVersion, newVersion string) ([]CapDiff, error)
 }
 
+// ModuleCapDiffer is implemented by differs that can compare two versions
+// published under different module paths, such as a Go module and its /v2
+// major version.
+type ModuleCapDiffer interface {
+	DiffModules(oldPath, oldVersion, newPath, newVersion string) ([]CapDiff, error)
+}
+
 // GoCapDiffer implements CapDiffer for Go modules.
 type GoCapDiffer struct{}
 
 func (GoCapDiffer) DiffCapabilities(modulePath, oldVersion, newVersion string) ([]CapDiff, error) {
-	return diffGoCapabilities(modulePath, oldVersion, newVersion)
+	return diffGoCapabilities(modulePath, oldVersion, modulePath, newVersion)
+}
+
+// DiffModules compares two major versions of a Go module, pairing each
+// package of oldPath with the package at the same sub-path of newPath.
+func (GoCapDiffer) DiffModules(oldPath, oldVersion, newPath, newVersion string) ([]CapDiff, error) {
+	return diffGoCapabilities(oldPath, oldVersion, newPath, newVersion)
 }
 
 // NodeCapDiffer implements CapDiffer for npm packages.
@@ -38,7 +55,7 @@ func (NodeCapDiffer) DiffCapabilities(pkgName, oldVersion, newVersion string) ([
 	return diffNodeCapabilities(pkgName, oldVersion, newVersion)
 }
 
-func diffGoCapabilities(modulePath, oldVersion, newVersion string) ([]CapDiff, error) {
+func diffGoCapabilities(oldPath, oldVersion, newPath, newVersion string) ([]CapDiff, error) {
 	oldDir, err := os.MkdirTemp("", "gorisk-old-*")
 	if err != nil {
 		return nil, err
@@ -51,23 +68,48 @@ func diffGoCapabilities(modulePath, oldVersion, newVersion string) ([]CapDiff, e
 	}
 	defer os.RemoveAll(newDir)
 
-	if err := goScaffoldTempModule(oldDir, modulePath, oldVersion); err != nil {
+	if err := goScaffoldTempModule(oldDir, oldPath, oldVersion); err != nil {
 		return nil, fmt.Errorf("scaffold old: %w", err)
 	}
-	if err := goScaffoldTempModule(newDir, modulePath, newVersion); err != nil {
+	if err := goScaffoldTempModule(newDir, newPath, newVersion); err != nil {
 		return nil, fmt.Errorf("scaffold new: %w", err)
 	}
 
-	oldCaps, err := scanDirCapabilities(oldDir, modulePath)
+	oldCaps, err := scanDirCapabilities(oldDir, oldPath)
 	if err != nil {
 		return nil, fmt.Errorf("scan old: %w", err)
 	}
-	newCaps, err := scanDirCapabilities(newDir, modulePath)
+	newCaps, err := scanDirCapabilities(newDir, newPath)
 	if err != nil {
 		return nil, fmt.Errorf("scan new: %w", err)
 	}
 
-	return buildDiffs(oldCaps, newCaps), nil
+	return buildModuleDiffs(oldCaps, oldPath, newCaps, newPath), nil
+}
+
+// buildModuleDiffs diffs two versions whose packages may live under
+// different module paths. Old packages are rebased onto newPath first so a
+// package is compared with its own successor rather than reported as
+// entirely removed and re-added.
+func buildModuleDiffs(oldCaps map[string]capability.CapabilitySet, oldPath string, newCaps map[string]capability.CapabilitySet, newPath string) []CapDiff {
+	if oldPath == newPath {
+		return buildDiffs(oldCaps, newCaps)
+	}
+	rebased, origin := rebaseModule(oldCaps, oldPath, newPath)
+	diffs := buildDiffs(rebased, newCaps)
+	for i := range diffs {
+		old, ok := origin[diffs[i].Package]
+		if !ok || old == diffs[i].Package {
+			continue
+		}
+		if _, kept := newCaps[diffs[i].Package]; kept {
+			diffs[i].OldPackage = old
+		} else {
+			// Dropped in the new major version: keep the path it had.
+			diffs[i].Package = old
+		}
+	}
+	return diffs
 }
 
 func scanDirCapabilities(dir, modulePath string) (map[string]capability.CapabilitySet, error) {
@@ -85,6 +127,7 @@ func scanDirCapabilities(dir, modulePath string) (map[string]capability.Capabili
 			ImportPath string   `json:"ImportPath"`
 			Dir        string   `json:"Dir"`
 			GoFiles    []string `json:"GoFiles"`
+			CgoFiles   []string `json:"CgoFiles"`
 			Standard   bool     `json:"Standard"`
 			Module     *struct {
 				Path string `json:"Path"`
@@ -93,13 +136,14 @@ func scanDirCapabilities(dir, modulePath string) (map[string]capability.Capabili
 		if err := dec.Decode(&p); err != nil {
 			continue
 		}
-		if p.Standard || p.Dir == "" || len(p.GoFiles) == 0 {
+		files := append(p.GoFiles, p.CgoFiles...)
+		if p.Standard || p.Dir == "" || len(files) == 0 {
 			continue
 		}
 		if p.Module == nil || p.Module.Path != modulePath {
 			continue
 		}
-		cs, err := goadapter.DetectPackage(p.Dir, p.GoFiles)
+		cs, err := goadapter.DetectPackage(p.Dir, files)
 		if err != nil {
 			continue
 		}
@@ -121,6 +165,8 @@ func buildDiffs(oldCaps, newCaps map[string]capability.CapabilitySet) []CapDiff
 		capability.CapFSRead, capability.CapFSWrite, capability.CapNetwork,
 		capability.CapExec, capability.CapEnv, capability.CapUnsafe,
 		capability.CapCrypto, capability.CapReflect, capability.CapPlugin,
+		capability.CapWeakCrypto, capability.CapProcessInspect, capability.CapDNS,
+		capability.CapInjection, capability.CapObfuscation,
 	}
 
 	var diffs []CapDiff
@@ -142,16 +188,11 @@ func buildDiffs(oldCaps, newCaps map[string]capability.CapabilitySet) []CapDiff
 			continue
 		}
 
-		escalated := added.Has(capability.CapExec) ||
-			added.Has(capability.CapNetwork) ||
-			added.Has(capability.CapUnsafe) ||
-			added.Has(capability.CapPlugin)
-
 		diffs = append(diffs, CapDiff{
 			Package:   pkg,
 			Added:     added,
 			Removed:   removed,
-			Escalated: escalated,
+			Escalated: added.HasHighRisk(),
 		})
 	}
 	return diffs
@@ -177,3 +218,57 @@ func diffNodeCapabilities(pkgName, oldVersion, newVersion string) ([]CapDiff, er
 
 	return buildDiffs(oldCaps, newCaps), nil
 }
+
+// DiffTrees compares the installed code of two node_modules trees, such as
+// one project before and after an npm upgrade. Each argument may be a
+// node_modules directory or a project root containing one. Every top-level
+// package, scoped ones included, is scanned in both trees; a package found in
+// only one tree shows all of its capabilities as added or removed.
+func (NodeCapDiffer) DiffTrees(oldDir, newDir string) ([]CapDiff, error) {
+	oldCaps, err := scanNodeModules(oldDir)
+	if err != nil {
+		return nil, fmt.Errorf("scan old: %w", err)
+	}
+	newCaps, err := scanNodeModules(newDir)
+	if err != nil {
+		return nil, fmt.Errorf("scan new: %w", err)
+	}
+	diffs := buildDiffs(oldCaps, newCaps)
+	sort.Slice(diffs, func(i, j int) bool { return diffs[i].Package < diffs[j].Package })
+	return diffs, nil
+}
+
+// scanNodeModules detects the capabilities of each package installed in
+// dir, a node_modules directory or a project root containing one.
+func scanNodeModules(dir string) (map[string]capability.CapabilitySet, error) {
+	if fi, err := os.Stat(filepath.Join(dir, "node_modules")); err == nil && fi.IsDir() {
+		dir = filepath.Join(dir, "node_modules")
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	caps := make(map[string]capability.CapabilitySet)
+	for _, e := range entries {
+		// .bin and .package-lock.json are npm bookkeeping, not packages.
+		if !e.IsDir() || strings.HasPrefix(e.Name(), ".") {
+			continue
+		}
+		if !strings.HasPrefix(e.Name(), "@") {
+			caps[e.Name()] = nodeadapter.Detect(filepath.Join(dir, e.Name()))
+			continue
+		}
+		scoped, err := os.ReadDir(filepath.Join(dir, e.Name()))
+		if err != nil {
+			continue
+		}
+		for _, se := range scoped {
+			if se.IsDir() {
+				name := e.Name() + "/" + se.Name()
+				caps[name] = nodeadapter.Detect(filepath.Join(dir, e.Name(), se.Name()))
+			}
+		}
+	}
+	return caps, nil
+}
@@ -0,0 +1,163 @@
+package upgrade
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"golang.org/x/mod/modfile"
+)
+
+// PinnedVersion returns the version of modulePath the module in dir builds
+// with: its go.mod requirement, or the version a replace directive pins it
+// to instead. go.sum must record that version, so the result is what the
+// build actually verified. sum is go.sum's h1 hash of the module's files,
+// or "" when go.sum records only its go.mod.
+func PinnedVersion(dir, modulePath string) (version, sum string, err error) {
+	data, err := os.ReadFile(filepath.Join(dir, "go.mod"))
+	if err != nil {
+		return "", "", err
+	}
+	f, err := modfile.ParseLax("go.mod", data, nil)
+	if err != nil {
+		return "", "", err
+	}
+	for _, r := range f.Require {
+		if r.Mod.Path == modulePath {
+			version = r.Mod.Version
+		}
+	}
+	if version == "" {
+		return "", "", fmt.Errorf("%s is not required by %s", modulePath, filepath.Join(dir, "go.mod"))
+	}
+	for _, r := range f.Replace {
+		if r.Old.Path != modulePath || (r.Old.Version != "" && r.Old.Version != version) {
+			continue
+		}
+		if r.New.Version == "" {
+			return "", "", fmt.Errorf("%s is replaced by the directory %s", modulePath, r.New.Path)
+		}
+		if r.New.Path != modulePath {
+			return "", "", fmt.Errorf("%s is replaced by %s@%s", modulePath, r.New.Path, r.New.Version)
+		}
+		version = r.New.Version
+	}
+
+	sums, err := os.ReadFile(filepath.Join(dir, "go.sum"))
+	if err != nil {
+		return "", "", fmt.Errorf("%s@%s: %w", modulePath, version, err)
+	}
+	recorded := false
+	for _, line := range strings.Split(string(sums), "\n") {
+		switch f := strings.Fields(line); {
+		case len(f) != 3 || f[0] != modulePath:
+		case f[1] == version+"/go.mod":
+			recorded = true
+		case f[1] == version:
+			sum = f[2]
+		}
+	}
+	if !recorded {
+		return "", "", fmt.Errorf("%s@%s is not in go.sum (run go mod tidy)", modulePath, version)
+	}
+	return version, sum, nil
+}
+
+// goModule is the part of `go list -m -json` and `go mod download -json`
+// output gorisk reads.
+type goModule struct {
+	Version string
+	Zip     string
+	Sum     string
+}
+
+// goModCommand runs a go module command outside any module, so that it
+// reads only the go command's own configuration: GOPROXY with its
+// fallbacks and "off", GOPRIVATE and GONOPROXY, GOSUMDB, and values saved
+// with `go env -w`. The module cache is shared with the user's builds, and
+// every download is verified against the checksum database as usual.
+func goModCommand(args ...string) (goModule, error) {
+	var m goModule
+	tmp, err := os.MkdirTemp("", "gorisk-gomod-*")
+	if err != nil {
+		return m, err
+	}
+	defer os.RemoveAll(tmp)
+
+	ctx, cancel := context.WithTimeout(context.Background(), goModTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, "go", args...)
+	cmd.Dir = tmp
+	cmd.Env = append(os.Environ(), "GOWORK=off", "GOFLAGS="+modCacheFlags())
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, runErr := cmd.Output()
+
+	// go mod download reports a failure as a string Error in its JSON;
+	// go list -m only on stderr.
+	var result struct {
+		goModule
+		Error any
+	}
+	if err := json.Unmarshal(out, &result); err == nil {
+		if msg, ok := result.Error.(string); ok && msg != "" {
+			return m, errors.New(msg)
+		}
+	}
+	if runErr != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return m, errors.New(strings.TrimPrefix(msg, "go: "))
+		}
+		return m, fmt.Errorf("go %s: %w", strings.Join(args, " "), runErr)
+	}
+	return result.goModule, nil
+}
+
+// goModTimeout bounds one go list -m or go mod download.
+const goModTimeout = 5 * time.Minute
+
+// modCacheFlags keeps the -modcacherw setting of the caller's GOFLAGS, the
+// only one that applies to a command run outside a module.
+func modCacheFlags() string {
+	if slices.Contains(strings.Fields(os.Getenv("GOFLAGS")), "-modcacherw") {
+		return "-modcacherw"
+	}
+	return ""
+}
+
+// LatestVersion asks the go command for the latest version of modulePath,
+// so the configured GOPROXY, GOPRIVATE, and GONOPROXY apply.
+func LatestVersion(modulePath string) (string, error) {
+	m, err := goModCommand("list", "-m", "-json", modulePath+"@latest")
+	if err != nil {
+		return "", err
+	}
+	if m.Version == "" {
+		return "", fmt.Errorf("%s@latest: no version found", modulePath)
+	}
+	return m.Version, nil
+}
+
+// DownloadModuleSource downloads modulePath@version with go mod download,
+// which verifies it against the checksum database, and opens the zip as a
+// ModuleSource. A non-empty sum, the h1 hash go.sum records, must match
+// too, so the pinned version is the code the build verified. Close removes
+// the extracted files.
+func DownloadModuleSource(modulePath, version, sum string) (*ModuleSource, error) {
+	m, err := goModCommand("mod", "download", "-json", modulePath+"@"+version)
+	if err != nil {
+		return nil, err
+	}
+	if sum != "" && m.Sum != sum {
+		return nil, fmt.Errorf("%s@%s: downloaded module hash %s does not match go.sum %s", modulePath, version, m.Sum, sum)
+	}
+	return OpenModuleSource(m.Zip)
+}
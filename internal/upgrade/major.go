@@ -0,0 +1,44 @@
+package upgrade
+
+import (
+	"strings"
+
+	"golang.org/x/mod/module"
+
+	"github.com/1homsi/gorisk/internal/capability"
+)
+
+// MajorVersionPair reports whether oldPath and newPath are the same Go module
+// on different major-version import paths: "example.com/mod" and
+// "example.com/mod/v2", or "gopkg.in/yaml.v2" and "gopkg.in/yaml.v3".
+func MajorVersionPair(oldPath, newPath string) bool {
+	oldPrefix, oldMajor, ok := module.SplitPathVersion(oldPath)
+	if !ok {
+		return false
+	}
+	newPrefix, newMajor, ok := module.SplitPathVersion(newPath)
+	if !ok {
+		return false
+	}
+	return oldPrefix == newPrefix && oldMajor != newMajor
+}
+
+// rebaseModule re-keys caps from packages under fromMod to the matching
+// packages under toMod, so "example.com/mod/sub" lines up with
+// "example.com/mod/v2/sub". It also returns the original path of every
+// rebased package, keyed by its new path.
+func rebaseModule(caps map[string]capability.CapabilitySet, fromMod, toMod string) (map[string]capability.CapabilitySet, map[string]string) {
+	rebased := make(map[string]capability.CapabilitySet, len(caps))
+	origin := make(map[string]string, len(caps))
+	for pkg, cs := range caps {
+		newPkg := pkg
+		if pkg == fromMod {
+			newPkg = toMod
+		} else if rest, ok := strings.CutPrefix(pkg, fromMod+"/"); ok {
+			newPkg = toMod + "/" + rest
+		}
+		rebased[newPkg] = cs
+		origin[newPkg] = pkg
+	}
+	return rebased, origin
+}
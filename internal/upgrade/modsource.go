@@ -0,0 +1,224 @@
+package upgrade
+
+import (
+	"archive/zip"
+	"fmt"
+	"go/build"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/module"
+	modzip "golang.org/x/mod/zip"
+	"golang.org/x/tools/go/packages"
+
+	goadapter "github.com/1homsi/gorisk/internal/adapters/go"
+	"github.com/1homsi/gorisk/internal/capability"
+	"github.com/1homsi/gorisk/internal/report"
+)
+
+// ModuleSource is a Go module's source on local disk: a directory, or a
+// module proxy .zip extracted to a temporary directory. It lets diff and
+// impact work on modules that are already at hand, without network access.
+type ModuleSource struct {
+	Dir     string
+	Path    string // module path, from go.mod
+	Version string // from the zip's path@version prefix; empty for a directory
+
+	extracted bool
+}
+
+// IsModuleSource reports whether arg names a module .zip or a directory
+// rather than a module@version.
+func IsModuleSource(arg string) bool {
+	if strings.HasSuffix(arg, ".zip") {
+		return true
+	}
+	fi, err := os.Stat(arg)
+	return err == nil && fi.IsDir()
+}
+
+// OpenModuleSource opens src, a module proxy .zip or a module directory.
+// Close removes the files extracted from a zip.
+func OpenModuleSource(src string) (*ModuleSource, error) {
+	if !strings.HasSuffix(src, ".zip") {
+		modPath, err := readModulePath(src)
+		if err != nil {
+			return nil, err
+		}
+		return &ModuleSource{Dir: src, Path: modPath}, nil
+	}
+
+	dir, err := os.MkdirTemp("", "gorisk-modzip-*")
+	if err != nil {
+		return nil, err
+	}
+	m := &ModuleSource{Dir: dir, extracted: true}
+	prefixPath, version, err := extractModuleZip(src, dir)
+	if err != nil {
+		m.Close()
+		return nil, fmt.Errorf("%s: %w", src, err)
+	}
+	m.Version = version
+	if m.Path, err = readModulePath(dir); err != nil {
+		// Pre-modules code has no go.mod; the proxy names it in the prefix.
+		m.Path = prefixPath
+	}
+	return m, nil
+}
+
+// Close removes the directory a zip was extracted to.
+func (m *ModuleSource) Close() error {
+	if !m.extracted {
+		return nil
+	}
+	return os.RemoveAll(m.Dir)
+}
+
+// extractModuleZip unpacks a module zip in the proxy layout, where every
+// file is stored under "<module>@<version>/", into dir and returns the
+// module path and version of that prefix. golang.org/x/mod/zip does the
+// unpacking, so the zip must pass the go command's own checks: valid file
+// paths, no case-insensitive collisions, and its size and file-count
+// limits.
+func extractModuleZip(src, dir string) (modPath, version string, err error) {
+	zr, err := zip.OpenReader(src)
+	if err != nil {
+		return "", "", err
+	}
+	if len(zr.File) == 0 {
+		zr.Close()
+		return "", "", fmt.Errorf("empty zip")
+	}
+	// The module path has slashes of its own; the prefix ends at the first
+	// slash after the "@".
+	name := zr.File[0].Name
+	zr.Close()
+	at := strings.Index(name, "@")
+	slash := -1
+	if at > 0 {
+		slash = strings.Index(name[at:], "/")
+	}
+	if slash < 0 {
+		return "", "", fmt.Errorf("%s: not in a <module>@<version>/ directory", name)
+	}
+	m := module.Version{Path: name[:at], Version: name[at+1 : at+slash]}
+	if err := modzip.Unzip(dir, m, src); err != nil {
+		return "", "", err
+	}
+	return m.Path, m.Version, nil
+}
+
+func readModulePath(dir string) (string, error) {
+	data, err := os.ReadFile(filepath.Join(dir, "go.mod"))
+	if err != nil {
+		return "", err
+	}
+	modPath := modfile.ModulePath(data)
+	if modPath == "" {
+		return "", fmt.Errorf("%s: no module directive", filepath.Join(dir, "go.mod"))
+	}
+	return modPath, nil
+}
+
+// DiffSources compares two local module sources: the capabilities of each
+// package, as DiffModules does for published versions, and the exported
+// API of each package present in both, type-checked from the local files.
+// Packages are paired by their path within the module, so a /v2 source
+// compares with its v1 predecessor.
+func (GoCapDiffer) DiffSources(oldSrc, newSrc *ModuleSource) ([]CapDiff, []report.BreakingChange, error) {
+	oldCaps, err := sourceCapabilities(oldSrc)
+	if err != nil {
+		return nil, nil, fmt.Errorf("scan old: %w", err)
+	}
+	newCaps, err := sourceCapabilities(newSrc)
+	if err != nil {
+		return nil, nil, fmt.Errorf("scan new: %w", err)
+	}
+	diffs := buildModuleDiffs(oldCaps, oldSrc.Path, newCaps, newSrc.Path)
+
+	oldPkgs, err := loadSourcePackages(oldSrc)
+	if err != nil {
+		return nil, nil, fmt.Errorf("load old: %w", err)
+	}
+	newPkgs, err := loadSourcePackages(newSrc)
+	if err != nil {
+		return nil, nil, fmt.Errorf("load new: %w", err)
+	}
+	oldBySub := make(map[string]*packages.Package)
+	for _, p := range oldPkgs {
+		oldBySub[strings.TrimPrefix(p.PkgPath, oldSrc.Path)] = p
+	}
+	var breaking []report.BreakingChange
+	for _, np := range newPkgs {
+		op, ok := oldBySub[strings.TrimPrefix(np.PkgPath, newSrc.Path)]
+		if !ok || op.Types == nil || np.Types == nil {
+			continue
+		}
+		for _, b := range diffScopes(op.Types, np.Types) {
+			b.Symbol = np.PkgPath + "." + b.Symbol
+			breaking = append(breaking, b)
+		}
+	}
+	sort.SliceStable(breaking, func(i, j int) bool { return breaking[i].Symbol < breaking[j].Symbol })
+	return diffs, breaking, nil
+}
+
+// sourceCapabilities detects the capabilities of every package in m, found
+// by walking its files rather than asking go list, so no dependency needs
+// to be downloaded. Nested modules, testdata, vendor, and directories
+// starting with "." or "_" are skipped, as the go command does.
+func sourceCapabilities(m *ModuleSource) (map[string]capability.CapabilitySet, error) {
+	caps := make(map[string]capability.CapabilitySet)
+	err := filepath.WalkDir(m.Dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || !d.IsDir() {
+			return err
+		}
+		if p != m.Dir {
+			name := d.Name()
+			if name == "testdata" || name == "vendor" || strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_") {
+				return filepath.SkipDir
+			}
+			if _, err := os.Stat(filepath.Join(p, "go.mod")); err == nil {
+				return filepath.SkipDir
+			}
+		}
+		ctx := build.Default
+		ctx.CgoEnabled = true
+		bp, err := ctx.ImportDir(p, 0)
+		if err != nil {
+			// No buildable Go files, or files that do not parse.
+			return nil
+		}
+		cs, err := goadapter.DetectPackage(p, append(slices.Clip(bp.GoFiles), bp.CgoFiles...))
+		if err != nil {
+			return nil
+		}
+		rel, _ := filepath.Rel(m.Dir, p)
+		caps[path.Join(m.Path, filepath.ToSlash(rel))] = cs
+		return nil
+	})
+	return caps, err
+}
+
+// loadSourcePackages type-checks the packages of m offline. Missing
+// dependencies leave their uses untyped but do not fail the load, so the
+// module's own exported API is still compared.
+func loadSourcePackages(m *ModuleSource) ([]*packages.Package, error) {
+	env := append(os.Environ(), "GOPROXY=off", "GOWORK=off")
+	if m.extracted {
+		// A proxy zip may lack go.sum; the temporary copy can be updated.
+		env = append(env, "GOFLAGS=-mod=mod")
+	}
+	cfg := &packages.Config{
+		Dir:  m.Dir,
+		Env:  env,
+		Mode: packages.NeedName | packages.NeedTypes | packages.NeedImports | packages.NeedDeps | packages.NeedModule,
+	}
+	return packages.Load(cfg, "./...")
+}
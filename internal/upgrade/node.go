@@ -94,13 +94,11 @@ func nodePackageDeps(dir string) map[string]string {
 	return meta.Dependencies
 }
 
-// capEscalated returns true if newCaps introduces any high-severity capability
-// not present in oldCaps.
+// capEscalated returns true if newCaps introduces any high-risk capability
+// (see capability.DefaultHighRisk) not present in oldCaps.
 func capEscalated(old, new capability.CapabilitySet) bool {
-	for _, c := range []capability.Capability{
-		capability.CapExec, capability.CapNetwork, capability.CapUnsafe, capability.CapPlugin,
-	} {
-		if new.Has(c) && !old.Has(c) {
+	for _, c := range new.List() {
+		if capability.IsHighRisk(c) && !old.Has(c) {
 			return true
 		}
 	}
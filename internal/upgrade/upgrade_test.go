@@ -1,6 +1,7 @@
 package upgrade
 
 import (
+	"archive/zip"
 	"encoding/json"
 	"go/constant"
 	"go/token"
@@ -176,6 +177,19 @@ func TestCapEscalated(t *testing.T) {
 	}
 }
 
+func TestBuildDiffsHighRiskDefaults(t *testing.T) {
+	var reflectOnly capability.CapabilitySet
+	reflectOnly.Add(capability.CapReflect)
+	newCaps := map[string]capability.CapabilitySet{"codec": reflectOnly}
+
+	if d := buildDiffs(nil, newCaps); len(d) != 1 || d[0].Escalated {
+		t.Fatalf("reflect-only diff under defaults = %+v, want one non-escalated diff", d)
+	}
+	if capEscalated(capability.CapabilitySet{}, reflectOnly) {
+		t.Error("capEscalated() = true for added reflect, want false under defaults")
+	}
+}
+
 // ── nodeCurrentVersion ────────────────────────────────────────────────────────
 
 func TestNodeCurrentVersion(t *testing.T) {
@@ -233,6 +247,41 @@ func TestNodeModuleCapsPresent(t *testing.T) {
 	}
 }
 
+func TestNodeDiffTrees(t *testing.T) {
+	write := func(path, src string) {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(src), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	before, after := t.TempDir(), t.TempDir()
+	// A project root and a bare node_modules directory are both accepted.
+	write(filepath.Join(before, "node_modules", "runner", "index.js"), "module.exports = (cmd) => cmd;\n")
+	write(filepath.Join(before, "node_modules", "@acme", "util", "index.js"), "module.exports = 1;\n")
+	write(filepath.Join(after, "runner", "index.js"), "const cp = require('child_process');\nmodule.exports = (cmd) => cp.exec(cmd);\n")
+	write(filepath.Join(after, "@acme", "util", "index.js"), "module.exports = 1;\n")
+	write(filepath.Join(after, ".bin", "runner"), "#!/bin/sh\n")
+
+	diffs, err := NodeCapDiffer{}.DiffTrees(before, after)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(diffs) != 1 {
+		t.Fatalf("got %d diffs, want 1: %+v", len(diffs), diffs)
+	}
+	d := diffs[0]
+	if d.Package != "runner" || !d.Added.Has(capability.CapExec) || !d.Escalated {
+		t.Errorf("diff = %+v, want runner escalated with exec added", d)
+	}
+
+	if _, err := (NodeCapDiffer{}).DiffTrees(filepath.Join(before, "missing"), after); err == nil {
+		t.Error("expected an error for a missing tree")
+	}
+}
+
 // ── nodePackageDeps ───────────────────────────────────────────────────────────
 
 func TestNodePackageDeps(t *testing.T) {
@@ -421,3 +470,164 @@ func TestBuildDiffs(t *testing.T) {
 		t.Error("Expected to find pkg1 in diffs")
 	}
 }
+
+func TestMajorVersionPair(t *testing.T) {
+	tests := []struct {
+		old, new string
+		want     bool
+	}{
+		{"example.com/mod", "example.com/mod/v2", true},
+		{"example.com/mod/v2", "example.com/mod/v3", true},
+		{"gopkg.in/yaml.v2", "gopkg.in/yaml.v3", true},
+		{"example.com/mod", "example.com/mod", false},
+		{"example.com/mod", "example.com/other/v2", false},
+		{"example.com/mod", "example.com/mod/sub", false},
+	}
+	for _, tt := range tests {
+		if got := MajorVersionPair(tt.old, tt.new); got != tt.want {
+			t.Errorf("MajorVersionPair(%q, %q) = %v, want %v", tt.old, tt.new, got, tt.want)
+		}
+	}
+}
+
+func TestBuildModuleDiffsMajorVersion(t *testing.T) {
+	caps := func(cs ...capability.Capability) capability.CapabilitySet {
+		var s capability.CapabilitySet
+		for _, c := range cs {
+			s.Add(c)
+		}
+		return s
+	}
+	oldCaps := map[string]capability.CapabilitySet{
+		"example.com/mod":        caps(capability.CapFSRead),
+		"example.com/mod/run":    caps(capability.CapEnv),
+		"example.com/mod/legacy": caps(capability.CapCrypto),
+	}
+	newCaps := map[string]capability.CapabilitySet{
+		"example.com/mod/v2":     caps(capability.CapFSRead),
+		"example.com/mod/v2/run": caps(capability.CapEnv, capability.CapExec),
+	}
+
+	diffs := buildModuleDiffs(oldCaps, "example.com/mod", newCaps, "example.com/mod/v2")
+	byPkg := make(map[string]CapDiff)
+	for _, d := range diffs {
+		byPkg[d.Package] = d
+	}
+	if len(diffs) != 2 {
+		t.Fatalf("got %d diffs, want 2 (run escalation and legacy removal): %+v", len(diffs), diffs)
+	}
+
+	// The root package kept its capabilities, so pairing it with /v2
+	// produces no diff instead of a full removal and re-add.
+	if _, ok := byPkg["example.com/mod"]; ok {
+		t.Error("root package should pair with example.com/mod/v2")
+	}
+
+	run, ok := byPkg["example.com/mod/v2/run"]
+	if !ok {
+		t.Fatalf("missing diff for example.com/mod/v2/run: %+v", diffs)
+	}
+	if run.OldPackage != "example.com/mod/run" {
+		t.Errorf("OldPackage = %q, want example.com/mod/run", run.OldPackage)
+	}
+	if !run.Added.Has(capability.CapExec) || !run.Removed.IsEmpty() || !run.Escalated {
+		t.Errorf("run diff = %+v, want exec added and escalated", run)
+	}
+
+	legacy, ok := byPkg["example.com/mod/legacy"]
+	if !ok {
+		t.Fatalf("dropped package should keep its v1 path: %+v", diffs)
+	}
+	if legacy.OldPackage != "" || !legacy.Removed.Has(capability.CapCrypto) {
+		t.Errorf("legacy diff = %+v, want crypto removed", legacy)
+	}
+}
+
+// writeModuleZip writes files as a module proxy zip for modPath@version.
+func writeModuleZip(t *testing.T, modPath, version string, files map[string]string) string {
+	t.Helper()
+	name := filepath.Join(t.TempDir(), version+".zip")
+	f, err := os.Create(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zw := zip.NewWriter(f)
+	for rel, src := range files {
+		w, err := zw.Create(modPath + "@" + version + "/" + rel)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(src)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return name
+}
+
+func TestDiffSourcesModuleZip(t *testing.T) {
+	const modPath = "example.com/tool"
+	gomod := "module " + modPath + "\n\ngo 1.22\n"
+	oldZip := writeModuleZip(t, modPath, "v1.0.0", map[string]string{
+		"go.mod":  gomod,
+		"tool.go": "package tool\n\nfunc Run(name string) error { return nil }\n\nfunc Version() string { return \"1.0.0\" }\n",
+	})
+	newZip := writeModuleZip(t, modPath, "v1.1.0", map[string]string{
+		"go.mod":  gomod,
+		"tool.go": "package tool\n\nimport \"os/exec\"\n\nfunc Run(name string, args ...string) error { return exec.Command(name, args...).Run() }\n",
+	})
+
+	oldSrc, err := OpenModuleSource(oldZip)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer oldSrc.Close()
+	newSrc, err := OpenModuleSource(newZip)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer newSrc.Close()
+	if oldSrc.Path != modPath || oldSrc.Version != "v1.0.0" {
+		t.Errorf("old source = %s@%s, want %s@v1.0.0", oldSrc.Path, oldSrc.Version, modPath)
+	}
+
+	diffs, breaking, err := GoCapDiffer{}.DiffSources(oldSrc, newSrc)
+	if err != nil {
+		t.Fatalf("DiffSources() error = %v", err)
+	}
+	if len(diffs) != 1 || diffs[0].Package != modPath || !diffs[0].Added.Has(capability.CapExec) || !diffs[0].Escalated {
+		t.Errorf("diffs = %+v, want exec added to %s", diffs, modPath)
+	}
+	kinds := make(map[string]string)
+	for _, b := range breaking {
+		kinds[b.Symbol] = b.Kind
+	}
+	if kinds[modPath+".Version"] != "removed" || kinds[modPath+".Run"] != "type_changed" {
+		t.Errorf("breaking = %+v, want Version removed and Run type_changed", breaking)
+	}
+}
+
+func TestOpenModuleSourceRejectsUnsafePath(t *testing.T) {
+	name := writeModuleZip(t, "example.com/evil", "v1.0.0", map[string]string{"../escape.go": "package evil\n"})
+	if src, err := OpenModuleSource(name); err == nil {
+		src.Close()
+		t.Fatal("OpenModuleSource accepted a zip entry outside the module directory")
+	}
+}
+
+func TestOpenModuleSourceRejectsCaseCollision(t *testing.T) {
+	name := writeModuleZip(t, "example.com/evil", "v1.0.0", map[string]string{
+		"go.mod":  "module example.com/evil\n",
+		"evil.go": "package evil\n",
+		"EVIL.go": "package evil\n",
+	})
+	if src, err := OpenModuleSource(name); err == nil {
+		src.Close()
+		t.Fatal("OpenModuleSource accepted files that collide on a case-insensitive file system")
+	}
+}
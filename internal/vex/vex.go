@@ -0,0 +1,330 @@
+// Package vex reads VEX (Vulnerability Exploitability eXchange) documents in
+// the OpenVEX and CSAF VEX formats so scans can honour "not affected"
+// statements for known vulnerabilities.
+package vex
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+)
+
+// Status values shared by OpenVEX and CSAF (CSAF's known_not_affected and
+// known_affected are mapped onto these).
+const (
+	StatusNotAffected        = "not_affected"
+	StatusAffected           = "affected"
+	StatusFixed              = "fixed"
+	StatusUnderInvestigation = "under_investigation"
+)
+
+// Statement is one VEX assertion about a vulnerability in a set of products.
+type Statement struct {
+	Vulnerability string   // CVE, GHSA, or GO advisory ID
+	Aliases       []string // other IDs for the same vulnerability
+	Products      []string // purls or module paths, optionally with @version
+	Status        string
+	Justification string
+	Impact        string // free-form impact statement
+}
+
+// Document is a parsed VEX document.
+type Document struct {
+	Statements []Statement
+}
+
+// Load reads and parses the VEX document at path.
+func Load(path string) (*Document, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read VEX document: %w", err)
+	}
+	doc, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("parse VEX document %s: %w", path, err)
+	}
+	return doc, nil
+}
+
+// Parse decodes an OpenVEX or CSAF VEX document, detected by its top-level
+// fields.
+func Parse(data []byte) (*Document, error) {
+	var probe struct {
+		Statements json.RawMessage `json:"statements"`
+		Document   json.RawMessage `json:"document"`
+	}
+	if err := json.Unmarshal(data, &probe); err != nil {
+		return nil, err
+	}
+	switch {
+	case probe.Statements != nil:
+		return parseOpenVEX(data)
+	case probe.Document != nil:
+		return parseCSAF(data)
+	}
+	return nil, fmt.Errorf("not an OpenVEX or CSAF VEX document")
+}
+
+// NotAffected reports the statement declaring module@version not affected by
+// the vulnerability known by any of ids. When several statements match, the
+// last one in the document wins, so a later "affected" revokes an earlier
+// "not_affected".
+func (d *Document) NotAffected(ids []string, module, version string) (Statement, bool) {
+	var match Statement
+	found := false
+	for _, st := range d.Statements {
+		if st.matchesVuln(ids) && st.matchesProduct(module, version) {
+			match, found = st, true
+		}
+	}
+	if !found || match.Status != StatusNotAffected {
+		return Statement{}, false
+	}
+	return match, true
+}
+
+func (st Statement) matchesVuln(ids []string) bool {
+	for _, id := range ids {
+		if strings.EqualFold(id, st.Vulnerability) {
+			return true
+		}
+		for _, alias := range st.Aliases {
+			if strings.EqualFold(id, alias) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (st Statement) matchesProduct(module, version string) bool {
+	for _, p := range st.Products {
+		name, ver := productRef(p)
+		if name != module {
+			continue
+		}
+		if ver == "" || strings.TrimPrefix(ver, "v") == strings.TrimPrefix(version, "v") {
+			return true
+		}
+	}
+	return false
+}
+
+// productRef splits a product identifier into a module path and an optional
+// version. It accepts purls (pkg:golang/github.com/foo/bar@v1.2.3) and plain
+// module paths with or without @version.
+func productRef(product string) (name, version string) {
+	p := product
+	if rest, ok := strings.CutPrefix(p, "pkg:"); ok {
+		// Drop the purl type and any qualifiers or subpath.
+		if _, after, ok := strings.Cut(rest, "/"); ok {
+			rest = after
+		}
+		if i := strings.IndexAny(rest, "?#"); i >= 0 {
+			rest = rest[:i]
+		}
+		if unescaped, err := url.PathUnescape(rest); err == nil {
+			rest = unescaped
+		}
+		p = rest
+	}
+	if i := strings.LastIndex(p, "@"); i > 0 {
+		return p[:i], p[i+1:]
+	}
+	return p, ""
+}
+
+// OpenVEX: https://github.com/openvex/spec. Both the v0.0.x form
+// (vulnerability and products as strings) and the v0.2 form (objects with
+// "name" and "@id") are accepted.
+type openVEXDoc struct {
+	Statements []struct {
+		Vulnerability   json.RawMessage   `json:"vulnerability"`
+		Products        []json.RawMessage `json:"products"`
+		Status          string            `json:"status"`
+		Justification   string            `json:"justification"`
+		ImpactStatement string            `json:"impact_statement"`
+	} `json:"statements"`
+}
+
+func parseOpenVEX(data []byte) (*Document, error) {
+	var raw openVEXDoc
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+	doc := &Document{}
+	for i, s := range raw.Statements {
+		st := Statement{
+			Status:        s.Status,
+			Justification: s.Justification,
+			Impact:        s.ImpactStatement,
+		}
+		var name string
+		var vuln struct {
+			Name    string   `json:"name"`
+			ID      string   `json:"@id"`
+			Aliases []string `json:"aliases"`
+		}
+		switch {
+		case json.Unmarshal(s.Vulnerability, &name) == nil:
+			st.Vulnerability = name
+		case json.Unmarshal(s.Vulnerability, &vuln) == nil:
+			st.Vulnerability = vuln.Name
+			if st.Vulnerability == "" {
+				st.Vulnerability = vuln.ID
+			}
+			st.Aliases = vuln.Aliases
+		}
+		if st.Vulnerability == "" {
+			return nil, fmt.Errorf("statement %d: missing vulnerability", i)
+		}
+		for _, rp := range s.Products {
+			var id string
+			var prod struct {
+				ID          string `json:"@id"`
+				Identifiers struct {
+					PURL string `json:"purl"`
+				} `json:"identifiers"`
+			}
+			switch {
+			case json.Unmarshal(rp, &id) == nil:
+				st.Products = append(st.Products, id)
+			case json.Unmarshal(rp, &prod) == nil:
+				if prod.Identifiers.PURL != "" {
+					st.Products = append(st.Products, prod.Identifiers.PURL)
+				}
+				if prod.ID != "" {
+					st.Products = append(st.Products, prod.ID)
+				}
+			}
+		}
+		doc.Statements = append(doc.Statements, st)
+	}
+	return doc, nil
+}
+
+// CSAF 2.0 VEX profile: product IDs in product_status are resolved through
+// the product tree to their purl (or, failing that, their name).
+type csafProduct struct {
+	ProductID string `json:"product_id"`
+	Name      string `json:"name"`
+	Helper    struct {
+		PURL string `json:"purl"`
+	} `json:"product_identification_helper"`
+}
+
+type csafBranch struct {
+	Product  *csafProduct `json:"product"`
+	Branches []csafBranch `json:"branches"`
+}
+
+type csafDoc struct {
+	ProductTree struct {
+		Branches         []csafBranch  `json:"branches"`
+		FullProductNames []csafProduct `json:"full_product_names"`
+	} `json:"product_tree"`
+	Vulnerabilities []struct {
+		CVE string `json:"cve"`
+		IDs []struct {
+			Text string `json:"text"`
+		} `json:"ids"`
+		ProductStatus struct {
+			KnownNotAffected   []string `json:"known_not_affected"`
+			KnownAffected      []string `json:"known_affected"`
+			Fixed              []string `json:"fixed"`
+			UnderInvestigation []string `json:"under_investigation"`
+		} `json:"product_status"`
+		Flags []struct {
+			Label      string   `json:"label"`
+			ProductIDs []string `json:"product_ids"`
+		} `json:"flags"`
+		Threats []struct {
+			Category   string   `json:"category"`
+			Details    string   `json:"details"`
+			ProductIDs []string `json:"product_ids"`
+		} `json:"threats"`
+	} `json:"vulnerabilities"`
+}
+
+func parseCSAF(data []byte) (*Document, error) {
+	var raw csafDoc
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+
+	products := make(map[string]string) // product_id -> purl or name
+	addProduct := func(p csafProduct) {
+		if p.Helper.PURL != "" {
+			products[p.ProductID] = p.Helper.PURL
+		} else {
+			products[p.ProductID] = p.Name
+		}
+	}
+	var walk func([]csafBranch)
+	walk = func(branches []csafBranch) {
+		for _, b := range branches {
+			if b.Product != nil {
+				addProduct(*b.Product)
+			}
+			walk(b.Branches)
+		}
+	}
+	walk(raw.ProductTree.Branches)
+	for _, p := range raw.ProductTree.FullProductNames {
+		addProduct(p)
+	}
+
+	doc := &Document{}
+	for _, v := range raw.Vulnerabilities {
+		vulnID := v.CVE
+		var aliases []string
+		for _, id := range v.IDs {
+			if vulnID == "" {
+				vulnID = id.Text
+			} else {
+				aliases = append(aliases, id.Text)
+			}
+		}
+		if vulnID == "" {
+			continue
+		}
+		for _, group := range []struct {
+			status string
+			ids    []string
+		}{
+			// not_affected first, so a product also listed under another
+			// status is not suppressed.
+			{StatusNotAffected, v.ProductStatus.KnownNotAffected},
+			{StatusAffected, v.ProductStatus.KnownAffected},
+			{StatusFixed, v.ProductStatus.Fixed},
+			{StatusUnderInvestigation, v.ProductStatus.UnderInvestigation},
+		} {
+			for _, pid := range group.ids {
+				st := Statement{
+					Vulnerability: vulnID,
+					Aliases:       aliases,
+					Products:      []string{pid},
+					Status:        group.status,
+				}
+				if ref := products[pid]; ref != "" {
+					st.Products = []string{ref}
+				}
+				for _, f := range v.Flags {
+					if slices.Contains(f.ProductIDs, pid) {
+						st.Justification = f.Label
+					}
+				}
+				for _, t := range v.Threats {
+					if t.Category == "impact" && slices.Contains(t.ProductIDs, pid) {
+						st.Impact = t.Details
+					}
+				}
+				doc.Statements = append(doc.Statements, st)
+			}
+		}
+	}
+	return doc, nil
+}
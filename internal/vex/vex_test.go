@@ -0,0 +1,105 @@
+package vex
+
+import "testing"
+
+const openVEX = `{
+  "@context": "https://openvex.dev/ns/v0.2.0",
+  "@id": "https://example.com/vex/2026-001",
+  "statements": [
+    {
+      "vulnerability": {"name": "CVE-2026-1111", "aliases": ["GHSA-aaaa-bbbb-cccc"]},
+      "products": [{"@id": "pkg:golang/github.com/foo/bar@v1.2.3"}],
+      "status": "not_affected",
+      "justification": "vulnerable_code_not_in_execute_path"
+    },
+    {
+      "vulnerability": {"name": "CVE-2026-2222"},
+      "products": [{"@id": "pkg:golang/github.com/foo/bar"}],
+      "status": "not_affected",
+      "justification": "component_not_present"
+    },
+    {
+      "vulnerability": {"name": "CVE-2026-2222"},
+      "products": [{"@id": "pkg:golang/github.com/foo/bar@v2.0.0"}],
+      "status": "affected"
+    }
+  ]
+}`
+
+func TestOpenVEXNotAffected(t *testing.T) {
+	doc, err := Parse([]byte(openVEX))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	st, ok := doc.NotAffected([]string{"GO-2026-0001", "CVE-2026-1111"}, "github.com/foo/bar", "v1.2.3")
+	if !ok {
+		t.Fatal("CVE-2026-1111 should be not_affected for github.com/foo/bar@v1.2.3")
+	}
+	if st.Justification != "vulnerable_code_not_in_execute_path" {
+		t.Errorf("justification = %q", st.Justification)
+	}
+
+	// Matched through the statement's alias.
+	if _, ok := doc.NotAffected([]string{"GHSA-aaaa-bbbb-cccc"}, "github.com/foo/bar", "v1.2.3"); !ok {
+		t.Error("alias GHSA-aaaa-bbbb-cccc should match")
+	}
+	// Version-pinned product does not cover other versions.
+	if _, ok := doc.NotAffected([]string{"CVE-2026-1111"}, "github.com/foo/bar", "v1.3.0"); ok {
+		t.Error("CVE-2026-1111 statement is pinned to v1.2.3")
+	}
+	// Other modules are never matched.
+	if _, ok := doc.NotAffected([]string{"CVE-2026-1111"}, "github.com/foo/baz", "v1.2.3"); ok {
+		t.Error("statement must not match a different product")
+	}
+	// Unversioned product covers every version; a later "affected"
+	// statement overrides it for v2.0.0.
+	if _, ok := doc.NotAffected([]string{"CVE-2026-2222"}, "github.com/foo/bar", "v1.9.0"); !ok {
+		t.Error("unversioned statement should cover v1.9.0")
+	}
+	if _, ok := doc.NotAffected([]string{"CVE-2026-2222"}, "github.com/foo/bar", "v2.0.0"); ok {
+		t.Error("later affected statement should win for v2.0.0")
+	}
+}
+
+const csafVEX = `{
+  "document": {"category": "csaf_vex", "title": "bar advisory"},
+  "product_tree": {
+    "branches": [{
+      "category": "vendor", "name": "foo",
+      "branches": [{
+        "category": "product_version", "name": "1.2.3",
+        "product": {
+          "product_id": "BAR-123", "name": "bar 1.2.3",
+          "product_identification_helper": {"purl": "pkg:golang/github.com/foo/bar@v1.2.3"}
+        }
+      }]
+    }]
+  },
+  "vulnerabilities": [{
+    "cve": "CVE-2026-3333",
+    "product_status": {"known_not_affected": ["BAR-123"]},
+    "flags": [{"label": "inline_mitigations_already_exist", "product_ids": ["BAR-123"]}],
+    "threats": [{"category": "impact", "details": "input is validated upstream", "product_ids": ["BAR-123"]}]
+  }]
+}`
+
+func TestCSAFNotAffected(t *testing.T) {
+	doc, err := Parse([]byte(csafVEX))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	st, ok := doc.NotAffected([]string{"CVE-2026-3333"}, "github.com/foo/bar", "v1.2.3")
+	if !ok {
+		t.Fatal("CVE-2026-3333 should be not_affected via the product tree purl")
+	}
+	if st.Justification != "inline_mitigations_already_exist" || st.Impact != "input is validated upstream" {
+		t.Errorf("statement = %+v", st)
+	}
+}
+
+func TestParseRejectsUnknownDocument(t *testing.T) {
+	if _, err := Parse([]byte(`{"bomFormat": "CycloneDX"}`)); err == nil {
+		t.Error("expected an error for a non-VEX document")
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/1homsi/gorisk/internal/analyzer"
 	"github.com/1homsi/gorisk/internal/astpipeline"
 	"github.com/1homsi/gorisk/internal/capability"
+	"github.com/1homsi/gorisk/internal/interproc"
 	"github.com/1homsi/gorisk/internal/priority"
 	"github.com/1homsi/gorisk/internal/taint"
 )
@@ -21,8 +22,15 @@ type ScanOptions struct {
 	Lang string
 	// Policy drives enforcement. Defaults to DefaultPolicy().
 	Policy Policy
+	// Cache, when set, holds interprocedural function summaries instead of
+	// the on-disk cache. Long-running processes that scan many projects can
+	// share one NewMemoryCache across Scanners.
+	Cache SummaryCache
 }
 
+// SummaryCache stores interprocedural function summaries between scans.
+type SummaryCache = interproc.SummaryCache
+
 // Scanner analyses a project directory for dependency risk.
 type Scanner struct {
 	opts ScanOptions
@@ -43,6 +51,13 @@ func NewScanner(opts ScanOptions) *Scanner {
 	return &Scanner{opts: opts}
 }
 
+// NewMemoryCache returns an in-memory SummaryCache of at most maxEntries
+// summaries (a default bound when maxEntries <= 0) that is safe to share
+// between concurrent scans.
+func NewMemoryCache(maxEntries int) SummaryCache {
+	return interproc.NewMemoryCache(maxEntries)
+}
+
 // Scan performs the full risk analysis pipeline and returns a ScanResult.
 func (s *Scanner) Scan() (*ScanResult, error) {
 	dir := s.opts.Dir
@@ -67,10 +82,10 @@ func (s *Scanner) Scan() (*ScanResult, error) {
 
 	// AST interprocedural pipeline + taint analysis.
 	resolvedLang := analyzer.ResolveLang(lang, dir)
-	astResult := astpipeline.Analyze(dir, resolvedLang, g)
-	taintFindings := taint.Analyze(g.Packages)
+	astResult := astpipeline.Analyze(dir, resolvedLang, g, astpipeline.Options{Cache: s.opts.Cache})
+	taintFindings := taint.Analyze(g.Packages, taint.Options{})
 	if astResult.UsedInterproc && len(astResult.Bundle.TaintFindings) > 0 {
-		taintFindings = astResult.Bundle.TaintFindings
+		taintFindings = taint.WithPackageLevel(astResult.Bundle.TaintFindings, taintFindings)
 	}
 
 	failLevel := capability.RiskValue(s.opts.Policy.FailOn)